@@ -0,0 +1,166 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeBackupMethod is how the schedules back up a persistent volume claim
+// +kubebuilder:validation:Enum=CSISnapshot;DataMover;FSBackup;None;Unknown
+type VolumeBackupMethod string
+
+const (
+	// VolumeBackupMethodCSISnapshot backs up the volume with a CSI snapshot
+	VolumeBackupMethodCSISnapshot VolumeBackupMethod = "CSISnapshot"
+	// VolumeBackupMethodDataMover moves the data of a CSI snapshot of the volume to the backup storage location
+	VolumeBackupMethodDataMover VolumeBackupMethod = "DataMover"
+	// VolumeBackupMethodFSBackup backs up the files of the volume with the node agent
+	VolumeBackupMethodFSBackup VolumeBackupMethod = "FSBackup"
+	// VolumeBackupMethodNone does not back up the volume data
+	VolumeBackupMethodNone VolumeBackupMethod = "None"
+	// VolumeBackupMethodUnknown backs up the volume data with a method the operator cannot determine,
+	// like a volume snapshot location of the volume provider, or not at all
+	VolumeBackupMethodUnknown VolumeBackupMethod = "Unknown"
+)
+
+// BackupCoverageReportSpec defines the namespaces to report the backup coverage of
+type BackupCoverageReportSpec struct {
+	// includedNamespaces are the namespaces to report on, wildcards are supported. By default every namespace.
+	// +optional
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	// excludedNamespaces are the namespaces not to report on, wildcards are supported.
+	// By default openshift, openshift-* and kube-*
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+	// staleThreshold is the age of the last successful backup of a namespace after which its protection is stale.
+	// By default 25h
+	// +optional
+	StaleThreshold *metav1.Duration `json:"staleThreshold,omitempty"`
+	// refreshPeriod is how often the report is computed again. By default 1h
+	// +optional
+	RefreshPeriod *metav1.Duration `json:"refreshPeriod,omitempty"`
+}
+
+// BackupCoverageReportStatus is the backup coverage of the namespaces and persistent volume claims.
+// Only the namespaces and persistent volume claims needing attention are listed, the others are counted in the summary.
+type BackupCoverageReportStatus struct {
+	// Conditions is the reconcile status of the BackupCoverageReport
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// LastReportTime is the last time the report was computed
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	LastReportTime *metav1.Time `json:"lastReportTime,omitempty"`
+	// Summary counts the namespaces and persistent volume claims by protection
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Summary CoverageSummary `json:"summary,omitempty"`
+	// UnprotectedNamespaces are the first 100 namespaces by name no schedule backs up, Summary counts them all
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:MaxItems=100
+	// +optional
+	UnprotectedNamespaces []string `json:"unprotectedNamespaces,omitempty"`
+	// StaleNamespaces are the first 100 namespaces by name whose last successful backup is older than the stale
+	// threshold, Summary counts them all
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:MaxItems=100
+	// +optional
+	StaleNamespaces []StaleNamespace `json:"staleNamespaces,omitempty"`
+	// PersistentVolumeClaims are the first 100 persistent volume claims by namespace and name of the protected
+	// namespaces whose data is not backed up, or backed up with an unknown method. Summary counts them all.
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +kubebuilder:validation:MaxItems=100
+	// +optional
+	PersistentVolumeClaims []PersistentVolumeClaimCoverage `json:"persistentVolumeClaims,omitempty"`
+}
+
+// CoverageSummary counts the namespaces and persistent volume claims by protection
+type CoverageSummary struct {
+	// Namespaces is the number of namespaces reported on
+	Namespaces int32 `json:"namespaces"`
+	// ProtectedNamespaces is the number of namespaces backed up by a schedule, including the stale namespaces
+	ProtectedNamespaces int32 `json:"protectedNamespaces"`
+	// StaleNamespaces is the number of protected namespaces whose last successful backup is too old
+	StaleNamespaces int32 `json:"staleNamespaces"`
+	// UnprotectedNamespaces is the number of namespaces no schedule backs up
+	UnprotectedNamespaces int32 `json:"unprotectedNamespaces"`
+	// PersistentVolumeClaims is the number of persistent volume claims in the protected namespaces
+	PersistentVolumeClaims int32 `json:"persistentVolumeClaims"`
+	// UnprotectedPersistentVolumeClaims is the number of persistent volume claims whose data is not backed up
+	UnprotectedPersistentVolumeClaims int32 `json:"unprotectedPersistentVolumeClaims"`
+	// UnknownBackupMethodPersistentVolumeClaims is the number of persistent volume claims backed up with an unknown method
+	UnknownBackupMethodPersistentVolumeClaims int32 `json:"unknownBackupMethodPersistentVolumeClaims"`
+}
+
+// StaleNamespace is a namespace whose last successful backup is older than the stale threshold
+type StaleNamespace struct {
+	// Namespace is the name of the namespace
+	Namespace string `json:"namespace"`
+	// Schedules are the schedules backing up the namespace
+	Schedules []string `json:"schedules"`
+	// LastSuccessfulBackup is the completion time of the last completed backup of the schedules, not set without one
+	// +optional
+	LastSuccessfulBackup *metav1.Time `json:"lastSuccessfulBackup,omitempty"`
+}
+
+// PersistentVolumeClaimCoverage is how the schedules back up a persistent volume claim
+type PersistentVolumeClaimCoverage struct {
+	// Namespace is the namespace of the persistent volume claim
+	Namespace string `json:"namespace"`
+	// Name is the name of the persistent volume claim
+	Name string `json:"name"`
+	// BackupMethod is how the schedules back up the persistent volume claim
+	BackupMethod VolumeBackupMethod `json:"backupMethod"`
+	// Reason explains the backup method
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:printcolumn:name="Namespaces",type=integer,JSONPath=".status.summary.namespaces",description="Namespaces reported on"
+// +kubebuilder:printcolumn:name="Unprotected",type=integer,JSONPath=".status.summary.unprotectedNamespaces",description="Namespaces no schedule backs up"
+// +kubebuilder:printcolumn:name="Stale",type=integer,JSONPath=".status.summary.staleNamespaces",description="Namespaces whose last successful backup is too old"
+// +kubebuilder:printcolumn:name="PVCs",type=integer,JSONPath=".status.summary.persistentVolumeClaims",description="Persistent volume claims in the protected namespaces"
+// +kubebuilder:printcolumn:name="UnprotectedPVCs",type=integer,JSONPath=".status.summary.unprotectedPersistentVolumeClaims",description="Persistent volume claims whose data is not backed up"
+// +kubebuilder:printcolumn:name="UnknownPVCs",type=integer,JSONPath=".status.summary.unknownBackupMethodPersistentVolumeClaims",description="Persistent volume claims backed up with an unknown method",priority=1
+// +kubebuilder:printcolumn:name="LastReport",type=date,JSONPath=".status.lastReportTime",description="Last time the report was computed"
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=backupcoveragereports,shortName=bcr
+
+// BackupCoverageReport is the Schema for the backupcoveragereports API
+type BackupCoverageReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupCoverageReportSpec   `json:"spec,omitempty"`
+	Status BackupCoverageReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BackupCoverageReportList contains a list of BackupCoverageReport
+type BackupCoverageReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupCoverageReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupCoverageReport{}, &BackupCoverageReportList{})
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupPolicySpec defines the scheduled backups of a set of namespaces and how long they are retained.
+type BackupPolicySpec struct {
+	// schedule is the cron expression (https://en.wikipedia.org/wiki/Cron#Overview) of the backups
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// includedNamespaces are the namespaces to back up, wildcards are supported.
+	// +kubebuilder:validation:MinItems=1
+	IncludedNamespaces []string `json:"includedNamespaces"`
+
+	// template is the spec of the scheduled backups.
+	// includedNamespaces and ttl are set by the policy and ignored in the template.
+	// +optional
+	Template velerov1.BackupSpec `json:"template,omitempty"`
+
+	// retention defines the backups kept when pruning the backups of the policy.
+	// A backup is kept when any of the retention rules selects it, every other backup of the policy is deleted.
+	Retention BackupRetention `json:"retention"`
+
+	// paused stops the policy from creating new backups. Existing backups are still pruned.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// BackupRetention defines the grandfather-father-son retention of the backups of a policy.
+// Each of hourly, daily, weekly, monthly and yearly keeps the most recent backup of each of the
+// last n hours, days, ISO weeks, months and years that have a backup.
+// Only completed and partially failed backups are retained, failed backups are deleted once a newer backup completes.
+type BackupRetention struct {
+	// last is the number of most recent backups to keep
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Last int32 `json:"last,omitempty"`
+	// hourly is the number of hours to keep the most recent backup of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Hourly int32 `json:"hourly,omitempty"`
+	// daily is the number of days to keep the most recent backup of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Daily int32 `json:"daily,omitempty"`
+	// weekly is the number of weeks to keep the most recent backup of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weekly int32 `json:"weekly,omitempty"`
+	// monthly is the number of months to keep the most recent backup of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Monthly int32 `json:"monthly,omitempty"`
+	// yearly is the number of years to keep the most recent backup of
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Yearly int32 `json:"yearly,omitempty"`
+	// within keeps every backup started within the duration before the last backup, e.g. "48h"
+	// +optional
+	Within *metav1.Duration `json:"within,omitempty"`
+}
+
+// BackupPolicyStatus defines the observed state of BackupPolicy
+type BackupPolicyStatus struct {
+	// Conditions is the reconcile status of the BackupPolicy
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Schedule is the name of the Velero Schedule creating the backups of the policy
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// LastPruneTime is the last time the backups of the policy were pruned
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	LastPruneTime *metav1.Time `json:"lastPruneTime,omitempty"`
+	// RetainedBackups is the number of backups kept by the last prune
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	RetainedBackups int32 `json:"retainedBackups,omitempty"`
+	// PrunedBackups is the number of backups deleted by the last prune
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	PrunedBackups int32 `json:"prunedBackups,omitempty"`
+}
+
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=".spec.schedule",description="Cron expression of the backups"
+// +kubebuilder:printcolumn:name="Reconciled",type=string,JSONPath=".status.conditions[?(@.type=='Reconciled')].status",description="BackupPolicy Reconciled Status"
+// +kubebuilder:printcolumn:name="Retained",type=integer,JSONPath=".status.retainedBackups",description="Backups kept by the last prune"
+// +kubebuilder:printcolumn:name="LastPrune",type=date,JSONPath=".status.lastPruneTime",description="Last time the backups were pruned"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp",description="Time since the BackupPolicy was created"
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=backuppolicies,shortName=bp
+
+// BackupPolicy is the Schema for the backuppolicies API
+type BackupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupPolicySpec   `json:"spec,omitempty"`
+	Status BackupPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BackupPolicyList contains a list of BackupPolicy
+type BackupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupPolicy{}, &BackupPolicyList{})
+}
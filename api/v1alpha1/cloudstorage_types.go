@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -29,6 +31,25 @@ const (
 	GCPBucketProvider   CloudStorageProvider = CloudStorageProvider(DefaultPluginGCP)
 )
 
+// +kubebuilder:validation:Enum=STANDARD_IA;ONEZONE_IA;INTELLIGENT_TIERING;GLACIER_IR
+type LifecycleTransitionStorageClass string
+
+const (
+	StandardIAStorageClass         LifecycleTransitionStorageClass = "STANDARD_IA"
+	OneZoneIAStorageClass          LifecycleTransitionStorageClass = "ONEZONE_IA"
+	IntelligentTieringStorageClass LifecycleTransitionStorageClass = "INTELLIGENT_TIERING"
+	GlacierIRStorageClass          LifecycleTransitionStorageClass = "GLACIER_IR"
+)
+
+// Lifecycle rules condition
+const ConditionLifecycleRulesApplied = "LifecycleRulesApplied"
+const LifecycleRulesReasonApplied = "Applied"
+const LifecycleRulesReasonInvalid = "Invalid"
+const LifecycleRulesReasonApplyFailed = "ApplyFailed"
+
+// DefaultUsageReportingPeriod is how often the bucket usage is computed when not set in the CloudStorage
+const DefaultUsageReportingPeriod = time.Hour
+
 type CloudStorageSpec struct {
 	// name is the name requested for the bucket (aws, gcp) or container (azure)
 	Name string `json:"name"`
@@ -44,6 +65,16 @@ type CloudStorageSpec struct {
 	// provider is the provider of the cloud storage
 	// +kubebuilder:validation:Enum=aws
 	Provider CloudStorageProvider `json:"provider"`
+	// lifecycleRules defines the object lifecycle configuration of the bucket.
+	// When set, the operator owns the bucket lifecycle configuration and replaces any rules set outside of it.
+	// When not set, the bucket lifecycle configuration is left untouched.
+	// +optional
+	LifecycleRules *LifecycleRules `json:"lifecycleRules,omitempty"`
+	// usageReportingPeriod defines how often the total size and object count of the bucket are computed.
+	// Computing the usage lists every object in the bucket. A value of 0 disables usage reporting.
+	// By default 1h
+	// +optional
+	UsageReportingPeriod *metav1.Duration `json:"usageReportingPeriod,omitempty"`
 
 	// https://pkg.go.dev/github.com/Azure/azure-sdk-for-go/sdk/storage/azblob@v0.2.0#section-readme
 	// azure blob primary endpoint
@@ -53,6 +84,33 @@ type CloudStorageSpec struct {
 	// azure account key will use CreationSecret to store key and account name
 }
 
+// LifecycleRules defines the lifecycle rules applied to every object in the bucket
+type LifecycleRules struct {
+	// abortIncompleteMultipartUploadDays is the number of days after initiation after which an incomplete multipart upload is aborted
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	AbortIncompleteMultipartUploadDays *int64 `json:"abortIncompleteMultipartUploadDays,omitempty"`
+	// noncurrentVersionExpirationDays is the number of days after an object version becomes noncurrent after which it is permanently deleted
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NoncurrentVersionExpirationDays *int64 `json:"noncurrentVersionExpirationDays,omitempty"`
+	// transition moves objects to an infrequent-access storage class
+	// +optional
+	Transition *LifecycleTransition `json:"transition,omitempty"`
+}
+
+// LifecycleTransition defines when objects are moved to another storage class
+type LifecycleTransition struct {
+	// days is the number of days after object creation after which objects are transitioned.
+	// Must be at least 30 for STANDARD_IA and ONEZONE_IA, and less than the backup TTLs configured in the DataProtectionApplications using this bucket.
+	// +kubebuilder:validation:Minimum=0
+	Days int64 `json:"days"`
+	// storageClass is the storage class objects are transitioned to, will be STANDARD_IA if not set.
+	// +kubebuilder:default=STANDARD_IA
+	// +optional
+	StorageClass LifecycleTransitionStorageClass `json:"storageClass,omitempty"`
+}
+
 type CloudStorageStatus struct {
 	// Name is the name requested for the bucket (aws, gcp) or container (azure)
 	// +operator-sdk:csv:customresourcedefinitions:type=status
@@ -60,6 +118,43 @@ type CloudStorageStatus struct {
 	// LastSyncTimestamp is the last time the contents of the CloudStorage was synced
 	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="LastSyncTimestamp"
 	LastSynced *metav1.Time `json:"lastSyncTimestamp,omitempty"`
+	// Usage is the last computed size and object count of the bucket
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Usage *CloudStorageUsage `json:"usage,omitempty"`
+	// Conditions is the status of the lifecycle rules of the bucket
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CloudStorageUsage is the size and object count of a bucket
+type CloudStorageUsage struct {
+	// LastComputed is the last time the usage of the bucket was computed
+	// +optional
+	LastComputed *metav1.Time `json:"lastComputed,omitempty"`
+	// TotalSizeBytes is the total size in bytes of the objects in the bucket
+	// +optional
+	TotalSizeBytes int64 `json:"totalSizeBytes,omitempty"`
+	// ObjectCount is the number of objects in the bucket
+	// +optional
+	ObjectCount int64 `json:"objectCount,omitempty"`
+	// Prefixes is the size and object count of the bucket broken down by top-level prefix
+	// (backups/, kopia/, restic/, under the prefix of each backup location using the bucket)
+	// +optional
+	Prefixes []PrefixUsage `json:"prefixes,omitempty"`
+}
+
+// PrefixUsage is the size and object count of the objects under a prefix
+type PrefixUsage struct {
+	// Prefix is the object key prefix
+	Prefix string `json:"prefix"`
+	// SizeBytes is the total size in bytes of the objects under the prefix
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// ObjectCount is the number of objects under the prefix
+	// +optional
+	ObjectCount int64 `json:"objectCount,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -32,10 +32,51 @@ import (
 const ConditionReconciled = "Reconciled"
 const ReconciledReasonComplete = "Complete"
 const ReconciledReasonError = "Error"
+const ReconciledReasonDryRun = "DryRun"
+const ReconciledReasonPaused = "Paused"
 const ReconcileCompleteMessage = "Reconcile complete"
+const ReconcileDryRunMessage = "Dry-run annotation is set, changes are planned in status.plan but not applied"
+const ReconcilePausedMessage = "Reconcile is paused, changes are planned in status.plan but not applied"
+
+// Per-component readiness conditions
+const ConditionVeleroReady = "VeleroReady"
+const ConditionNodeAgentReady = "NodeAgentReady"
+const ConditionNonAdminReady = "NonAdminReady"
+const ConditionBackupStorageLocationsAvailable = "BackupStorageLocationsAvailable"
+const ConditionVolumeSnapshotLocationsValid = "VolumeSnapshotLocationsValid"
+const ReadyReasonRolloutComplete = "RolloutComplete"
+const ReadyReasonRolloutInProgress = "RolloutInProgress"
+const ReadyReasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+const ReadyReasonNotFound = "NotFound"
+const AvailableReasonAvailable = "Available"
+const AvailableReasonUnavailable = "Unavailable"
+const AvailableReasonPendingValidation = "PendingValidation"
+const ValidReasonValid = "Valid"
+const ValidReasonInvalid = "Invalid"
+
+// Rollout gate condition
+const ConditionRolloutDeferred = "RolloutDeferred"
+const RolloutDeferredReasonOperationsInProgress = "OperationsInProgress"
+const RolloutDeferredReasonOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+
+// Credentials health condition, reasons in order of precedence
+const ConditionCredentialsHealthy = "CredentialsHealthy"
+const CredentialsReasonHealthy = "Healthy"
+const CredentialsReasonInvalid = "Invalid"
+const CredentialsReasonExpired = "Expired"
+const CredentialsReasonInsufficientPermissions = "InsufficientPermissions"
+const CredentialsReasonExpiring = "Expiring"
 
 const OadpOperatorLabel = "openshift.io/oadp"
 
+// DryRunAnnotation set to "true" on a DPA makes the operator compute the changes it would
+// make in status.plan instead of applying them
+const DryRunAnnotation = "oadp.openshift.io/dry-run"
+
+// GCDryRunAnnotation set to "true" on a DPA makes the operator report the orphaned resources it would
+// delete in an event instead of deleting them
+const GCDryRunAnnotation = "oadp.openshift.io/gc-dry-run"
+
 // +kubebuilder:validation:Enum=aws;legacy-aws;gcp;azure;csi;vsm;openshift;kubevirt;hypershift
 type DefaultPlugin string
 
@@ -52,6 +93,23 @@ const DefaultPluginHypershift DefaultPlugin = "hypershift"
 type CustomPlugin struct {
 	Name  string `json:"name"`
 	Image string `json:"image"`
+	// snapshotProviders declares the VolumeSnapshotLocation providers implemented by the plugin, like velero.io/vsphere,
+	// so snapshot locations of these providers are valid
+	// +optional
+	SnapshotProviders []SnapshotProvider `json:"snapshotProviders,omitempty"`
+}
+
+// SnapshotProvider is a VolumeSnapshotLocation provider of a custom plugin
+type SnapshotProvider struct {
+	// name of the provider, the provider of the snapshot locations
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// configKeys are the valid config keys of the snapshot locations of the provider, any key is valid when empty
+	// +optional
+	ConfigKeys []string `json:"configKeys,omitempty"`
+	// requiredConfigKeys are the config keys the snapshot locations of the provider must set
+	// +optional
+	RequiredConfigKeys []string `json:"requiredConfigKeys,omitempty"`
 }
 
 type LogFormat string
@@ -291,6 +349,11 @@ type VeleroConfig struct {
 	// If you need to install Velero without a default backup storage location noDefaultBackupLocation flag is required for confirmation
 	// +optional
 	NoDefaultBackupLocation bool `json:"noDefaultBackupLocation,omitempty"`
+	// mountLocationCredentials mounts the secret of each credential of the backup and snapshot locations in its own
+	// directory of the Velero and NodeAgent pods, and sets the credentialsFile of the BSL or VSL to its key, so locations
+	// of one provider use separate secrets instead of profiles of the default secret.
+	// +optional
+	MountLocationCredentials *bool `json:"mountLocationCredentials,omitempty"`
 	// Pod specific configuration
 	PodConfig *PodConfig `json:"podConfig,omitempty"`
 	// Velero server's log level (use debug for the most logging, leave unset for velero default)
@@ -564,8 +627,82 @@ type BackupLocation struct {
 	Velero *velero.BackupStorageLocationSpec `json:"velero,omitempty"`
 	// +optional
 	CloudStorage *CloudStorageLocation `json:"bucket,omitempty"`
+	// s3Vendor is the vendor of the S3 compatible storage of an aws backup location. When set, the config keys the vendor
+	// requires are validated and defaulted. When not set, or set to Generic, no vendor config is validated or defaulted.
+	// +optional
+	S3Vendor S3Vendor `json:"s3Vendor,omitempty"`
+	// secretProviderClass mounts the credentials of the location from a Secrets Store CSI SecretProviderClass
+	// instead of a Secret. Mutually exclusive with velero.credential.
+	// +optional
+	SecretProviderClass *SecretProviderClassCredential `json:"secretProviderClass,omitempty"`
+	// replication copies the backups and the Kopia repositories of the location to a secondary bucket, for example
+	// in another region or account, which a recovery cluster can use as a read-only backup location.
+	// Only supported by aws velero backup locations.
+	// +optional
+	Replication *BackupLocationReplication `json:"replication,omitempty"`
+}
+
+// BackupLocationReplication copies the objects of a backup location to a secondary bucket
+type BackupLocationReplication struct {
+	// target is the secondary bucket
+	Target ReplicationTarget `json:"target"`
+	// interval is how often new backups and Kopia repository blobs are copied. Completed backups of the location
+	// are also copied when they complete. Defaults to 15m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// prune deletes from the target the backups deleted from the backup location, like expired backups, so a
+	// recovery cluster does not sync them. The Kopia repository blobs are kept. The credentials of the target need
+	// the s3:DeleteObject permission.
+	// +optional
+	Prune bool `json:"prune,omitempty"`
 }
 
+// ReplicationTarget is the S3 compatible bucket a backup location is replicated to
+type ReplicationTarget struct {
+	// bucket is the name of the secondary bucket
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+	// prefix is the path inside the bucket the objects are copied under
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// caCert is a CA bundle to use when verifying TLS connections to the endpoint of the bucket
+	// +optional
+	CACert []byte `json:"caCert,omitempty"`
+	// config holds the config keys of an aws backup location used to access the bucket:
+	// region, s3Url, s3ForcePathStyle, insecureSkipTLSVerify and profile
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+	// credential is the secret key of the credentials of the bucket. Defaults to the cloud key of the cloud-credentials secret.
+	// +optional
+	Credential *corev1.SecretKeySelector `json:"credential,omitempty"`
+}
+
+// SecretProviderClassCredential is a credentials file mounted from a Secrets Store CSI SecretProviderClass
+type SecretProviderClassCredential struct {
+	// name of the SecretProviderClass in the namespace of the DataProtectionApplication
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// file is the credentials file in the volume of the SecretProviderClass, the objectAlias or objectName of
+	// the secret object holding the credentials
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^/]+$`
+	File string `json:"file"`
+}
+
+// S3Vendor is the vendor of the S3 compatible storage of an aws backup location
+// +kubebuilder:validation:Enum=AWS;MinIO;Ceph;NooBaa;StorageGRID;Generic
+type S3Vendor string
+
+const (
+	S3VendorAWS         S3Vendor = "AWS"
+	S3VendorMinIO       S3Vendor = "MinIO"
+	S3VendorCeph        S3Vendor = "Ceph"
+	S3VendorNooBaa      S3Vendor = "NooBaa"
+	S3VendorStorageGRID S3Vendor = "StorageGRID"
+	// S3VendorGeneric is an S3 compatible storage validated like AWS, without vendor specific config keys
+	S3VendorGeneric S3Vendor = "Generic"
+)
+
 // SnapshotLocation defines the configuration for the DPA snapshot store
 type SnapshotLocation struct {
 	// TODO: Add name/annotations/labels support
@@ -573,6 +710,10 @@ type SnapshotLocation struct {
 	// +optional
 	Name   string                             `json:"name,omitempty"`
 	Velero *velero.VolumeSnapshotLocationSpec `json:"velero"`
+	// secretProviderClass mounts the credentials of the location from a Secrets Store CSI SecretProviderClass
+	// instead of a Secret. Mutually exclusive with velero.credential.
+	// +optional
+	SecretProviderClass *SecretProviderClassCredential `json:"secretProviderClass,omitempty"`
 }
 
 // We need to create enforcement structures for the BSL spec fields, because the Velero BSL spec
@@ -695,6 +836,7 @@ type DataMover struct {
 	// +optional
 	VolumeOptionsForStorageClasses map[string]DataMoverVolumeOptions `json:"volumeOptionsForStorageClasses,omitempty"`
 	// defines the parameters that can be specified for retention of datamover snapshots
+	// Deprecated: not used, create a BackupPolicy to retain backups by hourly, daily, weekly, monthly and yearly tiers
 	// +optional
 	SnapshotRetainPolicy *RetainPolicy `json:"snapshotRetainPolicy,omitempty"`
 	// schedule is a cronspec (https://en.wikipedia.org/wiki/Cron#Overview) that
@@ -702,6 +844,7 @@ type DataMover struct {
 	// intervals. For example, in order to enforce datamover SnapshotRetainPolicy at a regular interval you need to
 	// specify this Schedule trigger as a cron expression, by default the trigger is a manual trigger. For more details
 	// on Volsync triggers, refer: https://volsync.readthedocs.io/en/stable/usage/triggers.html
+	// Deprecated: not used, create a BackupPolicy to schedule backups
 	//+kubebuilder:validation:Pattern=`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`
 	//+optional
 	Schedule string `json:"schedule,omitempty"`
@@ -764,6 +907,120 @@ type Features struct {
 	DataMover *DataMover `json:"dataMover,omitempty"`
 }
 
+// RolloutGate defines how disruptive changes to the Velero Deployment and NodeAgent DaemonSet
+// wait for in-progress backup and restore operations
+type RolloutGate struct {
+	// timeout is the maximum time a disruptive change is deferred while operations are in progress,
+	// after which it is applied anyway. Set to 0s to apply changes without waiting. (default 1h)
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring time window for changes that restart the Velero or NodeAgent pods
+type MaintenanceWindow struct {
+	// schedule is a cronspec (https://en.wikipedia.org/wiki/Cron#Overview) of the times the window opens,
+	// for example "0 2 * * 6" for every Saturday at 02:00 UTC
+	Schedule string `json:"schedule"`
+	// duration is how long the window stays open
+	Duration metav1.Duration `json:"duration"`
+}
+
+// Monitoring defines the Prometheus Operator resources created for the data protection application
+type Monitoring struct {
+	// serviceMonitors creates ServiceMonitors scraping the metrics of Velero, NodeAgent and the non-admin controller
+	// +optional
+	ServiceMonitors bool `json:"serviceMonitors,omitempty"`
+	// prometheusRule creates a PrometheusRule alerting on failed backups, unavailable BackupStorageLocations,
+	// NodeAgent pods that are not ready and schedules without a recent successful backup
+	// +optional
+	PrometheusRule bool `json:"prometheusRule,omitempty"`
+	// staleScheduleThreshold is how long a schedule can go without a successful backup before it is alerted on.
+	// Defaults to 25h.
+	// +optional
+	StaleScheduleThreshold *metav1.Duration `json:"staleScheduleThreshold,omitempty"`
+	// dashboards creates ConfigMaps with dashboards of backup and restore success rates and durations, data mover
+	// throughput, repository maintenance and DataProtectionTest results, labelled for the OpenShift console and Grafana sidecars
+	// +optional
+	Dashboards bool `json:"dashboards,omitempty"`
+	// dashboardsNamespace is the namespace of the dashboard ConfigMaps. The OpenShift console only reads dashboards
+	// from openshift-config-managed. Defaults to the DPA namespace.
+	// +optional
+	DashboardsNamespace string `json:"dashboardsNamespace,omitempty"`
+}
+
+// NotificationEvent is an event notified to the notification sinks
+// +kubebuilder:validation:Enum=BackupFailed;BackupPartiallyFailed;RestoreFailed;RestorePartiallyFailed;ScheduleMissed
+type NotificationEvent string
+
+const (
+	NotificationEventBackupFailed           NotificationEvent = "BackupFailed"
+	NotificationEventBackupPartiallyFailed  NotificationEvent = "BackupPartiallyFailed"
+	NotificationEventRestoreFailed          NotificationEvent = "RestoreFailed"
+	NotificationEventRestorePartiallyFailed NotificationEvent = "RestorePartiallyFailed"
+	// NotificationEventScheduleMissed is notified when a schedule has no backup for a run it should have started
+	NotificationEventScheduleMissed NotificationEvent = "ScheduleMissed"
+)
+
+// NotificationSinkType is the payload format of a notification sink
+// +kubebuilder:validation:Enum=Webhook;CloudEvents;Slack
+type NotificationSinkType string
+
+const (
+	// NotificationSinkWebhook posts a JSON document describing the event
+	NotificationSinkWebhook NotificationSinkType = "Webhook"
+	// NotificationSinkCloudEvents posts a CloudEvent in binary content mode
+	NotificationSinkCloudEvents NotificationSinkType = "CloudEvents"
+	// NotificationSinkSlack posts a Slack incoming webhook message, also accepted by Mattermost and Rocket.Chat
+	NotificationSinkSlack NotificationSinkType = "Slack"
+)
+
+// NotificationSink is a destination of the notifications
+type NotificationSink struct {
+	// name identifies the sink in the notification delivery status
+	Name string `json:"name"`
+	// type is the payload format of the sink
+	Type NotificationSinkType `json:"type"`
+	// url the notifications are posted to
+	// +optional
+	URL string `json:"url,omitempty"`
+	// urlSecret references the secret key holding the URL, for URLs embedding a token like Slack incoming webhooks
+	// +optional
+	URLSecret *corev1.SecretKeySelector `json:"urlSecret,omitempty"`
+	// headers are added to the notification requests
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// template is a Go template (https://pkg.go.dev/text/template) of the request body for Webhook sinks,
+	// of the event data for CloudEvents sinks and of the message text for Slack sinks.
+	// The template is executed with the fields Event, Kind, Namespace, Name, Phase, Schedule, Reason, Time and DataProtectionApplication.
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// Notifications defines the notifications sent for failed backups and restores and missed schedule runs
+type Notifications struct {
+	// sinks are the destinations of the notifications
+	Sinks []NotificationSink `json:"sinks"`
+	// events are the notified events. Defaults to every event.
+	// +optional
+	Events []NotificationEvent `json:"events,omitempty"`
+	// scheduleMissedGracePeriod is how long after a scheduled run its backup can start before ScheduleMissed is notified.
+	// Defaults to 1h.
+	// +optional
+	ScheduleMissedGracePeriod *metav1.Duration `json:"scheduleMissedGracePeriod,omitempty"`
+}
+
+// BackupLocationConnectivityCheck configures the connectivity check of the backup locations
+type BackupLocationConnectivityCheck struct {
+	// enable lists, writes and deletes an object under the prefix of each backup location with its credentials
+	// before its BackupStorageLocation is created or updated. Only AWS and S3 compatible locations are checked.
+	// +optional
+	Enable bool `json:"enable,omitempty"`
+	// interval is how long a passed check is valid before the location is checked again. A location is also checked
+	// again when it or its credentials change, and a failed check at every reconcile. Defaults to 1h.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
 // DataProtectionApplicationSpec defines the desired state of Velero
 type DataProtectionApplicationSpec struct {
 	// backupLocations defines the list of desired configuration to use for BackupStorageLocations
@@ -816,6 +1073,29 @@ type DataProtectionApplicationSpec struct {
 	// nonAdmin defines the configuration for the DPA to enable backup and restore operations for non-admin users
 	// +optional
 	NonAdmin *NonAdmin `json:"nonAdmin,omitempty"`
+	// rolloutGate defers changes that restart the Velero or NodeAgent pods while backups, restores
+	// and data movements are in progress
+	// +optional
+	RolloutGate *RolloutGate `json:"rolloutGate,omitempty"`
+	// maintenanceWindow restricts changes that restart the Velero or NodeAgent pods to a recurring time window.
+	// Outside the window, those changes are deferred until it opens.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+	// paused stops the operator from changing the resources it manages, for example to modify the Velero
+	// Deployment by hand. The DPA status is still updated, and status.plan lists the changes the operator would make.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// monitoring creates ServiceMonitors and a PrometheusRule for the data protection application.
+	// Requires the Prometheus Operator monitoring.coreos.com APIs.
+	// +optional
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+	// notifications sends notifications for failed backups and restores and missed schedule runs
+	// +optional
+	Notifications *Notifications `json:"notifications,omitempty"`
+	// backupLocationConnectivityCheck checks the credentials of the backup locations can access their bucket
+	// before the BackupStorageLocations are created, and reports the cause of the failures in the DPA status
+	// +optional
+	BackupLocationConnectivityCheck *BackupLocationConnectivityCheck `json:"backupLocationConnectivityCheck,omitempty"`
 	// The format for log output. Valid values are text, json. (default text)
 	// +kubebuilder:validation:Enum=text;json
 	// +kubebuilder:default=text
@@ -828,12 +1108,241 @@ type DataProtectionApplicationStatus struct {
 	// Conditions defines the observed state of DataProtectionApplication
 	//+operator-sdk:csv:customresourcedefinitions:type=status
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Plan is the list of changes the operator would make, computed when the DPA has the dry-run annotation or is paused
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Plan *DataProtectionApplicationPlan `json:"plan,omitempty"`
+	// ReconcileSteps is the result of each step of the last reconcile
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReconcileSteps []ReconcileStepStatus `json:"reconcileSteps,omitempty"`
+	// Notifications is the delivery status of each notification sink
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=sink
+	Notifications []NotificationDeliveryStatus `json:"notifications,omitempty"`
+	// BackupLocationConnectivity is the result of the connectivity check of each backup location
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	BackupLocationConnectivity []BackupLocationConnectivityStatus `json:"backupLocationConnectivity,omitempty"`
+	// BackupLocationReplication is the replication status of each replicated backup location
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	BackupLocationReplication []BackupLocationReplicationStatus `json:"backupLocationReplication,omitempty"`
+	// DashboardsNamespace is the namespace of the dashboard ConfigMaps created for the DPA
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	DashboardsNamespace string `json:"dashboardsNamespace,omitempty"`
+}
+
+// BackupLocationReplicationStatus is the replication status of a backup location
+type BackupLocationReplicationStatus struct {
+	// Name of the BackupStorageLocation of the backup location
+	Name string `json:"name"`
+	// Target is the URL of the secondary bucket and prefix, like s3://bucket/prefix
+	Target string `json:"target"`
+	// LastAttemptTime is the time of the last replication
+	// +optional
+	LastAttemptTime metav1.Time `json:"lastAttemptTime,omitempty"`
+	// LastSuccessTime is the time of the last replication copying every completed backup of the location
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+	// Message explains why the last replication failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// PendingBackups is the number of completed backups of the location not copied yet
+	// +optional
+	PendingBackups int `json:"pendingBackups,omitempty"`
+	// Backups is the replication of the most recent completed backups of the location
+	// +optional
+	Backups []BackupReplicationStatus `json:"backups,omitempty"`
+	// Resume is set when the last replication stopped at the deadline of its pass, the next one resumes it without
+	// waiting for the interval
+	// +optional
+	Resume bool `json:"resume,omitempty"`
+	// KopiaCheckpoint is the progress of the copy of the Kopia repositories of the location
+	// +optional
+	KopiaCheckpoint *KopiaReplicationCheckpoint `json:"kopiaCheckpoint,omitempty"`
+}
+
+// KopiaReplicationCheckpoint is the progress of the copy of the Kopia repositories of a backup location, which only
+// copies the objects modified since the last complete copy
+type KopiaReplicationCheckpoint struct {
+	// CopiedBefore is the start of the last complete copy, the objects modified before it are in the target
+	// +optional
+	CopiedBefore *metav1.Time `json:"copiedBefore,omitempty"`
+	// StartTime is the start of the copy in progress, not set when the last copy completed
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// ResumeAfter is the key, relative to the prefix of the location, of the last object of the copy in progress
+	// +optional
+	ResumeAfter string `json:"resumeAfter,omitempty"`
+}
+
+// BackupReplicationStatus is the replication of a backup
+type BackupReplicationStatus struct {
+	// Name of the Backup
+	Name string `json:"name"`
+	// CompletionTime is the time the backup completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// ReplicationTime is the time the backup was copied to the secondary bucket, not set while it is pending
+	// +optional
+	ReplicationTime *metav1.Time `json:"replicationTime,omitempty"`
+	// Lag is the time from the completion of the backup to its copy, or to the last replication while it is pending
+	// +optional
+	Lag *metav1.Duration `json:"lag,omitempty"`
+}
+
+// BackupLocationConnectivityResult is the outcome of the connectivity check of a backup location
+// +kubebuilder:validation:Enum=Passed;Failed;Skipped
+type BackupLocationConnectivityResult string
+
+const (
+	BackupLocationConnectivityPassed BackupLocationConnectivityResult = "Passed"
+	BackupLocationConnectivityFailed BackupLocationConnectivityResult = "Failed"
+	// BackupLocationConnectivitySkipped is the result of a location whose provider or credentials can not be checked
+	BackupLocationConnectivitySkipped BackupLocationConnectivityResult = "Skipped"
+)
+
+// BackupLocationConnectivityReason is the cause of a failed connectivity check of a backup location
+// +kubebuilder:validation:Enum=InvalidCredentials;AccessDenied;WrongRegion;BucketNotFound;UntrustedCertificate;Error
+type BackupLocationConnectivityReason string
+
+const (
+	BackupLocationConnectivityReasonInvalidCredentials   BackupLocationConnectivityReason = "InvalidCredentials"
+	BackupLocationConnectivityReasonAccessDenied         BackupLocationConnectivityReason = "AccessDenied"
+	BackupLocationConnectivityReasonWrongRegion          BackupLocationConnectivityReason = "WrongRegion"
+	BackupLocationConnectivityReasonBucketNotFound       BackupLocationConnectivityReason = "BucketNotFound"
+	BackupLocationConnectivityReasonUntrustedCertificate BackupLocationConnectivityReason = "UntrustedCertificate"
+	// BackupLocationConnectivityReasonError is the reason of the other failures, described in the message
+	BackupLocationConnectivityReasonError BackupLocationConnectivityReason = "Error"
+)
+
+// BackupLocationConnectivityStatus is the result of the connectivity check of a backup location
+type BackupLocationConnectivityStatus struct {
+	// Name of the BackupStorageLocation of the backup location
+	Name string `json:"name"`
+	// Result of the last check
+	Result BackupLocationConnectivityResult `json:"result"`
+	// Reason is the cause of a failed check
+	// +optional
+	Reason BackupLocationConnectivityReason `json:"reason,omitempty"`
+	// Message explains why the check failed or was skipped
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastCheckTime is the time of the last check
+	// +optional
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+	// Hash of the location and the credentials checked
+	// +optional
+	Hash string `json:"hash,omitempty"`
+}
+
+// NotificationDeliveryStatus is the delivery status of a notification sink
+type NotificationDeliveryStatus struct {
+	// Sink is the name of the notification sink
+	Sink string `json:"sink"`
+	// LastEvent is the last notified event, like "BackupFailed backup/daily-20250101000000"
+	// +optional
+	LastEvent string `json:"lastEvent,omitempty"`
+	// LastAttemptTime is the time of the last delivery attempt
+	// +optional
+	LastAttemptTime metav1.Time `json:"lastAttemptTime,omitempty"`
+	// LastSuccessTime is the time of the last successful delivery
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+	// Error of the last delivery attempt, empty when it succeeded
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ReconcileStepResult is the outcome of a reconcile step
+// +kubebuilder:validation:Enum=Succeeded;Failed;Skipped
+type ReconcileStepResult string
+
+const (
+	ReconcileStepResultSucceeded ReconcileStepResult = "Succeeded"
+	ReconcileStepResultFailed    ReconcileStepResult = "Failed"
+	// ReconcileStepResultSkipped is the result of a step that did not run because a step it depends on did not succeed
+	ReconcileStepResultSkipped ReconcileStepResult = "Skipped"
+)
+
+// ReconcileStepStatus is the result of a reconcile step
+type ReconcileStepStatus struct {
+	// Name of the step
+	Name string `json:"name"`
+	// Result of the step in the last reconcile
+	Result ReconcileStepResult `json:"result"`
+	// LastRunTime is the time the step last ran
+	// +optional
+	LastRunTime metav1.Time `json:"lastRunTime,omitempty"`
+	// Duration of the step in the last reconcile
+	// +optional
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// Error returned by the step, or the steps it waits for when skipped
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedAction is the operation a planned change would perform on an object
+// +kubebuilder:validation:Enum=Create;Update;Delete
+type PlannedAction string
+
+const (
+	PlannedActionCreate PlannedAction = "Create"
+	PlannedActionUpdate PlannedAction = "Update"
+	PlannedActionDelete PlannedAction = "Delete"
+)
+
+// DataProtectionApplicationPlan is the result of a dry-run reconcile of the DPA
+type DataProtectionApplicationPlan struct {
+	// ObservedGeneration is the DPA generation the plan was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastComputed is the time the plan was computed
+	LastComputed metav1.Time `json:"lastComputed,omitempty"`
+	// Changes are the objects that would be created, updated or deleted
+	// +optional
+	Changes []PlannedChange `json:"changes,omitempty"`
+	// RestartsPods is true if a change would restart the Velero or NodeAgent pods
+	RestartsPods bool `json:"restartsPods,omitempty"`
+	// DisruptedOperations are the in-progress backups, restores and data movements a pod restart would disrupt
+	// +optional
+	DisruptedOperations []string `json:"disruptedOperations,omitempty"`
+	// Error is the reconcile error that stopped the plan computation, if any
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedChange is a change the operator would make to an object
+type PlannedChange struct {
+	// Kind of the object
+	Kind string `json:"kind"`
+	// Name of the object
+	Name string `json:"name"`
+	// Action that would be performed on the object
+	Action PlannedAction `json:"action"`
+	// Fields are the paths of the fields that would change on update
+	// +optional
+	Fields []string `json:"fields,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:path=dataprotectionapplications,shortName=dpa
 // +kubebuilder:printcolumn:name="Reconciled",type="string",JSONPath=".status.conditions[?(@.type=='Reconciled')].status",description="DataProtectionApplication Reconciled Status"
+// +kubebuilder:printcolumn:name="Velero",type="string",JSONPath=".status.conditions[?(@.type=='VeleroReady')].status",description="Velero Deployment Ready Status"
+// +kubebuilder:printcolumn:name="NodeAgent",type="string",JSONPath=".status.conditions[?(@.type=='NodeAgentReady')].status",description="NodeAgent DaemonSet Ready Status"
+// +kubebuilder:printcolumn:name="BSLs",type="string",JSONPath=".status.conditions[?(@.type=='BackupStorageLocationsAvailable')].status",description="BackupStorageLocations Available Status"
+// +kubebuilder:printcolumn:name="VSLs",type="string",JSONPath=".status.conditions[?(@.type=='VolumeSnapshotLocationsValid')].status",description="VolumeSnapshotLocations Valid Status",priority=1
+// +kubebuilder:printcolumn:name="NonAdmin",type="string",JSONPath=".status.conditions[?(@.type=='NonAdminReady')].status",description="Non-Admin Controller Deployment Ready Status",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="DataProtectionApplication creation timestamp"
 
 // DataProtectionApplication represents configuration to install a data protection
@@ -873,6 +1382,12 @@ func (dpa *DataProtectionApplication) GetDisableInformerCache() bool {
 	return *dpa.Spec.Configuration.Velero.DisableInformerCache
 }
 
+// Default MountLocationCredentials behavior when nil to false
+func (dpa *DataProtectionApplication) MountLocationCredentials() bool {
+	return dpa.Spec.Configuration != nil && dpa.Spec.Configuration.Velero != nil &&
+		dpa.Spec.Configuration.Velero.MountLocationCredentials != nil && *dpa.Spec.Configuration.Velero.MountLocationCredentials
+}
+
 func (veleroConfig *VeleroConfig) HasFeatureFlag(flag string) bool {
 	for _, featureFlag := range veleroConfig.FeatureFlags {
 		if featureFlag == flag {
@@ -883,7 +1398,8 @@ func (veleroConfig *VeleroConfig) HasFeatureFlag(flag string) bool {
 }
 
 // AutoCorrect is a collection of auto-correction functions for the DPA CR
-// These auto corrects are in-memory only and do not persist to the CR
+// These auto corrects are persisted by the defaulting webhook, and applied in-memory
+// during reconcile for DPAs admitted without the webhook
 // There should not be another place where these auto-corrects are done
 func (dpa *DataProtectionApplication) AutoCorrect() {
 	//check if CSI plugin is added in spec
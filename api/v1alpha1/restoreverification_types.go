@@ -0,0 +1,243 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestoreVerificationSpec defines the restore drills of the backups of a Velero Schedule
+type RestoreVerificationSpec struct {
+	// scheduleName is the name of the Velero Schedule, in the namespace of the RestoreVerification,
+	// whose latest completed backup is restored
+	// +kubebuilder:validation:MinLength=1
+	ScheduleName string `json:"scheduleName"`
+
+	// schedule is the cron expression (https://en.wikipedia.org/wiki/Cron#Overview) of the restore drills
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// namespaceMapping maps the namespaces of the backup to restore to the scratch namespaces they are restored into.
+	// The scratch namespaces must not exist when a drill starts, and are deleted when it ends.
+	// +kubebuilder:validation:MinProperties=1
+	NamespaceMapping map[string]string `json:"namespaceMapping"`
+
+	// storageClassMapping maps the storage classes of the restored persistent volume claims to the storage classes
+	// to restore them with
+	// +optional
+	StorageClassMapping map[string]string `json:"storageClassMapping,omitempty"`
+
+	// checks are run in the scratch namespaces once the restore completes, the drill passes when all of them pass
+	// +optional
+	Checks []RestoreVerificationCheck `json:"checks,omitempty"`
+
+	// timeout is how long the restore and the checks may take before the drill fails. By default 1h
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// preserveOnFailure keeps the scratch namespaces of a failed drill for troubleshooting, until the next drill starts
+	// +optional
+	PreserveOnFailure bool `json:"preserveOnFailure,omitempty"`
+
+	// paused stops new drills from starting. A drill in progress runs to the end.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// RestoreVerificationCheck is a readiness check of the restored workloads, exactly one of podsReady, httpGet and job is set
+type RestoreVerificationCheck struct {
+	// name is the name of the check
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// podsReady passes when the pods of a scratch namespace are ready
+	// +optional
+	PodsReady *PodsReadyCheck `json:"podsReady,omitempty"`
+	// httpGet passes when a GET request from the operator gets a successful response
+	// +optional
+	HTTPGet *HTTPGetCheck `json:"httpGet,omitempty"`
+	// job passes when a job run in a scratch namespace exits with 0
+	// +optional
+	Job *JobCheck `json:"job,omitempty"`
+}
+
+// PodsReadyCheck checks the pods of a scratch namespace are ready
+type PodsReadyCheck struct {
+	// namespace is the scratch namespace of the pods
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// selector selects the pods to check, by default every pod of the namespace. The check fails without any pod.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// HTTPGetCheck checks a GET request gets a successful response
+type HTTPGetCheck struct {
+	// url is the URL of a service of a scratch namespace to get, e.g. http://my-service.my-scratch-namespace.svc:8080/healthz.
+	// Its host must be <service>.<scratch namespace>.svc or <service>.<scratch namespace>.svc.cluster.local,
+	// and redirects are not followed.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// expectedStatusCode is the status code of a successful response, by default any 2xx status code
+	// +optional
+	ExpectedStatusCode int32 `json:"expectedStatusCode,omitempty"`
+}
+
+// JobCheck checks a job run in a scratch namespace exits with 0
+type JobCheck struct {
+	// namespace is the scratch namespace to run the job in
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// image is the container image of the job
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+	// command is the entrypoint of the container
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// args are the arguments of the entrypoint
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
+
+// RestoreVerificationPhase is the step of the restore drill in progress
+// +kubebuilder:validation:Enum=Waiting;Restoring;Verifying;CleaningUp
+type RestoreVerificationPhase string
+
+const (
+	// RestoreVerificationPhaseWaiting waits for the next drill
+	RestoreVerificationPhaseWaiting RestoreVerificationPhase = "Waiting"
+	// RestoreVerificationPhaseRestoring waits for the restore into the scratch namespaces to finish
+	RestoreVerificationPhaseRestoring RestoreVerificationPhase = "Restoring"
+	// RestoreVerificationPhaseVerifying runs the checks
+	RestoreVerificationPhaseVerifying RestoreVerificationPhase = "Verifying"
+	// RestoreVerificationPhaseCleaningUp deletes the scratch namespaces
+	RestoreVerificationPhaseCleaningUp RestoreVerificationPhase = "CleaningUp"
+)
+
+// RestoreVerificationResult is the result of a restore drill
+// +kubebuilder:validation:Enum=Passed;Failed
+type RestoreVerificationResult string
+
+const (
+	// RestoreVerificationResultPassed is the result of a drill whose restore completed and checks passed
+	RestoreVerificationResultPassed RestoreVerificationResult = "Passed"
+	// RestoreVerificationResultFailed is the result of a drill whose restore or a check failed, or timed out
+	RestoreVerificationResultFailed RestoreVerificationResult = "Failed"
+)
+
+// RestoreVerificationRun is a restore drill
+type RestoreVerificationRun struct {
+	// Backup is the name of the backup restored
+	// +optional
+	Backup string `json:"backup,omitempty"`
+	// Restore is the name of the Velero Restore of the drill
+	// +optional
+	Restore string `json:"restore,omitempty"`
+	// Namespaces are the scratch namespaces of the drill
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// StartTime is when the drill started
+	StartTime metav1.Time `json:"startTime"`
+	// RestoreCompletionTime is when the restore finished
+	// +optional
+	RestoreCompletionTime *metav1.Time `json:"restoreCompletionTime,omitempty"`
+	// CompletionTime is when the checks finished, before the scratch namespaces are deleted
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// RTO is the time from the start of the drill to all the checks passing, the recovery time of the workloads
+	// +optional
+	RTO *metav1.Duration `json:"rto,omitempty"`
+	// Result is the result of the drill, set once the checks finished
+	// +optional
+	Result RestoreVerificationResult `json:"result,omitempty"`
+	// Message explains why the drill failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Checks are the results of the checks
+	// +optional
+	Checks []RestoreVerificationCheckResult `json:"checks,omitempty"`
+}
+
+// RestoreVerificationCheckResult is the result of a check of a restore drill
+type RestoreVerificationCheckResult struct {
+	// Name is the name of the check
+	Name string `json:"name"`
+	// Passed is true once the check passed
+	Passed bool `json:"passed"`
+	// Message explains why the check has not passed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RestoreVerificationStatus defines the observed state of RestoreVerification
+type RestoreVerificationStatus struct {
+	// Conditions is the reconcile status of the RestoreVerification
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Phase is the step of the drill in progress
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	Phase RestoreVerificationPhase `json:"phase,omitempty"`
+	// NextRunTime is when the next drill starts
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+	// CurrentRun is the drill in progress
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	CurrentRun *RestoreVerificationRun `json:"currentRun,omitempty"`
+	// LastRun is the last finished drill
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	LastRun *RestoreVerificationRun `json:"lastRun,omitempty"`
+	// LastSuccessfulRunTime is the start time of the last drill that passed
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// +optional
+	LastSuccessfulRunTime *metav1.Time `json:"lastSuccessfulRunTime,omitempty"`
+}
+
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=".spec.scheduleName",description="Velero Schedule whose backups are restored"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=".status.phase",description="Step of the drill in progress"
+// +kubebuilder:printcolumn:name="LastResult",type=string,JSONPath=".status.lastRun.result",description="Result of the last drill"
+// +kubebuilder:printcolumn:name="RTO",type=string,JSONPath=".status.lastRun.rto",description="Recovery time of the last drill"
+// +kubebuilder:printcolumn:name="LastSuccess",type=date,JSONPath=".status.lastSuccessfulRunTime",description="Start time of the last drill that passed"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp",description="Time since the RestoreVerification was created"
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=restoreverifications,shortName=rv
+
+// RestoreVerification is the Schema for the restoreverifications API
+type RestoreVerification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RestoreVerificationSpec   `json:"spec,omitempty"`
+	Status RestoreVerificationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RestoreVerificationList contains a list of RestoreVerification
+type RestoreVerificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RestoreVerification `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RestoreVerification{}, &RestoreVerificationList{})
+}
@@ -24,8 +24,8 @@ import (
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	"github.com/vmware-tanzu/velero/pkg/nodeagent"
 	"github.com/vmware-tanzu/velero/pkg/util/kube"
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	timex "time"
 )
@@ -68,64 +68,7 @@ func (in *ApplicationConfig) DeepCopy() *ApplicationConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BackupLocation) DeepCopyInto(out *BackupLocation) {
-	*out = *in
-	if in.Velero != nil {
-		in, out := &in.Velero, &out.Velero
-		*out = new(velerov1.BackupStorageLocationSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CloudStorage != nil {
-		in, out := &in.CloudStorage, &out.CloudStorage
-		*out = new(CloudStorageLocation)
-		(*in).DeepCopyInto(*out)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocation.
-func (in *BackupLocation) DeepCopy() *BackupLocation {
-	if in == nil {
-		return nil
-	}
-	out := new(BackupLocation)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketMetadata) DeepCopyInto(out *BucketMetadata) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketMetadata.
-func (in *BucketMetadata) DeepCopy() *BucketMetadata {
-	if in == nil {
-		return nil
-	}
-	out := new(BucketMetadata)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CSIVolumeSnapshotTestConfig) DeepCopyInto(out *CSIVolumeSnapshotTestConfig) {
-	*out = *in
-	out.Timeout = in.Timeout
-	out.VolumeSnapshotSource = in.VolumeSnapshotSource
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIVolumeSnapshotTestConfig.
-func (in *CSIVolumeSnapshotTestConfig) DeepCopy() *CSIVolumeSnapshotTestConfig {
-	if in == nil {
-		return nil
-	}
-	out := new(CSIVolumeSnapshotTestConfig)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStorage) DeepCopyInto(out *CloudStorage) {
+func (in *BackupCoverageReport) DeepCopyInto(out *BackupCoverageReport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -133,18 +76,18 @@ func (in *CloudStorage) DeepCopyInto(out *CloudStorage) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorage.
-func (in *CloudStorage) DeepCopy() *CloudStorage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCoverageReport.
+func (in *BackupCoverageReport) DeepCopy() *BackupCoverageReport {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStorage)
+	out := new(BackupCoverageReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStorage) DeepCopyObject() runtime.Object {
+func (in *BackupCoverageReport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -152,31 +95,31 @@ func (in *CloudStorage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStorageList) DeepCopyInto(out *CloudStorageList) {
+func (in *BackupCoverageReportList) DeepCopyInto(out *BackupCoverageReportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CloudStorage, len(*in))
+		*out = make([]BackupCoverageReport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageList.
-func (in *CloudStorageList) DeepCopy() *CloudStorageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCoverageReportList.
+func (in *BackupCoverageReportList) DeepCopy() *BackupCoverageReportList {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStorageList)
+	out := new(BackupCoverageReportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStorageList) DeepCopyObject() runtime.Object {
+func (in *BackupCoverageReportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -184,159 +127,210 @@ func (in *CloudStorageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStorageLocation) DeepCopyInto(out *CloudStorageLocation) {
+func (in *BackupCoverageReportSpec) DeepCopyInto(out *BackupCoverageReportSpec) {
 	*out = *in
-	out.CloudStorageRef = in.CloudStorageRef
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.IncludedNamespaces != nil {
+		in, out := &in.IncludedNamespaces, &out.IncludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Credential != nil {
-		in, out := &in.Credential, &out.Credential
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.BackupSyncPeriod != nil {
-		in, out := &in.BackupSyncPeriod, &out.BackupSyncPeriod
-		*out = new(metav1.Duration)
+	if in.StaleThreshold != nil {
+		in, out := &in.StaleThreshold, &out.StaleThreshold
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.CACert != nil {
-		in, out := &in.CACert, &out.CACert
-		*out = make([]byte, len(*in))
-		copy(*out, *in)
+	if in.RefreshPeriod != nil {
+		in, out := &in.RefreshPeriod, &out.RefreshPeriod
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageLocation.
-func (in *CloudStorageLocation) DeepCopy() *CloudStorageLocation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCoverageReportSpec.
+func (in *BackupCoverageReportSpec) DeepCopy() *BackupCoverageReportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStorageLocation)
+	out := new(BackupCoverageReportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStorageSpec) DeepCopyInto(out *CloudStorageSpec) {
+func (in *BackupCoverageReportStatus) DeepCopyInto(out *BackupCoverageReportStatus) {
 	*out = *in
-	in.CreationSecret.DeepCopyInto(&out.CreationSecret)
-	if in.EnableSharedConfig != nil {
-		in, out := &in.EnableSharedConfig, &out.EnableSharedConfig
-		*out = new(bool)
-		**out = **in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Tags != nil {
-		in, out := &in.Tags, &out.Tags
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.LastReportTime != nil {
+		in, out := &in.LastReportTime, &out.LastReportTime
+		*out = (*in).DeepCopy()
+	}
+	out.Summary = in.Summary
+	if in.UnprotectedNamespaces != nil {
+		in, out := &in.UnprotectedNamespaces, &out.UnprotectedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaleNamespaces != nil {
+		in, out := &in.StaleNamespaces, &out.StaleNamespaces
+		*out = make([]StaleNamespace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = make([]PersistentVolumeClaimCoverage, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageSpec.
-func (in *CloudStorageSpec) DeepCopy() *CloudStorageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCoverageReportStatus.
+func (in *BackupCoverageReportStatus) DeepCopy() *BackupCoverageReportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStorageSpec)
+	out := new(BackupCoverageReportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStorageStatus) DeepCopyInto(out *CloudStorageStatus) {
+func (in *BackupLocation) DeepCopyInto(out *BackupLocation) {
 	*out = *in
-	if in.LastSynced != nil {
-		in, out := &in.LastSynced, &out.LastSynced
-		*out = (*in).DeepCopy()
+	if in.Velero != nil {
+		in, out := &in.Velero, &out.Velero
+		*out = new(velerov1.BackupStorageLocationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudStorage != nil {
+		in, out := &in.CloudStorage, &out.CloudStorage
+		*out = new(CloudStorageLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretProviderClass != nil {
+		in, out := &in.SecretProviderClass, &out.SecretProviderClass
+		*out = new(SecretProviderClassCredential)
+		**out = **in
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(BackupLocationReplication)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageStatus.
-func (in *CloudStorageStatus) DeepCopy() *CloudStorageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocation.
+func (in *BackupLocation) DeepCopy() *BackupLocation {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStorageStatus)
+	out := new(BackupLocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CustomPlugin) DeepCopyInto(out *CustomPlugin) {
+func (in *BackupLocationConnectivityCheck) DeepCopyInto(out *BackupLocationConnectivityCheck) {
 	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPlugin.
-func (in *CustomPlugin) DeepCopy() *CustomPlugin {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationConnectivityCheck.
+func (in *BackupLocationConnectivityCheck) DeepCopy() *BackupLocationConnectivityCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(CustomPlugin)
+	out := new(BackupLocationConnectivityCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataMover) DeepCopyInto(out *DataMover) {
+func (in *BackupLocationConnectivityStatus) DeepCopyInto(out *BackupLocationConnectivityStatus) {
 	*out = *in
-	if in.VolumeOptionsForStorageClasses != nil {
-		in, out := &in.VolumeOptionsForStorageClasses, &out.VolumeOptionsForStorageClasses
-		*out = make(map[string]DataMoverVolumeOptions, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationConnectivityStatus.
+func (in *BackupLocationConnectivityStatus) DeepCopy() *BackupLocationConnectivityStatus {
+	if in == nil {
+		return nil
 	}
-	if in.SnapshotRetainPolicy != nil {
-		in, out := &in.SnapshotRetainPolicy, &out.SnapshotRetainPolicy
-		*out = new(RetainPolicy)
+	out := new(BackupLocationConnectivityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupLocationReplication) DeepCopyInto(out *BackupLocationReplication) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataMover.
-func (in *DataMover) DeepCopy() *DataMover {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationReplication.
+func (in *BackupLocationReplication) DeepCopy() *BackupLocationReplication {
 	if in == nil {
 		return nil
 	}
-	out := new(DataMover)
+	out := new(BackupLocationReplication)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataMoverVolumeOptions) DeepCopyInto(out *DataMoverVolumeOptions) {
+func (in *BackupLocationReplicationStatus) DeepCopyInto(out *BackupLocationReplicationStatus) {
 	*out = *in
-	if in.SourceVolumeOptions != nil {
-		in, out := &in.SourceVolumeOptions, &out.SourceVolumeOptions
-		*out = new(VolumeOptions)
-		**out = **in
+	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
 	}
-	if in.DestinationVolumeOptions != nil {
-		in, out := &in.DestinationVolumeOptions, &out.DestinationVolumeOptions
-		*out = new(VolumeOptions)
-		**out = **in
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = make([]BackupReplicationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KopiaCheckpoint != nil {
+		in, out := &in.KopiaCheckpoint, &out.KopiaCheckpoint
+		*out = new(KopiaReplicationCheckpoint)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataMoverVolumeOptions.
-func (in *DataMoverVolumeOptions) DeepCopy() *DataMoverVolumeOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupLocationReplicationStatus.
+func (in *BackupLocationReplicationStatus) DeepCopy() *BackupLocationReplicationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DataMoverVolumeOptions)
+	out := new(BackupLocationReplicationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionApplication) DeepCopyInto(out *DataProtectionApplication) {
+func (in *BackupPolicy) DeepCopyInto(out *BackupPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -344,18 +338,18 @@ func (in *DataProtectionApplication) DeepCopyInto(out *DataProtectionApplication
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplication.
-func (in *DataProtectionApplication) DeepCopy() *DataProtectionApplication {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicy.
+func (in *BackupPolicy) DeepCopy() *BackupPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionApplication)
+	out := new(BackupPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataProtectionApplication) DeepCopyObject() runtime.Object {
+func (in *BackupPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -363,31 +357,31 @@ func (in *DataProtectionApplication) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionApplicationList) DeepCopyInto(out *DataProtectionApplicationList) {
+func (in *BackupPolicyList) DeepCopyInto(out *BackupPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DataProtectionApplication, len(*in))
+		*out = make([]BackupPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationList.
-func (in *DataProtectionApplicationList) DeepCopy() *DataProtectionApplicationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicyList.
+func (in *BackupPolicyList) DeepCopy() *BackupPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionApplicationList)
+	out := new(BackupPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataProtectionApplicationList) DeepCopyObject() runtime.Object {
+func (in *BackupPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -395,98 +389,135 @@ func (in *DataProtectionApplicationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionApplicationSpec) DeepCopyInto(out *DataProtectionApplicationSpec) {
+func (in *BackupPolicySpec) DeepCopyInto(out *BackupPolicySpec) {
 	*out = *in
-	if in.BackupLocations != nil {
-		in, out := &in.BackupLocations, &out.BackupLocations
-		*out = make([]BackupLocation, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.IncludedNamespaces != nil {
+		in, out := &in.IncludedNamespaces, &out.IncludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.SnapshotLocations != nil {
-		in, out := &in.SnapshotLocations, &out.SnapshotLocations
-		*out = make([]SnapshotLocation, len(*in))
+	in.Template.DeepCopyInto(&out.Template)
+	in.Retention.DeepCopyInto(&out.Retention)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicySpec.
+func (in *BackupPolicySpec) DeepCopy() *BackupPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPolicyStatus) DeepCopyInto(out *BackupPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.UnsupportedOverrides != nil {
-		in, out := &in.UnsupportedOverrides, &out.UnsupportedOverrides
-		*out = make(map[UnsupportedImageKey]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.PodAnnotations != nil {
-		in, out := &in.PodAnnotations, &out.PodAnnotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	in.PodDnsConfig.DeepCopyInto(&out.PodDnsConfig)
-	if in.BackupImages != nil {
-		in, out := &in.BackupImages, &out.BackupImages
-		*out = new(bool)
-		**out = **in
+	if in.LastPruneTime != nil {
+		in, out := &in.LastPruneTime, &out.LastPruneTime
+		*out = (*in).DeepCopy()
 	}
-	if in.Configuration != nil {
-		in, out := &in.Configuration, &out.Configuration
-		*out = new(ApplicationConfig)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicyStatus.
+func (in *BackupPolicyStatus) DeepCopy() *BackupPolicyStatus {
+	if in == nil {
+		return nil
 	}
-	if in.Features != nil {
-		in, out := &in.Features, &out.Features
-		*out = new(Features)
-		(*in).DeepCopyInto(*out)
+	out := new(BackupPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupReplicationStatus) DeepCopyInto(out *BackupReplicationStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
-	if in.ImagePullPolicy != nil {
-		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
-		*out = new(v1.PullPolicy)
+	if in.ReplicationTime != nil {
+		in, out := &in.ReplicationTime, &out.ReplicationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Lag != nil {
+		in, out := &in.Lag, &out.Lag
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.NonAdmin != nil {
-		in, out := &in.NonAdmin, &out.NonAdmin
-		*out = new(NonAdmin)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupReplicationStatus.
+func (in *BackupReplicationStatus) DeepCopy() *BackupReplicationStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(BackupReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationSpec.
-func (in *DataProtectionApplicationSpec) DeepCopy() *DataProtectionApplicationSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetention) DeepCopyInto(out *BackupRetention) {
+	*out = *in
+	if in.Within != nil {
+		in, out := &in.Within, &out.Within
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetention.
+func (in *BackupRetention) DeepCopy() *BackupRetention {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionApplicationSpec)
+	out := new(BackupRetention)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionApplicationStatus) DeepCopyInto(out *DataProtectionApplicationStatus) {
+func (in *BucketMetadata) DeepCopyInto(out *BucketMetadata) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketMetadata.
+func (in *BucketMetadata) DeepCopy() *BucketMetadata {
+	if in == nil {
+		return nil
 	}
+	out := new(BucketMetadata)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationStatus.
-func (in *DataProtectionApplicationStatus) DeepCopy() *DataProtectionApplicationStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIVolumeSnapshotTestConfig) DeepCopyInto(out *CSIVolumeSnapshotTestConfig) {
+	*out = *in
+	out.Timeout = in.Timeout
+	out.VolumeSnapshotSource = in.VolumeSnapshotSource
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIVolumeSnapshotTestConfig.
+func (in *CSIVolumeSnapshotTestConfig) DeepCopy() *CSIVolumeSnapshotTestConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionApplicationStatus)
+	out := new(CSIVolumeSnapshotTestConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionTest) DeepCopyInto(out *DataProtectionTest) {
+func (in *CloudStorage) DeepCopyInto(out *CloudStorage) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -494,18 +525,18 @@ func (in *DataProtectionTest) DeepCopyInto(out *DataProtectionTest) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTest.
-func (in *DataProtectionTest) DeepCopy() *DataProtectionTest {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorage.
+func (in *CloudStorage) DeepCopy() *CloudStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionTest)
+	out := new(CloudStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataProtectionTest) DeepCopyObject() runtime.Object {
+func (in *CloudStorage) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -513,31 +544,31 @@ func (in *DataProtectionTest) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionTestList) DeepCopyInto(out *DataProtectionTestList) {
+func (in *CloudStorageList) DeepCopyInto(out *CloudStorageList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DataProtectionTest, len(*in))
+		*out = make([]CloudStorage, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTestList.
-func (in *DataProtectionTestList) DeepCopy() *DataProtectionTestList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageList.
+func (in *CloudStorageList) DeepCopy() *CloudStorageList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionTestList)
+	out := new(CloudStorageList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataProtectionTestList) DeepCopyObject() runtime.Object {
+func (in *CloudStorageList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -545,210 +576,833 @@ func (in *DataProtectionTestList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionTestSpec) DeepCopyInto(out *DataProtectionTestSpec) {
+func (in *CloudStorageLocation) DeepCopyInto(out *CloudStorageLocation) {
 	*out = *in
-	if in.BackupLocationSpec != nil {
-		in, out := &in.BackupLocationSpec, &out.BackupLocationSpec
-		*out = new(velerov1.BackupStorageLocationSpec)
+	out.CloudStorageRef = in.CloudStorageRef
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Credential != nil {
+		in, out := &in.Credential, &out.Credential
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.UploadSpeedTestConfig != nil {
-		in, out := &in.UploadSpeedTestConfig, &out.UploadSpeedTestConfig
-		*out = new(UploadSpeedTestConfig)
+	if in.BackupSyncPeriod != nil {
+		in, out := &in.BackupSyncPeriod, &out.BackupSyncPeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.CSIVolumeSnapshotTestConfigs != nil {
-		in, out := &in.CSIVolumeSnapshotTestConfigs, &out.CSIVolumeSnapshotTestConfigs
-		*out = make([]CSIVolumeSnapshotTestConfig, len(*in))
+	if in.CACert != nil {
+		in, out := &in.CACert, &out.CACert
+		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTestSpec.
-func (in *DataProtectionTestSpec) DeepCopy() *DataProtectionTestSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageLocation.
+func (in *CloudStorageLocation) DeepCopy() *CloudStorageLocation {
 	if in == nil {
 		return nil
 	}
-	out := new(DataProtectionTestSpec)
+	out := new(CloudStorageLocation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataProtectionTestStatus) DeepCopyInto(out *DataProtectionTestStatus) {
+func (in *CloudStorageSpec) DeepCopyInto(out *CloudStorageSpec) {
 	*out = *in
-	in.LastTested.DeepCopyInto(&out.LastTested)
-	if in.BucketMetadata != nil {
-		in, out := &in.BucketMetadata, &out.BucketMetadata
-		*out = new(BucketMetadata)
+	in.CreationSecret.DeepCopyInto(&out.CreationSecret)
+	if in.EnableSharedConfig != nil {
+		in, out := &in.EnableSharedConfig, &out.EnableSharedConfig
+		*out = new(bool)
 		**out = **in
 	}
-	out.UploadTest = in.UploadTest
-	if in.SnapshotTests != nil {
-		in, out := &in.SnapshotTests, &out.SnapshotTests
-		*out = make([]SnapshotTestStatus, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTestStatus.
-func (in *DataProtectionTestStatus) DeepCopy() *DataProtectionTestStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(DataProtectionTestStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EnforceBackupStorageLocationSpec) DeepCopyInto(out *EnforceBackupStorageLocationSpec) {
-	*out = *in
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Credential != nil {
-		in, out := &in.Credential, &out.Credential
-		*out = new(v1.SecretKeySelector)
+	if in.LifecycleRules != nil {
+		in, out := &in.LifecycleRules, &out.LifecycleRules
+		*out = new(LifecycleRules)
 		(*in).DeepCopyInto(*out)
 	}
-	in.StorageType.DeepCopyInto(&out.StorageType)
-	if in.BackupSyncPeriod != nil {
-		in, out := &in.BackupSyncPeriod, &out.BackupSyncPeriod
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.ValidationFrequency != nil {
-		in, out := &in.ValidationFrequency, &out.ValidationFrequency
-		*out = new(metav1.Duration)
+	if in.UsageReportingPeriod != nil {
+		in, out := &in.UsageReportingPeriod, &out.UsageReportingPeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforceBackupStorageLocationSpec.
-func (in *EnforceBackupStorageLocationSpec) DeepCopy() *EnforceBackupStorageLocationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageSpec.
+func (in *CloudStorageSpec) DeepCopy() *CloudStorageSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EnforceBackupStorageLocationSpec)
+	out := new(CloudStorageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Features) DeepCopyInto(out *Features) {
+func (in *CloudStorageStatus) DeepCopyInto(out *CloudStorageStatus) {
 	*out = *in
-	if in.DataMover != nil {
-		in, out := &in.DataMover, &out.DataMover
-		*out = new(DataMover)
+	if in.LastSynced != nil {
+		in, out := &in.LastSynced, &out.LastSynced
+		*out = (*in).DeepCopy()
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(CloudStorageUsage)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Features.
-func (in *Features) DeepCopy() *Features {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageStatus.
+func (in *CloudStorageStatus) DeepCopy() *CloudStorageStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Features)
+	out := new(CloudStorageStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GlobalFlags) DeepCopyInto(out *GlobalFlags) {
+func (in *CloudStorageUsage) DeepCopyInto(out *CloudStorageUsage) {
 	*out = *in
-	if in.Colorized != nil {
-		in, out := &in.Colorized, &out.Colorized
-		*out = new(bool)
-		**out = **in
+	if in.LastComputed != nil {
+		in, out := &in.LastComputed, &out.LastComputed
+		*out = (*in).DeepCopy()
+	}
+	if in.Prefixes != nil {
+		in, out := &in.Prefixes, &out.Prefixes
+		*out = make([]PrefixUsage, len(*in))
+		copy(*out, *in)
 	}
-	in.LoggingFlags.DeepCopyInto(&out.LoggingFlags)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalFlags.
-func (in *GlobalFlags) DeepCopy() *GlobalFlags {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStorageUsage.
+func (in *CloudStorageUsage) DeepCopy() *CloudStorageUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(GlobalFlags)
+	out := new(CloudStorageUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KopiaRepoOptions) DeepCopyInto(out *KopiaRepoOptions) {
+func (in *CoverageSummary) DeepCopyInto(out *CoverageSummary) {
 	*out = *in
-	if in.CacheLimitMB != nil {
-		in, out := &in.CacheLimitMB, &out.CacheLimitMB
-		*out = new(int64)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KopiaRepoOptions.
-func (in *KopiaRepoOptions) DeepCopy() *KopiaRepoOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoverageSummary.
+func (in *CoverageSummary) DeepCopy() *CoverageSummary {
 	if in == nil {
 		return nil
 	}
-	out := new(KopiaRepoOptions)
+	out := new(CoverageSummary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadAffinity) DeepCopyInto(out *LoadAffinity) {
+func (in *CustomPlugin) DeepCopyInto(out *CustomPlugin) {
 	*out = *in
-	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.SnapshotProviders != nil {
+		in, out := &in.SnapshotProviders, &out.SnapshotProviders
+		*out = make([]SnapshotProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadAffinity.
-func (in *LoadAffinity) DeepCopy() *LoadAffinity {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPlugin.
+func (in *CustomPlugin) DeepCopy() *CustomPlugin {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadAffinity)
+	out := new(CustomPlugin)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoadConcurrency) DeepCopyInto(out *LoadConcurrency) {
+func (in *DataMover) DeepCopyInto(out *DataMover) {
 	*out = *in
-	if in.PerNodeConfig != nil {
-		in, out := &in.PerNodeConfig, &out.PerNodeConfig
-		*out = make([]RuledConfigs, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.VolumeOptionsForStorageClasses != nil {
+		in, out := &in.VolumeOptionsForStorageClasses, &out.VolumeOptionsForStorageClasses
+		*out = make(map[string]DataMoverVolumeOptions, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.SnapshotRetainPolicy != nil {
+		in, out := &in.SnapshotRetainPolicy, &out.SnapshotRetainPolicy
+		*out = new(RetainPolicy)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadConcurrency.
-func (in *LoadConcurrency) DeepCopy() *LoadConcurrency {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataMover.
+func (in *DataMover) DeepCopy() *DataMover {
 	if in == nil {
 		return nil
 	}
-	out := new(LoadConcurrency)
+	out := new(DataMover)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoggingFlags) DeepCopyInto(out *LoggingFlags) {
+func (in *DataMoverVolumeOptions) DeepCopyInto(out *DataMoverVolumeOptions) {
 	*out = *in
-	if in.ToStderr != nil {
-		in, out := &in.ToStderr, &out.ToStderr
-		*out = new(bool)
+	if in.SourceVolumeOptions != nil {
+		in, out := &in.SourceVolumeOptions, &out.SourceVolumeOptions
+		*out = new(VolumeOptions)
 		**out = **in
 	}
-	if in.AlsoToStderr != nil {
-		in, out := &in.AlsoToStderr, &out.AlsoToStderr
-		*out = new(bool)
+	if in.DestinationVolumeOptions != nil {
+		in, out := &in.DestinationVolumeOptions, &out.DestinationVolumeOptions
+		*out = new(VolumeOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataMoverVolumeOptions.
+func (in *DataMoverVolumeOptions) DeepCopy() *DataMoverVolumeOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DataMoverVolumeOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionApplication) DeepCopyInto(out *DataProtectionApplication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplication.
+func (in *DataProtectionApplication) DeepCopy() *DataProtectionApplication {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionApplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataProtectionApplication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionApplicationList) DeepCopyInto(out *DataProtectionApplicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataProtectionApplication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationList.
+func (in *DataProtectionApplicationList) DeepCopy() *DataProtectionApplicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionApplicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataProtectionApplicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionApplicationPlan) DeepCopyInto(out *DataProtectionApplicationPlan) {
+	*out = *in
+	in.LastComputed.DeepCopyInto(&out.LastComputed)
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]PlannedChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DisruptedOperations != nil {
+		in, out := &in.DisruptedOperations, &out.DisruptedOperations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationPlan.
+func (in *DataProtectionApplicationPlan) DeepCopy() *DataProtectionApplicationPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionApplicationPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionApplicationSpec) DeepCopyInto(out *DataProtectionApplicationSpec) {
+	*out = *in
+	if in.BackupLocations != nil {
+		in, out := &in.BackupLocations, &out.BackupLocations
+		*out = make([]BackupLocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SnapshotLocations != nil {
+		in, out := &in.SnapshotLocations, &out.SnapshotLocations
+		*out = make([]SnapshotLocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnsupportedOverrides != nil {
+		in, out := &in.UnsupportedOverrides, &out.UnsupportedOverrides
+		*out = make(map[UnsupportedImageKey]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.PodDnsConfig.DeepCopyInto(&out.PodDnsConfig)
+	if in.BackupImages != nil {
+		in, out := &in.BackupImages, &out.BackupImages
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = new(ApplicationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = new(Features)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullPolicy != nil {
+		in, out := &in.ImagePullPolicy, &out.ImagePullPolicy
+		*out = new(corev1.PullPolicy)
+		**out = **in
+	}
+	if in.NonAdmin != nil {
+		in, out := &in.NonAdmin, &out.NonAdmin
+		*out = new(NonAdmin)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutGate != nil {
+		in, out := &in.RolloutGate, &out.RolloutGate
+		*out = new(RolloutGate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		**out = **in
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(Monitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(Notifications)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupLocationConnectivityCheck != nil {
+		in, out := &in.BackupLocationConnectivityCheck, &out.BackupLocationConnectivityCheck
+		*out = new(BackupLocationConnectivityCheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationSpec.
+func (in *DataProtectionApplicationSpec) DeepCopy() *DataProtectionApplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionApplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionApplicationStatus) DeepCopyInto(out *DataProtectionApplicationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(DataProtectionApplicationPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReconcileSteps != nil {
+		in, out := &in.ReconcileSteps, &out.ReconcileSteps
+		*out = make([]ReconcileStepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationDeliveryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackupLocationConnectivity != nil {
+		in, out := &in.BackupLocationConnectivity, &out.BackupLocationConnectivity
+		*out = make([]BackupLocationConnectivityStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackupLocationReplication != nil {
+		in, out := &in.BackupLocationReplication, &out.BackupLocationReplication
+		*out = make([]BackupLocationReplicationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionApplicationStatus.
+func (in *DataProtectionApplicationStatus) DeepCopy() *DataProtectionApplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionApplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionTest) DeepCopyInto(out *DataProtectionTest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTest.
+func (in *DataProtectionTest) DeepCopy() *DataProtectionTest {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataProtectionTest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionTestList) DeepCopyInto(out *DataProtectionTestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataProtectionTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTestList.
+func (in *DataProtectionTestList) DeepCopy() *DataProtectionTestList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionTestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataProtectionTestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionTestSpec) DeepCopyInto(out *DataProtectionTestSpec) {
+	*out = *in
+	if in.BackupLocationSpec != nil {
+		in, out := &in.BackupLocationSpec, &out.BackupLocationSpec
+		*out = new(velerov1.BackupStorageLocationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UploadSpeedTestConfig != nil {
+		in, out := &in.UploadSpeedTestConfig, &out.UploadSpeedTestConfig
+		*out = new(UploadSpeedTestConfig)
+		**out = **in
+	}
+	if in.CSIVolumeSnapshotTestConfigs != nil {
+		in, out := &in.CSIVolumeSnapshotTestConfigs, &out.CSIVolumeSnapshotTestConfigs
+		*out = make([]CSIVolumeSnapshotTestConfig, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTestSpec.
+func (in *DataProtectionTestSpec) DeepCopy() *DataProtectionTestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionTestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataProtectionTestStatus) DeepCopyInto(out *DataProtectionTestStatus) {
+	*out = *in
+	in.LastTested.DeepCopyInto(&out.LastTested)
+	if in.BucketMetadata != nil {
+		in, out := &in.BucketMetadata, &out.BucketMetadata
+		*out = new(BucketMetadata)
+		**out = **in
+	}
+	out.UploadTest = in.UploadTest
+	if in.SnapshotTests != nil {
+		in, out := &in.SnapshotTests, &out.SnapshotTests
+		*out = make([]SnapshotTestStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataProtectionTestStatus.
+func (in *DataProtectionTestStatus) DeepCopy() *DataProtectionTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataProtectionTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnforceBackupStorageLocationSpec) DeepCopyInto(out *EnforceBackupStorageLocationSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Credential != nil {
+		in, out := &in.Credential, &out.Credential
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.StorageType.DeepCopyInto(&out.StorageType)
+	if in.BackupSyncPeriod != nil {
+		in, out := &in.BackupSyncPeriod, &out.BackupSyncPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ValidationFrequency != nil {
+		in, out := &in.ValidationFrequency, &out.ValidationFrequency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforceBackupStorageLocationSpec.
+func (in *EnforceBackupStorageLocationSpec) DeepCopy() *EnforceBackupStorageLocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnforceBackupStorageLocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Features) DeepCopyInto(out *Features) {
+	*out = *in
+	if in.DataMover != nil {
+		in, out := &in.DataMover, &out.DataMover
+		*out = new(DataMover)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Features.
+func (in *Features) DeepCopy() *Features {
+	if in == nil {
+		return nil
+	}
+	out := new(Features)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalFlags) DeepCopyInto(out *GlobalFlags) {
+	*out = *in
+	if in.Colorized != nil {
+		in, out := &in.Colorized, &out.Colorized
+		*out = new(bool)
+		**out = **in
+	}
+	in.LoggingFlags.DeepCopyInto(&out.LoggingFlags)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalFlags.
+func (in *GlobalFlags) DeepCopy() *GlobalFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGetCheck) DeepCopyInto(out *HTTPGetCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGetCheck.
+func (in *HTTPGetCheck) DeepCopy() *HTTPGetCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGetCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobCheck) DeepCopyInto(out *JobCheck) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobCheck.
+func (in *JobCheck) DeepCopy() *JobCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(JobCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KopiaReplicationCheckpoint) DeepCopyInto(out *KopiaReplicationCheckpoint) {
+	*out = *in
+	if in.CopiedBefore != nil {
+		in, out := &in.CopiedBefore, &out.CopiedBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KopiaReplicationCheckpoint.
+func (in *KopiaReplicationCheckpoint) DeepCopy() *KopiaReplicationCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(KopiaReplicationCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KopiaRepoOptions) DeepCopyInto(out *KopiaRepoOptions) {
+	*out = *in
+	if in.CacheLimitMB != nil {
+		in, out := &in.CacheLimitMB, &out.CacheLimitMB
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KopiaRepoOptions.
+func (in *KopiaRepoOptions) DeepCopy() *KopiaRepoOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(KopiaRepoOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleRules) DeepCopyInto(out *LifecycleRules) {
+	*out = *in
+	if in.AbortIncompleteMultipartUploadDays != nil {
+		in, out := &in.AbortIncompleteMultipartUploadDays, &out.AbortIncompleteMultipartUploadDays
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NoncurrentVersionExpirationDays != nil {
+		in, out := &in.NoncurrentVersionExpirationDays, &out.NoncurrentVersionExpirationDays
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Transition != nil {
+		in, out := &in.Transition, &out.Transition
+		*out = new(LifecycleTransition)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleRules.
+func (in *LifecycleRules) DeepCopy() *LifecycleRules {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleTransition) DeepCopyInto(out *LifecycleTransition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleTransition.
+func (in *LifecycleTransition) DeepCopy() *LifecycleTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadAffinity) DeepCopyInto(out *LoadAffinity) {
+	*out = *in
+	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadAffinity.
+func (in *LoadAffinity) DeepCopy() *LoadAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadConcurrency) DeepCopyInto(out *LoadConcurrency) {
+	*out = *in
+	if in.PerNodeConfig != nil {
+		in, out := &in.PerNodeConfig, &out.PerNodeConfig
+		*out = make([]RuledConfigs, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadConcurrency.
+func (in *LoadConcurrency) DeepCopy() *LoadConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingFlags) DeepCopyInto(out *LoggingFlags) {
+	*out = *in
+	if in.ToStderr != nil {
+		in, out := &in.ToStderr, &out.ToStderr
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AlsoToStderr != nil {
+		in, out := &in.AlsoToStderr, &out.AlsoToStderr
+		*out = new(bool)
 		**out = **in
 	}
 	if in.StderrThreshold != nil {
@@ -756,114 +1410,505 @@ func (in *LoggingFlags) DeepCopyInto(out *LoggingFlags) {
 		*out = new(int)
 		**out = **in
 	}
-	if in.Verbosity != nil {
-		in, out := &in.Verbosity, &out.Verbosity
-		*out = new(int)
+	if in.Verbosity != nil {
+		in, out := &in.Verbosity, &out.Verbosity
+		*out = new(int)
+		**out = **in
+	}
+	if in.LogFileMaxSizeMB != nil {
+		in, out := &in.LogFileMaxSizeMB, &out.LogFileMaxSizeMB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SkipHeaders != nil {
+		in, out := &in.SkipHeaders, &out.SkipHeaders
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SkipLogHeaders != nil {
+		in, out := &in.SkipLogHeaders, &out.SkipLogHeaders
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AddDirHeader != nil {
+		in, out := &in.AddDirHeader, &out.AddDirHeader
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OneOutput != nil {
+		in, out := &in.OneOutput, &out.OneOutput
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingFlags.
+func (in *LoggingFlags) DeepCopy() *LoggingFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Monitoring) DeepCopyInto(out *Monitoring) {
+	*out = *in
+	if in.StaleScheduleThreshold != nil {
+		in, out := &in.StaleScheduleThreshold, &out.StaleScheduleThreshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Monitoring.
+func (in *Monitoring) DeepCopy() *Monitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(Monitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAgentCommonFields) DeepCopyInto(out *NodeAgentCommonFields) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SupplementalGroups != nil {
+		in, out := &in.SupplementalGroups, &out.SupplementalGroups
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodConfig != nil {
+		in, out := &in.PodConfig, &out.PodConfig
+		*out = new(PodConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAgentCommonFields.
+func (in *NodeAgentCommonFields) DeepCopy() *NodeAgentCommonFields {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAgentCommonFields)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAgentConfig) DeepCopyInto(out *NodeAgentConfig) {
+	*out = *in
+	in.NodeAgentCommonFields.DeepCopyInto(&out.NodeAgentCommonFields)
+	if in.DataMoverPrepareTimeout != nil {
+		in, out := &in.DataMoverPrepareTimeout, &out.DataMoverPrepareTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ResourceTimeout != nil {
+		in, out := &in.ResourceTimeout, &out.ResourceTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.NodeAgentConfigMapSettings.DeepCopyInto(&out.NodeAgentConfigMapSettings)
+	in.KopiaRepoOptions.DeepCopyInto(&out.KopiaRepoOptions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAgentConfig.
+func (in *NodeAgentConfig) DeepCopy() *NodeAgentConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAgentConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAgentConfigMapSettings) DeepCopyInto(out *NodeAgentConfigMapSettings) {
+	*out = *in
+	if in.LoadConcurrency != nil {
+		in, out := &in.LoadConcurrency, &out.LoadConcurrency
+		*out = new(LoadConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoadAffinityConfig != nil {
+		in, out := &in.LoadAffinityConfig, &out.LoadAffinityConfig
+		*out = make([]*LoadAffinity, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(LoadAffinity)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.BackupPVCConfig != nil {
+		in, out := &in.BackupPVCConfig, &out.BackupPVCConfig
+		*out = make(map[string]nodeagent.BackupPVC, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RestorePVCConfig != nil {
+		in, out := &in.RestorePVCConfig, &out.RestorePVCConfig
+		*out = new(nodeagent.RestorePVC)
+		**out = **in
+	}
+	if in.PodResources != nil {
+		in, out := &in.PodResources, &out.PodResources
+		*out = new(kube.PodResources)
 		**out = **in
 	}
-	if in.LogFileMaxSizeMB != nil {
-		in, out := &in.LogFileMaxSizeMB, &out.LogFileMaxSizeMB
-		*out = new(int64)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAgentConfigMapSettings.
+func (in *NodeAgentConfigMapSettings) DeepCopy() *NodeAgentConfigMapSettings {
+	if in == nil {
+		return nil
 	}
-	if in.SkipHeaders != nil {
-		in, out := &in.SkipHeaders, &out.SkipHeaders
+	out := new(NodeAgentConfigMapSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NonAdmin) DeepCopyInto(out *NonAdmin) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
 		*out = new(bool)
 		**out = **in
 	}
-	if in.SkipLogHeaders != nil {
-		in, out := &in.SkipLogHeaders, &out.SkipLogHeaders
+	if in.EnforceBackupSpec != nil {
+		in, out := &in.EnforceBackupSpec, &out.EnforceBackupSpec
+		*out = new(velerov1.BackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnforceRestoreSpec != nil {
+		in, out := &in.EnforceRestoreSpec, &out.EnforceRestoreSpec
+		*out = new(velerov1.RestoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnforceBSLSpec != nil {
+		in, out := &in.EnforceBSLSpec, &out.EnforceBSLSpec
+		*out = new(EnforceBackupStorageLocationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequireApprovalForBSL != nil {
+		in, out := &in.RequireApprovalForBSL, &out.RequireApprovalForBSL
 		*out = new(bool)
 		**out = **in
 	}
-	if in.AddDirHeader != nil {
-		in, out := &in.AddDirHeader, &out.AddDirHeader
-		*out = new(bool)
+	if in.GarbageCollectionPeriod != nil {
+		in, out := &in.GarbageCollectionPeriod, &out.GarbageCollectionPeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.OneOutput != nil {
-		in, out := &in.OneOutput, &out.OneOutput
-		*out = new(bool)
+	if in.BackupSyncPeriod != nil {
+		in, out := &in.BackupSyncPeriod, &out.BackupSyncPeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingFlags.
-func (in *LoggingFlags) DeepCopy() *LoggingFlags {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NonAdmin.
+func (in *NonAdmin) DeepCopy() *NonAdmin {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggingFlags)
+	out := new(NonAdmin)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeAgentCommonFields) DeepCopyInto(out *NodeAgentCommonFields) {
+func (in *NotificationDeliveryStatus) DeepCopyInto(out *NotificationDeliveryStatus) {
 	*out = *in
-	if in.Enable != nil {
-		in, out := &in.Enable, &out.Enable
-		*out = new(bool)
+	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationDeliveryStatus.
+func (in *NotificationDeliveryStatus) DeepCopy() *NotificationDeliveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationDeliveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSink) DeepCopyInto(out *NotificationSink) {
+	*out = *in
+	if in.URLSecret != nil {
+		in, out := &in.URLSecret, &out.URLSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSink.
+func (in *NotificationSink) DeepCopy() *NotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notifications) DeepCopyInto(out *Notifications) {
+	*out = *in
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]NotificationSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScheduleMissedGracePeriod != nil {
+		in, out := &in.ScheduleMissedGracePeriod, &out.ScheduleMissedGracePeriod
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.SupplementalGroups != nil {
-		in, out := &in.SupplementalGroups, &out.SupplementalGroups
-		*out = make([]int64, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notifications.
+func (in *Notifications) DeepCopy() *Notifications {
+	if in == nil {
+		return nil
+	}
+	out := new(Notifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageLocation) DeepCopyInto(out *ObjectStorageLocation) {
+	*out = *in
+	if in.CACert != nil {
+		in, out := &in.CACert, &out.CACert
+		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
-	if in.PodConfig != nil {
-		in, out := &in.PodConfig, &out.PodConfig
-		*out = new(PodConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageLocation.
+func (in *ObjectStorageLocation) DeepCopy() *ObjectStorageLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimCoverage) DeepCopyInto(out *PersistentVolumeClaimCoverage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimCoverage.
+func (in *PersistentVolumeClaimCoverage) DeepCopy() *PersistentVolumeClaimCoverage {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimCoverage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedChange) DeepCopyInto(out *PlannedChange) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedChange.
+func (in *PlannedChange) DeepCopy() *PlannedChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodConfig) DeepCopyInto(out *PodConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.ResourceAllocations.DeepCopyInto(&out.ResourceAllocations)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodConfig.
+func (in *PodConfig) DeepCopy() *PodConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodsReadyCheck) DeepCopyInto(out *PodsReadyCheck) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAgentCommonFields.
-func (in *NodeAgentCommonFields) DeepCopy() *NodeAgentCommonFields {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodsReadyCheck.
+func (in *PodsReadyCheck) DeepCopy() *PodsReadyCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeAgentCommonFields)
+	out := new(PodsReadyCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeAgentConfig) DeepCopyInto(out *NodeAgentConfig) {
+func (in *PrefixUsage) DeepCopyInto(out *PrefixUsage) {
 	*out = *in
-	in.NodeAgentCommonFields.DeepCopyInto(&out.NodeAgentCommonFields)
-	if in.DataMoverPrepareTimeout != nil {
-		in, out := &in.DataMoverPrepareTimeout, &out.DataMoverPrepareTimeout
-		*out = new(metav1.Duration)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrefixUsage.
+func (in *PrefixUsage) DeepCopy() *PrefixUsage {
+	if in == nil {
+		return nil
 	}
-	if in.ResourceTimeout != nil {
-		in, out := &in.ResourceTimeout, &out.ResourceTimeout
-		*out = new(metav1.Duration)
-		**out = **in
+	out := new(PrefixUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileStepStatus) DeepCopyInto(out *ReconcileStepStatus) {
+	*out = *in
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileStepStatus.
+func (in *ReconcileStepStatus) DeepCopy() *ReconcileStepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileStepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationTarget) DeepCopyInto(out *ReplicationTarget) {
+	*out = *in
+	if in.CACert != nil {
+		in, out := &in.CACert, &out.CACert
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Credential != nil {
+		in, out := &in.Credential, &out.Credential
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
-	in.NodeAgentConfigMapSettings.DeepCopyInto(&out.NodeAgentConfigMapSettings)
-	in.KopiaRepoOptions.DeepCopyInto(&out.KopiaRepoOptions)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAgentConfig.
-func (in *NodeAgentConfig) DeepCopy() *NodeAgentConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationTarget.
+func (in *ReplicationTarget) DeepCopy() *ReplicationTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeAgentConfig)
+	out := new(ReplicationTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeAgentConfigMapSettings) DeepCopyInto(out *NodeAgentConfigMapSettings) {
+func (in *RepositoryMaintenanceConfig) DeepCopyInto(out *RepositoryMaintenanceConfig) {
 	*out = *in
-	if in.LoadConcurrency != nil {
-		in, out := &in.LoadConcurrency, &out.LoadConcurrency
-		*out = new(LoadConcurrency)
-		(*in).DeepCopyInto(*out)
-	}
 	if in.LoadAffinityConfig != nil {
 		in, out := &in.LoadAffinityConfig, &out.LoadAffinityConfig
 		*out = make([]*LoadAffinity, len(*in))
@@ -875,18 +1920,6 @@ func (in *NodeAgentConfigMapSettings) DeepCopyInto(out *NodeAgentConfigMapSettin
 			}
 		}
 	}
-	if in.BackupPVCConfig != nil {
-		in, out := &in.BackupPVCConfig, &out.BackupPVCConfig
-		*out = make(map[string]nodeagent.BackupPVC, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.RestorePVCConfig != nil {
-		in, out := &in.RestorePVCConfig, &out.RestorePVCConfig
-		*out = new(nodeagent.RestorePVC)
-		**out = **in
-	}
 	if in.PodResources != nil {
 		in, out := &in.PodResources, &out.PodResources
 		*out = new(kube.PodResources)
@@ -894,188 +1927,287 @@ func (in *NodeAgentConfigMapSettings) DeepCopyInto(out *NodeAgentConfigMapSettin
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAgentConfigMapSettings.
-func (in *NodeAgentConfigMapSettings) DeepCopy() *NodeAgentConfigMapSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryMaintenanceConfig.
+func (in *RepositoryMaintenanceConfig) DeepCopy() *RepositoryMaintenanceConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeAgentConfigMapSettings)
+	out := new(RepositoryMaintenanceConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NonAdmin) DeepCopyInto(out *NonAdmin) {
+func (in *ResticConfig) DeepCopyInto(out *ResticConfig) {
 	*out = *in
-	if in.Enable != nil {
-		in, out := &in.Enable, &out.Enable
-		*out = new(bool)
-		**out = **in
+	in.NodeAgentCommonFields.DeepCopyInto(&out.NodeAgentCommonFields)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticConfig.
+func (in *ResticConfig) DeepCopy() *ResticConfig {
+	if in == nil {
+		return nil
 	}
-	if in.EnforceBackupSpec != nil {
-		in, out := &in.EnforceBackupSpec, &out.EnforceBackupSpec
-		*out = new(velerov1.BackupSpec)
-		(*in).DeepCopyInto(*out)
+	out := new(ResticConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerification) DeepCopyInto(out *RestoreVerification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerification.
+func (in *RestoreVerification) DeepCopy() *RestoreVerification {
+	if in == nil {
+		return nil
 	}
-	if in.EnforceRestoreSpec != nil {
-		in, out := &in.EnforceRestoreSpec, &out.EnforceRestoreSpec
-		*out = new(velerov1.RestoreSpec)
-		(*in).DeepCopyInto(*out)
+	out := new(RestoreVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RestoreVerification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	if in.EnforceBSLSpec != nil {
-		in, out := &in.EnforceBSLSpec, &out.EnforceBSLSpec
-		*out = new(EnforceBackupStorageLocationSpec)
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationCheck) DeepCopyInto(out *RestoreVerificationCheck) {
+	*out = *in
+	if in.PodsReady != nil {
+		in, out := &in.PodsReady, &out.PodsReady
+		*out = new(PodsReadyCheck)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.RequireApprovalForBSL != nil {
-		in, out := &in.RequireApprovalForBSL, &out.RequireApprovalForBSL
-		*out = new(bool)
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(HTTPGetCheck)
 		**out = **in
 	}
-	if in.GarbageCollectionPeriod != nil {
-		in, out := &in.GarbageCollectionPeriod, &out.GarbageCollectionPeriod
-		*out = new(metav1.Duration)
-		**out = **in
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobCheck)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.BackupSyncPeriod != nil {
-		in, out := &in.BackupSyncPeriod, &out.BackupSyncPeriod
-		*out = new(metav1.Duration)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationCheck.
+func (in *RestoreVerificationCheck) DeepCopy() *RestoreVerificationCheck {
+	if in == nil {
+		return nil
 	}
+	out := new(RestoreVerificationCheck)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NonAdmin.
-func (in *NonAdmin) DeepCopy() *NonAdmin {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationCheckResult) DeepCopyInto(out *RestoreVerificationCheckResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationCheckResult.
+func (in *RestoreVerificationCheckResult) DeepCopy() *RestoreVerificationCheckResult {
 	if in == nil {
 		return nil
 	}
-	out := new(NonAdmin)
+	out := new(RestoreVerificationCheckResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStorageLocation) DeepCopyInto(out *ObjectStorageLocation) {
+func (in *RestoreVerificationList) DeepCopyInto(out *RestoreVerificationList) {
 	*out = *in
-	if in.CACert != nil {
-		in, out := &in.CACert, &out.CACert
-		*out = make([]byte, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RestoreVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationList.
+func (in *RestoreVerificationList) DeepCopy() *RestoreVerificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RestoreVerificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationRun) DeepCopyInto(out *RestoreVerificationRun) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.RestoreCompletionTime != nil {
+		in, out := &in.RestoreCompletionTime, &out.RestoreCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RTO != nil {
+		in, out := &in.RTO, &out.RTO
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]RestoreVerificationCheckResult, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageLocation.
-func (in *ObjectStorageLocation) DeepCopy() *ObjectStorageLocation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationRun.
+func (in *RestoreVerificationRun) DeepCopy() *RestoreVerificationRun {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStorageLocation)
+	out := new(RestoreVerificationRun)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PodConfig) DeepCopyInto(out *PodConfig) {
+func (in *RestoreVerificationSpec) DeepCopyInto(out *RestoreVerificationSpec) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
+	if in.NamespaceMapping != nil {
+		in, out := &in.NamespaceMapping, &out.NamespaceMapping
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
+	if in.StorageClassMapping != nil {
+		in, out := &in.StorageClassMapping, &out.StorageClassMapping
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]RestoreVerificationCheck, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.ResourceAllocations.DeepCopyInto(&out.ResourceAllocations)
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make([]v1.EnvVar, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodConfig.
-func (in *PodConfig) DeepCopy() *PodConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationSpec.
+func (in *RestoreVerificationSpec) DeepCopy() *RestoreVerificationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PodConfig)
+	out := new(RestoreVerificationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepositoryMaintenanceConfig) DeepCopyInto(out *RepositoryMaintenanceConfig) {
+func (in *RestoreVerificationStatus) DeepCopyInto(out *RestoreVerificationStatus) {
 	*out = *in
-	if in.LoadAffinityConfig != nil {
-		in, out := &in.LoadAffinityConfig, &out.LoadAffinityConfig
-		*out = make([]*LoadAffinity, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(LoadAffinity)
-				(*in).DeepCopyInto(*out)
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.PodResources != nil {
-		in, out := &in.PodResources, &out.PodResources
-		*out = new(kube.PodResources)
-		**out = **in
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CurrentRun != nil {
+		in, out := &in.CurrentRun, &out.CurrentRun
+		*out = new(RestoreVerificationRun)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastRun != nil {
+		in, out := &in.LastRun, &out.LastRun
+		*out = new(RestoreVerificationRun)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSuccessfulRunTime != nil {
+		in, out := &in.LastSuccessfulRunTime, &out.LastSuccessfulRunTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryMaintenanceConfig.
-func (in *RepositoryMaintenanceConfig) DeepCopy() *RepositoryMaintenanceConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationStatus.
+func (in *RestoreVerificationStatus) DeepCopy() *RestoreVerificationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RepositoryMaintenanceConfig)
+	out := new(RestoreVerificationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResticConfig) DeepCopyInto(out *ResticConfig) {
+func (in *RetainPolicy) DeepCopyInto(out *RetainPolicy) {
 	*out = *in
-	in.NodeAgentCommonFields.DeepCopyInto(&out.NodeAgentCommonFields)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticConfig.
-func (in *ResticConfig) DeepCopy() *ResticConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainPolicy.
+func (in *RetainPolicy) DeepCopy() *RetainPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ResticConfig)
+	out := new(RetainPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RetainPolicy) DeepCopyInto(out *RetainPolicy) {
+func (in *RolloutGate) DeepCopyInto(out *RolloutGate) {
 	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainPolicy.
-func (in *RetainPolicy) DeepCopy() *RetainPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutGate.
+func (in *RolloutGate) DeepCopy() *RolloutGate {
 	if in == nil {
 		return nil
 	}
-	out := new(RetainPolicy)
+	out := new(RolloutGate)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1096,6 +2228,21 @@ func (in *RuledConfigs) DeepCopy() *RuledConfigs {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretProviderClassCredential) DeepCopyInto(out *SecretProviderClassCredential) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretProviderClassCredential.
+func (in *SecretProviderClassCredential) DeepCopy() *SecretProviderClassCredential {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretProviderClassCredential)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerFlags) DeepCopyInto(out *ServerFlags) {
 	*out = *in
@@ -1199,6 +2346,11 @@ func (in *SnapshotLocation) DeepCopyInto(out *SnapshotLocation) {
 		*out = new(velerov1.VolumeSnapshotLocationSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SecretProviderClass != nil {
+		in, out := &in.SecretProviderClass, &out.SecretProviderClass
+		*out = new(SecretProviderClassCredential)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLocation.
@@ -1211,6 +2363,31 @@ func (in *SnapshotLocation) DeepCopy() *SnapshotLocation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotProvider) DeepCopyInto(out *SnapshotProvider) {
+	*out = *in
+	if in.ConfigKeys != nil {
+		in, out := &in.ConfigKeys, &out.ConfigKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredConfigKeys != nil {
+		in, out := &in.RequiredConfigKeys, &out.RequiredConfigKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotProvider.
+func (in *SnapshotProvider) DeepCopy() *SnapshotProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SnapshotTestStatus) DeepCopyInto(out *SnapshotTestStatus) {
 	*out = *in
@@ -1226,6 +2403,30 @@ func (in *SnapshotTestStatus) DeepCopy() *SnapshotTestStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaleNamespace) DeepCopyInto(out *StaleNamespace) {
+	*out = *in
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSuccessfulBackup != nil {
+		in, out := &in.LastSuccessfulBackup, &out.LastSuccessfulBackup
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaleNamespace.
+func (in *StaleNamespace) DeepCopy() *StaleNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(StaleNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageType) DeepCopyInto(out *StorageType) {
 	*out = *in
@@ -1293,7 +2494,14 @@ func (in *VeleroConfig) DeepCopyInto(out *VeleroConfig) {
 	if in.CustomPlugins != nil {
 		in, out := &in.CustomPlugins, &out.CustomPlugins
 		*out = make([]CustomPlugin, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MountLocationCredentials != nil {
+		in, out := &in.MountLocationCredentials, &out.MountLocationCredentials
+		*out = new(bool)
+		**out = **in
 	}
 	if in.PodConfig != nil {
 		in, out := &in.PodConfig, &out.PodConfig
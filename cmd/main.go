@@ -30,6 +30,7 @@ import (
 	security "github.com/openshift/api/security/v1"
 	monitor "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	velerov2alpha1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v2alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -67,7 +68,7 @@ var (
 
 const (
 	// WebIdentityTokenPath mount present on operator CSV
-	WebIdentityTokenPath = "/var/run/secrets/openshift/serviceaccount/token"
+	WebIdentityTokenPath = common.BoundServiceAccountTokenPath
 
 	// CloudCredentials API constants
 	CloudCredentialGroupVersion = "cloudcredential.openshift.io/v1"
@@ -136,13 +137,14 @@ func main() {
 		os.Exit(1)
 	}
 
-	// check if this is standardized STS workflow via OLM and CCO
-	if common.CCOWorkflow() {
-		setupLog.Info("AWS Role ARN specified by the user, following standardized STS workflow")
-		// ROLEARN env var is set via operator subscription
-		roleARN := os.Getenv("ROLEARN")
-		setupLog.Info("getting role ARN", "role ARN =", roleARN)
+	// the Velero and NodeAgent pods get the Azure identity as env vars, there is no secret for CCO to write
+	if azureClientID, _, _, ok := common.AzureWorkloadIdentity(); ok {
+		setupLog.Info("Azure client ID specified by the user, following Azure Workload Identity workflow", "client ID", azureClientID)
+	}
 
+	// check if this is standardized STS workflow via OLM and CCO
+	gcpAudience, gcpServiceAccountEmail, gcpWorkloadIdentityFederation := common.GCPWorkloadIdentityFederation()
+	if common.CCOWorkflow() || gcpWorkloadIdentityFederation {
 		// check if cred request API exists in the cluster before creating a cred request
 		setupLog.Info("Checking if credentialsrequest CRD exists in the cluster")
 		credReqCRDExists, err := DoesCRDExist(CloudCredentialGroupVersion, CloudCredentialsCRDName, kubeconf)
@@ -151,15 +153,23 @@ func main() {
 			os.Exit(1)
 		}
 
-		if credReqCRDExists {
-			// create cred request
+		var credRequestErr error
+		switch {
+		case !credReqCRDExists:
+		case common.CCOWorkflow():
+			setupLog.Info("AWS Role ARN specified by the user, following standardized STS workflow")
+			// ROLEARN env var is set via operator subscription
+			roleARN := os.Getenv("ROLEARN")
+			setupLog.Info("getting role ARN", "role ARN =", roleARN)
 			setupLog.Info(fmt.Sprintf("Creating credentials request for role: %s, and WebIdentityTokenPath: %s", roleARN, WebIdentityTokenPath))
-			if err := CreateOrUpdateCredRequest(roleARN, WebIdentityTokenPath, watchNamespace, kubeconf); err != nil {
-				if !errors.IsAlreadyExists(err) {
-					setupLog.Error(err, "unable to create credRequest")
-					os.Exit(1)
-				}
-			}
+			credRequestErr = CreateOrUpdateCredRequest(roleARN, WebIdentityTokenPath, watchNamespace, kubeconf)
+		case gcpWorkloadIdentityFederation:
+			setupLog.Info("GCP service account specified by the user, following GCP Workload Identity Federation workflow", "service account", gcpServiceAccountEmail)
+			credRequestErr = CreateOrUpdateGCPCredRequest(gcpAudience, gcpServiceAccountEmail, WebIdentityTokenPath, watchNamespace, kubeconf)
+		}
+		if credRequestErr != nil && !errors.IsAlreadyExists(credRequestErr) {
+			setupLog.Error(credRequestErr, "unable to create credRequest")
+			os.Exit(1)
 		}
 	}
 
@@ -229,6 +239,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := velerov2alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		setupLog.Error(err, "unable to add Velero v2alpha1 APIs to scheme")
+		os.Exit(1)
+	}
+
 	if err := appsv1.AddToScheme(mgr.GetScheme()); err != nil {
 		setupLog.Error(err, "unable to add Kubernetes APIs to scheme")
 		os.Exit(1)
@@ -288,6 +303,64 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "DataProtectionTest")
 		os.Exit(1)
 	}
+
+	if err = (&controller.BackupPolicyReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("BackupPolicy-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupPolicy")
+		os.Exit(1)
+	}
+
+	if err = (&controller.BackupCoverageReportReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		EventRecorder:     mgr.GetEventRecorderFor("BackupCoverageReport-controller"),
+		ClusterWideClient: uncachedClient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupCoverageReport")
+		os.Exit(1)
+	}
+
+	if err = (&controller.RestoreVerificationReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		EventRecorder:     mgr.GetEventRecorderFor("RestoreVerification-controller"),
+		ClusterWideClient: uncachedClient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RestoreVerification")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NotificationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("Notification-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Notification")
+		os.Exit(1)
+	}
+
+	if err = (&controller.BackupReplicationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("BackupReplication-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupReplication")
+		os.Exit(1)
+	}
+
+	// webhooks need serving certificates, which OLM provides; disable them to run the manager locally
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&controller.DataProtectionApplicationWebhook{
+			Client:            mgr.GetClient(),
+			ClusterWideClient: uncachedClient,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DataProtectionApplication")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -376,48 +449,83 @@ func DoesCRDExist(CRDGroupVersion, CRDName string, kubeconf *rest.Config) (bool,
 
 // CreateCredRequest WITP : WebIdentityTokenPath
 func CreateOrUpdateCredRequest(roleARN string, WITP string, secretNS string, kubeconf *rest.Config) error {
-	clientInstance, err := client.New(kubeconf, client.Options{})
-	if err != nil {
-		setupLog.Error(err, "unable to create client")
-	}
+	return createOrUpdateCredRequest(awsCredRequest(roleARN, WITP, secretNS), kubeconf)
+}
+
+// CreateOrUpdateGCPCredRequest creates the credentials request of the GCP Workload Identity Federation
+func CreateOrUpdateGCPCredRequest(audience, serviceAccountEmail, WITP, secretNS string, kubeconf *rest.Config) error {
+	return createOrUpdateCredRequest(gcpCredRequest(audience, serviceAccountEmail, WITP, secretNS), kubeconf)
+}
 
-	// Extra deps were getting added and existing ones were getting upgraded when the CloudCredentials API was imported
-	// This caused updates to go.mod and started resulting in operator build failures due to incompatibility with the existing velero deps
-	// Hence for now going via the unstructured route
-	credRequest := &unstructured.Unstructured{
+// Extra deps were getting added and existing ones were getting upgraded when the CloudCredentials API was imported
+// This caused updates to go.mod and started resulting in operator build failures due to incompatibility with the existing velero deps
+// Hence for now going via the unstructured route
+func newCredRequest(name, secretName, secretNS, WITP string, serviceAccountNames []interface{}, providerSpec map[string]interface{}) *unstructured.Unstructured {
+	providerSpec["apiVersion"] = "cloudcredential.openshift.io/v1"
+	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "cloudcredential.openshift.io/v1",
 			"kind":       "CredentialsRequest",
 			"metadata": map[string]interface{}{
-				"name":      "oadp-aws-credentials-request",
+				"name":      name,
 				"namespace": "openshift-cloud-credential-operator",
 			},
 			"spec": map[string]interface{}{
 				"secretRef": map[string]interface{}{
-					"name":      "cloud-credentials",
+					"name":      secretName,
 					"namespace": secretNS,
 				},
-				"serviceAccountNames": []interface{}{
-					common.OADPOperatorServiceAccount,
-				},
-				"providerSpec": map[string]interface{}{
-					"apiVersion": "cloudcredential.openshift.io/v1",
-					"kind":       "AWSProviderSpec",
-					"statementEntries": []interface{}{
-						map[string]interface{}{
-							"effect": "Allow",
-							"action": []interface{}{
-								"s3:*",
-							},
-							"resource": "arn:aws:s3:*:*:*",
-						},
+				"serviceAccountNames": serviceAccountNames,
+				"providerSpec":        providerSpec,
+				"cloudTokenPath":      WITP,
+			},
+		},
+	}
+}
+
+func awsCredRequest(roleARN, WITP, secretNS string) *unstructured.Unstructured {
+	return newCredRequest("oadp-aws-credentials-request", "cloud-credentials", secretNS, WITP,
+		[]interface{}{common.OADPOperatorServiceAccount},
+		map[string]interface{}{
+			"kind": "AWSProviderSpec",
+			"statementEntries": []interface{}{
+				map[string]interface{}{
+					"effect": "Allow",
+					"action": []interface{}{
+						"s3:*",
 					},
-					"stsIAMRoleARN": roleARN,
+					"resource": "arn:aws:s3:*:*:*",
 				},
-				"cloudTokenPath": WITP,
 			},
-		},
+			"stsIAMRoleARN": roleARN,
+		})
+}
+
+// CCO writes the external account credentials of the service account to the service_account.json key of cloud-credentials-gcp
+func gcpCredRequest(audience, serviceAccountEmail, WITP, secretNS string) *unstructured.Unstructured {
+	return newCredRequest("oadp-gcp-credentials-request", "cloud-credentials-gcp", secretNS, WITP,
+		[]interface{}{common.OADPOperatorServiceAccount, common.Velero},
+		map[string]interface{}{
+			"kind": "GCPProviderSpec",
+			"predefinedRoles": []interface{}{
+				"roles/compute.storageAdmin",
+				"roles/iam.serviceAccountUser",
+				"roles/storage.objectAdmin",
+				"roles/iam.serviceAccountTokenCreator",
+			},
+			"skipServiceCheck":    true,
+			"audience":            audience,
+			"serviceAccountEmail": serviceAccountEmail,
+		})
+}
+
+func createOrUpdateCredRequest(credRequest *unstructured.Unstructured, kubeconf *rest.Config) error {
+	clientInstance, err := client.New(kubeconf, client.Options{})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return err
 	}
+
 	verb := "created"
 	if err := clientInstance.Create(context.Background(), credRequest); err != nil {
 		if errors.IsAlreadyExists(err) {
@@ -429,7 +537,7 @@ func CreateOrUpdateCredRequest(roleARN string, WITP string, secretNS string, kub
 					"kind":       "CredentialsRequest",
 				},
 			}
-			err = clientInstance.Get(context.Background(), types.NamespacedName{Name: "oadp-aws-credentials-request", Namespace: "openshift-cloud-credential-operator"}, fromCluster)
+			err = clientInstance.Get(context.Background(), types.NamespacedName{Name: credRequest.GetName(), Namespace: credRequest.GetNamespace()}, fromCluster)
 			if err != nil {
 				setupLog.Error(err, "unable to get existing credentials request resource")
 				return err
@@ -445,6 +553,6 @@ func CreateOrUpdateCredRequest(roleARN string, WITP string, secretNS string, kub
 			return err
 		}
 	}
-	setupLog.Info("Custom resource credentialsrequest " + verb + " successfully")
+	setupLog.Info("Custom resource credentialsrequest " + credRequest.GetName() + " " + verb + " successfully")
 	return nil
 }
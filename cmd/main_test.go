@@ -22,6 +22,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/fake"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
@@ -112,3 +113,57 @@ func TestAddPodSecurityPrivilegedLabels(t *testing.T) {
 		})
 	}
 }
+
+// Tests the CredentialsRequests of the cloud providers reference their secret, service accounts and identity
+func TestCredRequests(t *testing.T) {
+	var testNamespaceName = "openshift-adp"
+	tests := []struct {
+		name                string
+		credRequest         *unstructured.Unstructured
+		expectedName        string
+		expectedSecret      string
+		expectedKind        string
+		expectedProviderKey string
+		expectedProviderVal string
+	}{
+		{
+			name:                "AWS STS",
+			credRequest:         awsCredRequest("arn:aws:iam::123456789012:role/oadp", WebIdentityTokenPath, testNamespaceName),
+			expectedName:        "oadp-aws-credentials-request",
+			expectedSecret:      "cloud-credentials",
+			expectedKind:        "AWSProviderSpec",
+			expectedProviderKey: "stsIAMRoleARN",
+			expectedProviderVal: "arn:aws:iam::123456789012:role/oadp",
+		},
+		{
+			name:                "GCP Workload Identity Federation",
+			credRequest:         gcpCredRequest("//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider", "velero@project.iam.gserviceaccount.com", WebIdentityTokenPath, testNamespaceName),
+			expectedName:        "oadp-gcp-credentials-request",
+			expectedSecret:      "cloud-credentials-gcp",
+			expectedKind:        "GCPProviderSpec",
+			expectedProviderKey: "audience",
+			expectedProviderVal: "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.credRequest.GetName() != tt.expectedName || tt.credRequest.GetNamespace() != "openshift-cloud-credential-operator" {
+				t.Errorf("credentials request is %s/%s, instead of openshift-cloud-credential-operator/%s", tt.credRequest.GetNamespace(), tt.credRequest.GetName(), tt.expectedName)
+			}
+			secretName, _, _ := unstructured.NestedString(tt.credRequest.Object, "spec", "secretRef", "name")
+			secretNamespace, _, _ := unstructured.NestedString(tt.credRequest.Object, "spec", "secretRef", "namespace")
+			if secretName != tt.expectedSecret || secretNamespace != testNamespaceName {
+				t.Errorf("secretRef is %s/%s, instead of %s/%s", secretNamespace, secretName, testNamespaceName, tt.expectedSecret)
+			}
+			if tokenPath, _, _ := unstructured.NestedString(tt.credRequest.Object, "spec", "cloudTokenPath"); tokenPath != WebIdentityTokenPath {
+				t.Errorf("cloudTokenPath is %v, instead of %v", tokenPath, WebIdentityTokenPath)
+			}
+			if kind, _, _ := unstructured.NestedString(tt.credRequest.Object, "spec", "providerSpec", "kind"); kind != tt.expectedKind {
+				t.Errorf("providerSpec kind is %v, instead of %v", kind, tt.expectedKind)
+			}
+			if value, _, _ := unstructured.NestedString(tt.credRequest.Object, "spec", "providerSpec", tt.expectedProviderKey); value != tt.expectedProviderVal {
+				t.Errorf("providerSpec %v is %v, instead of %v", tt.expectedProviderKey, value, tt.expectedProviderVal)
+			}
+		})
+	}
+}
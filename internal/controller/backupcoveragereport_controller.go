@@ -0,0 +1,431 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	snapshotv1api "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"github.com/vmware-tanzu/velero/pkg/util/boolptr"
+	"github.com/vmware-tanzu/velero/pkg/util/collections"
+	"github.com/vmware-tanzu/velero/pkg/util/podvolume"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const (
+	defaultCoverageStaleThreshold = 25 * time.Hour
+	defaultCoverageRefreshPeriod  = time.Hour
+	// coverageReportMaxItems caps the lists of the report status, the summary counts every item
+	coverageReportMaxItems = 100
+)
+
+// defaultCoverageExcludedNamespaces are the namespaces of the platform, not reported on unless the report
+// sets its own excluded namespaces
+var defaultCoverageExcludedNamespaces = []string{"openshift", "openshift-*", "kube-*"}
+
+// BackupCoverageReportReconciler reconciles a BackupCoverageReport object
+type BackupCoverageReportReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+	// ClusterWideClient reads the namespaces, persistent volume claims, pods and persistent volumes of the cluster,
+	// outside of the namespace the manager cache is restricted to
+	ClusterWideClient client.Client
+}
+
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=backupcoveragereports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=backupcoveragereports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=backupcoveragereports/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch
+
+// Reconcile computes the backup coverage of the namespaces and persistent volume claims of the cluster
+func (r *BackupCoverageReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("backupcoveragereport", req.NamespacedName)
+	report := &oadpv1alpha1.BackupCoverageReport{}
+	if err := r.Get(ctx, req.NamespacedName, report); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	refreshPeriod := defaultCoverageRefreshPeriod
+	if report.Spec.RefreshPeriod != nil && report.Spec.RefreshPeriod.Duration > 0 {
+		refreshPeriod = report.Spec.RefreshPeriod.Duration
+	}
+
+	coverage, err := r.coverage(ctx, report, time.Now())
+	if err != nil {
+		logger.Error(err, "unable to compute the backup coverage")
+		r.EventRecorder.Event(report, corev1.EventTypeWarning, "BackupCoverageReportFailed", err.Error())
+	}
+	return ctrl.Result{RequeueAfter: refreshPeriod}, r.updateBackupCoverageReportStatus(ctx, report, coverage, err)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupCoverageReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&oadpv1alpha1.BackupCoverageReport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// coverageSchedule is a schedule protecting namespaces, with the defaults of the Velero server applied to its template
+type coverageSchedule struct {
+	name       string
+	created    time.Time
+	namespaces *collections.IncludesExcludes
+	template   velerov1.BackupSpec
+	// lastSuccessfulBackup is the completion time of the last completed backup of the schedule
+	lastSuccessfulBackup *metav1.Time
+}
+
+// coverageVolumes is what the backup method of the persistent volume claims of a namespace depends on
+type coverageVolumes struct {
+	// fsBackup is the persistent volume claims mounted in pod volumes backed up by the node agent,
+	// by default and when opted in only
+	fsBackup map[bool]map[string]bool
+	// csiDrivers is the CSI driver of each persistent volume
+	csiDrivers map[string]string
+	// snapshotClasses is the VolumeSnapshotClasses by name, and veleroSnapshotClassDrivers the drivers having
+	// a VolumeSnapshotClass labelled for Velero
+	snapshotClasses            map[string]snapshotv1api.VolumeSnapshotClass
+	veleroSnapshotClassDrivers map[string]bool
+}
+
+// coverage returns the status of the report
+func (r *BackupCoverageReportReconciler) coverage(ctx context.Context, report *oadpv1alpha1.BackupCoverageReport, now time.Time) (*oadpv1alpha1.BackupCoverageReportStatus, error) {
+	schedules, err := r.coverageSchedules(ctx, report.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := r.ClusterWideClient.List(ctx, namespaces); err != nil {
+		return nil, fmt.Errorf("unable to list namespaces: %w", err)
+	}
+	persistentVolumes := &corev1.PersistentVolumeList{}
+	if err := r.ClusterWideClient.List(ctx, persistentVolumes); err != nil {
+		return nil, fmt.Errorf("unable to list persistent volumes: %w", err)
+	}
+	snapshotClasses := &snapshotv1api.VolumeSnapshotClassList{}
+	if err := r.ClusterWideClient.List(ctx, snapshotClasses); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("unable to list volume snapshot classes: %w", err)
+	}
+	volumes := coverageVolumes{
+		csiDrivers:                 map[string]string{},
+		snapshotClasses:            map[string]snapshotv1api.VolumeSnapshotClass{},
+		veleroSnapshotClassDrivers: map[string]bool{},
+	}
+	for _, pv := range persistentVolumes.Items {
+		if pv.Spec.CSI != nil {
+			volumes.csiDrivers[pv.Name] = pv.Spec.CSI.Driver
+		}
+	}
+	for _, snapshotClass := range snapshotClasses.Items {
+		volumes.snapshotClasses[snapshotClass.Name] = snapshotClass
+		if snapshotClass.Labels[velerov1.VolumeSnapshotClassSelectorLabel] == "true" {
+			volumes.veleroSnapshotClassDrivers[snapshotClass.Driver] = true
+		}
+	}
+
+	staleThreshold := defaultCoverageStaleThreshold
+	if report.Spec.StaleThreshold != nil {
+		staleThreshold = report.Spec.StaleThreshold.Duration
+	}
+	excludedNamespaces := report.Spec.ExcludedNamespaces
+	if len(excludedNamespaces) == 0 {
+		excludedNamespaces = defaultCoverageExcludedNamespaces
+	}
+	reported := collections.NewIncludesExcludes().Includes(report.Spec.IncludedNamespaces...).Excludes(excludedNamespaces...)
+
+	status := &oadpv1alpha1.BackupCoverageReportStatus{}
+	for _, namespace := range namespaces.Items {
+		if !reported.ShouldInclude(namespace.Name) {
+			continue
+		}
+		status.Summary.Namespaces++
+		protecting := []coverageSchedule{}
+		for _, schedule := range schedules {
+			if schedule.namespaces.ShouldInclude(namespace.Name) {
+				protecting = append(protecting, schedule)
+			}
+		}
+		if len(protecting) == 0 {
+			status.Summary.UnprotectedNamespaces++
+			status.UnprotectedNamespaces = append(status.UnprotectedNamespaces, namespace.Name)
+			continue
+		}
+		status.Summary.ProtectedNamespaces++
+		if stale, lastSuccessfulBackup := isCoverageStale(protecting, staleThreshold, now); stale {
+			status.Summary.StaleNamespaces++
+			staleNamespace := oadpv1alpha1.StaleNamespace{Namespace: namespace.Name, LastSuccessfulBackup: lastSuccessfulBackup}
+			for _, schedule := range protecting {
+				staleNamespace.Schedules = append(staleNamespace.Schedules, schedule.name)
+			}
+			status.StaleNamespaces = append(status.StaleNamespaces, staleNamespace)
+		}
+
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		if err := r.ClusterWideClient.List(ctx, pvcs, client.InNamespace(namespace.Name)); err != nil {
+			return nil, fmt.Errorf("unable to list persistent volume claims of namespace %s: %w", namespace.Name, err)
+		}
+		if len(pvcs.Items) == 0 {
+			continue
+		}
+		pods := &corev1.PodList{}
+		if err := r.ClusterWideClient.List(ctx, pods, client.InNamespace(namespace.Name)); err != nil {
+			return nil, fmt.Errorf("unable to list pods of namespace %s: %w", namespace.Name, err)
+		}
+		volumes.fsBackup = fsBackupPersistentVolumeClaims(pods.Items)
+		for _, pvc := range pvcs.Items {
+			status.Summary.PersistentVolumeClaims++
+			coverage := persistentVolumeClaimCoverage(&pvc, protecting, volumes)
+			switch coverage.BackupMethod {
+			case oadpv1alpha1.VolumeBackupMethodNone:
+				status.Summary.UnprotectedPersistentVolumeClaims++
+			case oadpv1alpha1.VolumeBackupMethodUnknown:
+				status.Summary.UnknownBackupMethodPersistentVolumeClaims++
+			default:
+				continue
+			}
+			status.PersistentVolumeClaims = append(status.PersistentVolumeClaims, coverage)
+		}
+	}
+
+	slices.Sort(status.UnprotectedNamespaces)
+	status.UnprotectedNamespaces = status.UnprotectedNamespaces[:min(len(status.UnprotectedNamespaces), coverageReportMaxItems)]
+	slices.SortFunc(status.StaleNamespaces, func(a, b oadpv1alpha1.StaleNamespace) int {
+		return cmp.Compare(a.Namespace, b.Namespace)
+	})
+	status.StaleNamespaces = status.StaleNamespaces[:min(len(status.StaleNamespaces), coverageReportMaxItems)]
+	slices.SortFunc(status.PersistentVolumeClaims, func(a, b oadpv1alpha1.PersistentVolumeClaimCoverage) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	status.PersistentVolumeClaims = status.PersistentVolumeClaims[:min(len(status.PersistentVolumeClaims), coverageReportMaxItems)]
+	return status, nil
+}
+
+// coverageSchedules returns the schedules of the namespace creating backups
+func (r *BackupCoverageReportReconciler) coverageSchedules(ctx context.Context, namespace string) ([]coverageSchedule, error) {
+	schedules := &velerov1.ScheduleList{}
+	if err := r.List(ctx, schedules, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	backups := &velerov1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	lastSuccessfulBackups := map[string]*metav1.Time{}
+	for _, backup := range backups.Items {
+		scheduleName := backup.Labels[velerov1.ScheduleNameLabel]
+		if scheduleName == "" || backup.Status.Phase != velerov1.BackupPhaseCompleted || backup.Status.CompletionTimestamp == nil {
+			continue
+		}
+		if last := lastSuccessfulBackups[scheduleName]; last == nil || last.Before(backup.Status.CompletionTimestamp) {
+			lastSuccessfulBackups[scheduleName] = backup.Status.CompletionTimestamp
+		}
+	}
+	dpas := &oadpv1alpha1.DataProtectionApplicationList{}
+	if err := r.List(ctx, dpas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	coverageSchedules := []coverageSchedule{}
+	for _, schedule := range schedules.Items {
+		if schedule.Spec.Paused {
+			continue
+		}
+		template := *schedule.Spec.Template.DeepCopy()
+		// the defaults of the Velero server, for the backups not setting them
+		for _, dpa := range dpas.Items {
+			if dpa.Spec.Configuration == nil || dpa.Spec.Configuration.Velero == nil {
+				continue
+			}
+			if template.DefaultVolumesToFsBackup == nil {
+				template.DefaultVolumesToFsBackup = dpa.Spec.Configuration.Velero.DefaultVolumesToFSBackup
+			}
+			if template.SnapshotMoveData == nil {
+				template.SnapshotMoveData = dpa.Spec.Configuration.Velero.DefaultSnapshotMoveData
+			}
+		}
+		coverageSchedules = append(coverageSchedules, coverageSchedule{
+			name:                 schedule.Name,
+			created:              schedule.CreationTimestamp.Time,
+			namespaces:           collections.NewIncludesExcludes().Includes(template.IncludedNamespaces...).Excludes(template.ExcludedNamespaces...),
+			template:             template,
+			lastSuccessfulBackup: lastSuccessfulBackups[schedule.Name],
+		})
+	}
+	return coverageSchedules, nil
+}
+
+// isCoverageStale returns whether the last successful backup of the schedules protecting a namespace is older than
+// the threshold, and the last successful backup. Schedules newer than the threshold are not stale yet.
+func isCoverageStale(schedules []coverageSchedule, threshold time.Duration, now time.Time) (bool, *metav1.Time) {
+	var lastSuccessfulBackup *metav1.Time
+	oldest := now
+	for _, schedule := range schedules {
+		if lastSuccessfulBackup == nil || lastSuccessfulBackup.Before(schedule.lastSuccessfulBackup) {
+			lastSuccessfulBackup = schedule.lastSuccessfulBackup
+		}
+		if schedule.created.Before(oldest) {
+			oldest = schedule.created
+		}
+	}
+	since := now.Add(-threshold)
+	if lastSuccessfulBackup != nil {
+		return lastSuccessfulBackup.Time.Before(since), lastSuccessfulBackup
+	}
+	return oldest.Before(since), nil
+}
+
+// fsBackupPersistentVolumeClaims returns the persistent volume claims mounted in the pod volumes backed up by the
+// node agent, when file system backup is the default and when it is not
+func fsBackupPersistentVolumeClaims(pods []corev1.Pod) map[bool]map[string]bool {
+	fsBackup := map[bool]map[string]bool{}
+	for _, defaultVolumesToFsBackup := range []bool{true, false} {
+		fsBackup[defaultVolumesToFsBackup] = map[string]bool{}
+		for _, pod := range pods {
+			podVolumes, _ := podvolume.GetVolumesByPod(&pod, defaultVolumesToFsBackup, false, nil)
+			for _, volume := range pod.Spec.Volumes {
+				if volume.PersistentVolumeClaim != nil && slices.Contains(podVolumes, volume.Name) {
+					fsBackup[defaultVolumesToFsBackup][volume.PersistentVolumeClaim.ClaimName] = true
+				}
+			}
+		}
+	}
+	return fsBackup
+}
+
+// persistentVolumeClaimCoverage returns how the schedules protecting the namespace of a persistent volume claim
+// back it up. A known backup method of any of the schedules protects the claim.
+func persistentVolumeClaimCoverage(pvc *corev1.PersistentVolumeClaim, schedules []coverageSchedule, volumes coverageVolumes) oadpv1alpha1.PersistentVolumeClaimCoverage {
+	coverages := []oadpv1alpha1.PersistentVolumeClaimCoverage{}
+	for _, schedule := range schedules {
+		method, reason := volumeBackupMethod(pvc, schedule, volumes)
+		coverages = append(coverages, oadpv1alpha1.PersistentVolumeClaimCoverage{
+			Namespace:    pvc.Namespace,
+			Name:         pvc.Name,
+			BackupMethod: method,
+			Reason:       reason,
+		})
+	}
+	rank := func(method oadpv1alpha1.VolumeBackupMethod) int {
+		switch method {
+		case oadpv1alpha1.VolumeBackupMethodNone:
+			return 0
+		case oadpv1alpha1.VolumeBackupMethodUnknown:
+			return 1
+		}
+		return 2
+	}
+	return slices.MaxFunc(coverages, func(a, b oadpv1alpha1.PersistentVolumeClaimCoverage) int {
+		return cmp.Compare(rank(a.BackupMethod), rank(b.BackupMethod))
+	})
+}
+
+// volumeBackupMethod returns how a schedule backs up a persistent volume claim of a namespace it protects, and why
+func volumeBackupMethod(pvc *corev1.PersistentVolumeClaim, schedule coverageSchedule, volumes coverageVolumes) (oadpv1alpha1.VolumeBackupMethod, string) {
+	template := schedule.template
+	if !includesPersistentVolumeClaims(template) {
+		return oadpv1alpha1.VolumeBackupMethodNone, fmt.Sprintf("schedule %s does not include persistentvolumeclaims", schedule.name)
+	}
+	if !matchesBackupLabelSelectors(template, pvc.Labels) {
+		return oadpv1alpha1.VolumeBackupMethodNone, fmt.Sprintf("labels do not match the label selectors of schedule %s", schedule.name)
+	}
+	if template.ResourcePolicy != nil {
+		return oadpv1alpha1.VolumeBackupMethodUnknown, fmt.Sprintf("resource policy %s of schedule %s decides the backup method", template.ResourcePolicy.Name, schedule.name)
+	}
+	if volumes.fsBackup[boolptr.IsSetToTrue(template.DefaultVolumesToFsBackup)][pvc.Name] {
+		return oadpv1alpha1.VolumeBackupMethodFSBackup, fmt.Sprintf("mounted in a pod volume backed up by schedule %s", schedule.name)
+	}
+	if boolptr.IsSetToFalse(template.SnapshotVolumes) {
+		return oadpv1alpha1.VolumeBackupMethodNone, fmt.Sprintf("schedule %s does not snapshot volumes", schedule.name)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return oadpv1alpha1.VolumeBackupMethodUnknown, "not bound to a persistent volume"
+	}
+	driver, ok := volumes.csiDrivers[pvc.Spec.VolumeName]
+	if !ok {
+		return oadpv1alpha1.VolumeBackupMethodUnknown, fmt.Sprintf("persistent volume %s is not provisioned by a CSI driver, only a volume snapshot location of its provider can back it up", pvc.Spec.VolumeName)
+	}
+	if className := pvc.Annotations[velerov1.VolumeSnapshotClassDriverPVCAnnotation]; className != "" {
+		if snapshotClass, ok := volumes.snapshotClasses[className]; !ok || snapshotClass.Driver != driver {
+			return oadpv1alpha1.VolumeBackupMethodUnknown, fmt.Sprintf("VolumeSnapshotClass %s of driver %s in annotation %s does not exist", className, driver, velerov1.VolumeSnapshotClassDriverPVCAnnotation)
+		}
+	} else if !volumes.veleroSnapshotClassDrivers[driver] {
+		return oadpv1alpha1.VolumeBackupMethodUnknown, fmt.Sprintf("no VolumeSnapshotClass of driver %s has the label %s=true", driver, velerov1.VolumeSnapshotClassSelectorLabel)
+	}
+	if boolptr.IsSetToTrue(template.SnapshotMoveData) {
+		return oadpv1alpha1.VolumeBackupMethodDataMover, fmt.Sprintf("snapshot data moved by schedule %s", schedule.name)
+	}
+	return oadpv1alpha1.VolumeBackupMethodCSISnapshot, fmt.Sprintf("snapshot by schedule %s", schedule.name)
+}
+
+// includesPersistentVolumeClaims returns whether the resource filters of a backup include persistent volume claims
+func includesPersistentVolumeClaims(template velerov1.BackupSpec) bool {
+	included := collections.NewIncludesExcludes().
+		Includes(slices.Concat(template.IncludedResources, template.IncludedNamespaceScopedResources)...)
+	excluded := collections.NewIncludesExcludes().
+		Excludes(slices.Concat(template.ExcludedResources, template.ExcludedNamespaceScopedResources)...)
+	return (included.ShouldInclude("persistentvolumeclaims") || included.ShouldInclude("pvc")) &&
+		excluded.ShouldInclude("persistentvolumeclaims") && excluded.ShouldInclude("pvc")
+}
+
+// matchesBackupLabelSelectors returns whether a backup includes the resources with the labels
+func matchesBackupLabelSelectors(template velerov1.BackupSpec, resourceLabels map[string]string) bool {
+	selectors := slices.Clone(template.OrLabelSelectors)
+	if template.LabelSelector != nil {
+		selectors = append(selectors, template.LabelSelector)
+	}
+	if len(selectors) == 0 {
+		return true
+	}
+	for _, labelSelector := range selectors {
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err == nil && selector.Matches(labels.Set(resourceLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *BackupCoverageReportReconciler) updateBackupCoverageReportStatus(ctx context.Context, report *oadpv1alpha1.BackupCoverageReport, coverage *oadpv1alpha1.BackupCoverageReportStatus, reconcileErr error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &oadpv1alpha1.BackupCoverageReport{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(report), latest); err != nil {
+			return err
+		}
+		condition := metav1.Condition{
+			Type:               oadpv1alpha1.ConditionReconciled,
+			Status:             metav1.ConditionTrue,
+			Reason:             oadpv1alpha1.ReconciledReasonComplete,
+			Message:            "Reconcile complete",
+			ObservedGeneration: latest.Generation,
+		}
+		if reconcileErr != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = oadpv1alpha1.ReconciledReasonError
+			condition.Message = reconcileErr.Error()
+		}
+		if coverage != nil {
+			now := metav1.Now()
+			coverage.Conditions = latest.Status.Conditions
+			coverage.LastReportTime = &now
+			latest.Status = *coverage
+		}
+		meta.SetStatusCondition(&latest.Status.Conditions, condition)
+		return r.Status().Update(ctx, latest)
+	})
+}
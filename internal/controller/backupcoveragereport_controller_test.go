@@ -0,0 +1,314 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	snapshotv1api "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func newCoverageTestSchedule(name string, created time.Time, template velerov1.BackupSpec) *velerov1.Schedule {
+	return &velerov1.Schedule{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespaceName, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       velerov1.ScheduleSpec{Schedule: "@daily", Template: template},
+	}
+}
+
+func newCoverageTestBackup(schedule string, phase velerov1.BackupPhase, completed time.Time) *velerov1.Backup {
+	return &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      schedule + "-" + completed.Format("20060102150405"),
+			Namespace: testNamespaceName,
+			Labels:    map[string]string{velerov1.ScheduleNameLabel: schedule},
+		},
+		Status: velerov1.BackupStatus{Phase: phase, CompletionTimestamp: &metav1.Time{Time: completed}},
+	}
+}
+
+func newCoverageTestPVC(namespace, name, volumeName string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+	}
+}
+
+func newCoverageTestPV(name, csiDriver string) *corev1.PersistentVolume {
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if csiDriver != "" {
+		pv.Spec.CSI = &corev1.CSIPersistentVolumeSource{Driver: csiDriver, VolumeHandle: name}
+	} else {
+		pv.Spec.NFS = &corev1.NFSVolumeSource{Server: "nfs.example.com", Path: "/" + name}
+	}
+	return pv
+}
+
+func TestBackupCoverageReportReconciler_Reconcile(t *testing.T) {
+	now := time.Now()
+	report := &oadpv1alpha1.BackupCoverageReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "coverage", Namespace: testNamespaceName},
+	}
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{DefaultSnapshotMoveData: ptr.To(true)},
+			},
+		},
+	}
+	fsBackupPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "database",
+			Namespace:   "app-a",
+			Annotations: map[string]string{velerov1.VolumesToBackupAnnotation: "data"},
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "fs-backup"}},
+			}},
+		},
+	}
+	snapshotClass := &snapshotv1api.VolumeSnapshotClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "ebs",
+			Labels: map[string]string{velerov1.VolumeSnapshotClassSelectorLabel: "true"},
+		},
+		Driver:         "ebs.csi.aws.com",
+		DeletionPolicy: snapshotv1api.VolumeSnapshotContentRetain,
+	}
+	objs := []client.Object{
+		report,
+		dpa,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-c"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-monitoring"}},
+		newCoverageTestSchedule("daily", now.Add(-48*time.Hour), velerov1.BackupSpec{IncludedNamespaces: []string{"app-*"}, ExcludedNamespaces: []string{"app-b", "app-c"}}),
+		newCoverageTestSchedule("weekly", now.Add(-30*24*time.Hour), velerov1.BackupSpec{IncludedNamespaces: []string{"app-c"}, SnapshotVolumes: ptr.To(false)}),
+		newCoverageTestSchedule("paused", now.Add(-48*time.Hour), velerov1.BackupSpec{IncludedNamespaces: []string{"*"}}),
+		newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-time.Hour)),
+		newCoverageTestBackup("weekly", velerov1.BackupPhaseCompleted, now.Add(-10*24*time.Hour)),
+		newCoverageTestBackup("weekly", velerov1.BackupPhaseFailed, now.Add(-3*24*time.Hour)),
+		fsBackupPod,
+		snapshotClass,
+		newCoverageTestPV("pv-csi", "ebs.csi.aws.com"),
+		newCoverageTestPV("pv-fs", ""),
+		newCoverageTestPV("pv-nfs", ""),
+		newCoverageTestPVC("app-a", "data-mover", "pv-csi"),
+		newCoverageTestPVC("app-a", "fs-backup", "pv-fs"),
+		newCoverageTestPVC("app-a", "nfs", "pv-nfs"),
+		newCoverageTestPVC("app-a", "pending", ""),
+		newCoverageTestPVC("app-c", "data", "pv-csi"),
+	}
+	paused := objs[8].(*velerov1.Schedule)
+	paused.Spec.Paused = true
+
+	schemeForFakeClient, err := getSchemeForFakeClient()
+	if err != nil {
+		t.Fatalf("error in creating scheme, likely programmer error")
+	}
+	if err := snapshotv1api.AddToScheme(schemeForFakeClient); err != nil {
+		t.Fatalf("error in adding snapshot types to scheme, likely programmer error")
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(schemeForFakeClient).
+		WithObjects(objs...).
+		WithStatusSubresource(&oadpv1alpha1.BackupCoverageReport{}).
+		Build()
+	r := &BackupCoverageReportReconciler{
+		Client:            fakeClient,
+		Scheme:            schemeForFakeClient,
+		EventRecorder:     record.NewFakeRecorder(10),
+		ClusterWideClient: fakeClient,
+	}
+
+	result, err := r.Reconcile(newContextForTest(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != defaultCoverageRefreshPeriod {
+		t.Errorf("Reconcile() requeue after = %s, want %s", result.RequeueAfter, defaultCoverageRefreshPeriod)
+	}
+	if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(report), report); err != nil {
+		t.Fatalf("unable to get report: %v", err)
+	}
+	status := report.Status
+	if !meta.IsStatusConditionTrue(status.Conditions, oadpv1alpha1.ConditionReconciled) || status.LastReportTime == nil {
+		t.Errorf("Reconcile() conditions = %v, want Reconciled", status.Conditions)
+	}
+	wantSummary := oadpv1alpha1.CoverageSummary{
+		Namespaces:                                3,
+		ProtectedNamespaces:                       2,
+		StaleNamespaces:                           1,
+		UnprotectedNamespaces:                     1,
+		PersistentVolumeClaims:                    5,
+		UnprotectedPersistentVolumeClaims:         1,
+		UnknownBackupMethodPersistentVolumeClaims: 2,
+	}
+	if status.Summary != wantSummary {
+		t.Errorf("Reconcile() summary = %+v, want %+v", status.Summary, wantSummary)
+	}
+	if !slices.Equal(status.UnprotectedNamespaces, []string{"app-b"}) {
+		t.Errorf("Reconcile() unprotected namespaces = %v, want [app-b]", status.UnprotectedNamespaces)
+	}
+	if len(status.StaleNamespaces) != 1 || status.StaleNamespaces[0].Namespace != "app-c" ||
+		!slices.Equal(status.StaleNamespaces[0].Schedules, []string{"weekly"}) || status.StaleNamespaces[0].LastSuccessfulBackup == nil {
+		t.Errorf("Reconcile() stale namespaces = %+v, want app-c protected by weekly", status.StaleNamespaces)
+	}
+	methods := map[string]oadpv1alpha1.VolumeBackupMethod{}
+	for _, pvc := range status.PersistentVolumeClaims {
+		methods[pvc.Namespace+"/"+pvc.Name] = pvc.BackupMethod
+	}
+	wantMethods := map[string]oadpv1alpha1.VolumeBackupMethod{
+		"app-a/nfs":     oadpv1alpha1.VolumeBackupMethodUnknown,
+		"app-a/pending": oadpv1alpha1.VolumeBackupMethodUnknown,
+		"app-c/data":    oadpv1alpha1.VolumeBackupMethodNone,
+	}
+	if len(methods) != len(wantMethods) {
+		t.Errorf("Reconcile() persistent volume claims = %+v, want %v", status.PersistentVolumeClaims, wantMethods)
+	}
+	for name, want := range wantMethods {
+		if methods[name] != want {
+			t.Errorf("Reconcile() backup method of %s = %s, want %s", name, methods[name], want)
+		}
+	}
+}
+
+func TestVolumeBackupMethod(t *testing.T) {
+	pvc := newCoverageTestPVC("app", "data", "pv-csi")
+	pvc.Labels = map[string]string{"app": "database"}
+	volumes := coverageVolumes{
+		fsBackup:                   map[bool]map[string]bool{true: {"data": true}, false: {}},
+		csiDrivers:                 map[string]string{"pv-csi": "ebs.csi.aws.com"},
+		snapshotClasses:            map[string]snapshotv1api.VolumeSnapshotClass{},
+		veleroSnapshotClassDrivers: map[string]bool{"ebs.csi.aws.com": true},
+	}
+	tests := []struct {
+		name     string
+		template velerov1.BackupSpec
+		want     oadpv1alpha1.VolumeBackupMethod
+	}{
+		{
+			name: "csi snapshot",
+			want: oadpv1alpha1.VolumeBackupMethodCSISnapshot,
+		},
+		{
+			name:     "data mover",
+			template: velerov1.BackupSpec{SnapshotMoveData: ptr.To(true)},
+			want:     oadpv1alpha1.VolumeBackupMethodDataMover,
+		},
+		{
+			name:     "file system backup by default",
+			template: velerov1.BackupSpec{DefaultVolumesToFsBackup: ptr.To(true)},
+			want:     oadpv1alpha1.VolumeBackupMethodFSBackup,
+		},
+		{
+			name:     "persistent volume claims excluded",
+			template: velerov1.BackupSpec{ExcludedResources: []string{"persistentvolumeclaims"}},
+			want:     oadpv1alpha1.VolumeBackupMethodNone,
+		},
+		{
+			name:     "other resources included",
+			template: velerov1.BackupSpec{IncludedNamespaceScopedResources: []string{"deployments", "configmaps"}},
+			want:     oadpv1alpha1.VolumeBackupMethodNone,
+		},
+		{
+			name:     "persistent volume claims included by short name",
+			template: velerov1.BackupSpec{IncludedResources: []string{"pvc"}},
+			want:     oadpv1alpha1.VolumeBackupMethodCSISnapshot,
+		},
+		{
+			name:     "label selector not matching",
+			template: velerov1.BackupSpec{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			want:     oadpv1alpha1.VolumeBackupMethodNone,
+		},
+		{
+			name: "or label selectors matching",
+			template: velerov1.BackupSpec{OrLabelSelectors: []*metav1.LabelSelector{
+				{MatchLabels: map[string]string{"app": "web"}},
+				{MatchLabels: map[string]string{"app": "database"}},
+			}},
+			want: oadpv1alpha1.VolumeBackupMethodCSISnapshot,
+		},
+		{
+			name:     "resource policy",
+			template: velerov1.BackupSpec{ResourcePolicy: &corev1.TypedLocalObjectReference{Kind: "configmap", Name: "volume-policy"}},
+			want:     oadpv1alpha1.VolumeBackupMethodUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := volumeBackupMethod(pvc, coverageSchedule{name: "daily", template: tt.template}, volumes)
+			if got != tt.want {
+				t.Errorf("volumeBackupMethod() = %s (%s), want %s", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupCoverageReportReconciler_ReconcileCapsLists(t *testing.T) {
+	now := time.Now()
+	report := &oadpv1alpha1.BackupCoverageReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "coverage", Namespace: testNamespaceName},
+		Spec:       oadpv1alpha1.BackupCoverageReportSpec{IncludedNamespaces: []string{"app-*", "db"}},
+	}
+	objs := []client.Object{
+		report,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db"}},
+		newCoverageTestSchedule("daily", now.Add(-48*time.Hour), velerov1.BackupSpec{IncludedNamespaces: []string{"db"}}),
+		newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-time.Hour)),
+	}
+	for i := 149; i >= 0; i-- {
+		objs = append(objs,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("app-%03d", i)}},
+			newCoverageTestPVC("db", fmt.Sprintf("data-%03d", i), ""),
+		)
+	}
+	schemeForFakeClient, err := getSchemeForFakeClient()
+	if err != nil {
+		t.Fatalf("error in creating scheme, likely programmer error")
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(schemeForFakeClient).
+		WithObjects(objs...).
+		WithStatusSubresource(&oadpv1alpha1.BackupCoverageReport{}).
+		Build()
+	r := &BackupCoverageReportReconciler{
+		Client:            fakeClient,
+		Scheme:            schemeForFakeClient,
+		EventRecorder:     record.NewFakeRecorder(10),
+		ClusterWideClient: fakeClient,
+	}
+
+	if _, err := r.Reconcile(newContextForTest(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(report)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(report), report); err != nil {
+		t.Fatalf("unable to get report: %v", err)
+	}
+	status := report.Status
+	if status.Summary.UnprotectedNamespaces != 150 || status.Summary.UnknownBackupMethodPersistentVolumeClaims != 150 {
+		t.Errorf("Reconcile() summary = %+v, want 150 unprotected namespaces and persistent volume claims", status.Summary)
+	}
+	if len(status.UnprotectedNamespaces) != coverageReportMaxItems || status.UnprotectedNamespaces[0] != "app-000" ||
+		status.UnprotectedNamespaces[coverageReportMaxItems-1] != "app-099" {
+		t.Errorf("Reconcile() unprotected namespaces = %v, want app-000 to app-099", status.UnprotectedNamespaces)
+	}
+	pvcs := status.PersistentVolumeClaims
+	if len(pvcs) != coverageReportMaxItems || pvcs[0].Name != "data-000" || pvcs[coverageReportMaxItems-1].Name != "data-099" {
+		t.Errorf("Reconcile() listed %d persistent volume claims, want data-000 to data-099", len(pvcs))
+	}
+}
@@ -0,0 +1,371 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"github.com/vmware-tanzu/velero/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const (
+	// backupPolicyLabel is set to the name of the BackupPolicy on its Schedule, the Backups of the Schedule
+	// and the DeleteBackupRequests pruning them
+	backupPolicyLabel = "oadp.openshift.io/backup-policy"
+	// backupPolicyResyncPeriod is how often the backups of a policy are pruned when none of them changed phase,
+	// to prune the backups synced from the backup storage location and retry the deletions Velero failed
+	backupPolicyResyncPeriod = time.Hour
+)
+
+// BackupPolicyReconciler reconciles a BackupPolicy object
+type BackupPolicyReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=backuppolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=backuppolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=backuppolicies/finalizers,verbs=update
+
+// Reconcile creates the Velero Schedule of a BackupPolicy and deletes the backups of the policy outside of its retention
+func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("backuppolicy", req.NamespacedName)
+	policy := &oadpv1alpha1.BackupPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !policy.DeletionTimestamp.IsZero() {
+		// the Schedule is garbage collected, and the backups expire with their TTL
+		return ctrl.Result{}, nil
+	}
+
+	if err := validateBackupPolicy(policy); err != nil {
+		// retrying will not fix an invalid policy, it is reconciled again when its spec changes
+		logger.Error(err, "invalid BackupPolicy")
+		return ctrl.Result{}, r.updateBackupPolicyStatus(ctx, policy, nil, err)
+	}
+	err := r.reconcileSchedule(ctx, policy)
+	var prune *pruneResult
+	if err == nil {
+		prune, err = r.pruneBackups(ctx, policy)
+	}
+	if err != nil {
+		r.EventRecorder.Event(policy, corev1.EventTypeWarning, "BackupPolicyReconcileFailed", err.Error())
+		return ctrl.Result{}, errors.Join(err, r.updateBackupPolicyStatus(ctx, policy, prune, err))
+	}
+	if prune.pruned > 0 {
+		logger.Info("Pruned backups", "retained", prune.retained, "pruned", prune.pruned)
+		r.EventRecorder.Event(policy, corev1.EventTypeNormal, "BackupsPruned",
+			fmt.Sprintf("requested the deletion of %d backups outside of the retention, %d backups retained", prune.pruned, prune.retained))
+	}
+	return ctrl.Result{RequeueAfter: backupPolicyResyncPeriod}, r.updateBackupPolicyStatus(ctx, policy, prune, nil)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&oadpv1alpha1.BackupPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&velerov1.Schedule{}).
+		// the backups of a policy are pruned when one of them completes
+		Watches(&velerov1.Backup{}, handler.EnqueueRequestsFromMapFunc(backupPolicyForBackup),
+			builder.WithPredicates(phaseChangedPredicate(func(obj client.Object) string {
+				return string(obj.(*velerov1.Backup).Status.Phase)
+			}))).
+		Complete(r)
+}
+
+func backupPolicyForBackup(_ context.Context, backup client.Object) []reconcile.Request {
+	policyName, ok := backup.GetLabels()[backupPolicyLabel]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: backup.GetNamespace(), Name: policyName}}}
+}
+
+func validateBackupPolicy(policy *oadpv1alpha1.BackupPolicy) error {
+	if _, err := cron.ParseStandard(policy.Spec.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", policy.Spec.Schedule, err)
+	}
+	retention := policy.Spec.Retention
+	if retention.Last == 0 && retention.Hourly == 0 && retention.Daily == 0 && retention.Weekly == 0 &&
+		retention.Monthly == 0 && retention.Yearly == 0 && (retention.Within == nil || retention.Within.Duration <= 0) {
+		return errors.New("retention must keep backups by at least one of last, hourly, daily, weekly, monthly, yearly or within")
+	}
+	return nil
+}
+
+// reconcileSchedule creates or updates the Velero Schedule of the policy, which has the name of the policy
+func (r *BackupPolicyReconciler) reconcileSchedule(ctx context.Context, policy *oadpv1alpha1.BackupPolicy) error {
+	ttl, err := backupPolicyTTL(policy)
+	if err != nil {
+		return err
+	}
+	schedule := &velerov1.Schedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      policy.Name,
+			Namespace: policy.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrPatch(ctx, r.Client, schedule, func() error {
+		if !schedule.CreationTimestamp.IsZero() && !metav1.IsControlledBy(schedule, policy) {
+			return fmt.Errorf("schedule %s already exists and is not owned by BackupPolicy %s", schedule.Name, policy.Name)
+		}
+		if schedule.Labels == nil {
+			schedule.Labels = map[string]string{}
+		}
+		schedule.Labels[oadpv1alpha1.OadpOperatorLabel] = "True"
+		schedule.Labels[backupPolicyLabel] = policy.Name
+
+		template := policy.Spec.Template.DeepCopy()
+		template.IncludedNamespaces = policy.Spec.IncludedNamespaces
+		template.TTL = metav1.Duration{Duration: ttl}
+		if template.Metadata.Labels == nil {
+			template.Metadata.Labels = map[string]string{}
+		}
+		template.Metadata.Labels[backupPolicyLabel] = policy.Name
+		schedule.Spec.Template = *template
+		schedule.Spec.Schedule = policy.Spec.Schedule
+		schedule.Spec.Paused = policy.Spec.Paused
+		return controllerutil.SetControllerReference(policy, schedule, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+	if op == controllerutil.OperationResultCreated || op == controllerutil.OperationResultUpdated {
+		r.EventRecorder.Event(schedule,
+			corev1.EventTypeNormal,
+			"ScheduleReconciled",
+			fmt.Sprintf("performed %s on schedule %s/%s", op, schedule.Namespace, schedule.Name),
+		)
+	}
+	return nil
+}
+
+// backupPolicyTTL returns the TTL of the backups of a policy. It is longer than any backup is retained,
+// so Velero only expires the backups the operator does not prune anymore, like the backups of a deleted policy.
+func backupPolicyTTL(policy *oadpv1alpha1.BackupPolicy) (time.Duration, error) {
+	schedule, err := cron.ParseStandard(policy.Spec.Schedule)
+	if err != nil {
+		return 0, err
+	}
+	// the longest time between two runs, from a fixed time so the TTL does not change between reconciles
+	var interval time.Duration
+	run := schedule.Next(time.Unix(0, 0).UTC())
+	for range 24 {
+		next := schedule.Next(run)
+		interval = max(interval, next.Sub(run))
+		run = next
+	}
+
+	retention := policy.Spec.Retention
+	var horizon time.Duration
+	if retention.Within != nil {
+		horizon = retention.Within.Duration
+	}
+	for _, tier := range []struct {
+		count  int32
+		period time.Duration
+	}{
+		{retention.Last, interval},
+		{retention.Hourly, time.Hour},
+		{retention.Daily, 24 * time.Hour},
+		{retention.Weekly, 7 * 24 * time.Hour},
+		{retention.Monthly, 31 * 24 * time.Hour},
+		{retention.Yearly, 366 * 24 * time.Hour},
+	} {
+		horizon = max(horizon, time.Duration(tier.count)*max(tier.period, interval))
+	}
+	return horizon + interval + 24*time.Hour, nil
+}
+
+type pruneResult struct {
+	retained int32
+	pruned   int32
+}
+
+// pruneBackups requests the deletion of the backups of the policy not kept by its retention. Backups still running
+// are left alone, and failed backups are deleted once a newer backup completes.
+func (r *BackupPolicyReconciler) pruneBackups(ctx context.Context, policy *oadpv1alpha1.BackupPolicy) (*pruneResult, error) {
+	backups := &velerov1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(policy.Namespace), client.MatchingLabels{backupPolicyLabel: policy.Name}); err != nil {
+		return nil, err
+	}
+	deleteRequests := &velerov1.DeleteBackupRequestList{}
+	if err := r.List(ctx, deleteRequests, client.InNamespace(policy.Namespace), client.MatchingLabels{backupPolicyLabel: policy.Name}); err != nil {
+		return nil, err
+	}
+	deleting := map[string]bool{}
+	for _, deleteRequest := range deleteRequests.Items {
+		if deleteRequest.Status.Phase != velerov1.DeleteBackupRequestPhaseProcessed {
+			deleting[deleteRequest.Spec.BackupName] = true
+		}
+	}
+
+	usable := []velerov1.Backup{}
+	failed := []velerov1.Backup{}
+	for _, backup := range backups.Items {
+		switch backup.Status.Phase {
+		case velerov1.BackupPhaseCompleted, velerov1.BackupPhasePartiallyFailed:
+			usable = append(usable, backup)
+		case velerov1.BackupPhaseFailed, velerov1.BackupPhaseFailedValidation:
+			failed = append(failed, backup)
+		}
+	}
+	retained := retainedBackups(usable, policy.Spec.Retention)
+	prune := []velerov1.Backup{}
+	for _, backup := range usable {
+		if !retained[backup.Name] {
+			prune = append(prune, backup)
+		}
+	}
+	if len(usable) > 0 {
+		newest := slices.MaxFunc(usable, func(a, b velerov1.Backup) int { return backupTime(a).Compare(backupTime(b)) })
+		for _, backup := range failed {
+			if backupTime(backup).Before(backupTime(newest)) {
+				prune = append(prune, backup)
+			}
+		}
+	}
+
+	result := &pruneResult{retained: int32(len(retained))}
+	for _, backup := range prune {
+		result.pruned++
+		if deleting[backup.Name] {
+			continue
+		}
+		if err := r.Create(ctx, newPruneDeleteBackupRequest(policy, &backup)); err != nil {
+			return result, fmt.Errorf("unable to request the deletion of backup %s: %w", backup.Name, err)
+		}
+	}
+	return result, nil
+}
+
+// newPruneDeleteBackupRequest returns the DeleteBackupRequest deleting a backup, and its data in the backup storage
+// location, the way the velero CLI does
+func newPruneDeleteBackupRequest(policy *oadpv1alpha1.BackupPolicy, backup *velerov1.Backup) *velerov1.DeleteBackupRequest {
+	return &velerov1.DeleteBackupRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: backup.Name + "-",
+			Namespace:    backup.Namespace,
+			Labels: map[string]string{
+				velerov1.BackupNameLabel: label.GetValidName(backup.Name),
+				velerov1.BackupUIDLabel:  string(backup.UID),
+				backupPolicyLabel:        policy.Name,
+			},
+		},
+		Spec: velerov1.DeleteBackupRequestSpec{
+			BackupName: backup.Name,
+		},
+	}
+}
+
+// retainedBackups returns the names of the backups kept by the retention
+func retainedBackups(backups []velerov1.Backup, retention oadpv1alpha1.BackupRetention) map[string]bool {
+	retained := map[string]bool{}
+	if len(backups) == 0 {
+		return retained
+	}
+	// newest first, so each tier keeps the most recent backup of its periods
+	backups = slices.Clone(backups)
+	slices.SortFunc(backups, func(a, b velerov1.Backup) int { return backupTime(b).Compare(backupTime(a)) })
+
+	for i := range min(int(retention.Last), len(backups)) {
+		retained[backups[i].Name] = true
+	}
+	for _, tier := range []struct {
+		count  int32
+		period func(time.Time) string
+	}{
+		{retention.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{retention.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{retention.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{retention.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{retention.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	} {
+		remaining := tier.count
+		lastPeriod := ""
+		for _, backup := range backups {
+			if remaining == 0 {
+				break
+			}
+			if period := tier.period(backupTime(backup).UTC()); period != lastPeriod {
+				retained[backup.Name] = true
+				lastPeriod = period
+				remaining--
+			}
+		}
+	}
+	if retention.Within != nil {
+		// relative to the last backup, so the backups are not all pruned when the schedule stops running
+		since := backupTime(backups[0]).Add(-retention.Within.Duration)
+		for _, backup := range backups {
+			if !backupTime(backup).Before(since) {
+				retained[backup.Name] = true
+			}
+		}
+	}
+	return retained
+}
+
+// backupTime returns when the backup started, or was created for a backup not started
+func backupTime(backup velerov1.Backup) time.Time {
+	if backup.Status.StartTimestamp != nil {
+		return backup.Status.StartTimestamp.Time
+	}
+	return backup.CreationTimestamp.Time
+}
+
+func (r *BackupPolicyReconciler) updateBackupPolicyStatus(ctx context.Context, policy *oadpv1alpha1.BackupPolicy, prune *pruneResult, reconcileErr error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &oadpv1alpha1.BackupPolicy{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(policy), latest); err != nil {
+			return err
+		}
+		condition := metav1.Condition{
+			Type:               oadpv1alpha1.ConditionReconciled,
+			Status:             metav1.ConditionTrue,
+			Reason:             oadpv1alpha1.ReconciledReasonComplete,
+			Message:            "Reconcile complete",
+			ObservedGeneration: latest.Generation,
+		}
+		if reconcileErr != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = oadpv1alpha1.ReconciledReasonError
+			condition.Message = reconcileErr.Error()
+		} else {
+			latest.Status.Schedule = latest.Name
+		}
+		meta.SetStatusCondition(&latest.Status.Conditions, condition)
+		if prune != nil {
+			now := metav1.Now()
+			latest.Status.LastPruneTime = &now
+			latest.Status.RetainedBackups = prune.retained
+			latest.Status.PrunedBackups = prune.pruned
+		}
+		return r.Status().Update(ctx, latest)
+	})
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// newTestPolicyBackup returns a backup of the policy "hourly" started at the time
+func newTestPolicyBackup(start string, phase velerov1.BackupPhase) *velerov1.Backup {
+	started, _ := time.Parse(time.RFC3339, start)
+	return &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hourly-" + started.Format("20060102150405"),
+			Namespace: testNamespaceName,
+			Labels:    map[string]string{backupPolicyLabel: "hourly"},
+		},
+		Status: velerov1.BackupStatus{
+			Phase:          phase,
+			StartTimestamp: &metav1.Time{Time: started},
+		},
+	}
+}
+
+func backupNames(backups ...*velerov1.Backup) []string {
+	names := []string{}
+	for _, backup := range backups {
+		names = append(names, backup.Name)
+	}
+	return names
+}
+
+func TestRetainedBackups(t *testing.T) {
+	// every 6 hours, from Monday 2024-12-30 (ISO week 2025-W01) to Saturday 2025-02-01
+	backups := []velerov1.Backup{}
+	for start := time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC); start.Before(time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)); start = start.Add(6 * time.Hour) {
+		backups = append(backups, *newTestPolicyBackup(start.Format(time.RFC3339), velerov1.BackupPhaseCompleted))
+	}
+	// shuffled, the retention does not depend on the order of the backups
+	slices.Reverse(backups[:len(backups)/2])
+
+	name := func(start string) string {
+		return newTestPolicyBackup(start, velerov1.BackupPhaseCompleted).Name
+	}
+	tests := []struct {
+		name      string
+		retention oadpv1alpha1.BackupRetention
+		want      []string
+	}{
+		{
+			name:      "last",
+			retention: oadpv1alpha1.BackupRetention{Last: 2},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-02-01T12:00:00Z")},
+		},
+		{
+			name:      "daily keeps the last backup of each day",
+			retention: oadpv1alpha1.BackupRetention{Daily: 3},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-01-31T18:00:00Z"), name("2025-01-30T18:00:00Z")},
+		},
+		{
+			name:      "weekly uses ISO weeks",
+			retention: oadpv1alpha1.BackupRetention{Weekly: 2},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-01-26T18:00:00Z")},
+		},
+		{
+			name:      "monthly and yearly keep backups from before the first period",
+			retention: oadpv1alpha1.BackupRetention{Monthly: 3, Yearly: 2},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-01-31T18:00:00Z"), name("2024-12-31T18:00:00Z")},
+		},
+		{
+			name:      "hourly with fewer backups than hours",
+			retention: oadpv1alpha1.BackupRetention{Hourly: 3},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-02-01T12:00:00Z"), name("2025-02-01T06:00:00Z")},
+		},
+		{
+			name:      "within is relative to the last backup",
+			retention: oadpv1alpha1.BackupRetention{Within: &metav1.Duration{Duration: 12 * time.Hour}},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-02-01T12:00:00Z"), name("2025-02-01T06:00:00Z")},
+		},
+		{
+			name:      "tiers share backups",
+			retention: oadpv1alpha1.BackupRetention{Last: 1, Daily: 2, Weekly: 1},
+			want:      []string{name("2025-02-01T18:00:00Z"), name("2025-01-31T18:00:00Z")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retained := retainedBackups(backups, tt.retention)
+			got := []string{}
+			for name := range retained {
+				got = append(got, name)
+			}
+			slices.Sort(got)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("retainedBackups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupPolicyTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  string
+		retention oadpv1alpha1.BackupRetention
+		want      time.Duration
+	}{
+		{
+			name:      "yearly tier",
+			schedule:  "0 * * * *",
+			retention: oadpv1alpha1.BackupRetention{Hourly: 24, Daily: 7, Yearly: 2},
+			want:      2*366*24*time.Hour + time.Hour + 24*time.Hour,
+		},
+		{
+			name:      "tier periods are at least the schedule interval",
+			schedule:  "0 0 * * 0",
+			retention: oadpv1alpha1.BackupRetention{Daily: 7},
+			want:      7*7*24*time.Hour + 7*24*time.Hour + 24*time.Hour,
+		},
+		{
+			name:      "last backups of an irregular schedule",
+			schedule:  "0 22 * * 1-5",
+			retention: oadpv1alpha1.BackupRetention{Last: 5},
+			want:      5*3*24*time.Hour + 3*24*time.Hour + 24*time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := backupPolicyTTL(&oadpv1alpha1.BackupPolicy{
+				Spec: oadpv1alpha1.BackupPolicySpec{Schedule: tt.schedule, Retention: tt.retention},
+			})
+			if err != nil {
+				t.Fatalf("backupPolicyTTL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("backupPolicyTTL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupPolicyReconciler_Reconcile(t *testing.T) {
+	policy := &oadpv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "hourly",
+			Namespace:  testNamespaceName,
+			UID:        "policy-uid",
+			Generation: 1,
+		},
+		Spec: oadpv1alpha1.BackupPolicySpec{
+			Schedule:           "0 * * * *",
+			IncludedNamespaces: []string{"my-app"},
+			Template: velerov1.BackupSpec{
+				IncludedNamespaces: []string{"ignored"},
+				StorageLocation:    "default",
+				TTL:                metav1.Duration{Duration: time.Hour},
+			},
+			Retention: oadpv1alpha1.BackupRetention{Last: 2},
+		},
+	}
+	oldest := newTestPolicyBackup("2025-01-01T00:00:00Z", velerov1.BackupPhaseCompleted)
+	failed := newTestPolicyBackup("2025-01-01T01:00:00Z", velerov1.BackupPhaseFailed)
+	alreadyDeleting := newTestPolicyBackup("2025-01-01T02:00:00Z", velerov1.BackupPhasePartiallyFailed)
+	retained := []*velerov1.Backup{
+		newTestPolicyBackup("2025-01-01T03:00:00Z", velerov1.BackupPhaseCompleted),
+		newTestPolicyBackup("2025-01-01T04:00:00Z", velerov1.BackupPhasePartiallyFailed),
+	}
+	inProgress := newTestPolicyBackup("2025-01-01T05:00:00Z", velerov1.BackupPhaseInProgress)
+	otherBackup := newTestPolicyBackup("2024-01-01T00:00:00Z", velerov1.BackupPhaseCompleted)
+	otherBackup.Labels = nil
+	pendingDeletion := newPruneDeleteBackupRequest(policy, alreadyDeleting)
+	pendingDeletion.Name = alreadyDeleting.Name + "-pending"
+
+	schemeForFakeClient, err := getSchemeForFakeClient()
+	if err != nil {
+		t.Fatalf("error in creating scheme, likely programmer error")
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(schemeForFakeClient).
+		WithObjects(policy, oldest, failed, alreadyDeleting, retained[0], retained[1], inProgress, otherBackup, pendingDeletion).
+		WithStatusSubresource(&oadpv1alpha1.BackupPolicy{}).
+		Build()
+	r := &BackupPolicyReconciler{
+		Client:        fakeClient,
+		Scheme:        schemeForFakeClient,
+		EventRecorder: record.NewFakeRecorder(10),
+	}
+
+	result, err := r.Reconcile(newContextForTest(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != backupPolicyResyncPeriod {
+		t.Errorf("Reconcile() requeue after = %s, want %s", result.RequeueAfter, backupPolicyResyncPeriod)
+	}
+
+	schedule := &velerov1.Schedule{}
+	if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(policy), schedule); err != nil {
+		t.Fatalf("unable to get schedule: %v", err)
+	}
+	if !metav1.IsControlledBy(schedule, policy) {
+		t.Errorf("Reconcile() schedule is not controlled by the policy")
+	}
+	if schedule.Spec.Schedule != policy.Spec.Schedule || !slices.Equal(schedule.Spec.Template.IncludedNamespaces, policy.Spec.IncludedNamespaces) {
+		t.Errorf("Reconcile() schedule spec = %+v, want the schedule and namespaces of the policy", schedule.Spec)
+	}
+	if schedule.Spec.Template.StorageLocation != "default" || schedule.Spec.Template.TTL.Duration <= time.Hour {
+		t.Errorf("Reconcile() schedule template = %+v, want the policy template with the retention TTL", schedule.Spec.Template)
+	}
+	if schedule.Spec.Template.Metadata.Labels[backupPolicyLabel] != policy.Name {
+		t.Errorf("Reconcile() schedule backups are not labelled with the policy")
+	}
+
+	deleteRequests := &velerov1.DeleteBackupRequestList{}
+	if err := fakeClient.List(newContextForTest(), deleteRequests, client.InNamespace(testNamespaceName)); err != nil {
+		t.Fatalf("unable to list delete backup requests: %v", err)
+	}
+	deleted := []string{}
+	for _, deleteRequest := range deleteRequests.Items {
+		deleted = append(deleted, deleteRequest.Spec.BackupName)
+	}
+	slices.Sort(deleted)
+	if want := backupNames(oldest, failed, alreadyDeleting); !slices.Equal(deleted, want) {
+		t.Errorf("Reconcile() requested the deletion of %v, want %v", deleted, want)
+	}
+
+	if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(policy), policy); err != nil {
+		t.Fatalf("unable to get policy: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(policy.Status.Conditions, oadpv1alpha1.ConditionReconciled) {
+		t.Errorf("Reconcile() conditions = %v, want Reconciled", policy.Status.Conditions)
+	}
+	if policy.Status.Schedule != policy.Name || policy.Status.RetainedBackups != 2 || policy.Status.PrunedBackups != 3 || policy.Status.LastPruneTime == nil {
+		t.Errorf("Reconcile() status = %+v, want 2 backups retained and 3 pruned", policy.Status)
+	}
+}
+
+func TestBackupPolicyReconciler_ReconcileInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec oadpv1alpha1.BackupPolicySpec
+	}{
+		{
+			name: "invalid schedule",
+			spec: oadpv1alpha1.BackupPolicySpec{Schedule: "every hour", Retention: oadpv1alpha1.BackupRetention{Daily: 7}},
+		},
+		{
+			name: "retention keeping no backups",
+			spec: oadpv1alpha1.BackupPolicySpec{Schedule: "@daily"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &oadpv1alpha1.BackupPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: testNamespaceName},
+				Spec:       tt.spec,
+			}
+			schemeForFakeClient, err := getSchemeForFakeClient()
+			if err != nil {
+				t.Fatalf("error in creating scheme, likely programmer error")
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(schemeForFakeClient).
+				WithObjects(policy).
+				WithStatusSubresource(&oadpv1alpha1.BackupPolicy{}).
+				Build()
+			r := &BackupPolicyReconciler{Client: fakeClient, Scheme: schemeForFakeClient, EventRecorder: record.NewFakeRecorder(10)}
+
+			if _, err := r.Reconcile(newContextForTest(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(policy), &velerov1.Schedule{}); err == nil {
+				t.Errorf("Reconcile() created the schedule of an invalid policy")
+			}
+			if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(policy), policy); err != nil {
+				t.Fatalf("unable to get policy: %v", err)
+			}
+			if condition := meta.FindStatusCondition(policy.Status.Conditions, oadpv1alpha1.ConditionReconciled); condition == nil || condition.Reason != oadpv1alpha1.ReconciledReasonError {
+				t.Errorf("Reconcile() conditions = %v, want a reconcile error", policy.Status.Conditions)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/cloudprovider"
+	"github.com/openshift/oadp-operator/pkg/credentials"
+	"github.com/openshift/oadp-operator/pkg/utils"
+)
+
+const (
+	defaultReplicationInterval = 15 * time.Minute
+	// replicationStatusBackups is the number of most recent backups in the replication status of a location
+	replicationStatusBackups = 10
+	// veleroBackupMetadataFile is copied last, as Velero syncs the backups of a location from it
+	veleroBackupMetadataFile = "velero-backup.json"
+	// replicationPassTimeout bounds the copies of a reconcile, so a large replication does not hold the worker of
+	// every DPA; the next pass resumes it
+	replicationPassTimeout = 10 * time.Minute
+	// replicationWorkers is the number of DPAs replicated at once, so a large replication does not delay the others
+	replicationWorkers = 4
+	// replicationClockSkew is the margin of the checkpoint of a Kopia copy, compared with the modification times of
+	// the object storage
+	replicationClockSkew = time.Minute
+)
+
+// replicationStore is the object storage of the buckets a backup location is replicated from and to
+type replicationStore interface {
+	WalkObjects(ctx context.Context, bucket, prefix, startAfter string, fn func(cloudprovider.ObjectInfo) error) error
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	PutObject(ctx context.Context, bucket, key string, body io.Reader) error
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// replicationBucket is a bucket and the prefix of the Velero objects in it
+type replicationBucket struct {
+	store  replicationStore
+	bucket string
+	prefix string
+}
+
+// key returns the key of an object relative to the prefix of the bucket
+func (b replicationBucket) key(elem ...string) string {
+	return path.Join(append([]string{b.prefix}, elem...)...)
+}
+
+// relative returns the key of an object of the bucket relative to its prefix
+func (b replicationBucket) relative(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, path.Clean(b.prefix)+"/")
+}
+
+// walk calls fn with the relative key of each object of the bucket under a directory of the prefix, in key order,
+// after the relative key startAfter when set
+func (b replicationBucket) walk(ctx context.Context, dir, startAfter string, fn func(key string, object cloudprovider.ObjectInfo) error) error {
+	if startAfter != "" {
+		startAfter = b.key(startAfter)
+	}
+	return b.store.WalkObjects(ctx, b.bucket, b.key(dir)+"/", startAfter, func(object cloudprovider.ObjectInfo) error {
+		return fn(b.relative(object.Key), object)
+	})
+}
+
+// list returns the objects of the bucket under a directory of the prefix by relative key
+func (b replicationBucket) list(ctx context.Context, dir string) (map[string]cloudprovider.ObjectInfo, error) {
+	byKey := map[string]cloudprovider.ObjectInfo{}
+	err := b.walk(ctx, dir, "", func(key string, object cloudprovider.ObjectInfo) error {
+		byKey[key] = object
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return byKey, nil
+}
+
+// validReplicationTargetKeys are the config keys of an aws backup location used to access a replication target
+var validReplicationTargetKeys = map[string]bool{
+	Region:                true,
+	S3URL:                 true,
+	S3ForcePathStyle:      true,
+	InsecureSkipTLSVerify: true,
+	Profile:               true,
+}
+
+// validateBackupLocationReplication validates the replication of a backup location and that the credentials of its
+// target exist
+func (r *DataProtectionApplicationReconciler) validateBackupLocationReplication(location oadpv1alpha1.BackupLocation, replicationPath *field.Path) error {
+	if !isAWSBackupLocation(location) {
+		return newFieldError(field.Forbidden(replicationPath, "replication is only supported by aws velero backup locations"))
+	}
+	target := location.Replication.Target
+	targetPath := replicationPath.Child("target")
+	if target.Bucket == "" {
+		return newFieldError(field.Required(targetPath.Child("bucket"), "bucket of the replication target cannot be empty"))
+	}
+	for key := range target.Config {
+		if !validReplicationTargetKeys[key] {
+			return newFieldError(field.NotSupported(targetPath.Child("config").Key(key), key, slices.Sorted(maps.Keys(validReplicationTargetKeys))))
+		}
+	}
+	if objectStorage := location.Velero.ObjectStorage; objectStorage != nil && objectStorage.Bucket == target.Bucket &&
+		path.Clean("/"+objectStorage.Prefix) == path.Clean("/"+target.Prefix) && location.Velero.Config[S3URL] == target.Config[S3URL] {
+		return newFieldError(field.Invalid(targetPath, replicationTargetURL(target), "the replication target must be another bucket or prefix than the backup location"))
+	}
+	if r.dpa.Spec.Configuration.Velero.HasFeatureFlag("no-secret") {
+		return nil
+	}
+	_, _, err := r.getSecretNameAndKey(target.Config, target.Credential, oadpv1alpha1.DefaultPluginAWS)
+	return r.resourceCheck(err)
+}
+
+// replicationTargetURL returns the URL of the bucket and prefix of a replication target
+func replicationTargetURL(target oadpv1alpha1.ReplicationTarget) string {
+	return "s3://" + path.Join(target.Bucket, target.Prefix)
+}
+
+// replicationTargetSpec returns the target of a replication as the spec of an aws backup location
+func replicationTargetSpec(target oadpv1alpha1.ReplicationTarget) *velerov1.BackupStorageLocationSpec {
+	return &velerov1.BackupStorageLocationSpec{
+		Provider: AWSProvider,
+		Config:   target.Config,
+		StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+			Bucket: target.Bucket,
+			Prefix: target.Prefix,
+			CACert: target.CACert,
+		}},
+		Credential: target.Credential,
+	}
+}
+
+// BackupReplicationReconciler copies the backups and the Kopia repositories of the replicated backup locations of
+// a DPA to their secondary bucket
+type BackupReplicationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+	// newStore returns the store of the bucket of an aws location, an AWSProvider when nil
+	newStore func(bslSpec *velerov1.BackupStorageLocationSpec, creds utils.AWSProfileCredentials) (replicationStore, error)
+}
+
+// SetupWithManager sets up the backup replication controller with the Manager.
+func (r *BackupReplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.newStore == nil {
+		r.newStore = func(bslSpec *velerov1.BackupStorageLocationSpec, creds utils.AWSProfileCredentials) (replicationStore, error) {
+			return newAWSLocationProvider(bslSpec, creds)
+		}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("backup-replication").
+		WithOptions(controller.Options{MaxConcurrentReconciles: replicationWorkers}).
+		// the annotations trigger the replication of a DPA leaving dry-run
+		For(&oadpv1alpha1.DataProtectionApplication{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		// a backup is copied when it completes instead of at the next interval
+		Watches(&velerov1.Backup{}, handler.EnqueueRequestsFromMapFunc(r.backupDPAs), builder.WithPredicates(phaseChangedPredicate(func(obj client.Object) string {
+			return string(obj.(*velerov1.Backup).Status.Phase)
+		}))).
+		Complete(r)
+}
+
+// backupDPAs returns the DPAs of the namespace of a completed backup
+func (r *BackupReplicationReconciler) backupDPAs(ctx context.Context, obj client.Object) []reconcile.Request {
+	if !isReplicatedBackupPhase(obj.(*velerov1.Backup).Status.Phase) {
+		return nil
+	}
+	dpaList := &oadpv1alpha1.DataProtectionApplicationList{}
+	if err := r.List(ctx, dpaList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list DataProtectionApplications")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, dpa := range dpaList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&dpa)})
+	}
+	return requests
+}
+
+// isReplicatedBackupPhase returns true for the phases of the backups whose objects are complete in the bucket
+func isReplicatedBackupPhase(phase velerov1.BackupPhase) bool {
+	return phase == velerov1.BackupPhaseCompleted || phase == velerov1.BackupPhasePartiallyFailed
+}
+
+// Reconcile replicates the backup locations of the DPA that are due, when their interval elapsed, a backup of the
+// location completed since the last replication, the target changed or the last replication stopped at the deadline
+// of its pass, and requeues the DPA for the next one. A paused DPA, or one in dry-run, is not replicated.
+func (r *BackupReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	dpa := &oadpv1alpha1.DataProtectionApplication{}
+	if err := r.Get(ctx, req.NamespacedName, dpa); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			deleteBackupReplicationMetrics(req.Namespace, req.Name)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if dpa.Spec.Paused || dpa.Annotations[oadpv1alpha1.DryRunAnnotation] == "true" {
+		logger.V(1).Info("skipping the replication of a paused or dry-run DPA")
+		return ctrl.Result{}, nil
+	}
+
+	backups := &velerov1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(dpa.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	// the helpers resolving the credentials of the locations are the ones of the DPA reconcile
+	dpaReconciler := &DataProtectionApplicationReconciler{
+		Client:         r.Client,
+		Scheme:         r.Scheme,
+		Log:            logger,
+		Context:        ctx,
+		NamespacedName: req.NamespacedName,
+		EventRecorder:  r.EventRecorder,
+		dpa:            dpa,
+	}
+
+	previous := map[string]oadpv1alpha1.BackupLocationReplicationStatus{}
+	for _, status := range dpa.Status.BackupLocationReplication {
+		previous[status.Name] = status
+	}
+	passCtx, cancel := context.WithTimeout(ctx, replicationPassTimeout)
+	defer cancel()
+	var statuses []oadpv1alpha1.BackupLocationReplicationStatus
+	var requeueAfter time.Duration
+	for i, location := range dpa.Spec.BackupLocations {
+		if location.Replication == nil || location.Velero == nil {
+			continue
+		}
+		name := getBackupStorageLocationName(dpa.Name, i, location)
+		interval := defaultReplicationInterval
+		if location.Replication.Interval != nil && location.Replication.Interval.Duration > 0 {
+			interval = location.Replication.Interval.Duration
+		}
+		locationBackups := replicatedBackups(backups.Items, name)
+		status, ok := previous[name]
+		if !ok || replicationDue(status, location.Replication.Target, interval, locationBackups) {
+			status = r.replicateBackupLocation(passCtx, ctx, logger, dpaReconciler, name, location, locationBackups, status)
+		}
+		statuses = append(statuses, status)
+		after := max(time.Until(status.LastAttemptTime.Add(interval)), time.Second)
+		if status.Resume {
+			// the other DPAs queued meanwhile are reconciled first
+			after = time.Second
+		}
+		if requeueAfter == 0 || after < requeueAfter {
+			requeueAfter = after
+		}
+	}
+
+	setBackupReplicationMetrics(dpa.Namespace, dpa.Name, statuses)
+	if err := r.updateReplicationStatus(ctx, req.NamespacedName, statuses); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// replicationDue returns true when a location replicated before is to be replicated again
+func replicationDue(status oadpv1alpha1.BackupLocationReplicationStatus, target oadpv1alpha1.ReplicationTarget, interval time.Duration, backups []velerov1.Backup) bool {
+	if status.Resume || status.Target != replicationTargetURL(target) || time.Since(status.LastAttemptTime.Time) >= interval {
+		return true
+	}
+	for _, backup := range backups {
+		if backup.Status.CompletionTimestamp.After(status.LastAttemptTime.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicatedBackups returns the completed backups of a BSL, the most recent first
+func replicatedBackups(backups []velerov1.Backup, bslName string) []velerov1.Backup {
+	replicated := []velerov1.Backup{}
+	for _, backup := range backups {
+		if backup.Spec.StorageLocation == bslName && isReplicatedBackupPhase(backup.Status.Phase) && backup.Status.CompletionTimestamp != nil {
+			replicated = append(replicated, backup)
+		}
+	}
+	sort.SliceStable(replicated, func(i, j int) bool {
+		return replicated[j].Status.CompletionTimestamp.Before(replicated[i].Status.CompletionTimestamp)
+	})
+	return replicated
+}
+
+// replicateBackupLocation copies a backup location to its secondary bucket until the deadline of passCtx and returns
+// its replication status
+func (r *BackupReplicationReconciler) replicateBackupLocation(passCtx, ctx context.Context, logger logr.Logger, dpaReconciler *DataProtectionApplicationReconciler, name string, location oadpv1alpha1.BackupLocation, backups []velerov1.Backup, previous oadpv1alpha1.BackupLocationReplicationStatus) oadpv1alpha1.BackupLocationReplicationStatus {
+	target := location.Replication.Target
+	status := oadpv1alpha1.BackupLocationReplicationStatus{
+		Name:            name,
+		Target:          replicationTargetURL(target),
+		LastAttemptTime: metav1.Now(),
+	}
+	checkpoint := oadpv1alpha1.KopiaReplicationCheckpoint{}
+	if previous.Target == status.Target {
+		status.LastSuccessTime = previous.LastSuccessTime
+		if previous.KopiaCheckpoint != nil {
+			checkpoint = *previous.KopiaCheckpoint
+		}
+	}
+	status.KopiaCheckpoint = &checkpoint
+	fail := func(err error) oadpv1alpha1.BackupLocationReplicationStatus {
+		status.Message = err.Error()
+		status.PendingBackups = len(backups)
+		r.EventRecorder.Event(dpaReconciler.dpa, corev1.EventTypeWarning, "BackupLocationReplicationFailed", fmt.Sprintf("backup location %s: %v", name, err))
+		return status
+	}
+
+	sourceSpec := backupLocationVeleroSpec(location, dpaReconciler.dpa.MountLocationCredentials())
+	source, err := r.replicationBucket(dpaReconciler, &sourceSpec)
+	if err != nil {
+		return fail(fmt.Errorf("unable to access the backup location: %w", err))
+	}
+	destination, err := r.replicationBucket(dpaReconciler, replicationTargetSpec(target))
+	if err != nil {
+		return fail(fmt.Errorf("unable to access the replication target %s: %w", status.Target, err))
+	}
+
+	logger.Info("replicating backup location", "backupLocation", name, "target", status.Target)
+	status.Backups, checkpoint, err = replicate(passCtx, source, destination, backups, checkpoint, location.Replication.Prune)
+	for _, backup := range status.Backups {
+		if backup.ReplicationTime == nil {
+			status.PendingBackups++
+		}
+	}
+	if len(status.Backups) > replicationStatusBackups {
+		status.Backups = status.Backups[:replicationStatusBackups]
+	}
+	// the errors of the object storage do not all wrap the error of the context
+	if err != nil && passCtx.Err() != nil && ctx.Err() == nil {
+		logger.Info("backup location replication stopped at the deadline of its pass", "backupLocation", name, "timeout", replicationPassTimeout)
+		status.Message = fmt.Sprintf("replication stopped after %s, the next pass resumes it", replicationPassTimeout)
+		status.Resume = true
+		if status.Backups == nil {
+			// the backups were not listed, the pending ones are the previous ones and the ones completed since
+			if previous.Target == status.Target {
+				status.Backups = previous.Backups
+				status.PendingBackups = previous.PendingBackups
+			}
+			for _, backup := range backups {
+				if backup.Status.CompletionTimestamp.After(previous.LastAttemptTime.Time) {
+					status.PendingBackups++
+				}
+			}
+		}
+		return status
+	}
+	if err != nil {
+		status.Message = err.Error()
+		r.EventRecorder.Event(dpaReconciler.dpa, corev1.EventTypeWarning, "BackupLocationReplicationFailed", fmt.Sprintf("backup location %s: %v", name, err))
+		return status
+	}
+	status.LastSuccessTime = &status.LastAttemptTime
+	return status
+}
+
+// replicationBucket returns the bucket of an aws location with its credentials. Only keys, and the roles assumed
+// with them, are usable from the operator pod.
+func (r *BackupReplicationReconciler) replicationBucket(dpaReconciler *DataProtectionApplicationReconciler, bslSpec *velerov1.BackupStorageLocationSpec) (replicationBucket, error) {
+	var secret corev1.Secret
+	var secretName, secretKey string
+	var err error
+	if credentialsFile := bslSpec.Config[CredentialsFileKey]; credentials.IsSecretProviderClassFilePath(credentialsFile) {
+		secret, secretKey, err = credentials.GetSecretProviderClassFileSecret(credentialsFile)
+		if err != nil {
+			return replicationBucket{}, err
+		}
+		secretName = secret.Name
+	} else {
+		secretName, secretKey, err = dpaReconciler.getSecretNameAndKey(bslSpec.Config, bslSpec.Credential, oadpv1alpha1.DefaultPluginAWS)
+		if err != nil {
+			return replicationBucket{}, err
+		}
+		secret, err = dpaReconciler.getProviderSecret(secretName)
+		if err != nil {
+			return replicationBucket{}, fmt.Errorf("unable to get credentials secret %s: %w", secretName, err)
+		}
+	}
+	profile := "default"
+	if value, ok := bslSpec.Config[Profile]; ok {
+		profile = value
+	}
+	creds, err := utils.ResolveAWSCredentials(secret.Data[secretKey], profile)
+	if err != nil {
+		return replicationBucket{}, fmt.Errorf("error parsing AWS secret %s: %w", secretName, err)
+	}
+	if creds.Source != utils.AWSCredentialsSourceStatic {
+		return replicationBucket{}, fmt.Errorf("replication with %s credentials of profile %s is not supported", creds.Source, profile)
+	}
+	store, err := r.newStore(bslSpec, creds)
+	if err != nil {
+		return replicationBucket{}, err
+	}
+	return replicationBucket{store: store, bucket: bslSpec.ObjectStorage.Bucket, prefix: bslSpec.ObjectStorage.Prefix}, nil
+}
+
+// replicate copies the Kopia repository blobs from the checkpoint, then the completed backups missing from the target
+// bucket whose blobs the Kopia copy includes, and returns the replication of each backup and the checkpoint of the
+// Kopia copy. The metadata file of a backup is copied last, so a Velero syncing the target never finds a backup whose
+// objects are not all copied. Objects deleted from the source are kept in the target. No backup is returned when the
+// context ends before the backups are listed. With prune, the backups deleted from the source are deleted from the
+// target.
+func replicate(ctx context.Context, source, target replicationBucket, backups []velerov1.Backup, checkpoint oadpv1alpha1.KopiaReplicationCheckpoint, prune bool) ([]oadpv1alpha1.BackupReplicationStatus, oadpv1alpha1.KopiaReplicationCheckpoint, error) {
+	var errs []error
+	checkpoint, err := copyKopiaRepositories(ctx, source, target, checkpoint)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("unable to copy the Kopia repositories: %w", err))
+	}
+	if ctx.Err() != nil {
+		return nil, checkpoint, errors.Join(append(errs, ctx.Err())...)
+	}
+	// the backups are not copied without the repository blobs their data is in
+	canCopy := true
+	targetBackups, err := target.list(ctx, "backups")
+	if err != nil {
+		errs = append(errs, err)
+		canCopy = false
+	}
+
+	now := time.Now()
+	statuses := make([]oadpv1alpha1.BackupReplicationStatus, 0, len(backups))
+	for _, backup := range backups {
+		status := oadpv1alpha1.BackupReplicationStatus{Name: backup.Name, CompletionTime: backup.Status.CompletionTimestamp}
+		if metadata, ok := targetBackups[path.Join("backups", backup.Name, veleroBackupMetadataFile)]; ok {
+			status.ReplicationTime = &metav1.Time{Time: metadata.LastModified}
+		} else if canCopy && ctx.Err() == nil && checkpoint.CopiedBefore != nil && backup.Status.CompletionTimestamp.Before(checkpoint.CopiedBefore) {
+			if err := copyBackup(ctx, source, target, backup.Name, targetBackups); err != nil {
+				errs = append(errs, fmt.Errorf("unable to copy backup %s: %w", backup.Name, err))
+			} else {
+				status.ReplicationTime = &metav1.Time{Time: time.Now()}
+			}
+		}
+		lagUntil := now
+		if status.ReplicationTime != nil {
+			lagUntil = status.ReplicationTime.Time
+		}
+		status.Lag = &metav1.Duration{Duration: max(lagUntil.Sub(backup.Status.CompletionTimestamp.Time), 0)}
+		statuses = append(statuses, status)
+	}
+	if prune && canCopy && ctx.Err() == nil {
+		if err := pruneBackups(ctx, source, target, targetBackups); err != nil {
+			errs = append(errs, fmt.Errorf("unable to prune the deleted backups: %w", err))
+		}
+	}
+	return statuses, checkpoint, errors.Join(errs...)
+}
+
+// copyBackup copies the objects of a backup missing from the target bucket, its metadata file last
+func copyBackup(ctx context.Context, source, target replicationBucket, name string, targetBackups map[string]cloudprovider.ObjectInfo) error {
+	objects, err := source.list(ctx, path.Join("backups", name))
+	if err != nil {
+		return err
+	}
+	metadataKey := path.Join("backups", name, veleroBackupMetadataFile)
+	if _, ok := objects[metadataKey]; !ok {
+		return fmt.Errorf("%s not found in s3://%s", metadataKey, path.Join(source.bucket, source.prefix))
+	}
+	for _, key := range sortedKeys(objects) {
+		if key == metadataKey {
+			continue
+		}
+		if existing, ok := targetBackups[key]; ok && existing.Size == objects[key].Size {
+			continue
+		}
+		if err := copyObject(ctx, source, target, key); err != nil {
+			return err
+		}
+	}
+	return copyObject(ctx, source, target, metadataKey)
+}
+
+// pruneBackups deletes the objects of the backups of the target bucket whose metadata file is no longer in the source
+// bucket, the metadata file first, so a Velero syncing the target never finds a partly deleted backup
+func pruneBackups(ctx context.Context, source, target replicationBucket, targetBackups map[string]cloudprovider.ObjectInfo) error {
+	sourceBackups := map[string]bool{}
+	err := source.walk(ctx, "backups", "", func(key string, object cloudprovider.ObjectInfo) error {
+		if path.Base(key) == veleroBackupMetadataFile {
+			sourceBackups[path.Dir(key)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	pruned := map[string][]string{}
+	for _, key := range sortedKeys(targetBackups) {
+		// the keys are backups/<name>/<file>
+		parts := strings.SplitN(key, "/", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		if dir := path.Join(parts[0], parts[1]); !sourceBackups[dir] {
+			pruned[dir] = append(pruned[dir], key)
+		}
+	}
+	for _, dir := range slices.Sorted(maps.Keys(pruned)) {
+		keys := pruned[dir]
+		metadataKey := path.Join(dir, veleroBackupMetadataFile)
+		if i := slices.Index(keys, metadataKey); i > 0 {
+			keys = append([]string{metadataKey}, slices.Delete(keys, i, i+1)...)
+		}
+		for _, key := range keys {
+			if err := target.store.DeleteObject(ctx, target.bucket, target.key(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyKopiaRepositories copies the objects under kopia/ of the source bucket modified since the last complete copy of
+// the checkpoint, like new blobs or the format blobs of a Kopia repository, streaming the listing of the source in key
+// order. The returned checkpoint resumes an interrupted copy after its last object, and records the start of a
+// complete one.
+func copyKopiaRepositories(ctx context.Context, source, target replicationBucket, checkpoint oadpv1alpha1.KopiaReplicationCheckpoint) (oadpv1alpha1.KopiaReplicationCheckpoint, error) {
+	if checkpoint.StartTime == nil {
+		checkpoint.StartTime = &metav1.Time{Time: time.Now()}
+		checkpoint.ResumeAfter = ""
+	}
+	var since time.Time
+	if checkpoint.CopiedBefore != nil {
+		since = checkpoint.CopiedBefore.Add(-replicationClockSkew)
+	}
+	err := source.walk(ctx, "kopia", checkpoint.ResumeAfter, func(key string, object cloudprovider.ObjectInfo) error {
+		if object.LastModified.After(since) {
+			if err := copyObject(ctx, source, target, key); err != nil {
+				return err
+			}
+		}
+		checkpoint.ResumeAfter = key
+		return nil
+	})
+	if err != nil {
+		return checkpoint, err
+	}
+	return oadpv1alpha1.KopiaReplicationCheckpoint{CopiedBefore: checkpoint.StartTime}, nil
+}
+
+// copyObject streams an object from the source bucket to the target bucket
+func copyObject(ctx context.Context, source, target replicationBucket, key string) error {
+	body, err := source.store.GetObject(ctx, source.bucket, source.key(key))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return target.store.PutObject(ctx, target.bucket, target.key(key), body)
+}
+
+func sortedKeys(objects map[string]cloudprovider.ObjectInfo) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// updateReplicationStatus records the replication status of the backup locations in the DPA status
+func (r *BackupReplicationReconciler) updateReplicationStatus(ctx context.Context, key types.NamespacedName, statuses []oadpv1alpha1.BackupLocationReplicationStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		dpa := &oadpv1alpha1.DataProtectionApplication{}
+		if err := r.Get(ctx, key, dpa); err != nil {
+			return err
+		}
+		if len(statuses) == 0 && len(dpa.Status.BackupLocationReplication) == 0 {
+			return nil
+		}
+		dpa.Status.BackupLocationReplication = statuses
+		return r.Status().Update(ctx, dpa)
+	})
+}
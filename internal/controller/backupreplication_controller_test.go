@@ -0,0 +1,584 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/cloudprovider"
+	"github.com/openshift/oadp-operator/pkg/utils"
+)
+
+// memoryStore is a replicationStore keeping the objects of its buckets in memory
+type memoryStore struct {
+	objects map[string]map[string]memoryObject
+	// puts are the bucket/key of the written objects, in order
+	puts []string
+	// deletes are the bucket/key of the deleted objects, in order
+	deletes []string
+	// failGet fails the reads of the keys with this suffix
+	failGet string
+	// onPut is called after each written object
+	onPut func()
+}
+
+type memoryObject struct {
+	data         string
+	lastModified time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string]map[string]memoryObject{}}
+}
+
+func (s *memoryStore) add(bucket, key, data string, lastModified time.Time) {
+	if s.objects[bucket] == nil {
+		s.objects[bucket] = map[string]memoryObject{}
+	}
+	s.objects[bucket][key] = memoryObject{data: data, lastModified: lastModified}
+}
+
+func (s *memoryStore) WalkObjects(ctx context.Context, bucket, prefix, startAfter string, fn func(cloudprovider.ObjectInfo) error) error {
+	objects := []cloudprovider.ObjectInfo{}
+	for key, object := range s.objects[bucket] {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			objects = append(objects, cloudprovider.ObjectInfo{Key: key, Size: int64(len(object.data)), LastModified: object.lastModified})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	for _, object := range objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	object, ok := s.objects[bucket][key]
+	if !ok || (s.failGet != "" && strings.HasSuffix(key, s.failGet)) {
+		return nil, fmt.Errorf("GetObject on s3://%s/%s failed", bucket, key)
+	}
+	return io.NopCloser(strings.NewReader(object.data)), nil
+}
+
+func (s *memoryStore) PutObject(ctx context.Context, bucket, key string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.add(bucket, key, string(data), time.Now())
+	s.puts = append(s.puts, bucket+"/"+key)
+	if s.onPut != nil {
+		s.onPut()
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteObject(ctx context.Context, bucket, key string) error {
+	delete(s.objects[bucket], key)
+	s.deletes = append(s.deletes, bucket+"/"+key)
+	return nil
+}
+
+func completedBackup(name, location string, completion time.Time) velerov1.Backup {
+	return velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespaceName},
+		Spec:       velerov1.BackupSpec{StorageLocation: location},
+		Status: velerov1.BackupStatus{
+			Phase:               velerov1.BackupPhaseCompleted,
+			CompletionTimestamp: &metav1.Time{Time: completion},
+		},
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	completion := time.Now().Add(-time.Hour)
+	store := newMemoryStore()
+	source := replicationBucket{store: store, bucket: "primary", prefix: "velero"}
+	target := replicationBucket{store: store, bucket: "replica", prefix: "dr/velero"}
+
+	store.add("primary", "velero/kopia/app/kopia.repository", "format", completion.Add(-2*time.Hour))
+	store.add("primary", "velero/kopia/app/p0001", "pack", completion)
+	store.add("primary", "velero/kopia/app/kopia.maintenance", "schedule-v2", completion)
+	store.add("primary", "velero/backups/new/new.tar.gz", "resources", completion)
+	store.add("primary", "velero/backups/new/velero-backup.json", "{}", completion)
+	store.add("primary", "velero/backups/incomplete/incomplete.tar.gz", "resources", completion)
+	store.add("primary", "velero/backups/later/velero-backup.json", "{}", time.Now())
+	// already replicated
+	store.add("replica", "dr/velero/kopia/app/kopia.repository", "format", completion.Add(-2*time.Hour))
+	store.add("replica", "dr/velero/kopia/app/kopia.maintenance", "schedule", completion.Add(-2*time.Hour))
+	store.add("replica", "dr/velero/backups/old/velero-backup.json", "{}", completion.Add(-time.Hour+10*time.Minute))
+
+	backups := []velerov1.Backup{
+		// completed after the start of the Kopia copy, which may miss its blobs
+		completedBackup("later", "primary", time.Now().Add(time.Minute)),
+		completedBackup("new", "primary", completion),
+		completedBackup("incomplete", "primary", completion.Add(-time.Minute)),
+		completedBackup("old", "primary", completion.Add(-time.Hour)),
+	}
+	copiedBefore := metav1.NewTime(completion.Add(-30 * time.Minute))
+	statuses, checkpoint, err := replicate(context.Background(), source, target, backups, oadpv1alpha1.KopiaReplicationCheckpoint{CopiedBefore: &copiedBefore}, false)
+	if err == nil || !strings.Contains(err.Error(), "unable to copy backup incomplete: backups/incomplete/velero-backup.json not found in s3://primary/velero") {
+		t.Errorf("replicate() error = %v, want the error of the backup without metadata", err)
+	}
+
+	wantPuts := []string{
+		"replica/dr/velero/kopia/app/kopia.maintenance",
+		"replica/dr/velero/kopia/app/p0001",
+		"replica/dr/velero/backups/new/new.tar.gz",
+		"replica/dr/velero/backups/new/velero-backup.json",
+	}
+	if strings.Join(store.puts, ",") != strings.Join(wantPuts, ",") {
+		t.Errorf("replicate() copied %v, want %v", store.puts, wantPuts)
+	}
+	if checkpoint.CopiedBefore == nil || checkpoint.CopiedBefore.Before(&copiedBefore) || checkpoint.StartTime != nil || checkpoint.ResumeAfter != "" {
+		t.Errorf("replicate() checkpoint = %+v, want the start of the complete copy", checkpoint)
+	}
+	if len(statuses) != 4 {
+		t.Fatalf("replicate() returned %d statuses, want 4", len(statuses))
+	}
+	if statuses[0].ReplicationTime != nil {
+		t.Errorf("backup later = %+v, want it pending", statuses[0])
+	}
+	if statuses[1].ReplicationTime == nil || statuses[1].Lag.Duration < time.Hour-time.Minute {
+		t.Errorf("backup new = %+v, want it replicated with a lag of about an hour", statuses[1])
+	}
+	if statuses[2].ReplicationTime != nil || statuses[2].Lag.Duration < time.Hour {
+		t.Errorf("backup incomplete = %+v, want it pending with a lag over an hour", statuses[2])
+	}
+	if statuses[3].ReplicationTime == nil || statuses[3].Lag.Duration != 10*time.Minute {
+		t.Errorf("backup old = %+v, want it replicated with a lag of 10m", statuses[3])
+	}
+}
+
+func TestReplicateResume(t *testing.T) {
+	completion := time.Now().Add(-time.Hour)
+	store := newMemoryStore()
+	source := replicationBucket{store: store, bucket: "primary"}
+	target := replicationBucket{store: store, bucket: "replica"}
+	store.add("primary", "kopia/app/p0001", "pack", completion)
+	store.add("primary", "kopia/app/p0002", "pack", completion)
+	store.add("primary", "kopia/app/p0003", "pack", completion)
+	store.add("primary", "backups/new/velero-backup.json", "{}", completion)
+	backups := []velerov1.Backup{completedBackup("new", "primary", completion)}
+
+	// the deadline of the pass is reached after the first copied object
+	ctx, cancel := context.WithCancel(context.Background())
+	store.onPut = cancel
+	_, checkpoint, err := replicate(ctx, source, target, backups, oadpv1alpha1.KopiaReplicationCheckpoint{}, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("replicate() error = %v, want the error of the context", err)
+	}
+	if checkpoint.StartTime == nil || checkpoint.CopiedBefore != nil || checkpoint.ResumeAfter != "kopia/app/p0001" {
+		t.Errorf("replicate() checkpoint = %+v, want the copy in progress after kopia/app/p0001", checkpoint)
+	}
+
+	store.onPut = nil
+	store.puts = nil
+	statuses, resumed, err := replicate(context.Background(), source, target, backups, checkpoint, false)
+	if err != nil {
+		t.Fatalf("replicate() error = %v", err)
+	}
+	wantPuts := []string{
+		"replica/kopia/app/p0002",
+		"replica/kopia/app/p0003",
+		"replica/backups/new/velero-backup.json",
+	}
+	if strings.Join(store.puts, ",") != strings.Join(wantPuts, ",") {
+		t.Errorf("replicate() copied %v, want %v", store.puts, wantPuts)
+	}
+	if resumed.CopiedBefore == nil || !resumed.CopiedBefore.Equal(checkpoint.StartTime) || resumed.StartTime != nil {
+		t.Errorf("replicate() checkpoint = %+v, want the copy completed since %v", resumed, checkpoint.StartTime)
+	}
+	if len(statuses) != 1 || statuses[0].ReplicationTime == nil {
+		t.Errorf("replicate() statuses = %+v, want backup new replicated", statuses)
+	}
+}
+
+func TestReplicatePrune(t *testing.T) {
+	completion := time.Now().Add(-time.Hour)
+	store := newMemoryStore()
+	source := replicationBucket{store: store, bucket: "primary", prefix: "velero"}
+	target := replicationBucket{store: store, bucket: "replica", prefix: "velero"}
+	store.add("primary", "velero/backups/kept/velero-backup.json", "{}", completion)
+	store.add("replica", "velero/backups/kept/velero-backup.json", "{}", completion)
+	// expired from the backup location
+	store.add("replica", "velero/backups/expired/expired.tar.gz", "resources", completion)
+	store.add("replica", "velero/backups/expired/velero-backup.json", "{}", completion)
+	store.add("replica", "velero/kopia/app/p0001", "pack", completion)
+	backups := []velerov1.Backup{completedBackup("kept", "primary", completion)}
+	copiedBefore := metav1.Now()
+
+	if _, _, err := replicate(context.Background(), source, target, backups, oadpv1alpha1.KopiaReplicationCheckpoint{CopiedBefore: &copiedBefore}, false); err != nil {
+		t.Fatalf("replicate() error = %v", err)
+	}
+	if len(store.deletes) != 0 {
+		t.Errorf("replicate() without prune deleted %v", store.deletes)
+	}
+
+	if _, _, err := replicate(context.Background(), source, target, backups, oadpv1alpha1.KopiaReplicationCheckpoint{CopiedBefore: &copiedBefore}, true); err != nil {
+		t.Fatalf("replicate() error = %v", err)
+	}
+	wantDeletes := []string{
+		"replica/velero/backups/expired/velero-backup.json",
+		"replica/velero/backups/expired/expired.tar.gz",
+	}
+	if strings.Join(store.deletes, ",") != strings.Join(wantDeletes, ",") {
+		t.Errorf("replicate() deleted %v, want %v", store.deletes, wantDeletes)
+	}
+}
+
+func TestReplicateKopiaFailure(t *testing.T) {
+	store := newMemoryStore()
+	store.failGet = "p0001"
+	store.add("primary", "kopia/app/p0001", "pack", time.Now())
+	store.add("primary", "backups/new/velero-backup.json", "{}", time.Now())
+
+	statuses, _, err := replicate(context.Background(),
+		replicationBucket{store: store, bucket: "primary"},
+		replicationBucket{store: store, bucket: "replica"},
+		[]velerov1.Backup{completedBackup("new", "primary", time.Now().Add(-time.Minute))},
+		oadpv1alpha1.KopiaReplicationCheckpoint{},
+		false,
+	)
+	if err == nil || !strings.Contains(err.Error(), "unable to copy the Kopia repositories") {
+		t.Errorf("replicate() error = %v, want the error of the Kopia repositories", err)
+	}
+	if len(store.puts) != 0 {
+		t.Errorf("replicate() copied %v, want no backup copied without its repository blobs", store.puts)
+	}
+	if len(statuses) != 1 || statuses[0].ReplicationTime != nil {
+		t.Errorf("replicate() statuses = %+v, want the backup pending", statuses)
+	}
+}
+
+func TestBackupReplicationReconciler_Reconcile(t *testing.T) {
+	completion := time.Now().Add(-time.Hour)
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			BackupLocations: []oadpv1alpha1.BackupLocation{
+				{
+					Name: "primary",
+					Velero: &velerov1.BackupStorageLocationSpec{
+						Provider:    AWSProvider,
+						StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "primary"}},
+						Config:      map[string]string{Region: "us-east-1"},
+					},
+					Replication: &oadpv1alpha1.BackupLocationReplication{
+						Target: oadpv1alpha1.ReplicationTarget{
+							Bucket:     "replica",
+							Config:     map[string]string{Region: "us-west-2"},
+							Credential: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "replica-credentials"}, Key: "cloud"},
+						},
+					},
+				},
+				{
+					Name: "other",
+					Velero: &velerov1.BackupStorageLocationSpec{
+						Provider:    AWSProvider,
+						StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "other"}},
+						Config:      map[string]string{Region: "us-east-1"},
+					},
+				},
+			},
+		},
+	}
+	newBackup := completedBackup("new", "primary", completion)
+	otherBackup := completedBackup("other", "other", completion)
+	schemeForFakeClient, err := getSchemeForFakeClient()
+	if err != nil {
+		t.Fatalf("error in creating scheme, likely programmer error")
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(schemeForFakeClient).
+		WithObjects(dpa, &newBackup, &otherBackup,
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials", Namespace: testNamespaceName},
+				Data:       map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=primary\naws_secret_access_key=secret\n")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "replica-credentials", Namespace: testNamespaceName},
+				Data:       map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=replica\naws_secret_access_key=secret\n")},
+			},
+		).
+		WithStatusSubresource(&oadpv1alpha1.DataProtectionApplication{}).
+		Build()
+
+	store := newMemoryStore()
+	store.add("primary", "backups/new/velero-backup.json", "{}", completion)
+	store.add("other", "backups/other/velero-backup.json", "{}", completion)
+	keys := map[string]string{}
+	r := &BackupReplicationReconciler{
+		Client:        fakeClient,
+		Scheme:        schemeForFakeClient,
+		EventRecorder: record.NewFakeRecorder(10),
+		newStore: func(bslSpec *velerov1.BackupStorageLocationSpec, creds utils.AWSProfileCredentials) (replicationStore, error) {
+			keys[bslSpec.ObjectStorage.Bucket] = creds.AccessKeyID
+			return store, nil
+		},
+	}
+
+	key := types.NamespacedName{Namespace: testNamespaceName, Name: testDpaName}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter <= defaultReplicationInterval-time.Minute || result.RequeueAfter > defaultReplicationInterval {
+		t.Errorf("Reconcile() requeued after %v, want %v", result.RequeueAfter, defaultReplicationInterval)
+	}
+	if keys["primary"] != "primary" || keys["replica"] != "replica" {
+		t.Errorf("Reconcile() accessed the buckets with the keys %v, want the keys of their credentials", keys)
+	}
+	if _, ok := store.objects["replica"]["backups/new/velero-backup.json"]; !ok {
+		t.Errorf("Reconcile() did not copy backup new")
+	}
+	if _, ok := store.objects["replica"]["backups/other/velero-backup.json"]; ok {
+		t.Errorf("Reconcile() copied the backup of a location that is not replicated")
+	}
+
+	got := &oadpv1alpha1.DataProtectionApplication{}
+	if err := fakeClient.Get(context.Background(), key, got); err != nil {
+		t.Fatalf("error getting DPA: %v", err)
+	}
+	if len(got.Status.BackupLocationReplication) != 1 {
+		t.Fatalf("DPA status.backupLocationReplication = %+v, want the status of location primary", got.Status.BackupLocationReplication)
+	}
+	status := got.Status.BackupLocationReplication[0]
+	if status.Name != "primary" || status.Target != "s3://replica" || status.Message != "" || status.LastSuccessTime == nil ||
+		status.PendingBackups != 0 || len(status.Backups) != 1 || status.Backups[0].ReplicationTime == nil {
+		t.Errorf("DPA status.backupLocationReplication[0] = %+v, want backup new replicated", status)
+	}
+
+	// a replication that is not due is not run again
+	store.puts = nil
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(store.puts) != 0 {
+		t.Errorf("Reconcile() copied %v before the replication was due", store.puts)
+	}
+}
+
+func TestBackupReplicationReconciler_ReconcilePausedOrDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		paused      bool
+		annotations map[string]string
+	}{
+		{
+			name:   "paused DPA is not replicated",
+			paused: true,
+		},
+		{
+			name:        "dry-run DPA is not replicated",
+			annotations: map[string]string{oadpv1alpha1.DryRunAnnotation: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpa := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName, Annotations: tt.annotations},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Paused: tt.paused,
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{
+							Velero: &velerov1.BackupStorageLocationSpec{
+								Provider:    AWSProvider,
+								StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "primary"}},
+								Config:      map[string]string{Region: "us-east-1"},
+							},
+							Replication: &oadpv1alpha1.BackupLocationReplication{
+								Target: oadpv1alpha1.ReplicationTarget{Bucket: "replica", Config: map[string]string{Region: "us-west-2"}},
+							},
+						},
+					},
+				},
+			}
+			schemeForFakeClient, err := getSchemeForFakeClient()
+			if err != nil {
+				t.Fatalf("error in creating scheme, likely programmer error")
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(schemeForFakeClient).
+				WithObjects(dpa).
+				WithStatusSubresource(&oadpv1alpha1.DataProtectionApplication{}).
+				Build()
+			r := &BackupReplicationReconciler{
+				Client:        fakeClient,
+				Scheme:        schemeForFakeClient,
+				EventRecorder: record.NewFakeRecorder(10),
+				newStore: func(bslSpec *velerov1.BackupStorageLocationSpec, creds utils.AWSProfileCredentials) (replicationStore, error) {
+					t.Errorf("Reconcile() accessed bucket %s", bslSpec.ObjectStorage.Bucket)
+					return newMemoryStore(), nil
+				},
+			}
+
+			key := types.NamespacedName{Namespace: testNamespaceName, Name: testDpaName}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if result.RequeueAfter != 0 {
+				t.Errorf("Reconcile() requeued after %v, want no requeue", result.RequeueAfter)
+			}
+			got := &oadpv1alpha1.DataProtectionApplication{}
+			if err := fakeClient.Get(context.Background(), key, got); err != nil {
+				t.Fatalf("error getting DPA: %v", err)
+			}
+			if len(got.Status.BackupLocationReplication) != 0 {
+				t.Errorf("DPA status.backupLocationReplication = %+v, want no replication", got.Status.BackupLocationReplication)
+			}
+		})
+	}
+}
+
+func TestReplicationDue(t *testing.T) {
+	target := oadpv1alpha1.ReplicationTarget{Bucket: "replica"}
+	status := oadpv1alpha1.BackupLocationReplicationStatus{Target: "s3://replica", LastAttemptTime: metav1.Now()}
+	if replicationDue(status, target, time.Hour, nil) {
+		t.Errorf("replicationDue() = true, want false before the interval")
+	}
+	status.Resume = true
+	if !replicationDue(status, target, time.Hour, nil) {
+		t.Errorf("replicationDue() = false, want true when the last replication stopped at the deadline of its pass")
+	}
+}
+
+func TestDPAReconciler_ValidateBackupLocationReplication(t *testing.T) {
+	primary := func() *velerov1.BackupStorageLocationSpec {
+		return &velerov1.BackupStorageLocationSpec{
+			Provider:    AWSProvider,
+			Default:     true,
+			StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "primary", Prefix: "velero"}},
+			Config:      map[string]string{Region: "us-east-1"},
+		}
+	}
+	tests := []struct {
+		name      string
+		velero    *velerov1.BackupStorageLocationSpec
+		target    oadpv1alpha1.ReplicationTarget
+		wantField string
+		wantErr   bool
+	}{
+		{
+			name:   "target in another bucket",
+			velero: primary(),
+			target: oadpv1alpha1.ReplicationTarget{Bucket: "replica", Prefix: "velero", Config: map[string]string{Region: "us-west-2"}},
+		},
+		{
+			name:   "target under another prefix of the same bucket",
+			velero: primary(),
+			target: oadpv1alpha1.ReplicationTarget{Bucket: "primary", Prefix: "replica"},
+		},
+		{
+			name:      "target is the backup location",
+			velero:    primary(),
+			target:    oadpv1alpha1.ReplicationTarget{Bucket: "primary", Prefix: "velero/"},
+			wantField: "spec.backupLocations[0].replication.target",
+		},
+		{
+			name:      "target without bucket",
+			velero:    primary(),
+			wantField: "spec.backupLocations[0].replication.target.bucket",
+		},
+		{
+			name:      "invalid config key of the target",
+			velero:    primary(),
+			target:    oadpv1alpha1.ReplicationTarget{Bucket: "replica", Config: map[string]string{"serverSideEncryption": "AES256"}},
+			wantField: "spec.backupLocations[0].replication.target.config[serverSideEncryption]",
+		},
+		{
+			name: "location of another provider",
+			velero: &velerov1.BackupStorageLocationSpec{
+				Provider:    GCPProvider,
+				Default:     true,
+				StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "primary", Prefix: "velero"}},
+			},
+			target:    oadpv1alpha1.ReplicationTarget{Bucket: "replica"},
+			wantField: "spec.backupLocations[0].replication",
+		},
+		{
+			name:   "credentials of the target not found",
+			velero: primary(),
+			target: oadpv1alpha1.ReplicationTarget{
+				Bucket:     "replica",
+				Credential: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "replica-credentials"}, Key: "cloud"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpa := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS, oadpv1alpha1.DefaultPluginGCP},
+						},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{{
+						Velero:      tt.velero,
+						Replication: &oadpv1alpha1.BackupLocationReplication{Target: tt.target},
+					}},
+				},
+			}
+			fakeClient, err := getFakeClientFromObjects(dpa,
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials", Namespace: testNamespaceName},
+					Data:       map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=access\naws_secret_access_key=secret\n")},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials-gcp", Namespace: testNamespaceName},
+					Data:       map[string][]byte{"cloud": []byte("{}")},
+				},
+			)
+			if err != nil {
+				t.Fatalf("error creating fake client: %v", err)
+			}
+			r := &DataProtectionApplicationReconciler{
+				Client:         fakeClient,
+				Scheme:         fakeClient.Scheme(),
+				Log:            logr.Discard(),
+				Context:        newContextForTest(),
+				NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+				EventRecorder:  record.NewFakeRecorder(10),
+				dpa:            dpa,
+			}
+
+			_, err = r.ValidateBackupStorageLocations()
+			switch {
+			case tt.wantField != "":
+				var fieldErr fieldError
+				if !errors.As(err, &fieldErr) || fieldErr.fieldErr.Field != tt.wantField {
+					t.Errorf("ValidateBackupStorageLocations() error = %v, want an error on %s", err, tt.wantField)
+				}
+			case (err != nil) != tt.wantErr:
+				t.Errorf("ValidateBackupStorageLocations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,13 +3,14 @@ package controller
 import (
 	"errors"
 	"fmt"
-	"slices"
+	"maps"
 	"strings"
 
 	"github.com/go-logr/logr"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -24,84 +25,113 @@ func (r *DataProtectionApplicationReconciler) ValidateBackupStorageLocations() (
 	// First, check for provider and then call functions based on the cloud provider for each backupstoragelocation configured
 	dpa := r.dpa
 	numDefaultLocations := 0
-	for _, bslSpec := range dpa.Spec.BackupLocations {
+	backupLocationsPath := field.NewPath("spec", "backupLocations")
+	for i, bslSpec := range dpa.Spec.BackupLocations {
+		bslPath := backupLocationsPath.Index(i)
 		if err := r.ensureBackupLocationHasVeleroOrCloudStorage(&bslSpec); err != nil {
-			return false, err
+			return false, newFieldError(field.Invalid(bslPath, field.OmitValueType{}, err.Error()))
+		}
+
+		if bslSpec.SecretProviderClass != nil {
+			if bslSpec.Velero == nil {
+				return false, newFieldError(field.Forbidden(bslPath.Child("secretProviderClass"), "secretProviderClass is only supported by velero backup locations"))
+			}
+			if err := validateSecretProviderClass(bslSpec.SecretProviderClass, bslSpec.Velero.Credential, bslSpec.Velero.Config, bslPath); err != nil {
+				return false, err
+			}
+		}
+
+		if bslSpec.Replication != nil {
+			if err := r.validateBackupLocationReplication(bslSpec, bslPath.Child("replication")); err != nil {
+				return false, err
+			}
 		}
 
 		if err := r.ensurePrefixWhenBackupImages(&bslSpec); err != nil {
-			return false, err
+			return false, newFieldError(field.Required(bslPath, err.Error()))
 		}
 
-		if err := r.ensureSecretDataExists(&bslSpec); err != nil {
+		if err := r.resourceCheck(r.ensureSecretDataExists(&bslSpec)); err != nil {
 			return false, err
 		}
+		if bslSpec.S3Vendor != "" && !isAWSBackupLocation(bslSpec) {
+			return false, newFieldError(field.Invalid(bslPath.Child("s3Vendor"), bslSpec.S3Vendor, "s3Vendor is only supported by aws velero backup locations"))
+		}
 		if bslSpec.Velero != nil {
 			if bslSpec.Velero.Default {
 				numDefaultLocations++
 			} else if bslSpec.Name == "default" {
-				return false, fmt.Errorf("Storage location named 'default' must be set as default")
+				return false, newFieldError(field.Invalid(bslPath.Child("velero", "default"), false, "Storage location named 'default' must be set as default"))
 			}
 			provider := bslSpec.Velero.Provider
 			if len(provider) == 0 {
-				return false, fmt.Errorf("no provider specified for one of the backupstoragelocations configured")
+				return false, newFieldError(field.Required(bslPath.Child("velero", "provider"), "no provider specified for one of the backupstoragelocations configured"))
 			}
 
-			// TODO: cases might need some updates for IBM/Minio/noobaa
 			switch provider {
 			case AWSProvider, "velero.io/aws":
-				err := r.validateAWSBackupStorageLocation(*bslSpec.Velero)
+				err := r.validateAWSBackupStorageLocation(backupLocationVeleroSpec(bslSpec, r.dpa.MountLocationCredentials()), bslPath.Child("velero"))
 				if err != nil {
 					return false, err
 				}
+				if err := validateS3Vendor(bslSpec, bslPath); err != nil {
+					return false, err
+				}
+				if hint := s3VendorHint(bslSpec); hint != "" {
+					r.Log.Info(fmt.Sprintf("backup location %s: %s", bslPath, hint))
+				}
 			case AzureProvider, "velero.io/azure":
-				err := r.validateAzureBackupStorageLocation(*bslSpec.Velero)
+				err := r.validateAzureBackupStorageLocation(backupLocationVeleroSpec(bslSpec, r.dpa.MountLocationCredentials()), bslPath.Child("velero"))
 				if err != nil {
 					return false, err
 				}
 			case GCPProvider, "velero.io/gcp":
-				err := r.validateGCPBackupStorageLocation(*bslSpec.Velero)
+				err := r.validateGCPBackupStorageLocation(backupLocationVeleroSpec(bslSpec, r.dpa.MountLocationCredentials()), bslPath.Child("velero"))
 				if err != nil {
 					return false, err
 				}
 			default:
-				return false, fmt.Errorf("invalid provider")
+				return false, newFieldError(field.Invalid(bslPath.Child("velero", "provider"), provider, "invalid provider"))
 			}
 		}
 		if bslSpec.CloudStorage != nil {
 			if bslSpec.CloudStorage.Default {
 				numDefaultLocations++
 			} else if bslSpec.Name == "default" {
-				return false, fmt.Errorf("Storage location named 'default' must be set as default")
+				return false, newFieldError(field.Invalid(bslPath.Child("bucket", "default"), false, "Storage location named 'default' must be set as default"))
 			}
 		}
 	}
 	if numDefaultLocations > 1 {
-		return false, fmt.Errorf("Only one Storage Location be set as default")
+		return false, newFieldError(field.Invalid(backupLocationsPath, field.OmitValueType{}, "Only one Storage Location be set as default"))
 	}
 	if numDefaultLocations == 0 && !dpa.Spec.Configuration.Velero.NoDefaultBackupLocation {
-		return false, errors.New("no default backupstoragelocations configured, ensure that one backupstoragelocation has been configured as the default location")
+		return false, newFieldError(field.Required(backupLocationsPath, "no default backupstoragelocations configured, ensure that one backupstoragelocation has been configured as the default location"))
 	}
 	// TODO: Discuss If multiple BSLs exist, ensure we have multiple credentials
 
 	return true, nil
 }
 
+// getBackupStorageLocationName returns the name of the BSL created for the
+// backup location at index i of the DPA spec.backupLocations
+func getBackupStorageLocationName(dpaName string, i int, bslSpec oadpv1alpha1.BackupLocation) string {
+	// check if BSL name is specified in DPA spec
+	if bslSpec.Name != "" {
+		return bslSpec.Name
+	}
+	return fmt.Sprintf("%s-%d", dpaName, i+1)
+}
+
 func (r *DataProtectionApplicationReconciler) ReconcileBackupStorageLocations(log logr.Logger) (bool, error) {
 	dpa := r.dpa
-	dpaBSLNames := []string{}
 
 	// Loop through all configured BSLs
 	for i, bslSpec := range dpa.Spec.BackupLocations {
 		// Create BSL as is, we can safely assume they are valid from
 		// ValidateBackupStorageLocations
 
-		// check if BSL name is specified in DPA spec
-		bslName := fmt.Sprintf("%s-%d", r.NamespacedName.Name, i+1)
-		if bslSpec.Name != "" {
-			bslName = bslSpec.Name
-		}
-		dpaBSLNames = append(dpaBSLNames, bslName)
+		bslName := getBackupStorageLocationName(r.NamespacedName.Name, i, bslSpec)
 
 		bsl := velerov1.BackupStorageLocation{
 			ObjectMeta: metav1.ObjectMeta{
@@ -121,9 +151,12 @@ func (r *DataProtectionApplicationReconciler) ReconcileBackupStorageLocations(lo
 		if bslSpec.Velero != nil {
 			secretName, _, _ = r.getSecretNameAndKey(bslSpec.Velero.Config, bslSpec.Velero.Credential, oadpv1alpha1.DefaultPlugin(bslSpec.Velero.Provider))
 		}
-		err := r.UpdateCredentialsSecretLabels(secretName, dpa.Name)
-		if err != nil {
-			return false, err
+		// the credentials of a SecretProviderClass are not in a secret
+		if bslSpec.SecretProviderClass == nil {
+			err := r.UpdateCredentialsSecretLabels(secretName, dpa.Name)
+			if err != nil {
+				return false, err
+			}
 		}
 
 		// Create BSL
@@ -134,7 +167,7 @@ func (r *DataProtectionApplicationReconciler) ReconcileBackupStorageLocations(lo
 
 			// TODO: check for BSL status condition errors and respond here
 			if bslSpec.Velero != nil {
-				err := r.updateBSLFromSpec(&bsl, *bslSpec.Velero)
+				err := r.updateBSLFromSpec(&bsl, backupLocationVeleroSpec(bslSpec, r.dpa.MountLocationCredentials()))
 
 				return err
 			}
@@ -157,6 +190,9 @@ func (r *DataProtectionApplicationReconciler) ReconcileBackupStorageLocations(lo
 					bsl.Spec.Config["enableSharedConfig"] = "true"
 				}
 				bsl.Spec.Credential = bslSpec.CloudStorage.Credential
+				if r.dpa.MountLocationCredentials() {
+					bsl.Spec.Config, bsl.Spec.Credential = mountedLocationCredential(maps.Clone(bsl.Spec.Config), bsl.Spec.Credential)
+				}
 				bsl.Spec.Default = bslSpec.CloudStorage.Default
 				bsl.Spec.ObjectStorage = &velerov1.ObjectStorageLocation{
 					Bucket: bucket.Spec.Name,
@@ -189,32 +225,7 @@ func (r *DataProtectionApplicationReconciler) ReconcileBackupStorageLocations(lo
 		}
 	}
 
-	dpaBSLs := velerov1.BackupStorageLocationList{}
-	dpaBSLLabels := map[string]string{
-		"app.kubernetes.io/name":       common.OADPOperatorVelero,
-		"app.kubernetes.io/managed-by": common.OADPOperator,
-		"app.kubernetes.io/component":  "bsl",
-	}
-	err := r.List(r.Context, &dpaBSLs, client.InNamespace(r.NamespacedName.Namespace), client.MatchingLabels(dpaBSLLabels))
-	if err != nil {
-		return false, err
-	}
-	// If current BSLs do not match the spec, delete extra BSLs
-	if len(dpaBSLNames) != len(dpaBSLs.Items) {
-		for _, bsl := range dpaBSLs.Items {
-			if !slices.Contains(dpaBSLNames, bsl.Name) {
-				if err := r.Delete(r.Context, &bsl); err != nil {
-					return false, err
-				}
-				// Record event for BSL deletion
-				r.EventRecorder.Event(&bsl,
-					corev1.EventTypeNormal,
-					"BackupStorageLocationDeleted",
-					fmt.Sprintf("BackupStorageLocation %s created by OADP in namespace %s was deleted as it was not in DPA spec.", bsl.Name, bsl.Namespace))
-			}
-		}
-	}
-
+	// BSLs removed from the spec are deleted by ReconcileOrphanedResources
 	return true, nil
 }
 
@@ -274,7 +285,7 @@ func (r *DataProtectionApplicationReconciler) updateBSLFromSpec(bsl *velerov1.Ba
 	return nil
 }
 
-func (r *DataProtectionApplicationReconciler) validateAWSBackupStorageLocation(bslSpec velerov1.BackupStorageLocationSpec) error {
+func (r *DataProtectionApplicationReconciler) validateAWSBackupStorageLocation(bslSpec velerov1.BackupStorageLocationSpec, veleroPath *field.Path) error {
 	// validate provider plugin and secret
 	err := r.validateProviderPluginAndSecret(bslSpec)
 	if err != nil {
@@ -283,32 +294,36 @@ func (r *DataProtectionApplicationReconciler) validateAWSBackupStorageLocation(b
 
 	// check for bsl non-optional bsl configs and object storage
 	if bslSpec.ObjectStorage == nil {
-		return fmt.Errorf("object storage configuration for AWS backupstoragelocation cannot be nil")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage"), "object storage configuration for AWS backupstoragelocation cannot be nil"))
 	}
 
 	if len(bslSpec.ObjectStorage.Bucket) == 0 {
-		return fmt.Errorf("bucket name for AWS backupstoragelocation cannot be empty")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage", "bucket"), "bucket name for AWS backupstoragelocation cannot be empty"))
 	}
 
 	if len(bslSpec.StorageType.ObjectStorage.Prefix) == 0 && r.dpa.BackupImages() {
-		return fmt.Errorf("prefix for AWS backupstoragelocation object storage cannot be empty. It is required for backing up images")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage", "prefix"), "prefix for AWS backupstoragelocation object storage cannot be empty. It is required for backing up images"))
 	}
 
 	// BSL region is required when
 	// - s3ForcePathStyle is true, because some velero processes requires region to be set and is not auto-discoverable when s3ForcePathStyle is true
 	//   imagestream backup in openshift-velero-plugin now uses the same method to discover region as the rest of the velero codebase
 	// - even when s3ForcePathStyle is false, some aws bucket regions may not be discoverable and the user has to set it manually
-	if (bslSpec.Config == nil || len(bslSpec.Config[Region]) == 0) &&
-		(bslSpec.Config != nil && bslSpec.Config[S3ForcePathStyle] == "true" || !aws.BucketRegionIsDiscoverable(bslSpec.ObjectStorage.Bucket)) {
-		return fmt.Errorf("region for AWS backupstoragelocation not automatically discoverable. Please set the region in the backupstoragelocation config")
+	if len(bslSpec.Config[Region]) == 0 {
+		regionPath := veleroPath.Child("config").Key(Region)
+		if bslSpec.Config[S3ForcePathStyle] == "true" {
+			return newFieldError(field.Required(regionPath, "region for AWS backupstoragelocation is required when s3ForcePathStyle is true. Please set the region in the backupstoragelocation config"))
+		}
+		// the region is discovered with a request to the bucket, which the webhook does not send
+		if !r.admission && !aws.BucketRegionIsDiscoverable(bslSpec.ObjectStorage.Bucket) {
+			return newFieldError(field.Required(regionPath, "region for AWS backupstoragelocation not automatically discoverable. Please set the region in the backupstoragelocation config"))
+		}
 	}
 
-	//TODO: Add minio, noobaa, local storage validations
-
 	return nil
 }
 
-func (r *DataProtectionApplicationReconciler) validateAzureBackupStorageLocation(bslSpec velerov1.BackupStorageLocationSpec) error {
+func (r *DataProtectionApplicationReconciler) validateAzureBackupStorageLocation(bslSpec velerov1.BackupStorageLocationSpec, veleroPath *field.Path) error {
 	// validate provider plugin and secret
 	err := r.validateProviderPluginAndSecret(bslSpec)
 	if err != nil {
@@ -317,29 +332,29 @@ func (r *DataProtectionApplicationReconciler) validateAzureBackupStorageLocation
 
 	// check for bsl non-optional bsl configs and object storage
 	if bslSpec.ObjectStorage == nil {
-		return fmt.Errorf("object storage configuration for Azure backupstoragelocation cannot be nil")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage"), "object storage configuration for Azure backupstoragelocation cannot be nil"))
 	}
 
 	if len(bslSpec.ObjectStorage.Bucket) == 0 {
-		return fmt.Errorf("bucket name for Azure backupstoragelocation cannot be empty")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage", "bucket"), "bucket name for Azure backupstoragelocation cannot be empty"))
 	}
 
 	if len(bslSpec.Config[ResourceGroup]) == 0 {
-		return fmt.Errorf("resourceGroup for Azure backupstoragelocation config cannot be empty")
+		return newFieldError(field.Required(veleroPath.Child("config").Key(ResourceGroup), "resourceGroup for Azure backupstoragelocation config cannot be empty"))
 	}
 
 	if len(bslSpec.Config[StorageAccount]) == 0 {
-		return fmt.Errorf("storageAccount for Azure backupstoragelocation config cannot be empty")
+		return newFieldError(field.Required(veleroPath.Child("config").Key(StorageAccount), "storageAccount for Azure backupstoragelocation config cannot be empty"))
 	}
 
 	if len(bslSpec.StorageType.ObjectStorage.Prefix) == 0 && r.dpa.BackupImages() {
-		return fmt.Errorf("prefix for Azure backupstoragelocation object storage cannot be empty. it is required for backing up images")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage", "prefix"), "prefix for Azure backupstoragelocation object storage cannot be empty. it is required for backing up images"))
 	}
 
 	return nil
 }
 
-func (r *DataProtectionApplicationReconciler) validateGCPBackupStorageLocation(bslSpec velerov1.BackupStorageLocationSpec) error {
+func (r *DataProtectionApplicationReconciler) validateGCPBackupStorageLocation(bslSpec velerov1.BackupStorageLocationSpec, veleroPath *field.Path) error {
 	// validate provider plugin and secret
 	err := r.validateProviderPluginAndSecret(bslSpec)
 	if err != nil {
@@ -348,14 +363,14 @@ func (r *DataProtectionApplicationReconciler) validateGCPBackupStorageLocation(b
 
 	// check for bsl non-optional bsl configs and object storage
 	if bslSpec.ObjectStorage == nil {
-		return fmt.Errorf("object storage configuration for GCP backupstoragelocation cannot be nil")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage"), "object storage configuration for GCP backupstoragelocation cannot be nil"))
 	}
 
 	if len(bslSpec.ObjectStorage.Bucket) == 0 {
-		return fmt.Errorf("bucket name for GCP backupstoragelocation cannot be empty")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage", "bucket"), "bucket name for GCP backupstoragelocation cannot be empty"))
 	}
 	if len(bslSpec.StorageType.ObjectStorage.Prefix) == 0 && r.dpa.BackupImages() {
-		return fmt.Errorf("prefix for GCP backupstoragelocation object storage cannot be empty. it is required for backing up images")
+		return newFieldError(field.Required(veleroPath.Child("objectStorage", "prefix"), "prefix for GCP backupstoragelocation object storage cannot be empty. it is required for backing up images"))
 	}
 
 	return nil
@@ -379,13 +394,17 @@ func (r *DataProtectionApplicationReconciler) validateProviderPluginAndSecret(bs
 		r.Log.Info(fmt.Sprintf("%s backupstoragelocation is configured but velero plugin for %s is not present", bslSpec.Provider, bslSpec.Provider))
 		//TODO: set warning condition on Velero CR
 	}
+	// the credentials file of a SecretProviderClass is only mounted in the Velero and NodeAgent pods
+	if credentials.IsSecretProviderClassFilePath(bslSpec.Config[CredentialsFileKey]) {
+		return nil
+	}
 	secretName, _, _ := r.getSecretNameAndKey(bslSpec.Config, bslSpec.Credential, oadpv1alpha1.DefaultPlugin(bslSpec.Provider))
 
 	_, err := r.getProviderSecret(secretName)
 
 	if err != nil {
 		r.Log.Info(fmt.Sprintf("error validating %s provider secret:  %s/%s", bslSpec.Provider, r.NamespacedName.Namespace, secretName))
-		return err
+		return r.resourceCheck(err)
 	}
 	return nil
 }
@@ -460,6 +479,19 @@ func (r *DataProtectionApplicationReconciler) ensureSecretDataExists(bsl *oadpv1
 				awsProfile = value
 			}
 		}
+	} else if bsl.SecretProviderClass != nil {
+		// the credentials file of a SecretProviderClass is only read by the operator to back up images
+		if !r.dpa.BackupImages() {
+			return nil
+		}
+		secret, secretKey, err := credentials.GetSecretProviderClassFileSecret(credentials.SecretProviderClassFilePath(bsl.SecretProviderClass))
+		if err != nil {
+			return err
+		}
+		if value, exists := bsl.Velero.Config[Profile]; exists {
+			awsProfile = value
+		}
+		return r.parseProviderSecret(bsl.Velero.Provider, secret, secretKey, awsProfile)
 	} else if bsl.Velero != nil {
 		secretName, secretKey, err = r.getSecretNameAndKey(bsl.Velero.Config, bsl.Velero.Credential, oadpv1alpha1.DefaultPlugin(bsl.Velero.Provider))
 		if err != nil {
@@ -482,21 +514,26 @@ func (r *DataProtectionApplicationReconciler) ensureSecretDataExists(bsl *oadpv1
 	if err != nil {
 		return err
 	}
-	// Only parse secrets when backupImages is true
-	if !r.dpa.BackupImages() {
+	// Only parse secrets when backupImages is true, or when Velero reads the mounted secrets of the locations as is
+	if !r.dpa.BackupImages() && !r.dpa.MountLocationCredentials() {
 		return nil
 	}
+	return r.parseProviderSecret(provider, secret, secretKey, awsProfile)
+}
+
+// parseProviderSecret parses the credentials of a provider secret, the data of a secret or the credentials file of a SecretProviderClass
+func (r *DataProtectionApplicationReconciler) parseProviderSecret(provider string, secret corev1.Secret, secretKey string, awsProfile string) error {
 	// Parse the secret based on provider type
 	switch {
 	case provider == AWSProvider || strings.Contains(provider, "aws"):
-		_, _, err = r.parseAWSSecret(secret, secretKey, awsProfile)
+		_, err := r.parseAWSSecret(secret, secretKey, awsProfile)
 		if err != nil {
-			return fmt.Errorf("error parsing AWS secret %s: %v", secretName, err)
+			return fmt.Errorf("error parsing AWS secret %s: %v", secret.Name, err)
 		}
 	case provider == AzureProvider || strings.Contains(provider, "azure"):
-		_, err = r.parseAzureSecret(secret, secretKey)
+		_, err := r.parseAzureSecret(secret, secretKey)
 		if err != nil {
-			return fmt.Errorf("error parsing Azure secret %s: %v", secretName, err)
+			return fmt.Errorf("error parsing Azure secret %s: %v", secret.Name, err)
 		}
 	}
 
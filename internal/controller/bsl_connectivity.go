@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/cloudprovider"
+	"github.com/openshift/oadp-operator/pkg/credentials"
+	"github.com/openshift/oadp-operator/pkg/storage/aws"
+	"github.com/openshift/oadp-operator/pkg/utils"
+)
+
+const (
+	// defaultConnectivityCheckInterval is how long a passed connectivity check of a backup location is valid
+	defaultConnectivityCheckInterval = time.Hour
+	// connectivityCheckTimeout is how long the requests of the connectivity check of a backup location may take
+	connectivityCheckTimeout = 30 * time.Second
+)
+
+// CheckBackupLocationConnectivity checks the credentials of each backup location can list, write and delete objects
+// under its prefix, so a misconfigured location fails the reconcile with its cause before its BSL is created,
+// instead of Velero reporting the BSL Unavailable.
+func (r *DataProtectionApplicationReconciler) CheckBackupLocationConnectivity(log logr.Logger) (bool, error) {
+	check := r.dpa.Spec.BackupLocationConnectivityCheck
+	if check == nil || !check.Enable {
+		r.dpa.Status.BackupLocationConnectivity = nil
+		return true, nil
+	}
+	// a plan must not write to the buckets
+	if r.isPlanning() {
+		return true, nil
+	}
+	interval := defaultConnectivityCheckInterval
+	if check.Interval != nil && check.Interval.Duration > 0 {
+		interval = check.Interval.Duration
+	}
+
+	previous := map[string]oadpv1alpha1.BackupLocationConnectivityStatus{}
+	for _, status := range r.dpa.Status.BackupLocationConnectivity {
+		previous[status.Name] = status
+	}
+	statuses := make([]oadpv1alpha1.BackupLocationConnectivityStatus, 0, len(r.dpa.Spec.BackupLocations))
+	var errs []error
+	for i, location := range r.dpa.Spec.BackupLocations {
+		name := getBackupStorageLocationName(r.NamespacedName.Name, i, location)
+		status := r.checkBackupLocationConnectivity(log, name, location, previous[name], interval)
+		statuses = append(statuses, status)
+		switch status.Result {
+		case oadpv1alpha1.BackupLocationConnectivityFailed:
+			errs = append(errs, fmt.Errorf("backup location %s: %s", name, status.Message))
+		case oadpv1alpha1.BackupLocationConnectivityPassed:
+			recheckAfter := time.Until(status.LastCheckTime.Add(interval))
+			if r.connectivityRecheckAfter == 0 || recheckAfter < r.connectivityRecheckAfter {
+				r.connectivityRecheckAfter = recheckAfter
+			}
+		}
+	}
+	r.dpa.Status.BackupLocationConnectivity = statuses
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	return true, nil
+}
+
+// checkBackupLocationConnectivity returns the connectivity of a backup location, the previous result while it is valid
+func (r *DataProtectionApplicationReconciler) checkBackupLocationConnectivity(log logr.Logger, name string, location oadpv1alpha1.BackupLocation, previous oadpv1alpha1.BackupLocationConnectivityStatus, interval time.Duration) oadpv1alpha1.BackupLocationConnectivityStatus {
+	status := oadpv1alpha1.BackupLocationConnectivityStatus{Name: name, LastCheckTime: metav1.Now()}
+	done := func(result oadpv1alpha1.BackupLocationConnectivityResult, message string) oadpv1alpha1.BackupLocationConnectivityStatus {
+		status.Result, status.Message = result, message
+		return status
+	}
+	failed := func(reason oadpv1alpha1.BackupLocationConnectivityReason, message string) oadpv1alpha1.BackupLocationConnectivityStatus {
+		status.Reason = reason
+		return done(oadpv1alpha1.BackupLocationConnectivityFailed, message)
+	}
+
+	if r.dpa.Spec.Configuration.Velero.HasFeatureFlag("no-secret") {
+		return done(oadpv1alpha1.BackupLocationConnectivitySkipped, "credentials are not checked with the no-secret feature flag")
+	}
+	bslSpec, err := r.backupLocationStorageSpec(location)
+	if err != nil {
+		return failed(oadpv1alpha1.BackupLocationConnectivityReasonError, err.Error())
+	}
+	var secret corev1.Secret
+	var secretName, secretKey string
+	if credentialsFile := bslSpec.Config[CredentialsFileKey]; credentials.IsSecretProviderClassFilePath(credentialsFile) {
+		// the SecretProviderClass is mounted in the operator pod at the discretion of the user
+		secret, secretKey, err = credentials.GetSecretProviderClassFileSecret(credentialsFile)
+		if err != nil {
+			return done(oadpv1alpha1.BackupLocationConnectivitySkipped, err.Error())
+		}
+		secretName = secret.Name
+	} else {
+		if location.CloudStorage != nil {
+			secretName, secretKey, err = r.getSecretNameAndKeyFromCloudStorage(location.CloudStorage)
+		} else {
+			secretName, secretKey, err = r.getSecretNameAndKey(bslSpec.Config, bslSpec.Credential, oadpv1alpha1.DefaultPlugin(bslSpec.Provider))
+		}
+		if err != nil {
+			return failed(oadpv1alpha1.BackupLocationConnectivityReasonError, err.Error())
+		}
+		secret, err = r.getProviderSecret(secretName)
+		if err != nil {
+			return failed(oadpv1alpha1.BackupLocationConnectivityReasonError, fmt.Sprintf("unable to get credentials secret %s: %v", secretName, err))
+		}
+	}
+
+	status.Hash = connectivityCheckHash(bslSpec, secret, secretKey)
+	if previous.Hash == status.Hash {
+		switch previous.Result {
+		case oadpv1alpha1.BackupLocationConnectivitySkipped:
+			return previous
+		case oadpv1alpha1.BackupLocationConnectivityPassed:
+			if time.Since(previous.LastCheckTime.Time) < interval {
+				return previous
+			}
+		}
+	}
+
+	if bslSpec.Provider != AWSProvider && bslSpec.Provider != "velero.io/aws" {
+		return done(oadpv1alpha1.BackupLocationConnectivitySkipped, fmt.Sprintf("connectivity check of %s backup locations is not supported", bslSpec.Provider))
+	}
+	profile := "default"
+	if value, ok := bslSpec.Config[Profile]; ok {
+		profile = value
+	}
+	creds, err := utils.ResolveAWSCredentials(secret.Data[secretKey], profile)
+	if err != nil {
+		return failed(oadpv1alpha1.BackupLocationConnectivityReasonError, fmt.Sprintf("error parsing AWS secret %s: %v", secretName, err))
+	}
+	// only keys, and the roles assumed with them, are usable from the operator pod
+	if creds.Source != utils.AWSCredentialsSourceStatic {
+		return done(oadpv1alpha1.BackupLocationConnectivitySkipped, fmt.Sprintf("connectivity check of %s credentials is not supported", creds.Source))
+	}
+
+	bucket := bslSpec.ObjectStorage.Bucket
+	provider, err := newAWSLocationProvider(bslSpec, creds)
+	if err != nil {
+		return failed(oadpv1alpha1.BackupLocationConnectivityReasonError, err.Error())
+	}
+
+	log.Info("checking backup location connectivity", "backupLocation", name, "bucket", bucket)
+	ctx, cancel := context.WithTimeout(r.Context, connectivityCheckTimeout)
+	defer cancel()
+	if err := provider.CheckAccess(ctx, bucket, bslSpec.ObjectStorage.Prefix); err != nil {
+		r.EventRecorder.Event(r.dpa, corev1.EventTypeWarning, "BackupLocationConnectivityFailed", fmt.Sprintf("backup location %s: %v", name, err))
+		reason := oadpv1alpha1.BackupLocationConnectivityReasonError
+		var accessErr *cloudprovider.AccessCheckError
+		if errors.As(err, &accessErr) {
+			reason = accessErr.Reason
+		}
+		return failed(reason, err.Error())
+	}
+	status.Result = oadpv1alpha1.BackupLocationConnectivityPassed
+	return status
+}
+
+// newAWSLocationProvider returns an AWSProvider for the bucket of an aws location with the resolved keys of its
+// credentials, discovering the region of the bucket when the config has none
+func newAWSLocationProvider(bslSpec *velerov1.BackupStorageLocationSpec, creds utils.AWSProfileCredentials) (*cloudprovider.AWSProvider, error) {
+	bucket := bslSpec.ObjectStorage.Bucket
+	region := bslSpec.Config[Region]
+	if region == "" {
+		var err error
+		region, err = aws.GetBucketRegion(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("region of bucket %s not discoverable, set the region in the backup location config: %v", bucket, err)
+		}
+	}
+	return cloudprovider.NewAWSProviderWithOptions(region, bslSpec.Config[S3URL], creds.AccessKeyID, creds.SecretAccessKey, cloudprovider.AWSProviderOptions{
+		ForcePathStyle:        bslSpec.Config[S3ForcePathStyle] == "true",
+		CACert:                bslSpec.ObjectStorage.CACert,
+		InsecureSkipTLSVerify: bslSpec.Config[InsecureSkipTLSVerify] == "true",
+		SessionToken:          creds.SessionToken,
+		AssumeRoles:           creds.AssumeRoles,
+	})
+}
+
+// backupLocationStorageSpec returns the provider, object storage and config of the BSL of a backup location
+func (r *DataProtectionApplicationReconciler) backupLocationStorageSpec(location oadpv1alpha1.BackupLocation) (*velerov1.BackupStorageLocationSpec, error) {
+	if location.Velero != nil {
+		if location.Velero.ObjectStorage == nil {
+			return nil, fmt.Errorf("object storage configuration of the backup location cannot be nil")
+		}
+		spec := backupLocationVeleroSpec(location, r.dpa.MountLocationCredentials())
+		return &spec, nil
+	}
+
+	bucket := &oadpv1alpha1.CloudStorage{}
+	if err := r.Get(r.Context, client.ObjectKey{Namespace: r.dpa.Namespace, Name: location.CloudStorage.CloudStorageRef.Name}, bucket); err != nil {
+		return nil, err
+	}
+	spec := &velerov1.BackupStorageLocationSpec{
+		Provider: strings.ToLower(string(bucket.Spec.Provider)),
+		Config:   map[string]string{},
+		StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+			Bucket: bucket.Spec.Name,
+			Prefix: location.CloudStorage.Prefix,
+			CACert: location.CloudStorage.CACert,
+		}},
+		Credential: location.CloudStorage.Credential,
+	}
+	if bucket.Spec.Provider == oadpv1alpha1.AWSBucketProvider {
+		spec.Provider = AWSProvider
+	}
+	for key, value := range location.CloudStorage.Config {
+		spec.Config[key] = value
+	}
+	if spec.Config[Region] == "" && bucket.Spec.Region != "" {
+		spec.Config[Region] = bucket.Spec.Region
+	}
+	return spec, nil
+}
+
+// connectivityCheckHash identifies the location and the credentials of a connectivity check
+func connectivityCheckHash(bslSpec *velerov1.BackupStorageLocationSpec, secret corev1.Secret, secretKey string) string {
+	hash := sha256.New()
+	// encoding/json sorts map keys, so the hash of the config is stable
+	spec, _ := json.Marshal(bslSpec)
+	hash.Write(spec)
+	hash.Write([]byte(secret.Name + "/" + secretKey))
+	hash.Write(secret.Data[secretKey])
+	return hex.EncodeToString(hash.Sum(nil))
+}
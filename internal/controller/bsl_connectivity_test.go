@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// s3Stub answers the requests of the connectivity check, failing the requests of failMethod with failStatus and failBody
+type s3Stub struct {
+	requests   []string
+	failMethod string
+	failStatus int
+	failBody   string
+	header     map[string]string
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.requests = append(s.requests, req.Method+" "+req.URL.Path)
+	if req.Method == s.failMethod {
+		for key, value := range s.header {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(s.failStatus)
+		_, _ = w.Write([]byte(s.failBody))
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		_, _ = w.Write([]byte(`<ListBucketResult><Name>bucket</Name></ListBucketResult>`))
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newConnectivityTestDPA(s3URL string, caCert []byte) *oadpv1alpha1.DataProtectionApplication {
+	return &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{Velero: &oadpv1alpha1.VeleroConfig{}},
+			BackupLocations: []oadpv1alpha1.BackupLocation{{
+				Name: "aws",
+				Velero: &velerov1.BackupStorageLocationSpec{
+					Provider: "aws",
+					Config: map[string]string{
+						Region:           "us-east-1",
+						S3URL:            s3URL,
+						S3ForcePathStyle: "true",
+					},
+					Credential: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "cloud-credentials"},
+						Key:                  "cloud",
+					},
+					StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+						Bucket: "bucket",
+						Prefix: "velero",
+						CACert: caCert,
+					}},
+				},
+			}},
+			BackupLocationConnectivityCheck: &oadpv1alpha1.BackupLocationConnectivityCheck{Enable: true},
+		},
+	}
+}
+
+func newConnectivityTestSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials", Namespace: testNamespaceName},
+		Data:       map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=access\naws_secret_access_key=secret\n")},
+	}
+}
+
+func TestDPAReconciler_CheckBackupLocationConnectivity(t *testing.T) {
+	tests := []struct {
+		name        string
+		stub        s3Stub
+		tls         bool
+		trustCA     bool
+		modify      func(dpa *oadpv1alpha1.DataProtectionApplication)
+		wantResult  oadpv1alpha1.BackupLocationConnectivityResult
+		wantReason  oadpv1alpha1.BackupLocationConnectivityReason
+		wantMessage string
+		wantCalls   []string
+	}{
+		{
+			name:       "list, put and delete succeed",
+			wantResult: oadpv1alpha1.BackupLocationConnectivityPassed,
+			wantCalls:  []string{"GET /bucket", "PUT /bucket/velero/oadp-connectivity-check-", "DELETE /bucket/velero/oadp-connectivity-check-"},
+		},
+		{
+			name: "access denied on PutObject",
+			stub: s3Stub{
+				failMethod: http.MethodPut,
+				failStatus: http.StatusForbidden,
+				failBody:   `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`,
+			},
+			wantResult:  oadpv1alpha1.BackupLocationConnectivityFailed,
+			wantReason:  oadpv1alpha1.BackupLocationConnectivityReasonAccessDenied,
+			wantMessage: "PutObject on s3://bucket/velero failed, access denied, the credentials need the s3:PutObject permission",
+			wantCalls:   []string{"GET /bucket", "PUT /bucket/velero/oadp-connectivity-check-"},
+		},
+		{
+			name: "bucket in another region",
+			stub: s3Stub{
+				failMethod: http.MethodGet,
+				failStatus: http.StatusMovedPermanently,
+				header:     map[string]string{"x-amz-bucket-region": "eu-west-1"},
+			},
+			wantResult:  oadpv1alpha1.BackupLocationConnectivityFailed,
+			wantReason:  oadpv1alpha1.BackupLocationConnectivityReasonWrongRegion,
+			wantMessage: "ListObjectsV2 on s3://bucket/velero failed, the bucket is in another region",
+			wantCalls:   []string{"GET /bucket"},
+		},
+		{
+			name:        "certificate of the endpoint not trusted",
+			tls:         true,
+			wantResult:  oadpv1alpha1.BackupLocationConnectivityFailed,
+			wantReason:  oadpv1alpha1.BackupLocationConnectivityReasonUntrustedCertificate,
+			wantMessage: "the certificate of the endpoint is not trusted",
+		},
+		{
+			name:       "certificate of the endpoint trusted with caCert",
+			tls:        true,
+			trustCA:    true,
+			wantResult: oadpv1alpha1.BackupLocationConnectivityPassed,
+			wantCalls:  []string{"GET /bucket", "PUT /bucket/velero/oadp-connectivity-check-", "DELETE /bucket/velero/oadp-connectivity-check-"},
+		},
+		{
+			name: "azure location skipped",
+			modify: func(dpa *oadpv1alpha1.DataProtectionApplication) {
+				dpa.Spec.BackupLocations[0].Velero.Provider = "azure"
+			},
+			wantResult:  oadpv1alpha1.BackupLocationConnectivitySkipped,
+			wantMessage: "connectivity check of azure backup locations is not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := tt.stub
+			var server *httptest.Server
+			if tt.tls {
+				server = httptest.NewTLSServer(&stub)
+			} else {
+				server = httptest.NewServer(&stub)
+			}
+			defer server.Close()
+			var caCert []byte
+			if tt.trustCA {
+				caCert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			}
+			dpa := newConnectivityTestDPA(server.URL, caCert)
+			if tt.modify != nil {
+				tt.modify(dpa)
+			}
+			fakeClient, err := getFakeClientFromObjects(dpa, newConnectivityTestSecret())
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			r := &DataProtectionApplicationReconciler{
+				Client:         fakeClient,
+				Scheme:         fakeClient.Scheme(),
+				Log:            logr.Discard(),
+				Context:        newContextForTest(),
+				NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+				EventRecorder:  record.NewFakeRecorder(10),
+				dpa:            dpa,
+			}
+
+			cont, err := r.CheckBackupLocationConnectivity(logr.Discard())
+			wantErr := tt.wantResult == oadpv1alpha1.BackupLocationConnectivityFailed
+			if cont == wantErr || (err != nil) != wantErr {
+				t.Fatalf("CheckBackupLocationConnectivity() = %v, %v, want error %v", cont, err, wantErr)
+			}
+			if len(dpa.Status.BackupLocationConnectivity) != 1 {
+				t.Fatalf("status.backupLocationConnectivity = %v, want one location", dpa.Status.BackupLocationConnectivity)
+			}
+			status := dpa.Status.BackupLocationConnectivity[0]
+			if status.Name != "aws" || status.Result != tt.wantResult || status.Reason != tt.wantReason {
+				t.Errorf("status = %s %s %s, want aws %s %s: %s", status.Name, status.Result, status.Reason, tt.wantResult, tt.wantReason, status.Message)
+			}
+			if !strings.Contains(status.Message, tt.wantMessage) {
+				t.Errorf("status message = %q, want it to contain %q", status.Message, tt.wantMessage)
+			}
+			if wantErr && (!strings.HasPrefix(err.Error(), "backup location aws: ") || !strings.Contains(err.Error(), tt.wantMessage)) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantMessage)
+			}
+			if tt.wantCalls != nil {
+				if len(stub.requests) != len(tt.wantCalls) {
+					t.Fatalf("requests = %v, want %v", stub.requests, tt.wantCalls)
+				}
+				for i, call := range tt.wantCalls {
+					if !strings.HasPrefix(stub.requests[i], call) {
+						t.Errorf("request %d = %s, want %s", i, stub.requests[i], call)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDPAReconciler_CheckBackupLocationConnectivityCache(t *testing.T) {
+	stub := &s3Stub{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+	dpa := newConnectivityTestDPA(server.URL, nil)
+	fakeClient, err := getFakeClientFromObjects(dpa, newConnectivityTestSecret())
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:         fakeClient,
+		Scheme:         fakeClient.Scheme(),
+		Log:            logr.Discard(),
+		Context:        newContextForTest(),
+		NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+		EventRecorder:  record.NewFakeRecorder(10),
+		dpa:            dpa,
+	}
+
+	if _, err := r.CheckBackupLocationConnectivity(logr.Discard()); err != nil {
+		t.Fatalf("CheckBackupLocationConnectivity() error = %v", err)
+	}
+	if len(stub.requests) != 3 {
+		t.Fatalf("requests = %v, want list, put and delete", stub.requests)
+	}
+	if r.connectivityRecheckAfter <= 0 || r.connectivityRecheckAfter > defaultConnectivityCheckInterval {
+		t.Errorf("connectivityRecheckAfter = %v, want the default interval", r.connectivityRecheckAfter)
+	}
+
+	// a passed check is valid for the interval
+	if _, err := r.CheckBackupLocationConnectivity(logr.Discard()); err != nil {
+		t.Fatalf("CheckBackupLocationConnectivity() error = %v", err)
+	}
+	if len(stub.requests) != 3 {
+		t.Errorf("requests = %v, want the location not checked again", stub.requests)
+	}
+
+	// a changed location is checked again
+	dpa.Spec.BackupLocations[0].Velero.ObjectStorage.Prefix = "oadp"
+	if _, err := r.CheckBackupLocationConnectivity(logr.Discard()); err != nil {
+		t.Fatalf("CheckBackupLocationConnectivity() error = %v", err)
+	}
+	if len(stub.requests) != 6 || !strings.HasPrefix(stub.requests[4], "PUT /bucket/oadp/") {
+		t.Errorf("requests = %v, want the location checked again", stub.requests)
+	}
+
+	// an expired check is checked again
+	dpa.Status.BackupLocationConnectivity[0].LastCheckTime = metav1.NewTime(time.Now().Add(-2 * defaultConnectivityCheckInterval))
+	if _, err := r.CheckBackupLocationConnectivity(logr.Discard()); err != nil {
+		t.Fatalf("CheckBackupLocationConnectivity() error = %v", err)
+	}
+	if len(stub.requests) != 9 {
+		t.Errorf("requests = %v, want the location checked again", stub.requests)
+	}
+
+	// disabling the check clears its status
+	dpa.Spec.BackupLocationConnectivityCheck.Enable = false
+	if _, err := r.CheckBackupLocationConnectivity(logr.Discard()); err != nil {
+		t.Fatalf("CheckBackupLocationConnectivity() error = %v", err)
+	}
+	if dpa.Status.BackupLocationConnectivity != nil {
+		t.Errorf("status.backupLocationConnectivity = %v, want none", dpa.Status.BackupLocationConnectivity)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	configv1 "github.com/openshift/api/config/v1"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	velerov2alpha1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v2alpha1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,6 +40,11 @@ func getSchemeForFakeClient() (*runtime.Scheme, error) {
 		return nil, err
 	}
 
+	err = velerov2alpha1.AddToScheme(scheme.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
 	err = configv1.AddToScheme((scheme.Scheme))
 	if err != nil {
 		return nil, err
@@ -55,6 +62,26 @@ func getFakeClientFromObjects(objs ...client.Object) (client.WithWatch, error) {
 	return fake.NewClientBuilder().WithScheme(schemeForFakeClient).WithObjects(objs...).Build(), nil
 }
 
+// getFakeClientWithStatusFromObjects returns a fake client updating the status of the objects with a status
+// subresource only through Status(), like the API server
+func getFakeClientWithStatusFromObjects(objs ...client.Object) (client.WithWatch, error) {
+	schemeForFakeClient, err := getSchemeForFakeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(schemeForFakeClient).
+		WithObjects(objs...).
+		WithStatusSubresource(
+			&oadpv1alpha1.DataProtectionApplication{},
+			&oadpv1alpha1.RestoreVerification{},
+			&velerov1.Restore{},
+			&batchv1.Job{},
+		).
+		Build(), nil
+}
+
 func TestDPAReconciler_ValidateBackupStorageLocations(t *testing.T) {
 	tests := []struct {
 		name    string
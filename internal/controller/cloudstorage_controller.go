@@ -26,6 +26,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,8 +35,10 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
 	bucketpkg "github.com/openshift/oadp-operator/pkg/bucket"
@@ -70,6 +73,9 @@ func (b CloudStorageReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	bucket := oadpv1alpha1.CloudStorage{}
 
 	if err := b.Client.Get(ctx, req.NamespacedName, &bucket); err != nil {
+		if errors.IsNotFound(err) {
+			deleteCloudStorageUsageMetrics(req.Namespace, req.Name)
+		}
 		logger.Error(err, "unable to fetch bucket CR")
 		return result, nil
 	}
@@ -111,6 +117,7 @@ func (b CloudStorageReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 			}
 			logger.Info("bucket deleted")
+			deleteCloudStorageUsageMetrics(bucket.Namespace, bucket.Name)
 			b.EventRecorder.Event(&bucket, corev1.EventTypeNormal, "BucketDeleted", fmt.Sprintf("bucket %v deleted", bucket.Spec.Name))
 
 			//Removing oadpFinalizerBucket from bucket.Finalizers
@@ -156,18 +163,103 @@ func (b CloudStorageReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 	}
 
+	if bucket.Spec.LifecycleRules != nil {
+		backupTTLs, err := b.backupTTLsForCloudStorage(ctx, bucket)
+		if err != nil {
+			logger.Error(err, "unable to list DataProtectionApplications using bucket")
+			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		}
+		condition := v1.Condition{
+			Type:               oadpv1alpha1.ConditionLifecycleRulesApplied,
+			Status:             v1.ConditionTrue,
+			Reason:             oadpv1alpha1.LifecycleRulesReasonApplied,
+			Message:            fmt.Sprintf("lifecycle rules applied to bucket %v", bucket.Spec.Name),
+			ObservedGeneration: bucket.Generation,
+		}
+		if err := bucketpkg.ValidateLifecycleRules(bucket.Spec.LifecycleRules, backupTTLs); err != nil {
+			logger.Info("invalid bucket lifecycle rules", "error", err.Error())
+			condition.Status = v1.ConditionFalse
+			condition.Reason = oadpv1alpha1.LifecycleRulesReasonInvalid
+			condition.Message = fmt.Sprintf("lifecycle rules not applied to bucket %v: %v", bucket.Spec.Name, err)
+			b.EventRecorder.Event(&bucket, corev1.EventTypeWarning, "InvalidLifecycleRules", condition.Message)
+		} else if err := clnt.PutLifecycleRules(); err != nil {
+			logger.Error(err, "unable to apply bucket lifecycle rules")
+			condition.Status = v1.ConditionFalse
+			condition.Reason = oadpv1alpha1.LifecycleRulesReasonApplyFailed
+			condition.Message = fmt.Sprintf("unable to apply lifecycle rules to bucket %v: %v", bucket.Spec.Name, err)
+			b.EventRecorder.Event(&bucket, corev1.EventTypeWarning, "UnableToApplyLifecycleRules", condition.Message)
+			meta.SetStatusCondition(&bucket.Status.Conditions, condition)
+			b.Client.Status().Update(ctx, &bucket)
+			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		}
+		meta.SetStatusCondition(&bucket.Status.Conditions, condition)
+	} else {
+		meta.RemoveStatusCondition(&bucket.Status.Conditions, oadpv1alpha1.ConditionLifecycleRulesApplied)
+	}
+
+	usageReportingPeriod := oadpv1alpha1.DefaultUsageReportingPeriod
+	if bucket.Spec.UsageReportingPeriod != nil {
+		usageReportingPeriod = bucket.Spec.UsageReportingPeriod.Duration
+	}
+	if usageReportingPeriod > 0 {
+		usage := bucket.Status.Usage
+		result.RequeueAfter = usageReportingPeriod
+		if usage == nil || usage.LastComputed == nil || time.Since(usage.LastComputed.Time) >= usageReportingPeriod {
+			usage, err = b.getUsage(ctx, bucket, clnt)
+			if err != nil {
+				logger.Error(err, "unable to compute bucket usage")
+				b.EventRecorder.Event(&bucket, corev1.EventTypeWarning, "UnableToComputeUsage", fmt.Sprintf("unable to compute usage of bucket %v: %v", bucket.Spec.Name, err))
+				// the stale usage is past its period, retry like the other failures
+				result.RequeueAfter = 1 * time.Minute
+			} else {
+				bucket.Status.Usage = usage
+			}
+		} else {
+			// a zero or negative RequeueAfter would stop the periodic reporting
+			result.RequeueAfter = max(usageReportingPeriod-time.Since(usage.LastComputed.Time), time.Second)
+		}
+	} else {
+		bucket.Status.Usage = nil
+	}
+	setCloudStorageUsageMetrics(&bucket)
+
 	// Update status with updated value
 	bucket.Status.LastSynced = &v1.Time{Time: time.Now()}
 	bucket.Status.Name = bucket.Spec.Name
 
 	b.Client.Status().Update(ctx, &bucket)
-	return ctrl.Result{}, nil
+	return result, nil
+}
+
+// getUsage computes the usage of the bucket, broken down by the prefixes
+// Velero uses under each backup location using the bucket
+func (b CloudStorageReconciler) getUsage(ctx context.Context, bucket oadpv1alpha1.CloudStorage, clnt bucketpkg.Client) (*oadpv1alpha1.CloudStorageUsage, error) {
+	dpas, err := b.dpasUsingCloudStorage(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	locationPrefixes := []string{}
+	for _, dpa := range dpas {
+		for _, bsl := range dpa.Spec.BackupLocations {
+			if bsl.CloudStorage != nil && bsl.CloudStorage.CloudStorageRef.Name == bucket.Name {
+				locationPrefixes = append(locationPrefixes, bsl.CloudStorage.Prefix)
+			}
+		}
+	}
+	usage, err := clnt.GetUsage(bucketpkg.UsagePrefixes(locationPrefixes))
+	if err != nil {
+		return nil, err
+	}
+	usage.LastComputed = &v1.Time{Time: time.Now()}
+	return usage, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (b *CloudStorageReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&oadpv1alpha1.CloudStorage{}).
+		// DPA backup TTLs are used to validate bucket lifecycle rules
+		Watches(&oadpv1alpha1.DataProtectionApplication{}, handler.EnqueueRequestsFromMapFunc(cloudStoragesForDPA)).
 		WithEventFilter(bucketPredicate()).
 		Complete(b)
 
@@ -193,6 +285,64 @@ func bucketPredicate() predicate.Predicate {
 	}
 }
 
+// cloudStoragesForDPA maps a DPA to the CloudStorages it uses as backup locations
+func cloudStoragesForDPA(ctx context.Context, obj client.Object) []reconcile.Request {
+	dpa, ok := obj.(*oadpv1alpha1.DataProtectionApplication)
+	if !ok {
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, bsl := range dpa.Spec.BackupLocations {
+		if bsl.CloudStorage != nil && bsl.CloudStorage.CloudStorageRef.Name != "" {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      bsl.CloudStorage.CloudStorageRef.Name,
+				Namespace: dpa.Namespace,
+			}})
+		}
+	}
+	return requests
+}
+
+// dpasUsingCloudStorage returns the DPAs which use the CloudStorage as a backup location
+func (b CloudStorageReconciler) dpasUsingCloudStorage(ctx context.Context, bucket oadpv1alpha1.CloudStorage) ([]oadpv1alpha1.DataProtectionApplication, error) {
+	dpaList := &oadpv1alpha1.DataProtectionApplicationList{}
+	if err := b.Client.List(ctx, dpaList, client.InNamespace(bucket.Namespace)); err != nil {
+		return nil, err
+	}
+	dpas := []oadpv1alpha1.DataProtectionApplication{}
+	for _, dpa := range dpaList.Items {
+		for _, request := range cloudStoragesForDPA(ctx, &dpa) {
+			if request.Name == bucket.Name {
+				dpas = append(dpas, dpa)
+				break
+			}
+		}
+	}
+	return dpas, nil
+}
+
+// backupTTLsForCloudStorage returns the backup TTLs configured in the DPAs
+// which use the CloudStorage as a backup location
+func (b CloudStorageReconciler) backupTTLsForCloudStorage(ctx context.Context, bucket oadpv1alpha1.CloudStorage) ([]time.Duration, error) {
+	dpas, err := b.dpasUsingCloudStorage(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	backupTTLs := []time.Duration{}
+	for _, dpa := range dpas {
+		backupTTL := bucketpkg.DefaultBackupTTL
+		if dpa.Spec.Configuration != nil && dpa.Spec.Configuration.Velero != nil &&
+			dpa.Spec.Configuration.Velero.Args != nil && dpa.Spec.Configuration.Velero.Args.DefaultBackupTTL != nil {
+			backupTTL = *dpa.Spec.Configuration.Velero.Args.DefaultBackupTTL
+		}
+		backupTTLs = append(backupTTLs, backupTTL)
+		if dpa.Spec.NonAdmin != nil && dpa.Spec.NonAdmin.EnforceBackupSpec != nil && dpa.Spec.NonAdmin.EnforceBackupSpec.TTL.Duration != 0 {
+			backupTTLs = append(backupTTLs, dpa.Spec.NonAdmin.EnforceBackupSpec.TTL.Duration)
+		}
+	}
+	return backupTTLs, nil
+}
+
 func containFinalizer(finalizers []string, f string) bool {
 	for _, finalizer := range finalizers {
 		if finalizer == f {
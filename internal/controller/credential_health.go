@@ -0,0 +1,374 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+	"github.com/openshift/oadp-operator/pkg/credentials"
+	"github.com/openshift/oadp-operator/pkg/utils"
+)
+
+const (
+	// credentialExpiryWarningPeriod is how long before they expire credentials are reported as expiring
+	credentialExpiryWarningPeriod = 7 * 24 * time.Hour
+	// gcpKeyExpirationCacheTTL is how long the expiration of a GCP service account key, fetched from Google, is cached
+	gcpKeyExpirationCacheTTL = 12 * time.Hour
+	// gcpKeyExpirationTimeout is how long fetching the expiration of a GCP service account key may take
+	gcpKeyExpirationTimeout = 10 * time.Second
+)
+
+// credentialsReasonSeverity orders the reasons of the CredentialsHealthy condition, the most severe reason of the
+// credentials is the reason of the condition
+var credentialsReasonSeverity = map[string]int{
+	oadpv1alpha1.CredentialsReasonHealthy:                 0,
+	oadpv1alpha1.CredentialsReasonExpiring:                1,
+	oadpv1alpha1.CredentialsReasonInsufficientPermissions: 2,
+	oadpv1alpha1.CredentialsReasonExpired:                 3,
+	oadpv1alpha1.CredentialsReasonInvalid:                 4,
+}
+
+// gcpCertificatesClient fetches the certificates of GCP service account keys, without following redirects
+var gcpCertificatesClient = &http.Client{
+	Timeout: gcpKeyExpirationTimeout,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// gcpServiceAccountKeyExpiration returns when a GCP service account key expires, replaced in tests
+var gcpServiceAccountKeyExpiration = func(ctx context.Context, key credentials.GCPServiceAccountKey) (time.Time, error) {
+	return credentials.GCPServiceAccountKeyExpiration(ctx, gcpCertificatesClient, key)
+}
+
+// gcpKeyExpiration is the cached expiration of a GCP service account key
+type gcpKeyExpiration struct {
+	expiration time.Time
+	err        error
+	fetchTime  time.Time
+}
+
+// referencedCredential is a credentials file referenced by backup and snapshot locations
+type referencedCredential struct {
+	provider string
+	// secret and key of the credentials file, the SecretProviderClass and its file for a SecretProviderClass
+	secret string
+	key    string
+	// credentialsFile is the path of the file of a SecretProviderClass
+	credentialsFile string
+	// profile is the AWS profile of the locations
+	profile string
+	// backupLocations are the names of the BackupStorageLocations using the credentials
+	backupLocations []string
+}
+
+func (c referencedCredential) String() string {
+	if c.credentialsFile != "" {
+		return fmt.Sprintf("file %s of SecretProviderClass %s", c.key, c.secret)
+	}
+	return fmt.Sprintf("key %s of secret %s", c.key, c.secret)
+}
+
+// credentialHealth is the health of a credentials file
+type credentialHealth struct {
+	// reason is one of the reasons of the CredentialsHealthy condition, empty when the credentials could not be read
+	reason  string
+	message string
+	// expiration is when the credentials expire, zero when they do not expire or the provider does not expose it
+	expiration time.Time
+}
+
+func healthyCredential(message string) credentialHealth {
+	return credentialHealth{reason: oadpv1alpha1.CredentialsReasonHealthy, message: message}
+}
+
+func invalidCredential(format string, args ...interface{}) credentialHealth {
+	return credentialHealth{reason: oadpv1alpha1.CredentialsReasonInvalid, message: fmt.Sprintf(format, args...)}
+}
+
+// updateCredentialsHealthCondition checks the credentials referenced by the backup and snapshot locations, and sets the
+// CredentialsHealthy condition and the credential metrics. The condition is removed when no credentials are checked.
+func (r *DataProtectionApplicationReconciler) updateCredentialsHealthCondition() error {
+	dpa := r.dpa
+	deleteCredentialHealthMetrics(dpa.Namespace, dpa.Name)
+	if dpa.Spec.Configuration == nil || dpa.Spec.Configuration.Velero == nil || dpa.Spec.Configuration.Velero.HasFeatureFlag("no-secret") {
+		apimeta.RemoveStatusCondition(&dpa.Status.Conditions, oadpv1alpha1.ConditionCredentialsHealthy)
+		return nil
+	}
+
+	now := time.Now()
+	reason := oadpv1alpha1.CredentialsReasonHealthy
+	var problems []string
+	checked := 0
+	for _, cred := range r.locationCredentials() {
+		health, err := r.checkCredentialHealth(cred, now)
+		if err != nil {
+			return err
+		}
+		if health.reason == "" {
+			continue
+		}
+		checked++
+		setCredentialHealthMetrics(dpa.Namespace, dpa.Name, cred.secret, cred.key, health)
+		if health.reason != oadpv1alpha1.CredentialsReasonHealthy {
+			problems = append(problems, fmt.Sprintf("%s: %s", cred, health.message))
+			if credentialsReasonSeverity[health.reason] > credentialsReasonSeverity[reason] {
+				reason = health.reason
+			}
+		}
+		// check again when the credentials start expiring, and when they expire
+		if !health.expiration.IsZero() {
+			recheckAfter := health.expiration.Add(-credentialExpiryWarningPeriod).Sub(now)
+			if recheckAfter <= 0 {
+				recheckAfter = health.expiration.Sub(now)
+			}
+			if recheckAfter > 0 && (r.credentialsRecheckAfter == 0 || recheckAfter < r.credentialsRecheckAfter) {
+				r.credentialsRecheckAfter = recheckAfter
+			}
+		}
+	}
+	if checked == 0 {
+		apimeta.RemoveStatusCondition(&dpa.Status.Conditions, oadpv1alpha1.ConditionCredentialsHealthy)
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:    oadpv1alpha1.ConditionCredentialsHealthy,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: "Credentials of the backup and snapshot locations are healthy",
+	}
+	if len(problems) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = strings.Join(problems, "; ")
+	}
+	apimeta.SetStatusCondition(&dpa.Status.Conditions, condition)
+	return nil
+}
+
+// locationCredentials returns the credentials files referenced by the backup and snapshot locations, once for all
+// the locations using them
+func (r *DataProtectionApplicationReconciler) locationCredentials() []referencedCredential {
+	var creds []referencedCredential
+	index := map[string]int{}
+	add := func(cred referencedCredential, bslName string) {
+		id := strings.Join([]string{cred.provider, cred.secret, cred.key, cred.credentialsFile, cred.profile}, "/")
+		i, ok := index[id]
+		if !ok {
+			i = len(creds)
+			index[id] = i
+			creds = append(creds, cred)
+		}
+		if bslName != "" {
+			creds[i].backupLocations = append(creds[i].backupLocations, bslName)
+		}
+	}
+
+	for i, location := range r.dpa.Spec.BackupLocations {
+		// invalid locations fail the validation of the reconcile
+		spec, err := r.backupLocationStorageSpec(location)
+		if err != nil {
+			continue
+		}
+		if cred, ok := locationCredential(spec.Provider, spec.Config, spec.Credential); ok {
+			add(cred, getBackupStorageLocationName(r.NamespacedName.Name, i, location))
+		}
+	}
+	for _, location := range r.dpa.Spec.SnapshotLocations {
+		if location.Velero == nil {
+			continue
+		}
+		spec := snapshotLocationVeleroSpec(location, r.dpa.MountLocationCredentials())
+		if cred, ok := locationCredential(spec.Provider, spec.Config, spec.Credential); ok {
+			add(cred, "")
+		}
+	}
+	return creds
+}
+
+// locationCredential returns the credentials file of a location of a cloud provider
+func locationCredential(provider string, config map[string]string, credential *corev1.SecretKeySelector) (referencedCredential, bool) {
+	provider = strings.TrimPrefix(provider, veleroIOPrefix)
+	fields, ok := credentials.PluginSpecificFields[oadpv1alpha1.DefaultPlugin(provider)]
+	if !ok || !fields.IsCloudProvider {
+		return referencedCredential{}, false
+	}
+	cred := referencedCredential{provider: provider, secret: fields.SecretName, key: fields.PluginSecretKey}
+	if provider == AWSProvider {
+		cred.profile = "default"
+		if profile := config[Profile]; profile != "" {
+			cred.profile = profile
+		}
+	}
+	credentialsFile := config[CredentialsFileKey]
+	switch {
+	case credentials.IsSecretProviderClassFilePath(credentialsFile):
+		cred.credentialsFile = credentialsFile
+		cred.secret, cred.key, _ = strings.Cut(strings.TrimPrefix(credentialsFile, credentials.SecretProviderClassMountPath+"/"), "/")
+	case credentialsFile != "":
+		secretName, secretKey, err := credentials.GetSecretNameKeyFromCredentialsFileConfigString(credentialsFile)
+		if err != nil {
+			return referencedCredential{}, false
+		}
+		cred.secret, cred.key = secretName, secretKey
+	case credential != nil:
+		cred.secret, cred.key = credential.Name, credential.Key
+	}
+	return cred, true
+}
+
+// checkCredentialHealth parses a credentials file with the parser of its provider, and reports the failed connectivity
+// checks of the backup locations using it
+func (r *DataProtectionApplicationReconciler) checkCredentialHealth(cred referencedCredential, now time.Time) (credentialHealth, error) {
+	var secret corev1.Secret
+	if cred.credentialsFile != "" {
+		var err error
+		// the SecretProviderClass is mounted in the operator pod at the discretion of the user
+		secret, _, err = credentials.GetSecretProviderClassFileSecret(cred.credentialsFile)
+		if err != nil {
+			return credentialHealth{}, nil
+		}
+	} else {
+		err := r.Get(r.Context, types.NamespacedName{Namespace: r.NamespacedName.Namespace, Name: cred.secret}, &secret)
+		if k8serror.IsNotFound(err) {
+			return invalidCredential("secret %s does not exist", cred.secret), nil
+		}
+		if err != nil {
+			return credentialHealth{}, err
+		}
+		secret.Data = utils.ReplaceCarriageReturn(secret.Data)
+	}
+	if len(secret.Data[cred.key]) == 0 {
+		return invalidCredential("credentials are missing or empty"), nil
+	}
+
+	var health credentialHealth
+	switch cred.provider {
+	case AWSProvider:
+		health = awsCredentialHealth(secret, cred.key, cred.profile, now)
+	case GCPProvider:
+		health = r.gcpCredentialHealth(secret.Data[cred.key], now)
+	case AzureProvider:
+		health = r.azureCredentialHealth(secret, cred.key)
+	default:
+		health = healthyCredential("")
+	}
+	if health.reason == oadpv1alpha1.CredentialsReasonInvalid || health.reason == oadpv1alpha1.CredentialsReasonExpired {
+		return health, nil
+	}
+
+	// the identity of the credentials is only checked against the storage by the connectivity check
+	for _, status := range r.dpa.Status.BackupLocationConnectivity {
+		if status.Result != oadpv1alpha1.BackupLocationConnectivityFailed || !slices.Contains(cred.backupLocations, status.Name) {
+			continue
+		}
+		switch status.Reason {
+		case oadpv1alpha1.BackupLocationConnectivityReasonInvalidCredentials:
+			return invalidCredential("backup location %s: %s", status.Name, status.Message), nil
+		case oadpv1alpha1.BackupLocationConnectivityReasonAccessDenied:
+			health.reason = oadpv1alpha1.CredentialsReasonInsufficientPermissions
+			health.message = fmt.Sprintf("backup location %s: %s", status.Name, status.Message)
+		}
+	}
+	return health, nil
+}
+
+// expirationHealth returns the health of credentials expiring at expiration
+func expirationHealth(expiration, now time.Time) credentialHealth {
+	health := healthyCredential("")
+	health.expiration = expiration
+	switch {
+	case expiration.IsZero():
+	case !now.Before(expiration):
+		health.reason = oadpv1alpha1.CredentialsReasonExpired
+		health.message = fmt.Sprintf("credentials expired at %s", expiration.UTC().Format(time.RFC3339))
+	case expiration.Sub(now) < credentialExpiryWarningPeriod:
+		health.reason = oadpv1alpha1.CredentialsReasonExpiring
+		health.message = fmt.Sprintf("credentials expire at %s", expiration.UTC().Format(time.RFC3339))
+	}
+	return health
+}
+
+// awsCredentialHealth checks a profile of an AWS credentials file resolves to credentials, and reads the expiration
+// of temporary keys. Roles, SSO and credential processes renew their credentials and are healthy.
+func awsCredentialHealth(secret corev1.Secret, key, profile string, now time.Time) credentialHealth {
+	creds, err := utils.ResolveAWSCredentials(secret.Data[key], profile)
+	if err != nil {
+		return invalidCredential("%v", err)
+	}
+	if creds.Source != utils.AWSCredentialsSourceStatic || len(creds.AssumeRoles) > 0 || creds.Expiration.IsZero() {
+		return healthyCredential("")
+	}
+	return expirationHealth(creds.Expiration, now)
+}
+
+// gcpCredentialHealth checks a GCP credentials file is a service account key or external account credentials, and
+// gets the expiration of a service account key
+func (r *DataProtectionApplicationReconciler) gcpCredentialHealth(content []byte, now time.Time) credentialHealth {
+	key, isKey, err := credentials.GetGCPServiceAccountKey(content)
+	if err != nil {
+		return invalidCredential("%v", err)
+	}
+	// external account credentials are short-lived tokens renewed by the workload identity federation
+	if !isKey {
+		return healthyCredential("")
+	}
+	expiration, err := r.gcpKeyExpiration(key, now)
+	if errors.Is(err, credentials.ErrGCPServiceAccountKeyNotFound) {
+		return invalidCredential("%v", err)
+	}
+	if err != nil {
+		// the expiration is unknown, on disconnected clusters for example
+		r.Log.Info("unable to get the expiration of a GCP service account key", "serviceAccount", key.ClientEmail, "error", err.Error())
+		return healthyCredential("")
+	}
+	return expirationHealth(expiration, now)
+}
+
+// gcpKeyExpiration returns the expiration of a GCP service account key, cached by key id
+func (r *DataProtectionApplicationReconciler) gcpKeyExpiration(key credentials.GCPServiceAccountKey, now time.Time) (time.Time, error) {
+	if cached, ok := r.gcpKeyExpirations[key.PrivateKeyID]; ok && now.Sub(cached.fetchTime) < gcpKeyExpirationCacheTTL {
+		return cached.expiration, cached.err
+	}
+	expiration, err := gcpServiceAccountKeyExpiration(r.Context, key)
+	if r.gcpKeyExpirations == nil {
+		r.gcpKeyExpirations = map[string]gcpKeyExpiration{}
+	}
+	r.gcpKeyExpirations[key.PrivateKeyID] = gcpKeyExpiration{expiration: expiration, err: err, fetchTime: now}
+	return expiration, err
+}
+
+// azureCredentialHealth checks an Azure credentials file has a storage account key or the identity of a service principal.
+// Azure does not expose the expiration of client secrets in the credentials file.
+func (r *DataProtectionApplicationReconciler) azureCredentialHealth(secret corev1.Secret, key string) credentialHealth {
+	azcreds, err := r.parseAzureSecret(secret, key)
+	if err != nil {
+		return invalidCredential("%v", err)
+	}
+	if azcreds.strorageAccountKey != "" {
+		return healthyCredential("")
+	}
+	// Azure Workload Identity sets the identity as env vars of the Velero pod
+	if _, _, _, ok := common.AzureWorkloadIdentity(); ok {
+		return healthyCredential("")
+	}
+	if azcreds.clientID == "" || azcreds.tenantID == "" {
+		return invalidCredential("credentials need AZURE_STORAGE_ACCOUNT_ACCESS_KEY, or AZURE_CLIENT_ID and AZURE_TENANT_ID")
+	}
+	if azcreds.clientSecret == "" && !strings.Contains(string(secret.Data[key]), "AZURE_CLIENT_CERTIFICATE_PATH") {
+		return invalidCredential("credentials of client %s need AZURE_CLIENT_SECRET or AZURE_CLIENT_CERTIFICATE_PATH", azcreds.clientID)
+	}
+	return healthyCredential("")
+}
@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/credentials"
+)
+
+func TestAWSCredentialHealth(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		content        string
+		profile        string
+		wantReason     string
+		wantExpiration bool
+	}{
+		{
+			name:       "static keys",
+			content:    "[default]\naws_access_key_id=access\naws_secret_access_key=secret\n",
+			profile:    "default",
+			wantReason: oadpv1alpha1.CredentialsReasonHealthy,
+		},
+		{
+			name:       "missing profile",
+			content:    "[default]\naws_access_key_id=access\naws_secret_access_key=secret\n",
+			profile:    "backups",
+			wantReason: oadpv1alpha1.CredentialsReasonInvalid,
+		},
+		{
+			name:       "missing secret key",
+			content:    "[default]\naws_access_key_id=access\n",
+			profile:    "default",
+			wantReason: oadpv1alpha1.CredentialsReasonInvalid,
+		},
+		{
+			name:       "STS web identity",
+			content:    "[default]\nrole_arn = arn:aws:iam::123456789012:role/velero\nweb_identity_token_file = /var/run/secrets/openshift/serviceaccount/token\n",
+			profile:    "default",
+			wantReason: oadpv1alpha1.CredentialsReasonHealthy,
+		},
+		{
+			name: "temporary keys",
+			content: "[default]\naws_session_token=token\nexpiration=2025-07-01T00:00:00Z\n" +
+				"aws_access_key_id=access\naws_secret_access_key=secret\n",
+			profile:        "default",
+			wantReason:     oadpv1alpha1.CredentialsReasonHealthy,
+			wantExpiration: true,
+		},
+		{
+			name: "temporary keys of another profile expiring",
+			content: "[default]\naws_access_key_id=access\naws_secret_access_key=secret\n" +
+				"[backups]\naws_access_key_id=access\naws_secret_access_key=secret\naws_session_token=token\nx_security_token_expires=2025-06-03T00:00:00Z\n",
+			profile:        "backups",
+			wantReason:     oadpv1alpha1.CredentialsReasonExpiring,
+			wantExpiration: true,
+		},
+		{
+			name:           "expired temporary keys",
+			content:        "[default]\naws_access_key_id=access\naws_secret_access_key=secret\naws_session_token=token\naws_expiration=2025-05-01T00:00:00Z\n",
+			profile:        "default",
+			wantReason:     oadpv1alpha1.CredentialsReasonExpired,
+			wantExpiration: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := corev1.Secret{Data: map[string][]byte{"cloud": []byte(tt.content)}}
+			health := awsCredentialHealth(secret, "cloud", tt.profile, now)
+			if health.reason != tt.wantReason {
+				t.Errorf("awsCredentialHealth() reason = %s, want %s: %s", health.reason, tt.wantReason, health.message)
+			}
+			if health.expiration.IsZero() == tt.wantExpiration {
+				t.Errorf("awsCredentialHealth() expiration = %s, want an expiration %v", health.expiration, tt.wantExpiration)
+			}
+		})
+	}
+}
+
+func TestDPAReconciler_UpdateCredentialsHealthCondition(t *testing.T) {
+	gcpKey := func(keyID string) string {
+		return fmt.Sprintf(`{"type": "service_account", "client_email": "velero@project.iam.gserviceaccount.com", "private_key": "key", "private_key_id": %q, "client_x509_cert_url": "https://www.googleapis.com/robot/v1/metadata/x509/velero"}`, keyID)
+	}
+	defer func(fetch func(context.Context, credentials.GCPServiceAccountKey) (time.Time, error)) {
+		gcpServiceAccountKeyExpiration = fetch
+	}(gcpServiceAccountKeyExpiration)
+	gcpServiceAccountKeyExpiration = func(_ context.Context, key credentials.GCPServiceAccountKey) (time.Time, error) {
+		switch key.PrivateKeyID {
+		case "expiring":
+			return time.Now().Add(48 * time.Hour), nil
+		case "deleted":
+			return time.Time{}, credentials.ErrGCPServiceAccountKeyNotFound
+		}
+		return time.Time{}, nil
+	}
+
+	awsLocation := oadpv1alpha1.BackupLocation{
+		Name: "aws",
+		Velero: &velerov1.BackupStorageLocationSpec{
+			Provider: AWSProvider,
+			StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+				Bucket: "bucket",
+			}},
+		},
+	}
+	gcpLocation := oadpv1alpha1.BackupLocation{
+		Name: "gcp",
+		Velero: &velerov1.BackupStorageLocationSpec{
+			Provider: GCPProvider,
+			StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+				Bucket: "bucket",
+			}},
+		},
+	}
+	awsSecret := func(content string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials", Namespace: testNamespaceName},
+			Data:       map[string][]byte{"cloud": []byte(content)},
+		}
+	}
+	gcpSecret := func(content string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials-gcp", Namespace: testNamespaceName},
+			Data:       map[string][]byte{"cloud": []byte(content)},
+		}
+	}
+	tests := []struct {
+		name         string
+		locations    []oadpv1alpha1.BackupLocation
+		connectivity []oadpv1alpha1.BackupLocationConnectivityStatus
+		objects      []client.Object
+		wantReason   string
+		wantMessage  string
+		wantRecheck  bool
+	}{
+		{
+			name:      "no locations",
+			locations: nil,
+		},
+		{
+			name:       "healthy AWS keys",
+			locations:  []oadpv1alpha1.BackupLocation{awsLocation},
+			objects:    []client.Object{awsSecret("[default]\naws_access_key_id=access\naws_secret_access_key=secret\n")},
+			wantReason: oadpv1alpha1.CredentialsReasonHealthy,
+		},
+		{
+			name:        "missing secret",
+			locations:   []oadpv1alpha1.BackupLocation{awsLocation},
+			wantReason:  oadpv1alpha1.CredentialsReasonInvalid,
+			wantMessage: "key cloud of secret cloud-credentials: secret cloud-credentials does not exist",
+		},
+		{
+			name:      "access denied by the connectivity check",
+			locations: []oadpv1alpha1.BackupLocation{awsLocation},
+			connectivity: []oadpv1alpha1.BackupLocationConnectivityStatus{{
+				Name:    "aws",
+				Result:  oadpv1alpha1.BackupLocationConnectivityFailed,
+				Reason:  oadpv1alpha1.BackupLocationConnectivityReasonAccessDenied,
+				Message: "list objects on bucket/ failed, access denied, the credentials need the s3:ListBucket permission",
+			}},
+			objects:     []client.Object{awsSecret("[default]\naws_access_key_id=access\naws_secret_access_key=secret\n")},
+			wantReason:  oadpv1alpha1.CredentialsReasonInsufficientPermissions,
+			wantMessage: "s3:ListBucket",
+		},
+		{
+			name:        "expiring GCP key",
+			locations:   []oadpv1alpha1.BackupLocation{gcpLocation},
+			objects:     []client.Object{gcpSecret(gcpKey("expiring"))},
+			wantReason:  oadpv1alpha1.CredentialsReasonExpiring,
+			wantMessage: "key cloud of secret cloud-credentials-gcp: credentials expire at",
+			wantRecheck: true,
+		},
+		{
+			name:        "deleted GCP key is more severe than an expiring AWS key",
+			locations:   []oadpv1alpha1.BackupLocation{awsLocation, gcpLocation},
+			objects:     []client.Object{awsSecret(fmt.Sprintf("[default]\naws_access_key_id=access\naws_secret_access_key=secret\nexpiration=%s\n", time.Now().Add(time.Hour).UTC().Format(time.RFC3339))), gcpSecret(gcpKey("deleted"))},
+			wantReason:  oadpv1alpha1.CredentialsReasonInvalid,
+			wantMessage: "was deleted from the service account",
+			wantRecheck: true,
+		},
+		{
+			name:       "GCP external account",
+			locations:  []oadpv1alpha1.BackupLocation{gcpLocation},
+			objects:    []client.Object{gcpSecret(`{"type": "external_account"}`)},
+			wantReason: oadpv1alpha1.CredentialsReasonHealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpa := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration:   &oadpv1alpha1.ApplicationConfig{Velero: &oadpv1alpha1.VeleroConfig{}},
+					BackupLocations: tt.locations,
+				},
+				Status: oadpv1alpha1.DataProtectionApplicationStatus{BackupLocationConnectivity: tt.connectivity},
+			}
+			fakeClient, err := getFakeClientFromObjects(append(tt.objects, dpa)...)
+			if err != nil {
+				t.Fatalf("error creating fake client: %v", err)
+			}
+			r := &DataProtectionApplicationReconciler{
+				Client:         fakeClient,
+				Scheme:         fakeClient.Scheme(),
+				Log:            logr.Discard(),
+				Context:        newContextForTest(),
+				NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+				EventRecorder:  record.NewFakeRecorder(10),
+				dpa:            dpa,
+			}
+
+			if err := r.updateCredentialsHealthCondition(); err != nil {
+				t.Fatalf("updateCredentialsHealthCondition() error = %v", err)
+			}
+			condition := apimeta.FindStatusCondition(dpa.Status.Conditions, oadpv1alpha1.ConditionCredentialsHealthy)
+			if tt.wantReason == "" {
+				if condition != nil {
+					t.Errorf("updateCredentialsHealthCondition() condition = %v, want none", condition)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("updateCredentialsHealthCondition() did not set the %s condition", oadpv1alpha1.ConditionCredentialsHealthy)
+			}
+			wantStatus := metav1.ConditionFalse
+			if tt.wantReason == oadpv1alpha1.CredentialsReasonHealthy {
+				wantStatus = metav1.ConditionTrue
+			}
+			if condition.Status != wantStatus || condition.Reason != tt.wantReason || !strings.Contains(condition.Message, tt.wantMessage) {
+				t.Errorf("updateCredentialsHealthCondition() condition = %s %s %q, want %s %s %q",
+					condition.Status, condition.Reason, condition.Message, wantStatus, tt.wantReason, tt.wantMessage)
+			}
+			if (r.credentialsRecheckAfter > 0) != tt.wantRecheck {
+				t.Errorf("updateCredentialsHealthCondition() recheck after %s, want a recheck %v", r.credentialsRecheckAfter, tt.wantRecheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+	"github.com/openshift/oadp-operator/pkg/credentials"
+)
+
+// referencedCredentials returns the names of the credential secrets and the CA bundles referenced by the DPA
+func (r *DataProtectionApplicationReconciler) referencedCredentials() ([]string, [][]byte) {
+	dpa := r.dpa
+	secretNames := map[string]bool{}
+	caBundles := [][]byte{}
+
+	if dpa.Spec.Configuration != nil && dpa.Spec.Configuration.Velero != nil {
+		for _, plugin := range dpa.Spec.Configuration.Velero.DefaultPlugins {
+			if pluginSpecificMap, ok := credentials.PluginSpecificFields[plugin]; ok && pluginSpecificMap.IsCloudProvider {
+				secretNames[pluginSpecificMap.SecretName] = true
+			}
+		}
+	}
+	for _, bslSpec := range dpa.Spec.BackupLocations {
+		if bslSpec.Velero != nil {
+			secretName, _ := credentials.GetSecretNameAndKey(bslSpec.Velero, oadpv1alpha1.DefaultPlugin(bslSpec.Velero.Provider))
+			if secretName != "" && bslSpec.SecretProviderClass == nil {
+				secretNames[secretName] = true
+			}
+			if bslSpec.Velero.ObjectStorage != nil && len(bslSpec.Velero.ObjectStorage.CACert) > 0 {
+				caBundles = append(caBundles, bslSpec.Velero.ObjectStorage.CACert)
+			}
+		}
+		if bslSpec.CloudStorage != nil {
+			if bslSpec.CloudStorage.Credential != nil && bslSpec.CloudStorage.Credential.Name != "" {
+				secretNames[bslSpec.CloudStorage.Credential.Name] = true
+			}
+			if len(bslSpec.CloudStorage.CACert) > 0 {
+				caBundles = append(caBundles, bslSpec.CloudStorage.CACert)
+			}
+		}
+	}
+	for _, vslSpec := range dpa.Spec.SnapshotLocations {
+		if vslSpec.Velero == nil || vslSpec.SecretProviderClass != nil {
+			continue
+		}
+		if vslSpec.Velero.Credential != nil && vslSpec.Velero.Credential.Name != "" {
+			secretNames[vslSpec.Velero.Credential.Name] = true
+		} else if pluginSpecificMap, ok := credentials.PluginSpecificFields[oadpv1alpha1.DefaultPlugin(strings.TrimPrefix(vslSpec.Velero.Provider, veleroIOPrefix))]; ok && pluginSpecificMap.IsCloudProvider {
+			secretNames[pluginSpecificMap.SecretName] = true
+		}
+	}
+
+	names := []string{}
+	for name := range secretNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, caBundles
+}
+
+// credentialsHash returns a hash of the contents of the credential secrets and CA bundles referenced by the DPA,
+// or an empty string if none of them exists.
+// Secrets that do not exist yet are left out, so creating them changes the hash.
+func (r *DataProtectionApplicationReconciler) credentialsHash() (string, error) {
+	secretNames, caBundles := r.referencedCredentials()
+	hash := sha256.New()
+	found := len(caBundles) > 0
+	for _, secretName := range secretNames {
+		secret := &corev1.Secret{}
+		if err := r.Get(r.Context, types.NamespacedName{Namespace: r.dpa.Namespace, Name: secretName}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		found = true
+		keys := []string{}
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		hash.Write([]byte(secretName))
+		for _, key := range keys {
+			hash.Write([]byte(key))
+			hash.Write(secret.Data[key])
+		}
+	}
+	for _, caBundle := range caBundles {
+		hash.Write(caBundle)
+	}
+	if !found {
+		return "", nil
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// setCredentialsHashAnnotation stamps the credentials hash on a pod template, so rotating a credential rolls out
+// new pods. Like any other pod template change, the rollout waits for the rollout gate.
+func (r *DataProtectionApplicationReconciler) setCredentialsHashAnnotation(template *corev1.PodTemplateSpec) error {
+	hash, err := r.credentialsHash()
+	if err != nil {
+		return err
+	}
+	if hash == "" {
+		return nil
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[common.CredentialsHashAnnotation] = hash
+	return nil
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+func newCredentialsHashTestDPA() *oadpv1alpha1.DataProtectionApplication {
+	return &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{
+					DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS, oadpv1alpha1.DefaultPluginOpenShift},
+				},
+			},
+			BackupLocations: []oadpv1alpha1.BackupLocation{
+				{
+					Velero: &velerov1.BackupStorageLocationSpec{
+						Provider: "aws",
+						Credential: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "bsl-credentials"},
+							Key:                  "cloud",
+						},
+						StorageType: velerov1.StorageType{
+							ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "bucket", CACert: []byte("ca-bundle")},
+						},
+					},
+				},
+			},
+			SnapshotLocations: []oadpv1alpha1.SnapshotLocation{
+				{
+					Velero: &velerov1.VolumeSnapshotLocationSpec{Provider: "velero.io/gcp"},
+				},
+			},
+		},
+	}
+}
+
+func TestDPAReconciler_referencedCredentials(t *testing.T) {
+	r := &DataProtectionApplicationReconciler{dpa: newCredentialsHashTestDPA()}
+	secretNames, caBundles := r.referencedCredentials()
+	wantSecretNames := []string{"bsl-credentials", "cloud-credentials", "cloud-credentials-gcp"}
+	if !reflect.DeepEqual(secretNames, wantSecretNames) {
+		t.Errorf("referencedCredentials() secret names = %v, want %v", secretNames, wantSecretNames)
+	}
+	if !reflect.DeepEqual(caBundles, [][]byte{[]byte("ca-bundle")}) {
+		t.Errorf("referencedCredentials() CA bundles = %v, want the BSL CA bundle", caBundles)
+	}
+}
+
+func TestDPAReconciler_credentialsHash(t *testing.T) {
+	credentialsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bsl-credentials", Namespace: testNamespaceName},
+		Data:       map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=old")},
+	}
+	fakeClient, err := getFakeClientFromObjects(credentialsSecret)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	dpa := newCredentialsHashTestDPA()
+	dpa.Spec.BackupLocations[0].Velero.ObjectStorage.CACert = nil
+	r := &DataProtectionApplicationReconciler{
+		Client:  fakeClient,
+		Log:     logr.Discard(),
+		Context: newContextForTest(),
+		dpa:     dpa,
+	}
+
+	template := &corev1.PodTemplateSpec{}
+	if err := r.setCredentialsHashAnnotation(template); err != nil {
+		t.Fatalf("setCredentialsHashAnnotation() error = %v", err)
+	}
+	hash := template.Annotations[common.CredentialsHashAnnotation]
+	if hash == "" {
+		t.Fatalf("setCredentialsHashAnnotation() did not set the credentials hash annotation")
+	}
+
+	credentialsSecret.Data["cloud"] = []byte("[default]\naws_access_key_id=new")
+	if err := fakeClient.Update(r.Context, credentialsSecret); err != nil {
+		t.Fatalf("unable to update secret: %v", err)
+	}
+	rotatedHash, err := r.credentialsHash()
+	if err != nil {
+		t.Fatalf("credentialsHash() error = %v", err)
+	}
+	if rotatedHash == hash {
+		t.Errorf("credentialsHash() did not change after the credentials were rotated")
+	}
+
+	if err := fakeClient.Delete(r.Context, credentialsSecret); err != nil {
+		t.Fatalf("unable to delete secret: %v", err)
+	}
+	template = &corev1.PodTemplateSpec{}
+	if err := r.setCredentialsHashAnnotation(template); err != nil {
+		t.Fatalf("setCredentialsHashAnnotation() error = %v", err)
+	}
+	if _, ok := template.Annotations[common.CredentialsHashAnnotation]; ok {
+		t.Errorf("setCredentialsHashAnnotation() set the annotation without any existing credentials")
+	}
+}
@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const (
+	// consoleDashboardLabel makes the OpenShift console list a dashboard ConfigMap of openshift-config-managed
+	consoleDashboardLabel = "console.openshift.io/dashboard"
+	// grafanaDashboardLabel is the label the Grafana dashboard sidecar watches ConfigMaps for
+	grafanaDashboardLabel = "grafana_dashboard"
+	// dashboardDPANamespaceLabel records the DPA namespace of a dashboard, which may live in another namespace
+	dashboardDPANamespaceLabel = "oadp.openshift.io/dpa-namespace"
+)
+
+// grafanaDashboard is the subset of the Grafana dashboard JSON model used by the OADP dashboards
+type grafanaDashboard struct {
+	UID           string           `json:"uid"`
+	Title         string           `json:"title"`
+	Tags          []string         `json:"tags"`
+	SchemaVersion int              `json:"schemaVersion"`
+	Refresh       string           `json:"refresh"`
+	Time          grafanaTimeRange `json:"time"`
+	Panels        []grafanaPanel   `json:"panels"`
+}
+
+type grafanaTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaPanel struct {
+	ID          int                `json:"id"`
+	Title       string             `json:"title"`
+	Type        string             `json:"type"`
+	GridPos     grafanaGridPos     `json:"gridPos"`
+	FieldConfig grafanaFieldConfig `json:"fieldConfig"`
+	Targets     []grafanaTarget    `json:"targets"`
+}
+
+type grafanaGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type grafanaFieldConfig struct {
+	Defaults grafanaFieldDefaults `json:"defaults"`
+}
+
+type grafanaFieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+type grafanaTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	RefID        string `json:"refId"`
+}
+
+// dashboardPanel is a time series panel of a dashboard, with one query per legend
+type dashboardPanel struct {
+	title   string
+	unit    string
+	queries map[string]string
+}
+
+// newGrafanaDashboard lays out the panels two per row
+func newGrafanaDashboard(uid, title string, panels []dashboardPanel) grafanaDashboard {
+	dashboard := grafanaDashboard{
+		UID:           uid,
+		Title:         title,
+		Tags:          []string{"oadp", "velero"},
+		SchemaVersion: 36,
+		Refresh:       "1m",
+		Time:          grafanaTimeRange{From: "now-24h", To: "now"},
+	}
+	for i, panel := range panels {
+		grafanaPanel := grafanaPanel{
+			ID:          i + 1,
+			Title:       panel.title,
+			Type:        "timeseries",
+			GridPos:     grafanaGridPos{H: 8, W: 12, X: (i % 2) * 12, Y: (i / 2) * 8},
+			FieldConfig: grafanaFieldConfig{Defaults: grafanaFieldDefaults{Unit: panel.unit}},
+		}
+		for _, legend := range slices.Sorted(maps.Keys(panel.queries)) {
+			grafanaPanel.Targets = append(grafanaPanel.Targets, grafanaTarget{
+				Expr:         panel.queries[legend],
+				LegendFormat: legend,
+				RefID:        string(rune('A' + len(grafanaPanel.Targets))),
+			})
+		}
+		dashboard.Panels = append(dashboard.Panels, grafanaPanel)
+	}
+	return dashboard
+}
+
+// oadpDashboards returns the dashboards of the Velero, NodeAgent, operator and kube-state-metrics metrics of the DPA namespace.
+// The operator metrics carry the namespace of the object they describe, which matches when the operator runs in the DPA namespace.
+func oadpDashboards(namespace string) map[string]grafanaDashboard {
+	selector := fmt.Sprintf(`namespace=%q`, namespace)
+	// the maintenance Jobs of a repository are named <repository>-maintain-job-<timestamp>
+	maintenanceJobSelector := selector + `,job_name=~".+-maintain-job-[0-9]+"`
+	return map[string]grafanaDashboard{
+		"backups": newGrafanaDashboard("oadp-backups-"+namespace, fmt.Sprintf("OADP / Backups and restores (%s)", namespace), []dashboardPanel{
+			{
+				title: "Backup success rate",
+				unit:  "percentunit",
+				queries: map[string]string{
+					"backups": fmt.Sprintf(`sum(increase(velero_backup_success_total{%[1]s}[1h])) / sum(increase(velero_backup_attempt_total{%[1]s}[1h]))`, selector),
+				},
+			},
+			{
+				title: "Restore success rate",
+				unit:  "percentunit",
+				queries: map[string]string{
+					"restores": fmt.Sprintf(`sum(increase(velero_restore_success_total{%[1]s}[1h])) / sum(increase(velero_restore_attempt_total{%[1]s}[1h]))`, selector),
+				},
+			},
+			{
+				title: "Backup duration",
+				unit:  "s",
+				queries: map[string]string{
+					"p50": fmt.Sprintf(`histogram_quantile(0.5, sum(rate(velero_backup_duration_seconds_bucket{%s}[1h])) by (le))`, selector),
+					"p95": fmt.Sprintf(`histogram_quantile(0.95, sum(rate(velero_backup_duration_seconds_bucket{%s}[1h])) by (le))`, selector),
+				},
+			},
+			{
+				title: "Failed backups by schedule",
+				queries: map[string]string{
+					"{{schedule}}": fmt.Sprintf(`sum(increase(velero_backup_failure_total{%s}[1h])) by (schedule)`, selector),
+				},
+			},
+		}),
+		// the data mover and file system backup metrics are exported by NodeAgent
+		"data-mover": newGrafanaDashboard("oadp-data-mover-"+namespace, fmt.Sprintf("OADP / Data mover (%s)", namespace), []dashboardPanel{
+			{
+				title: "Data uploads per hour",
+				queries: map[string]string{
+					"succeeded": fmt.Sprintf(`sum(increase(podVolume_data_upload_success_total{%s}[1h]))`, selector),
+					"failed":    fmt.Sprintf(`sum(increase(podVolume_data_upload_failure_total{%s}[1h]))`, selector),
+				},
+			},
+			{
+				title: "Data downloads per hour",
+				queries: map[string]string{
+					"succeeded": fmt.Sprintf(`sum(increase(podVolume_data_download_success_total{%s}[1h]))`, selector),
+					"failed":    fmt.Sprintf(`sum(increase(podVolume_data_download_failure_total{%s}[1h]))`, selector),
+				},
+			},
+			{
+				title: "Pod volume operation latency",
+				unit:  "s",
+				queries: map[string]string{
+					"{{operation}} p95": fmt.Sprintf(`histogram_quantile(0.95, sum(rate(podVolume_pod_volume_operation_latency_seconds_bucket{%s}[1h])) by (le, operation))`, selector),
+				},
+			},
+		}),
+		// Velero does not export metrics of the maintenance Jobs it keeps, kube-state-metrics does
+		"repository-maintenance": newGrafanaDashboard("oadp-repository-maintenance-"+namespace, fmt.Sprintf("OADP / Repository maintenance (%s)", namespace), []dashboardPanel{
+			{
+				title: "Maintenance jobs",
+				queries: map[string]string{
+					"succeeded": fmt.Sprintf(`count(kube_job_status_succeeded{%s} > 0)`, maintenanceJobSelector),
+					"failed":    fmt.Sprintf(`count(kube_job_status_failed{%s} > 0)`, maintenanceJobSelector),
+				},
+			},
+			{
+				title: "Maintenance duration",
+				unit:  "s",
+				queries: map[string]string{
+					"{{job_name}}": fmt.Sprintf(`kube_job_status_completion_time{%[1]s} - kube_job_status_start_time{%[1]s}`, maintenanceJobSelector),
+				},
+			},
+		}),
+		"data-protection-tests": newGrafanaDashboard("oadp-data-protection-tests-"+namespace, fmt.Sprintf("OADP / DataProtectionTests (%s)", namespace), []dashboardPanel{
+			{
+				title: "Upload speed",
+				unit:  "Mbits",
+				queries: map[string]string{
+					"{{name}}": fmt.Sprintf(`%s_dpt_upload_speed_mbps{%s}`, metricsNamespace, selector),
+				},
+			},
+			{
+				title: "Test runs per day",
+				queries: map[string]string{
+					"{{phase}}": fmt.Sprintf(`sum(increase(%s_dpt_runs_total{%s}[1d])) by (phase)`, metricsNamespace, selector),
+				},
+			},
+		}),
+	}
+}
+
+// dashboardsNamespace returns the namespace of the dashboard ConfigMaps, or an empty string when dashboards are disabled
+func (r *DataProtectionApplicationReconciler) dashboardsNamespace() string {
+	monitoring := r.dpa.Spec.Monitoring
+	if monitoring == nil || !monitoring.Dashboards {
+		return ""
+	}
+	if monitoring.DashboardsNamespace != "" {
+		return monitoring.DashboardsNamespace
+	}
+	return r.NamespacedName.Namespace
+}
+
+// dashboardsClient returns the client for the dashboards namespace, as the reconciler cache only covers the DPA namespace
+func (r *DataProtectionApplicationReconciler) dashboardsClient(namespace string) client.Client {
+	if namespace == r.NamespacedName.Namespace {
+		return r.Client
+	}
+	return r.ClusterWideClient
+}
+
+// ReconcileDashboards creates the dashboard ConfigMaps when spec.monitoring.dashboards is set,
+// and deletes the dashboards that are no longer required, including the ones left in the previous dashboards namespace
+// recorded in the DPA status
+func (r *DataProtectionApplicationReconciler) ReconcileDashboards(log logr.Logger) (bool, error) {
+	namespace := r.dashboardsNamespace()
+	if namespace == "" && r.dpa.Status.DashboardsNamespace == "" {
+		return true, nil
+	}
+	desired := map[types.NamespacedName]bool{}
+	if namespace != "" {
+		for name, dashboard := range oadpDashboards(r.NamespacedName.Namespace) {
+			configMap := &corev1.ConfigMap{}
+			configMap.Name = fmt.Sprintf("oadp-%s-%s", r.NamespacedName.Namespace, name)
+			configMap.Namespace = namespace
+			desired[client.ObjectKeyFromObject(configMap)] = true
+			// the plan client only covers the DPA namespace
+			if r.isPlanning() && namespace != r.NamespacedName.Namespace {
+				continue
+			}
+
+			dashboardJSON, err := json.MarshalIndent(dashboard, "", "  ")
+			if err != nil {
+				return false, err
+			}
+			op, err := controllerutil.CreateOrPatch(r.Context, r.dashboardsClient(namespace), configMap, func() error {
+				configMap.Labels = map[string]string{
+					consoleDashboardLabel:          "true",
+					grafanaDashboardLabel:          "1",
+					dashboardDPANamespaceLabel:     r.NamespacedName.Namespace,
+					oadpv1alpha1.OadpOperatorLabel: "True",
+				}
+				configMap.Data = map[string]string{name + ".json": string(dashboardJSON)}
+				// owner references can not cross namespaces, dashboards elsewhere are deleted by their labels
+				if namespace != r.NamespacedName.Namespace {
+					return nil
+				}
+				return controllerutil.SetControllerReference(r.dpa, configMap, r.Scheme)
+			})
+			if err != nil {
+				return false, err
+			}
+			if op == controllerutil.OperationResultCreated || op == controllerutil.OperationResultUpdated {
+				r.EventRecorder.Event(configMap,
+					corev1.EventTypeNormal,
+					"DashboardReconciled",
+					fmt.Sprintf("performed %s on dashboard configmap %s/%s", op, configMap.Namespace, configMap.Name),
+				)
+			}
+		}
+	}
+	if r.isPlanning() {
+		return true, nil
+	}
+
+	for _, dashboardsNamespace := range slices.Compact(slices.Sorted(slices.Values([]string{namespace, r.dpa.Status.DashboardsNamespace}))) {
+		if dashboardsNamespace == "" {
+			continue
+		}
+		dashboardsClient := r.dashboardsClient(dashboardsNamespace)
+		configMaps := &corev1.ConfigMapList{}
+		if err := dashboardsClient.List(r.Context, configMaps, client.InNamespace(dashboardsNamespace), client.MatchingLabels{
+			consoleDashboardLabel:      "true",
+			dashboardDPANamespaceLabel: r.NamespacedName.Namespace,
+		}); err != nil {
+			return false, err
+		}
+		for i := range configMaps.Items {
+			configMap := &configMaps.Items[i]
+			if desired[client.ObjectKeyFromObject(configMap)] {
+				continue
+			}
+			log.Info("Deleting dashboard", "namespace", configMap.Namespace, "name", configMap.Name)
+			if err := dashboardsClient.Delete(r.Context, configMap); err != nil && !k8serror.IsNotFound(err) {
+				return false, err
+			}
+		}
+	}
+	r.dpa.Status.DashboardsNamespace = namespace
+	return true, nil
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	velerometrics "github.com/vmware-tanzu/velero/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestDPAReconciler_ReconcileDashboards(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDpaName,
+			Namespace: testNamespaceName,
+			UID:       "dpa-uid",
+		},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{},
+			},
+			Monitoring: &oadpv1alpha1.Monitoring{Dashboards: true},
+		},
+	}
+	fakeClient, err := getFakeClientFromObjects(dpa)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:            fakeClient,
+		ClusterWideClient: fakeClient,
+		Scheme:            fakeClient.Scheme(),
+		Log:               logr.Discard(),
+		Context:           newContextForTest(),
+		NamespacedName: types.NamespacedName{
+			Namespace: dpa.Namespace,
+			Name:      dpa.Name,
+		},
+		EventRecorder: record.NewFakeRecorder(10),
+		dpa:           dpa,
+	}
+	listDashboards := func() []corev1.ConfigMap {
+		configMaps := &corev1.ConfigMapList{}
+		if err := fakeClient.List(r.Context, configMaps, client.MatchingLabels{consoleDashboardLabel: "true"}); err != nil {
+			t.Fatalf("unable to list dashboards: %v", err)
+		}
+		return configMaps.Items
+	}
+
+	if _, err := r.ReconcileDashboards(r.Log); err != nil {
+		t.Fatalf("ReconcileDashboards() error = %v", err)
+	}
+	dashboards := listDashboards()
+	if len(dashboards) != len(oadpDashboards(testNamespaceName)) {
+		t.Fatalf("ReconcileDashboards() created %d dashboards, want %d", len(dashboards), len(oadpDashboards(testNamespaceName)))
+	}
+	for _, configMap := range dashboards {
+		if configMap.Namespace != testNamespaceName || !metav1.IsControlledBy(&configMap, dpa) {
+			t.Errorf("ReconcileDashboards() dashboard %s/%s is not controlled by the DPA", configMap.Namespace, configMap.Name)
+		}
+		if configMap.Labels[grafanaDashboardLabel] != "1" {
+			t.Errorf("ReconcileDashboards() dashboard %s is not labelled for the Grafana sidecar", configMap.Name)
+		}
+		for key, data := range configMap.Data {
+			dashboard := grafanaDashboard{}
+			if err := json.Unmarshal([]byte(data), &dashboard); err != nil {
+				t.Fatalf("ReconcileDashboards() dashboard %s has invalid JSON: %v", key, err)
+			}
+			for _, panel := range dashboard.Panels {
+				for _, target := range panel.Targets {
+					if !strings.Contains(target.Expr, `namespace="test-ns"`) {
+						t.Errorf("ReconcileDashboards() query %s of dashboard %s is not limited to the DPA namespace", target.Expr, key)
+					}
+				}
+			}
+		}
+	}
+
+	dpa.Spec.Monitoring.DashboardsNamespace = "openshift-config-managed"
+	if _, err := r.ReconcileDashboards(r.Log); err != nil {
+		t.Fatalf("ReconcileDashboards() error = %v", err)
+	}
+	dashboards = listDashboards()
+	if len(dashboards) != len(oadpDashboards(testNamespaceName)) {
+		t.Fatalf("ReconcileDashboards() kept %d dashboards after the namespace changed, want %d", len(dashboards), len(oadpDashboards(testNamespaceName)))
+	}
+	for _, configMap := range dashboards {
+		if configMap.Namespace != "openshift-config-managed" || len(configMap.OwnerReferences) != 0 {
+			t.Errorf("ReconcileDashboards() dashboard %s/%s owner references = %v, want none in openshift-config-managed", configMap.Namespace, configMap.Name, configMap.OwnerReferences)
+		}
+	}
+
+	dpa.Spec.Monitoring = nil
+	if _, err := r.ReconcileDashboards(r.Log); err != nil {
+		t.Fatalf("ReconcileDashboards() error = %v", err)
+	}
+	if dashboards := listDashboards(); len(dashboards) != 0 {
+		t.Errorf("ReconcileDashboards() kept %d dashboards after they were disabled", len(dashboards))
+	}
+	if dpa.Status.DashboardsNamespace != "" {
+		t.Errorf("ReconcileDashboards() status.dashboardsNamespace = %s, want none after they were disabled", dpa.Status.DashboardsNamespace)
+	}
+
+	// without dashboards to create or delete, the ConfigMaps are not listed
+	r.ClusterWideClient = interceptor.NewClient(fakeClient, interceptor.Funcs{
+		List: func(ctx context.Context, client client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			t.Errorf("ReconcileDashboards() listed %T with dashboards disabled", list)
+			return nil
+		},
+	})
+	r.Client = r.ClusterWideClient
+	if _, err := r.ReconcileDashboards(r.Log); err != nil {
+		t.Fatalf("ReconcileDashboards() error = %v", err)
+	}
+}
+
+// metricNamesRegisterer records the names of the metrics registered with it
+type metricNamesRegisterer map[string]bool
+
+var descFQNameRegexp = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+func (m metricNamesRegisterer) Register(collector prometheus.Collector) error {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		collector.Describe(descs)
+		close(descs)
+	}()
+	for desc := range descs {
+		if match := descFQNameRegexp.FindStringSubmatch(desc.String()); match != nil {
+			m[match[1]] = true
+		}
+	}
+	return nil
+}
+
+func (m metricNamesRegisterer) MustRegister(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		_ = m.Register(collector)
+	}
+}
+
+func (m metricNamesRegisterer) Unregister(prometheus.Collector) bool {
+	return false
+}
+
+func TestOADPDashboards_metricNames(t *testing.T) {
+	// the Velero metrics register with the default registerer
+	registered := metricNamesRegisterer{}
+	defaultRegisterer := prometheus.DefaultRegisterer
+	prometheus.DefaultRegisterer = registered
+	defer func() { prometheus.DefaultRegisterer = defaultRegisterer }()
+	velerometrics.NewServerMetrics().RegisterAllMetrics()
+	velerometrics.NewNodeMetrics().RegisterAllMetrics()
+	registered.MustRegister(operatorMetrics...)
+	// the metrics of the maintenance Jobs are exported by kube-state-metrics
+	for _, name := range []string{"kube_job_status_succeeded", "kube_job_status_failed", "kube_job_status_start_time", "kube_job_status_completion_time"} {
+		registered[name] = true
+	}
+
+	// the series of a histogram are suffixed
+	histogramSuffix := regexp.MustCompile(`_(bucket|sum|count)$`)
+	metricName := regexp.MustCompile(`([a-zA-Z_:][a-zA-Z0-9_:]*)\{`)
+	for name, dashboard := range oadpDashboards(testNamespaceName) {
+		for _, panel := range dashboard.Panels {
+			for _, target := range panel.Targets {
+				for _, match := range metricName.FindAllStringSubmatch(target.Expr, -1) {
+					if !registered[match[1]] && !registered[histogramSuffix.ReplaceAllString(match[1], "")] {
+						t.Errorf("oadpDashboards() panel %q of dashboard %s queries metric %s, which is not registered", panel.Title, name, match[1])
+					}
+				}
+			}
+		}
+	}
+}
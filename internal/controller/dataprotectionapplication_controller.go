@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 	routev1 "github.com/openshift/api/route/v1"
@@ -26,12 +27,14 @@ import (
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -52,10 +55,33 @@ type DataProtectionApplicationReconciler struct {
 	EventRecorder     record.EventRecorder
 	dpa               *oadpv1alpha1.DataProtectionApplication
 	ClusterWideClient client.Client
+	// deferredRollouts lists the components whose rollout was deferred by the rollout gate in the current reconcile
+	deferredRollouts []string
+	// rolloutRetryAfter is when the deferred rollouts are retried
+	rolloutRetryAfter time.Duration
+	// connectivityRecheckAfter is when the first passed connectivity check of a backup location expires
+	connectivityRecheckAfter time.Duration
+	// credentialsRecheckAfter is when the first credentials start expiring or expire
+	credentialsRecheckAfter time.Duration
+	// gcpKeyExpirations caches the expirations of the GCP service account keys across reconciles, by key id
+	gcpKeyExpirations map[string]gcpKeyExpiration
+	// admission is true when the webhook validates the DPA: the failed checks of other resources are collected in
+	// admissionWarnings instead of failing the validation, and no request is sent to the cloud providers
+	admission         bool
+	admissionWarnings []string
+	// clock is the time of the maintenance window and the rollout gate timeout, the real time when nil
+	clock clock.PassiveClock
 }
 
 var debugMode = os.Getenv("DEBUG") == "true"
 
+func (r *DataProtectionApplicationReconciler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
 //+kubebuilder:rbac:groups=oadp.openshift.io,resources=dataprotectionapplications,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=oadp.openshift.io,resources=dataprotectionapplications/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=oadp.openshift.io,resources=dataprotectionapplications/finalizers,verbs=update
@@ -71,7 +97,7 @@ var debugMode = os.Getenv("DEBUG") == "true"
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=apps,resources=deployments;daemonsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main Kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -83,8 +109,15 @@ func (r *DataProtectionApplicationReconciler) Reconcile(ctx context.Context, req
 	r.Context = ctx
 	r.NamespacedName = req.NamespacedName
 	r.dpa = &oadpv1alpha1.DataProtectionApplication{}
+	r.deferredRollouts = nil
+	r.rolloutRetryAfter = 0
+	r.connectivityRecheckAfter = 0
+	r.credentialsRecheckAfter = 0
 
 	if err := r.Get(ctx, req.NamespacedName, r.dpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteDPAMetrics(req.Namespace, req.Name)
+		}
 		logger.Error(err, "unable to fetch DataProtectionApplication CR")
 		return result, nil
 	}
@@ -92,25 +125,30 @@ func (r *DataProtectionApplicationReconciler) Reconcile(ctx context.Context, req
 	// set client to pkg/client for use in non-reconcile functions
 	oadpclient.SetClient(r.Client)
 
-	_, err := ReconcileBatch(r.Log,
-		r.ValidateDataProtectionCR,
-		r.ReconcileFsRestoreHelperConfig,
-		r.ReconcileBackupStorageLocations,
-		r.ReconcileRegistrySecrets,
-		r.ReconcileRegistries,
-		r.ReconcileRegistrySVCs,
-		r.ReconcileRegistryRoutes,
-		r.ReconcileRegistryRouteConfigs,
-		r.LabelVSLSecrets,
-		r.ReconcileVolumeSnapshotLocations,
-		r.ReconcileVeleroDeployment,
-		r.ReconcileNodeAgentConfigMap,
-		r.ReconcileBackupRepositoryConfigMap,
-		r.ReconcileRepositoryMaintenanceConfigMap,
-		r.ReconcileNodeAgentDaemonset,
-		r.ReconcileVeleroMetricsSVC,
-		r.ReconcileNonAdminController,
-	)
+	steps := r.reconcileSteps()
+
+	var err error
+	// a paused DPA reports the changes it would make, like a dry run, so drift from hand-made changes is visible
+	if r.dpa.Spec.Paused || r.dpa.Annotations[oadpv1alpha1.DryRunAnnotation] == "true" {
+		r.dpa.Status.Plan, err = r.planReconcile(r.Log, steps...)
+	} else {
+		r.dpa.Status.Plan = nil
+		var stepStatuses []oadpv1alpha1.ReconcileStepStatus
+		stepStatuses, err = runReconcileSteps(r.Log, steps...)
+		if stepStatuses != nil {
+			r.dpa.Status.ReconcileSteps = stepStatuses
+			observeReconcileSteps(r.dpa.Namespace, r.dpa.Name, stepStatuses)
+		}
+		if err == nil && len(r.deferredRollouts) == 0 {
+			apimeta.RemoveStatusCondition(&r.dpa.Status.Conditions, oadpv1alpha1.ConditionRolloutDeferred)
+		}
+		if len(r.deferredRollouts) > 0 {
+			result.RequeueAfter = r.rolloutRetryAfter
+		}
+		if r.connectivityRecheckAfter > 0 && (result.RequeueAfter == 0 || r.connectivityRecheckAfter < result.RequeueAfter) {
+			result.RequeueAfter = r.connectivityRecheckAfter
+		}
+	}
 
 	if err != nil {
 		apimeta.SetStatusCondition(&r.dpa.Status.Conditions,
@@ -122,6 +160,24 @@ func (r *DataProtectionApplicationReconciler) Reconcile(ctx context.Context, req
 			},
 		)
 
+	} else if r.dpa.Spec.Paused {
+		apimeta.SetStatusCondition(&r.dpa.Status.Conditions,
+			metav1.Condition{
+				Type:    oadpv1alpha1.ConditionReconciled,
+				Status:  metav1.ConditionFalse,
+				Reason:  oadpv1alpha1.ReconciledReasonPaused,
+				Message: oadpv1alpha1.ReconcilePausedMessage,
+			},
+		)
+	} else if r.dpa.Status.Plan != nil {
+		apimeta.SetStatusCondition(&r.dpa.Status.Conditions,
+			metav1.Condition{
+				Type:    oadpv1alpha1.ConditionReconciled,
+				Status:  metav1.ConditionFalse,
+				Reason:  oadpv1alpha1.ReconciledReasonDryRun,
+				Message: oadpv1alpha1.ReconcileDryRunMessage,
+			},
+		)
 	} else {
 		apimeta.SetStatusCondition(&r.dpa.Status.Conditions,
 			metav1.Condition{
@@ -132,12 +188,27 @@ func (r *DataProtectionApplicationReconciler) Reconcile(ctx context.Context, req
 			},
 		)
 	}
+	if readinessErr := r.updateReadinessConditions(); readinessErr != nil {
+		logger.Error(readinessErr, "unable to update readiness conditions")
+	}
+	if credentialsErr := r.updateCredentialsHealthCondition(); credentialsErr != nil {
+		logger.Error(credentialsErr, "unable to check credentials health")
+	}
+	if r.credentialsRecheckAfter > 0 && (result.RequeueAfter == 0 || r.credentialsRecheckAfter < result.RequeueAfter) {
+		result.RequeueAfter = r.credentialsRecheckAfter
+	}
+	setDPAStatusMetrics(r.dpa)
+	if counts, countErr := r.managedObjectCounts(); countErr != nil {
+		logger.Error(countErr, "unable to count managed objects")
+	} else {
+		setDPAManagedObjectMetrics(r.dpa.Namespace, r.dpa.Name, counts)
+	}
 	statusErr := r.Client.Status().Update(ctx, r.dpa)
 	if err == nil { // Don't mask previous error
 		err = statusErr
 	}
 
-	return ctrl.Result{}, err
+	return result, err
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -213,15 +284,3 @@ func (l *labelHandler) Generic(ctx context.Context, evt event.TypedGenericEvent[
 }
 
 type ReconcileFunc func(logr.Logger) (bool, error)
-
-// reconcileBatch steps through a list of reconcile functions until one returns
-// false or an error.
-func ReconcileBatch(l logr.Logger, reconcileFuncs ...ReconcileFunc) (bool, error) {
-	// TODO: #1127 DPAReconciler already have a logger, use it instead of passing to each reconcile functions
-	for _, f := range reconcileFuncs {
-		if cont, err := f(l); !cont || err != nil {
-			return cont, err
-		}
-	}
-	return true, nil
-}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// DataProtectionApplicationWebhook defaults and validates DataProtectionApplications at admission time,
+// using the same rules the reconciler applies
+type DataProtectionApplicationWebhook struct {
+	Client            client.Client
+	ClusterWideClient client.Client
+}
+
+//+kubebuilder:webhook:path=/mutate-oadp-openshift-io-v1alpha1-dataprotectionapplication,mutating=true,failurePolicy=fail,sideEffects=None,groups=oadp.openshift.io,resources=dataprotectionapplications,verbs=create;update,versions=v1alpha1,name=mdataprotectionapplication.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-oadp-openshift-io-v1alpha1-dataprotectionapplication,mutating=false,failurePolicy=fail,sideEffects=None,groups=oadp.openshift.io,resources=dataprotectionapplications,verbs=create;update,versions=v1alpha1,name=vdataprotectionapplication.kb.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks with the Manager.
+func (w *DataProtectionApplicationWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&oadpv1alpha1.DataProtectionApplication{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default persists the DPA auto-corrections
+func (w *DataProtectionApplicationWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	dpa, ok := obj.(*oadpv1alpha1.DataProtectionApplication)
+	if !ok {
+		return fmt.Errorf("expected a DataProtectionApplication but got a %T", obj)
+	}
+	// DPAs without Velero configuration are rejected by the validating webhook
+	if dpa.Spec.Configuration == nil || dpa.Spec.Configuration.Velero == nil {
+		return nil
+	}
+	dpa.AutoCorrect()
+	return nil
+}
+
+// ValidateCreate validates a new DPA
+func (w *DataProtectionApplicationWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return w.validate(ctx, obj)
+}
+
+// ValidateUpdate validates an updated DPA. A violation the DPA already had before the update, like one of a DPA
+// created before the webhook or the rule, is a warning, so the DPA can still be fixed, paused or planned in dry-run.
+func (w *DataProtectionApplicationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	warnings, err := w.validate(ctx, newObj)
+	if !apierrors.IsInvalid(err) {
+		return warnings, err
+	}
+	if _, oldErr := w.validate(ctx, oldObj); oldErr != nil && oldErr.Error() == err.Error() {
+		return append(warnings, fmt.Sprintf("DPA is still invalid: %v", err)), nil
+	}
+	return warnings, err
+}
+
+// ValidateDelete allows every DPA deletion
+func (w *DataProtectionApplicationWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate rejects the DPA if a validation rule fails on one of its fields.
+// Failures that depend on other resources, like a missing credentials secret, are returned as
+// warnings, as those resources may be created after the DPA; the reconciler reports them.
+func (w *DataProtectionApplicationWebhook) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	dpa, ok := obj.(*oadpv1alpha1.DataProtectionApplication)
+	if !ok {
+		return nil, fmt.Errorf("expected a DataProtectionApplication but got a %T", obj)
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:            w.Client,
+		ClusterWideClient: w.ClusterWideClient,
+		Log:               log.FromContext(ctx),
+		Context:           ctx,
+		NamespacedName:    types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+		dpa:               dpa.DeepCopy(),
+		admission:         true,
+	}
+
+	warnings := admission.Warnings{}
+	if usesRestic(dpa) {
+		warnings = append(warnings, resticDeprecationWarning)
+	}
+
+	err := r.validateDataProtectionApplication()
+	warnings = append(warnings, r.admissionWarnings...)
+	if err == nil {
+		return warnings, nil
+	}
+	var fieldErr fieldError
+	if errors.As(err, &fieldErr) {
+		return warnings, apierrors.NewInvalid(
+			oadpv1alpha1.GroupVersion.WithKind("DataProtectionApplication").GroupKind(),
+			dpa.Name,
+			field.ErrorList{fieldErr.fieldErr},
+		)
+	}
+	return append(warnings, fmt.Sprintf("DPA could not be fully validated: %v", err)), nil
+}
@@ -0,0 +1,398 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestDataProtectionApplicationWebhook_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		dpa          *oadpv1alpha1.DataProtectionApplication
+		objects      []client.Object
+		wantField    string
+		wantWarnings int
+	}{
+		{
+			name: "valid DPA is admitted",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins:          []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS},
+							NoDefaultBackupLocation: true,
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+		},
+		{
+			name: "DPA without Velero configuration is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+			},
+			wantField: "spec.configuration.velero",
+		},
+		{
+			name: "second DPA in the namespace is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{NoDefaultBackupLocation: true},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			objects: []client.Object{
+				&oadpv1alpha1.DataProtectionApplication{
+					ObjectMeta: metav1.ObjectMeta{Name: "other-dpa", Namespace: testNamespaceName},
+				},
+			},
+			wantField: "metadata.namespace",
+		},
+		{
+			name: "backup location with invalid provider is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							FeatureFlags: []string{"no-secret"},
+						},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{
+							Velero: &velerov1.BackupStorageLocationSpec{
+								Provider: "unknown",
+								Default:  true,
+							},
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			wantField: "spec.backupLocations[0].velero.provider",
+		},
+		{
+			name: "non-enforceable non-admin backup spec field is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{NoDefaultBackupLocation: true},
+					},
+					BackupImages: ptr.To(false),
+					NonAdmin: &oadpv1alpha1.NonAdmin{
+						EnforceBackupSpec: &velerov1.BackupSpec{StorageLocation: "bsl"},
+					},
+				},
+			},
+			wantField: "spec.nonAdmin.enforceBackupSpec.storageLocation",
+		},
+		{
+			name: "invalid maintenance window schedule is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{NoDefaultBackupLocation: true},
+					},
+					BackupImages: ptr.To(false),
+					MaintenanceWindow: &oadpv1alpha1.MaintenanceWindow{
+						Schedule: "every saturday",
+						Duration: metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			wantField: "spec.maintenanceWindow.schedule",
+		},
+		{
+			name: "missing credentials secret is a warning",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS},
+						},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{
+							Velero: &velerov1.BackupStorageLocationSpec{
+								Provider: "aws",
+								Default:  true,
+								Config:   map[string]string{"region": "us-east-1"},
+								StorageType: velerov1.StorageType{
+									ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "bucket"},
+								},
+							},
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "empty bucket is rejected despite a missing credentials secret",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS},
+						},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{
+							Velero: &velerov1.BackupStorageLocationSpec{
+								Provider: "aws",
+								Default:  true,
+								Config:   map[string]string{"region": "us-east-1"},
+								StorageType: velerov1.StorageType{
+									ObjectStorage: &velerov1.ObjectStorageLocation{},
+								},
+							},
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			wantField:    "spec.backupLocations[0].velero.objectStorage.bucket",
+			wantWarnings: 1,
+		},
+		{
+			name: "azure backup location without resourceGroup is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginMicrosoftAzure},
+							FeatureFlags:   []string{"no-secret"},
+						},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{
+							Velero: &velerov1.BackupStorageLocationSpec{
+								Provider: "azure",
+								Default:  true,
+								Config:   map[string]string{"storageAccount": "account"},
+								StorageType: velerov1.StorageType{
+									ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "container"},
+								},
+							},
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			wantField: "spec.backupLocations[0].velero.config[resourceGroup]",
+		},
+		{
+			name: "aws backup location without region and with s3ForcePathStyle is rejected",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS},
+							FeatureFlags:   []string{"no-secret"},
+						},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{
+							Velero: &velerov1.BackupStorageLocationSpec{
+								Provider: "aws",
+								Default:  true,
+								Config:   map[string]string{"s3ForcePathStyle": "true", "s3Url": "https://s3.example.com"},
+								StorageType: velerov1.StorageType{
+									ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: "bucket"},
+								},
+							},
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			wantField: "spec.backupLocations[0].velero.config[region]",
+		},
+		{
+			name: "restic uploader is a deprecation warning",
+			dpa: &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{NoDefaultBackupLocation: true},
+						NodeAgent: &oadpv1alpha1.NodeAgentConfig{
+							UploaderType: "restic",
+						},
+					},
+					BackupImages: ptr.To(false),
+				},
+			},
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient, err := getFakeClientFromObjects(tt.objects...)
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			w := &DataProtectionApplicationWebhook{Client: fakeClient, ClusterWideClient: fakeClient}
+			warnings, err := w.ValidateCreate(newContextForTest(), tt.dpa)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateCreate() error = %v, want none", err)
+				}
+			} else {
+				statusErr, ok := err.(*apierrors.StatusError)
+				if !ok || !apierrors.IsInvalid(err) {
+					t.Fatalf("ValidateCreate() error = %v, want an Invalid error", err)
+				}
+				causes := statusErr.Status().Details.Causes
+				if len(causes) != 1 || causes[0].Field != tt.wantField {
+					t.Errorf("ValidateCreate() causes = %v, want field %s", causes, tt.wantField)
+				}
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("ValidateCreate() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestDataProtectionApplicationWebhook_ValidateUpdate(t *testing.T) {
+	validSpec := oadpv1alpha1.DataProtectionApplicationSpec{
+		Configuration: &oadpv1alpha1.ApplicationConfig{
+			Velero: &oadpv1alpha1.VeleroConfig{NoDefaultBackupLocation: true},
+		},
+		BackupImages: ptr.To(false),
+	}
+	pausedSpec := *validSpec.DeepCopy()
+	pausedSpec.Paused = true
+	invalidSpec := *validSpec.DeepCopy()
+	invalidSpec.Configuration.Velero = &oadpv1alpha1.VeleroConfig{FeatureFlags: []string{"no-secret"}}
+	invalidSpec.BackupLocations = []oadpv1alpha1.BackupLocation{
+		{Velero: &velerov1.BackupStorageLocationSpec{Provider: "unknown", Default: true}},
+	}
+	pausedInvalidSpec := *invalidSpec.DeepCopy()
+	pausedInvalidSpec.Paused = true
+	otherDPA := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-dpa", Namespace: testNamespaceName},
+	}
+	tests := []struct {
+		name         string
+		oldSpec      oadpv1alpha1.DataProtectionApplicationSpec
+		newSpec      oadpv1alpha1.DataProtectionApplicationSpec
+		objects      []client.Object
+		wantField    string
+		wantWarnings int
+	}{
+		{
+			name:    "valid update is admitted",
+			oldSpec: validSpec,
+			newSpec: pausedSpec,
+		},
+		{
+			name:      "new violation is rejected",
+			oldSpec:   validSpec,
+			newSpec:   invalidSpec,
+			wantField: "spec.backupLocations[0].velero.provider",
+		},
+		{
+			name:         "violation of the old DPA is a warning",
+			oldSpec:      invalidSpec,
+			newSpec:      pausedInvalidSpec,
+			wantWarnings: 1,
+		},
+		{
+			name:         "second DPA in the namespace can be paused",
+			oldSpec:      validSpec,
+			newSpec:      pausedSpec,
+			objects:      []client.Object{otherDPA},
+			wantWarnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient, err := getFakeClientFromObjects(tt.objects...)
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			w := &DataProtectionApplicationWebhook{Client: fakeClient, ClusterWideClient: fakeClient}
+			oldDPA := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec:       tt.oldSpec,
+			}
+			newDPA := oldDPA.DeepCopy()
+			newDPA.Spec = tt.newSpec
+			warnings, err := w.ValidateUpdate(newContextForTest(), oldDPA, newDPA)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateUpdate() error = %v, want none", err)
+				}
+			} else {
+				statusErr, ok := err.(*apierrors.StatusError)
+				if !ok || !apierrors.IsInvalid(err) {
+					t.Fatalf("ValidateUpdate() error = %v, want an Invalid error", err)
+				}
+				causes := statusErr.Status().Details.Causes
+				if len(causes) != 1 || causes[0].Field != tt.wantField {
+					t.Errorf("ValidateUpdate() causes = %v, want field %s", causes, tt.wantField)
+				}
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("ValidateUpdate() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestDataProtectionApplicationWebhook_Default(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{
+					DefaultPlugins: []oadpv1alpha1.DefaultPlugin{
+						oadpv1alpha1.DefaultPluginCSI,
+						oadpv1alpha1.DefaultPluginCSI,
+					},
+					Args: &oadpv1alpha1.VeleroServerArgs{},
+				},
+			},
+		},
+	}
+	w := &DataProtectionApplicationWebhook{}
+	if err := w.Default(newContextForTest(), dpa); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	velero := dpa.Spec.Configuration.Velero
+	if len(velero.DefaultPlugins) != 1 {
+		t.Errorf("Default() defaultPlugins = %v, want duplicates removed", velero.DefaultPlugins)
+	}
+	if !slices.Contains(velero.FeatureFlags, "EnableCSI") {
+		t.Errorf("Default() featureFlags = %v, want EnableCSI", velero.FeatureFlags)
+	}
+	if velero.Args.RestoreResourcePriorities == "" || velero.Args.PodVolumeOperationTimeout == nil {
+		t.Errorf("Default() args = %+v, want restore resource priorities and pod volume operation timeout set", velero.Args)
+	}
+
+	if err := w.Default(newContextForTest(), &oadpv1alpha1.DataProtectionApplication{}); err != nil {
+		t.Errorf("Default() of DPA without Velero configuration error = %v", err)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/hashicorp/go-multierror"
 	snapshotv1api "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -40,6 +41,7 @@ import (
 
 	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
 	"github.com/openshift/oadp-operator/pkg/cloudprovider"
+	"github.com/openshift/oadp-operator/pkg/credentials"
 	"github.com/openshift/oadp-operator/pkg/utils"
 )
 
@@ -73,6 +75,7 @@ func (r *DataProtectionTestReconciler) Reconcile(ctx context.Context, req ctrl.R
 	if err := r.Get(ctx, req.NamespacedName, r.dpt); err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("DPT not found; skipping reconciliation")
+			deleteDataProtectionTestMetrics(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "failed to get DPT")
@@ -300,10 +303,25 @@ func (r *DataProtectionTestReconciler) initializeProvider(backupLocationSpec *ve
 
 	switch providerName {
 	case AWSProvider:
-		r.Log.Info("Fetching AWS provider secret", "secretName", cred.Name, "namespace", r.NamespacedName.Namespace)
-		secret, err := utils.GetProviderSecret(cred.Name, r.NamespacedName.Namespace, r.Client, r.Context)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get AWS secret: %w", err)
+		var secret corev1.Secret
+		var secretKey string
+		var err error
+		if credentialsFile := cfg[CredentialsFileKey]; credentials.IsSecretProviderClassFilePath(credentialsFile) {
+			r.Log.Info("Reading AWS credentials file of SecretProviderClass", "credentialsFile", credentialsFile)
+			secret, secretKey, err = credentials.GetSecretProviderClassFileSecret(credentialsFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get AWS credentials: %w", err)
+			}
+		} else {
+			if cred == nil {
+				return nil, fmt.Errorf("backupLocationSpec.Credential is nil")
+			}
+			r.Log.Info("Fetching AWS provider secret", "secretName", cred.Name, "namespace", r.NamespacedName.Namespace)
+			secret, err = utils.GetProviderSecret(cred.Name, r.NamespacedName.Namespace, r.Client, r.Context)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get AWS secret: %w", err)
+			}
+			secretKey = cred.Key
 		}
 
 		AWSProfile := "default"
@@ -314,13 +332,25 @@ func (r *DataProtectionTestReconciler) initializeProvider(backupLocationSpec *ve
 		}
 
 		r.Log.Info("Parsing AWS credentials", "profile", AWSProfile)
-		accessKey, secretKey, err := utils.ParseAWSSecret(secret, cred.Key, AWSProfile)
+		creds, err := utils.ResolveAWSCredentials(secret.Data[secretKey], AWSProfile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse AWS secret: %w", err)
 		}
+		// only keys, and the roles assumed with them, are usable from the operator pod
+		if creds.Source != utils.AWSCredentialsSourceStatic {
+			return nil, fmt.Errorf("profile %s uses %s credentials, which are not supported by DataProtectionTest", AWSProfile, creds.Source)
+		}
 
+		provider, err := cloudprovider.NewAWSProviderWithOptions(region, s3Url, creds.AccessKeyID, creds.SecretAccessKey, cloudprovider.AWSProviderOptions{
+			ForcePathStyle: s3Url != "",
+			SessionToken:   creds.SessionToken,
+			AssumeRoles:    creds.AssumeRoles,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize AWS provider: %w", err)
+		}
 		r.Log.Info("Successfully initialized AWS provider")
-		return cloudprovider.NewAWSProvider(region, s3Url, accessKey, secretKey), nil
+		return provider, nil
 	case GCPProvider:
 		return nil, fmt.Errorf("GCP provider support not implemented yet")
 	case AzureProvider:
@@ -585,10 +615,11 @@ func (r *DataProtectionTestReconciler) updateDPTErrorStatus(ctx context.Context,
 	if err != nil {
 		r.Log.Error(err, "failed to update DPT error status", "message", msg)
 	}
+	observeDataProtectionTest(r.NamespacedName.Namespace, r.NamespacedName.Name, "Failed", oadpv1alpha1.UploadTestStatus{})
 }
 
 func (r *DataProtectionTestReconciler) updateDPTStatusToComplete(ctx context.Context) error {
-	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		latest := &oadpv1alpha1.DataProtectionTest{}
 		if err := r.Get(ctx, r.NamespacedName, latest); err != nil {
 			return err
@@ -604,4 +635,9 @@ func (r *DataProtectionTestReconciler) updateDPTStatusToComplete(ctx context.Con
 
 		return r.Status().Update(ctx, latest)
 	})
+	if err != nil {
+		return err
+	}
+	observeDataProtectionTest(r.NamespacedName.Namespace, r.NamespacedName.Name, "Complete", r.dpt.Status.UploadTest)
+	return nil
 }
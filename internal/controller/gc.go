@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+// ReconcileOrphanedResources deletes the BSLs, VSLs, registry secrets and ConfigMaps controlled by the DPA
+// that its spec no longer requires, like the BSL of a removed backup location or the ConfigMap of a disabled feature.
+// With the GC dry-run annotation, the orphaned objects are only reported in an event.
+func (r *DataProtectionApplicationReconciler) ReconcileOrphanedResources(log logr.Logger) (bool, error) {
+	orphans, err := r.findOrphanedResources()
+	if err != nil {
+		return false, err
+	}
+	if len(orphans) == 0 {
+		return true, nil
+	}
+
+	names := []string{}
+	if r.dpa.Annotations[oadpv1alpha1.GCDryRunAnnotation] == "true" {
+		for _, orphan := range orphans {
+			names = append(names, orphanName(r.Client, orphan))
+		}
+		r.EventRecorder.Event(r.dpa,
+			corev1.EventTypeNormal,
+			"OrphanedResourcesFound",
+			fmt.Sprintf("resources no longer required by the DPA spec, not deleted as the GC dry-run annotation is set: %s", strings.Join(names, ", ")),
+		)
+		return true, nil
+	}
+
+	for _, orphan := range orphans {
+		log.Info("Deleting orphaned resource", "name", orphan.GetName())
+		if err := r.Delete(r.Context, orphan); err != nil && !k8serror.IsNotFound(err) {
+			return false, err
+		}
+		names = append(names, orphanName(r.Client, orphan))
+	}
+	r.EventRecorder.Event(r.dpa,
+		corev1.EventTypeNormal,
+		"OrphanedResourcesDeleted",
+		fmt.Sprintf("deleted resources no longer required by the DPA spec: %s", strings.Join(names, ", ")),
+	)
+	return true, nil
+}
+
+// findOrphanedResources returns the objects controlled by the DPA that are not in the set of objects
+// the current spec requires
+func (r *DataProtectionApplicationReconciler) findOrphanedResources() ([]client.Object, error) {
+	dpa := r.dpa
+	listOptions := []client.ListOption{
+		client.InNamespace(r.NamespacedName.Namespace),
+		client.MatchingLabels{oadpv1alpha1.OadpOperatorLabel: "True"},
+	}
+	orphans := []client.Object{}
+
+	desiredBSLs := map[string]bool{}
+	for i, bslSpec := range dpa.Spec.BackupLocations {
+		desiredBSLs[getBackupStorageLocationName(r.NamespacedName.Name, i, bslSpec)] = true
+	}
+	desiredRegistrySecrets := map[string]bool{}
+	// BSLs of CloudStorage backup locations are not labelled, their controller reference is enough
+	bsls := &velerov1.BackupStorageLocationList{}
+	if err := r.List(r.Context, bsls, client.InNamespace(r.NamespacedName.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range bsls.Items {
+		bsl := &bsls.Items[i]
+		if !metav1.IsControlledBy(bsl, dpa) {
+			continue
+		}
+		if !desiredBSLs[bsl.Name] {
+			orphans = append(orphans, bsl)
+			continue
+		}
+		if dpa.BackupImages() && bsl.Spec.Provider != GCPProvider {
+			desiredRegistrySecrets[registrySecretName(bsl)] = true
+		}
+	}
+
+	desiredVSLs := map[string]bool{}
+	for i, vslSpec := range dpa.Spec.SnapshotLocations {
+		desiredVSLs[getVolumeSnapshotLocationName(r.NamespacedName.Name, i, vslSpec)] = true
+	}
+	vsls := &velerov1.VolumeSnapshotLocationList{}
+	if err := r.List(r.Context, vsls, listOptions...); err != nil {
+		return nil, err
+	}
+	for i := range vsls.Items {
+		if metav1.IsControlledBy(&vsls.Items[i], dpa) && !desiredVSLs[vsls.Items[i].Name] {
+			orphans = append(orphans, &vsls.Items[i])
+		}
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := r.List(r.Context, secrets, listOptions...); err != nil {
+		return nil, err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if metav1.IsControlledBy(secret, dpa) && strings.HasSuffix(secret.Name, "-registry-secret") && !desiredRegistrySecrets[secret.Name] {
+			orphans = append(orphans, secret)
+		}
+	}
+
+	desiredConfigMaps := map[string]bool{}
+	if dpa.Spec.Configuration != nil {
+		if isNodeAgentEnabled(dpa) && isNodeAgentCMRequired(dpa.Spec.Configuration.NodeAgent.NodeAgentConfigMapSettings) {
+			desiredConfigMaps[common.NodeAgentConfigMapPrefix+dpa.Name] = true
+		}
+		if isBackupRepositoryCmRequired(dpa.Spec.Configuration.NodeAgent) {
+			desiredConfigMaps[common.BackupRepoConfigMapPrefix+dpa.Name] = true
+		}
+		if isRepositoryMaintenanceCmRequired(dpa.Spec.Configuration) {
+			desiredConfigMaps[common.RepoMaintConfigMapPrefix+dpa.Name] = true
+		}
+	}
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.List(r.Context, configMaps, listOptions...); err != nil {
+		return nil, err
+	}
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if !metav1.IsControlledBy(configMap, dpa) || desiredConfigMaps[configMap.Name] {
+			continue
+		}
+		for _, prefix := range []string{common.NodeAgentConfigMapPrefix, common.BackupRepoConfigMapPrefix, common.RepoMaintConfigMapPrefix} {
+			if strings.HasPrefix(configMap.Name, prefix) {
+				orphans = append(orphans, configMap)
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return orphanName(r.Client, orphans[i]) < orphanName(r.Client, orphans[j])
+	})
+	return orphans, nil
+}
+
+// orphanName returns the kind and name of an orphaned object, for example "BackupStorageLocation/dpa-2"
+func orphanName(c client.Client, obj client.Object) string {
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return obj.GetName()
+	}
+	return gvk.Kind + "/" + obj.GetName()
+}
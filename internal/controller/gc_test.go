@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+func TestDPAReconciler_ReconcileOrphanedResources(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantDeleted bool
+		wantReason  string
+	}{
+		{
+			name:        "orphaned resources are deleted",
+			wantDeleted: true,
+			wantReason:  "OrphanedResourcesDeleted",
+		},
+		{
+			name:        "orphaned resources are reported in GC dry-run",
+			annotations: map[string]string{oadpv1alpha1.GCDryRunAnnotation: "true"},
+			wantReason:  "OrphanedResourcesFound",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpa := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        testDpaName,
+					Namespace:   testNamespaceName,
+					UID:         "dpa-uid",
+					Annotations: tt.annotations,
+				},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{},
+					},
+					BackupLocations: []oadpv1alpha1.BackupLocation{
+						{Velero: &velerov1.BackupStorageLocationSpec{Provider: AWSProvider}},
+					},
+					BackupImages: ptr.To(true),
+				},
+			}
+			managedLabels := map[string]string{oadpv1alpha1.OadpOperatorLabel: "True"}
+			objectMeta := func(name string) metav1.ObjectMeta {
+				return metav1.ObjectMeta{Name: name, Namespace: testNamespaceName, Labels: managedLabels}
+			}
+			desired := []client.Object{
+				&velerov1.BackupStorageLocation{ObjectMeta: objectMeta(testDpaName + "-1"), Spec: velerov1.BackupStorageLocationSpec{Provider: AWSProvider}},
+				&corev1.Secret{ObjectMeta: objectMeta("oadp-" + testDpaName + "-1-aws-registry-secret")},
+				&corev1.ConfigMap{ObjectMeta: objectMeta("oadp-registry-config")},
+			}
+			orphaned := []client.Object{
+				&velerov1.BackupStorageLocation{ObjectMeta: objectMeta(testDpaName + "-2"), Spec: velerov1.BackupStorageLocationSpec{Provider: AWSProvider}},
+				&velerov1.VolumeSnapshotLocation{ObjectMeta: objectMeta(testDpaName + "-1")},
+				&corev1.Secret{ObjectMeta: objectMeta("oadp-" + testDpaName + "-2-aws-registry-secret")},
+				&corev1.ConfigMap{ObjectMeta: objectMeta(common.NodeAgentConfigMapPrefix + testDpaName)},
+			}
+			notOwned := []client.Object{
+				&velerov1.BackupStorageLocation{ObjectMeta: objectMeta("user-bsl")},
+			}
+			schemeForFakeClient, err := getSchemeForFakeClient()
+			if err != nil {
+				t.Fatalf("error in creating scheme, likely programmer error")
+			}
+			objs := []client.Object{dpa}
+			for _, obj := range append(desired, orphaned...) {
+				if err := controllerutil.SetControllerReference(dpa, obj, schemeForFakeClient); err != nil {
+					t.Fatalf("unable to set controller reference: %v", err)
+				}
+				objs = append(objs, obj)
+			}
+			objs = append(objs, notOwned...)
+			fakeClient, err := getFakeClientFromObjects(objs...)
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			eventRecorder := record.NewFakeRecorder(10)
+			r := &DataProtectionApplicationReconciler{
+				Client:        fakeClient,
+				Scheme:        fakeClient.Scheme(),
+				Log:           logr.Discard(),
+				Context:       newContextForTest(),
+				EventRecorder: eventRecorder,
+				NamespacedName: types.NamespacedName{
+					Namespace: dpa.Namespace,
+					Name:      dpa.Name,
+				},
+				dpa: dpa,
+			}
+
+			if _, err := r.ReconcileOrphanedResources(r.Log); err != nil {
+				t.Fatalf("ReconcileOrphanedResources() error = %v", err)
+			}
+			for _, obj := range append(desired, notOwned...) {
+				if err := fakeClient.Get(r.Context, client.ObjectKeyFromObject(obj), obj); err != nil {
+					t.Errorf("ReconcileOrphanedResources() deleted %T %s: %v", obj, obj.GetName(), err)
+				}
+			}
+			for _, obj := range orphaned {
+				err := fakeClient.Get(r.Context, client.ObjectKeyFromObject(obj), obj)
+				if deleted := k8serror.IsNotFound(err); deleted != tt.wantDeleted {
+					t.Errorf("ReconcileOrphanedResources() deleted %T %s = %v, want %v", obj, obj.GetName(), deleted, tt.wantDeleted)
+				}
+			}
+			if len(eventRecorder.Events) != 1 {
+				t.Fatalf("ReconcileOrphanedResources() recorded %d events, want 1", len(eventRecorder.Events))
+			}
+			event := <-eventRecorder.Events
+			if !strings.Contains(event, tt.wantReason) || !strings.Contains(event, "BackupStorageLocation/"+testDpaName+"-2") {
+				t.Errorf("ReconcileOrphanedResources() event = %q, want %s listing the orphaned BSL", event, tt.wantReason)
+			}
+		})
+	}
+}
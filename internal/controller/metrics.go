@@ -0,0 +1,367 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const metricsNamespace = "oadp"
+
+var (
+	cloudStorageTotalSizeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "cloudstorage_total_size_bytes",
+			Help:      "Total size in bytes of the objects in the bucket of a CloudStorage",
+		},
+		[]string{"namespace", "name", "bucket"},
+	)
+	cloudStorageTotalObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "cloudstorage_total_objects",
+			Help:      "Number of objects in the bucket of a CloudStorage",
+		},
+		[]string{"namespace", "name", "bucket"},
+	)
+	cloudStoragePrefixSizeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "cloudstorage_prefix_size_bytes",
+			Help:      "Total size in bytes of the objects under a top-level prefix in the bucket of a CloudStorage",
+		},
+		[]string{"namespace", "name", "bucket", "prefix"},
+	)
+	cloudStoragePrefixObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "cloudstorage_prefix_objects",
+			Help:      "Number of objects under a top-level prefix in the bucket of a CloudStorage",
+		},
+		[]string{"namespace", "name", "bucket", "prefix"},
+	)
+	reconcileStepTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpa_reconcile_step_total",
+			Help:      "Number of times a DataProtectionApplication reconcile step ran or was skipped, by result",
+		},
+		[]string{"namespace", "name", "step", "result"},
+	)
+	reconcileStepDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpa_reconcile_step_duration_seconds",
+			Help:      "Duration in seconds of a DataProtectionApplication reconcile step",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"namespace", "name", "step"},
+	)
+	dpaReconcileTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpa_reconcile_total",
+			Help:      "Number of DataProtectionApplication reconciles, by the reason of the Reconciled condition",
+		},
+		[]string{"namespace", "name", "result"},
+	)
+	dpaValidationErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpa_validation_errors_total",
+			Help:      "Number of DataProtectionApplication reconciles that failed validation",
+		},
+		[]string{"namespace", "name"},
+	)
+	dpaStatusCondition = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpa_status_condition",
+			Help:      "Status of a DataProtectionApplication condition, 1 if True and 0 otherwise",
+		},
+		[]string{"namespace", "name", "type"},
+	)
+	dpaManagedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpa_managed_objects",
+			Help:      "Number of objects controlled by a DataProtectionApplication, by kind",
+		},
+		[]string{"namespace", "name", "kind"},
+	)
+	credentialHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "credential_healthy",
+			Help:      "Health of a credentials file of the locations of a DataProtectionApplication, 1 if healthy and 0 otherwise",
+		},
+		[]string{"namespace", "name", "secret", "key", "reason"},
+	)
+	credentialExpirationTimestampSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "credential_expiration_timestamp_seconds",
+			Help:      "Expiration time of a credentials file of the locations of a DataProtectionApplication, when the provider exposes it",
+		},
+		[]string{"namespace", "name", "secret", "key"},
+	)
+	backupReplicationPendingBackups = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "backup_replication_pending_backups",
+			Help:      "Number of completed backups of a replicated backup location not copied to its secondary bucket yet",
+		},
+		[]string{"namespace", "name", "location"},
+	)
+	backupReplicationLagSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "backup_replication_lag_seconds",
+			Help:      "Replication lag in seconds of the oldest backup of a replicated backup location not copied yet, 0 when every backup is copied",
+		},
+		[]string{"namespace", "name", "location"},
+	)
+	backupReplicationLastSuccessTimestampSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "backup_replication_last_success_timestamp_seconds",
+			Help:      "Time of the last replication of a backup location copying every completed backup",
+		},
+		[]string{"namespace", "name", "location"},
+	)
+	dptRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpt_runs_total",
+			Help:      "Number of finished DataProtectionTest runs, by phase",
+		},
+		[]string{"namespace", "name", "phase"},
+	)
+	dptUploadSpeedMbps = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "dpt_upload_speed_mbps",
+			Help:      "Upload speed in Mbps measured by the last successful DataProtectionTest",
+		},
+		[]string{"namespace", "name"},
+	)
+	restoreVerificationRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "restore_verification_runs_total",
+			Help:      "Number of finished restore drills of a RestoreVerification, by result",
+		},
+		[]string{"namespace", "name", "result"},
+	)
+	restoreVerificationLastResult = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "restore_verification_last_result",
+			Help:      "Result of the last restore drill of a RestoreVerification, 1 if it passed and 0 otherwise",
+		},
+		[]string{"namespace", "name"},
+	)
+	restoreVerificationRTOSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "restore_verification_rto_seconds",
+			Help:      "Recovery time in seconds of the last restore drill of a RestoreVerification that passed",
+		},
+		[]string{"namespace", "name"},
+	)
+	restoreVerificationLastSuccessTimestampSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "restore_verification_last_success_timestamp_seconds",
+			Help:      "Start time of the last restore drill of a RestoreVerification that passed",
+		},
+		[]string{"namespace", "name"},
+	)
+)
+
+// operatorMetrics are the metrics exported by the operator
+var operatorMetrics = []prometheus.Collector{
+	cloudStorageTotalSizeBytes,
+	cloudStorageTotalObjects,
+	cloudStoragePrefixSizeBytes,
+	cloudStoragePrefixObjects,
+	reconcileStepTotal,
+	reconcileStepDurationSeconds,
+	dpaReconcileTotal,
+	dpaValidationErrorsTotal,
+	dpaStatusCondition,
+	dpaManagedObjects,
+	credentialHealthy,
+	credentialExpirationTimestampSeconds,
+	backupReplicationPendingBackups,
+	backupReplicationLagSeconds,
+	backupReplicationLastSuccessTimestampSeconds,
+	dptRunsTotal,
+	dptUploadSpeedMbps,
+	restoreVerificationRunsTotal,
+	restoreVerificationLastResult,
+	restoreVerificationRTOSeconds,
+	restoreVerificationLastSuccessTimestampSeconds,
+}
+
+func init() {
+	metrics.Registry.MustRegister(operatorMetrics...)
+}
+
+// setCloudStorageUsageMetrics exports the usage in the CloudStorage status
+func setCloudStorageUsageMetrics(bucket *oadpv1alpha1.CloudStorage) {
+	deleteCloudStorageUsageMetrics(bucket.Namespace, bucket.Name)
+	if bucket.Status.Usage == nil {
+		return
+	}
+	usage := bucket.Status.Usage
+	cloudStorageTotalSizeBytes.WithLabelValues(bucket.Namespace, bucket.Name, bucket.Spec.Name).Set(float64(usage.TotalSizeBytes))
+	cloudStorageTotalObjects.WithLabelValues(bucket.Namespace, bucket.Name, bucket.Spec.Name).Set(float64(usage.ObjectCount))
+	for _, prefixUsage := range usage.Prefixes {
+		cloudStoragePrefixSizeBytes.WithLabelValues(bucket.Namespace, bucket.Name, bucket.Spec.Name, prefixUsage.Prefix).Set(float64(prefixUsage.SizeBytes))
+		cloudStoragePrefixObjects.WithLabelValues(bucket.Namespace, bucket.Name, bucket.Spec.Name, prefixUsage.Prefix).Set(float64(prefixUsage.ObjectCount))
+	}
+}
+
+// deleteCloudStorageUsageMetrics removes every usage series of a CloudStorage
+func deleteCloudStorageUsageMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	cloudStorageTotalSizeBytes.DeletePartialMatch(labels)
+	cloudStorageTotalObjects.DeletePartialMatch(labels)
+	cloudStoragePrefixSizeBytes.DeletePartialMatch(labels)
+	cloudStoragePrefixObjects.DeletePartialMatch(labels)
+}
+
+// observeReconcileSteps exports the results of the steps of a DPA reconcile
+func observeReconcileSteps(namespace, name string, steps []oadpv1alpha1.ReconcileStepStatus) {
+	for _, step := range steps {
+		reconcileStepTotal.WithLabelValues(namespace, name, step.Name, string(step.Result)).Inc()
+		if step.Name == validateStepName && step.Result == oadpv1alpha1.ReconcileStepResultFailed {
+			dpaValidationErrorsTotal.WithLabelValues(namespace, name).Inc()
+		}
+		if step.Result != oadpv1alpha1.ReconcileStepResultSkipped {
+			reconcileStepDurationSeconds.WithLabelValues(namespace, name, step.Name).Observe(step.Duration.Seconds())
+		}
+	}
+}
+
+// setDPAStatusMetrics exports the outcome of a DPA reconcile and the status of the DPA conditions
+func setDPAStatusMetrics(dpa *oadpv1alpha1.DataProtectionApplication) {
+	if reconciled := apimeta.FindStatusCondition(dpa.Status.Conditions, oadpv1alpha1.ConditionReconciled); reconciled != nil {
+		dpaReconcileTotal.WithLabelValues(dpa.Namespace, dpa.Name, reconciled.Reason).Inc()
+	}
+	dpaStatusCondition.DeletePartialMatch(prometheus.Labels{"namespace": dpa.Namespace, "name": dpa.Name})
+	for _, condition := range dpa.Status.Conditions {
+		value := 0.0
+		if condition.Status == metav1.ConditionTrue {
+			value = 1
+		}
+		dpaStatusCondition.WithLabelValues(dpa.Namespace, dpa.Name, condition.Type).Set(value)
+	}
+}
+
+// setDPAManagedObjectMetrics exports the number of objects of each kind controlled by a DPA
+func setDPAManagedObjectMetrics(namespace, name string, counts map[string]int) {
+	for kind, count := range counts {
+		dpaManagedObjects.WithLabelValues(namespace, name, kind).Set(float64(count))
+	}
+}
+
+// deleteDPAMetrics removes every series of a DPA
+func deleteDPAMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	reconcileStepTotal.DeletePartialMatch(labels)
+	reconcileStepDurationSeconds.DeletePartialMatch(labels)
+	dpaReconcileTotal.DeletePartialMatch(labels)
+	dpaValidationErrorsTotal.DeletePartialMatch(labels)
+	dpaStatusCondition.DeletePartialMatch(labels)
+	dpaManagedObjects.DeletePartialMatch(labels)
+	deleteCredentialHealthMetrics(namespace, name)
+}
+
+// setCredentialHealthMetrics exports the health of a credentials file of a DPA
+func setCredentialHealthMetrics(namespace, name, secret, key string, health credentialHealth) {
+	value := 0.0
+	if health.reason == oadpv1alpha1.CredentialsReasonHealthy {
+		value = 1
+	}
+	credentialHealthy.WithLabelValues(namespace, name, secret, key, health.reason).Set(value)
+	if !health.expiration.IsZero() {
+		credentialExpirationTimestampSeconds.WithLabelValues(namespace, name, secret, key).Set(float64(health.expiration.Unix()))
+	}
+}
+
+// deleteCredentialHealthMetrics removes every credential series of a DPA
+func deleteCredentialHealthMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	credentialHealthy.DeletePartialMatch(labels)
+	credentialExpirationTimestampSeconds.DeletePartialMatch(labels)
+}
+
+// setBackupReplicationMetrics exports the replication status of the backup locations of a DPA
+func setBackupReplicationMetrics(namespace, name string, statuses []oadpv1alpha1.BackupLocationReplicationStatus) {
+	deleteBackupReplicationMetrics(namespace, name)
+	for _, status := range statuses {
+		backupReplicationPendingBackups.WithLabelValues(namespace, name, status.Name).Set(float64(status.PendingBackups))
+		lag := 0.0
+		for _, backup := range status.Backups {
+			if backup.ReplicationTime == nil && backup.Lag != nil {
+				lag = max(lag, backup.Lag.Seconds())
+			}
+		}
+		backupReplicationLagSeconds.WithLabelValues(namespace, name, status.Name).Set(lag)
+		if status.LastSuccessTime != nil {
+			backupReplicationLastSuccessTimestampSeconds.WithLabelValues(namespace, name, status.Name).Set(float64(status.LastSuccessTime.Unix()))
+		}
+	}
+}
+
+// deleteBackupReplicationMetrics removes every replication series of a DPA
+func deleteBackupReplicationMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	backupReplicationPendingBackups.DeletePartialMatch(labels)
+	backupReplicationLagSeconds.DeletePartialMatch(labels)
+	backupReplicationLastSuccessTimestampSeconds.DeletePartialMatch(labels)
+}
+
+// observeDataProtectionTest exports the result of a finished DataProtectionTest run
+func observeDataProtectionTest(namespace, name, phase string, uploadTest oadpv1alpha1.UploadTestStatus) {
+	dptRunsTotal.WithLabelValues(namespace, name, phase).Inc()
+	if uploadTest.Success {
+		dptUploadSpeedMbps.WithLabelValues(namespace, name).Set(float64(uploadTest.SpeedMbps))
+	}
+}
+
+// deleteDataProtectionTestMetrics removes every series of a DataProtectionTest
+func deleteDataProtectionTestMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	dptRunsTotal.DeletePartialMatch(labels)
+	dptUploadSpeedMbps.DeletePartialMatch(labels)
+}
+
+// observeRestoreVerification exports the result of a finished restore drill
+func observeRestoreVerification(namespace, name string, run *oadpv1alpha1.RestoreVerificationRun) {
+	restoreVerificationRunsTotal.WithLabelValues(namespace, name, string(run.Result)).Inc()
+	if run.Result != oadpv1alpha1.RestoreVerificationResultPassed {
+		restoreVerificationLastResult.WithLabelValues(namespace, name).Set(0)
+		return
+	}
+	restoreVerificationLastResult.WithLabelValues(namespace, name).Set(1)
+	if run.RTO != nil {
+		restoreVerificationRTOSeconds.WithLabelValues(namespace, name).Set(run.RTO.Seconds())
+	}
+	restoreVerificationLastSuccessTimestampSeconds.WithLabelValues(namespace, name).Set(float64(run.StartTime.Unix()))
+}
+
+// deleteRestoreVerificationMetrics removes every series of a RestoreVerification
+func deleteRestoreVerificationMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	restoreVerificationRunsTotal.DeletePartialMatch(labels)
+	restoreVerificationLastResult.DeletePartialMatch(labels)
+	restoreVerificationRTOSeconds.DeletePartialMatch(labels)
+	restoreVerificationLastSuccessTimestampSeconds.DeletePartialMatch(labels)
+}
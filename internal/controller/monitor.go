@@ -2,12 +2,23 @@ package controller
 
 import (
 	"fmt"
+	"maps"
+	"time"
 
 	"github.com/go-logr/logr"
+	monitor "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
 )
 
 func (r *DataProtectionApplicationReconciler) ReconcileVeleroMetricsSVC(log logr.Logger) (bool, error) {
@@ -65,3 +76,289 @@ func (r *DataProtectionApplicationReconciler) updateVeleroMetricsSVC(svc *corev1
 	svc.Labels = getDpaAppLabels(r.dpa)
 	return nil
 }
+
+const (
+	veleroServiceMonitorName    = "openshift-adp-velero-metrics-sm"
+	nodeAgentMetricsSVCName     = "openshift-adp-node-agent-metrics-svc"
+	nodeAgentServiceMonitorName = "openshift-adp-node-agent-metrics-sm"
+	nonAdminMetricsSVCName      = "openshift-adp-non-admin-metrics-svc"
+	nonAdminServiceMonitorName  = "openshift-adp-non-admin-metrics-sm"
+	prometheusRuleName          = "openshift-adp-alerts"
+
+	// nodeAgentMetricsPort is the default metrics address port of the velero node-agent server
+	nodeAgentMetricsPort = int32(8085)
+	// nonAdminMetricsPort is the default metrics bind address port of the non-admin controller
+	nonAdminMetricsPort = int32(8080)
+
+	defaultStaleScheduleThreshold = 25 * time.Hour
+)
+
+// metricsTarget is a component scraped by a ServiceMonitor
+type metricsTarget struct {
+	serviceMonitorName string
+	enabled            bool
+	// serviceName is the metrics Service of the component, created along the ServiceMonitor unless it is managed elsewhere
+	serviceName   string
+	serviceLabels map[string]string
+	podSelector   map[string]string
+	port          int32
+	createService bool
+}
+
+func (r *DataProtectionApplicationReconciler) metricsTargets() []metricsTarget {
+	enabled := r.dpa.Spec.Monitoring != nil && r.dpa.Spec.Monitoring.ServiceMonitors
+	nodeAgentServiceLabels := getDpaAppLabels(r.dpa)
+	nodeAgentServiceLabels["app.kubernetes.io/component"] = common.NodeAgent
+	return []metricsTarget{
+		{
+			serviceMonitorName: veleroServiceMonitorName,
+			enabled:            enabled,
+			serviceName:        "openshift-adp-velero-metrics-svc",
+			serviceLabels:      getDpaAppLabels(r.dpa),
+		},
+		{
+			serviceMonitorName: nodeAgentServiceMonitorName,
+			enabled:            enabled && isNodeAgentEnabled(r.dpa),
+			serviceName:        nodeAgentMetricsSVCName,
+			serviceLabels:      nodeAgentServiceLabels,
+			podSelector:        nodeAgentMatchLabels,
+			port:               nodeAgentMetricsPort,
+			createService:      true,
+		},
+		{
+			serviceMonitorName: nonAdminServiceMonitorName,
+			enabled:            enabled && r.checkNonAdminEnabled(),
+			serviceName:        nonAdminMetricsSVCName,
+			serviceLabels: map[string]string{
+				controlPlaneKey:                nonAdminObjectName,
+				oadpv1alpha1.OadpOperatorLabel: "True",
+			},
+			podSelector:   controlPlaneLabel,
+			port:          nonAdminMetricsPort,
+			createService: true,
+		},
+	}
+}
+
+// ReconcileServiceMonitors creates the ServiceMonitors of Velero, NodeAgent and the non-admin controller
+// when spec.monitoring.serviceMonitors is set, and deletes them otherwise
+func (r *DataProtectionApplicationReconciler) ReconcileServiceMonitors(log logr.Logger) (bool, error) {
+	for _, target := range r.metricsTargets() {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      target.serviceName,
+				Namespace: r.NamespacedName.Namespace,
+			},
+		}
+		serviceMonitor := &monitor.ServiceMonitor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      target.serviceMonitorName,
+				Namespace: r.NamespacedName.Namespace,
+			},
+		}
+
+		if !target.enabled {
+			if err := r.deleteMonitoringObject(serviceMonitor); err != nil {
+				return false, err
+			}
+			if target.createService {
+				if err := r.deleteMonitoringObject(svc); err != nil {
+					return false, err
+				}
+			}
+			continue
+		}
+
+		if target.createService {
+			_, err := controllerutil.CreateOrPatch(r.Context, r.Client, svc, func() error {
+				svc.Labels = maps.Clone(target.serviceLabels)
+				svc.Spec.Selector = maps.Clone(target.podSelector)
+				svc.Spec.Type = corev1.ServiceTypeClusterIP
+				svc.Spec.Ports = []corev1.ServicePort{
+					{
+						Protocol:   corev1.ProtocolTCP,
+						Name:       "monitoring",
+						Port:       target.port,
+						TargetPort: intstr.FromInt32(target.port),
+					},
+				}
+				return controllerutil.SetControllerReference(r.dpa, svc, r.Scheme)
+			})
+			if err != nil {
+				return false, err
+			}
+		}
+
+		op, err := controllerutil.CreateOrPatch(r.Context, r.Client, serviceMonitor, func() error {
+			serviceMonitor.Labels = getDpaAppLabels(r.dpa)
+			serviceMonitor.Spec.Selector = metav1.LabelSelector{MatchLabels: maps.Clone(target.serviceLabels)}
+			serviceMonitor.Spec.NamespaceSelector = monitor.NamespaceSelector{MatchNames: []string{r.NamespacedName.Namespace}}
+			serviceMonitor.Spec.Endpoints = []monitor.Endpoint{
+				{
+					Port:     "monitoring",
+					Interval: "30s",
+				},
+			}
+			return controllerutil.SetControllerReference(r.dpa, serviceMonitor, r.Scheme)
+		})
+		if err != nil {
+			return false, err
+		}
+		if op == controllerutil.OperationResultCreated || op == controllerutil.OperationResultUpdated {
+			r.EventRecorder.Event(serviceMonitor,
+				corev1.EventTypeNormal,
+				"ServiceMonitorReconciled",
+				fmt.Sprintf("performed %s on service monitor %s/%s", op, serviceMonitor.Namespace, serviceMonitor.Name),
+			)
+		}
+	}
+	return true, nil
+}
+
+// ReconcilePrometheusRule creates the PrometheusRule with the data protection alerts
+// when spec.monitoring.prometheusRule is set, and deletes it otherwise
+func (r *DataProtectionApplicationReconciler) ReconcilePrometheusRule(log logr.Logger) (bool, error) {
+	rule := &monitor.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prometheusRuleName,
+			Namespace: r.NamespacedName.Namespace,
+		},
+	}
+	if r.dpa.Spec.Monitoring == nil || !r.dpa.Spec.Monitoring.PrometheusRule {
+		return true, r.deleteMonitoringObject(rule)
+	}
+
+	op, err := controllerutil.CreateOrPatch(r.Context, r.Client, rule, func() error {
+		rule.Labels = getDpaAppLabels(r.dpa)
+		rule.Spec.Groups = []monitor.RuleGroup{
+			{
+				Name:  "oadp",
+				Rules: r.alertingRules(),
+			},
+		}
+		return controllerutil.SetControllerReference(r.dpa, rule, r.Scheme)
+	})
+	if err != nil {
+		return false, err
+	}
+	if op == controllerutil.OperationResultCreated || op == controllerutil.OperationResultUpdated {
+		r.EventRecorder.Event(rule,
+			corev1.EventTypeNormal,
+			"PrometheusRuleReconciled",
+			fmt.Sprintf("performed %s on prometheus rule %s/%s", op, rule.Namespace, rule.Name),
+		)
+	}
+	return true, nil
+}
+
+// alertingRules returns the alerts on the Velero metrics and on the DPA status conditions exported by the operator
+func (r *DataProtectionApplicationReconciler) alertingRules() []monitor.Rule {
+	namespace := r.NamespacedName.Namespace
+	staleScheduleThreshold := defaultStaleScheduleThreshold
+	if r.dpa.Spec.Monitoring.StaleScheduleThreshold != nil {
+		staleScheduleThreshold = r.dpa.Spec.Monitoring.StaleScheduleThreshold.Duration
+	}
+	conditionExpr := func(conditionType string) intstr.IntOrString {
+		return intstr.FromString(fmt.Sprintf(`%s_dpa_status_condition{namespace=%q,name=%q,type=%q} == 0`, metricsNamespace, namespace, r.dpa.Name, conditionType))
+	}
+
+	rules := []monitor.Rule{
+		{
+			Alert:  "OADPBackupFailed",
+			Expr:   intstr.FromString(fmt.Sprintf(`increase(velero_backup_failure_total{namespace=%q}[1h]) > 0`, namespace)),
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Velero backups failed",
+				"description": fmt.Sprintf("Backups of schedule {{ $labels.schedule }} failed in namespace %s in the last hour.", namespace),
+			},
+		},
+		{
+			Alert:  "OADPBackupStorageLocationUnavailable",
+			Expr:   conditionExpr(oadpv1alpha1.ConditionBackupStorageLocationsAvailable),
+			For:    "15m",
+			Labels: map[string]string{"severity": "critical"},
+			Annotations: map[string]string{
+				"summary":     "BackupStorageLocations are unavailable",
+				"description": fmt.Sprintf("A BackupStorageLocation of DataProtectionApplication %s/%s is unavailable, backups to it fail.", namespace, r.dpa.Name),
+			},
+		},
+		{
+			Alert:  "OADPCredentialsUnhealthy",
+			Expr:   conditionExpr(oadpv1alpha1.ConditionCredentialsHealthy),
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Credentials of backup or snapshot locations are unhealthy",
+				"description": fmt.Sprintf("Credentials of DataProtectionApplication %s/%s are invalid, expired, expiring or lack permissions, see its CredentialsHealthy condition.", namespace, r.dpa.Name),
+			},
+		},
+	}
+	if isNodeAgentEnabled(r.dpa) {
+		rules = append(rules, monitor.Rule{
+			Alert:  "OADPNodeAgentNotReady",
+			Expr:   conditionExpr(oadpv1alpha1.ConditionNodeAgentReady),
+			For:    "15m",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "NodeAgent pods are not ready",
+				"description": fmt.Sprintf("NodeAgent pods of DataProtectionApplication %s/%s are not ready, file system backups and data movements on their nodes fail.", namespace, r.dpa.Name),
+			},
+		})
+	}
+	rules = append(rules, monitor.Rule{
+		Alert:  "OADPScheduleStale",
+		Expr:   intstr.FromString(fmt.Sprintf(`time() - velero_backup_last_successful_timestamp{namespace=%q,schedule!=""} > %d`, namespace, int64(staleScheduleThreshold.Seconds()))),
+		Labels: map[string]string{"severity": "warning"},
+		Annotations: map[string]string{
+			"summary":     "Schedule has no recent successful backup",
+			"description": fmt.Sprintf("Schedule {{ $labels.schedule }} in namespace %s has had no successful backup for more than %s.", namespace, staleScheduleThreshold),
+		},
+	}, monitor.Rule{
+		Alert:  "OADPRestoreVerificationFailed",
+		Expr:   intstr.FromString(fmt.Sprintf(`%s_restore_verification_last_result{namespace=%q} == 0`, metricsNamespace, namespace)),
+		Labels: map[string]string{"severity": "warning"},
+		Annotations: map[string]string{
+			"summary":     "Restore drill failed",
+			"description": "The last restore drill of RestoreVerification {{ $labels.name }} failed, the backups it verifies may not be restorable.",
+		},
+	})
+	return rules
+}
+
+// deleteMonitoringObject deletes obj if it exists, ignoring a missing monitoring.coreos.com API
+func (r *DataProtectionApplicationReconciler) deleteMonitoringObject(obj client.Object) error {
+	if err := r.Delete(r.Context, obj); err != nil && !k8serror.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+		return err
+	}
+	return nil
+}
+
+// managedObjectCounts returns the number of objects of each kind controlled by the DPA
+func (r *DataProtectionApplicationReconciler) managedObjectCounts() (map[string]int, error) {
+	lists := map[string]client.ObjectList{
+		"BackupStorageLocation":  &velerov1.BackupStorageLocationList{},
+		"VolumeSnapshotLocation": &velerov1.VolumeSnapshotLocationList{},
+		"Deployment":             &appsv1.DeploymentList{},
+		"DaemonSet":              &appsv1.DaemonSetList{},
+		"Service":                &corev1.ServiceList{},
+		"ConfigMap":              &corev1.ConfigMapList{},
+		"Secret":                 &corev1.SecretList{},
+	}
+	counts := map[string]int{}
+	for kind, list := range lists {
+		if err := r.List(r.Context, list, client.InNamespace(r.NamespacedName.Namespace)); err != nil {
+			return nil, err
+		}
+		items, err := apimeta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		counts[kind] = 0
+		for _, item := range items {
+			if obj, ok := item.(client.Object); ok && metav1.IsControlledBy(obj, r.dpa) {
+				counts[kind]++
+			}
+		}
+	}
+	return counts, nil
+}
@@ -4,19 +4,17 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	monitor "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
-	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -24,32 +22,13 @@ import (
 	"github.com/openshift/oadp-operator/pkg/common"
 )
 
-func getSchemeForFakeClientForMonitor() (*runtime.Scheme, error) {
-	err := oadpv1alpha1.AddToScheme(scheme.Scheme)
-	if err != nil {
-		return nil, err
-	}
-
-	err = velerov1.AddToScheme(scheme.Scheme)
-	if err != nil {
-		return nil, err
-	}
-
-	err = monitor.AddToScheme(scheme.Scheme)
-	if err != nil {
-		return nil, err
-	}
-
-	err = rbacv1.AddToScheme(scheme.Scheme)
+func getFakeClientFromObjectsForMonitor(objs ...client.Object) (client.WithWatch, error) {
+	schemeForFakeClient, err := getSchemeForFakeClient()
 	if err != nil {
 		return nil, err
 	}
 
-	return scheme.Scheme, nil
-}
-
-func getFakeClientFromObjectsForMonitor(objs ...client.Object) (client.WithWatch, error) {
-	schemeForFakeClient, err := getSchemeForFakeClientForMonitor()
+	err = monitor.AddToScheme(schemeForFakeClient)
 	if err != nil {
 		return nil, err
 	}
@@ -172,3 +151,148 @@ func TestDPAReconciler_updateVeleroMetricsSVC(t *testing.T) {
 		})
 	}
 }
+
+func TestDPAReconciler_ReconcileServiceMonitors(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDpaName,
+			Namespace: testNamespaceName,
+		},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{},
+				NodeAgent: &oadpv1alpha1.NodeAgentConfig{
+					NodeAgentCommonFields: oadpv1alpha1.NodeAgentCommonFields{Enable: ptr.To(true)},
+				},
+			},
+			Monitoring: &oadpv1alpha1.Monitoring{ServiceMonitors: true},
+		},
+	}
+	fakeClient, err := getFakeClientFromObjectsForMonitor(dpa)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:  fakeClient,
+		Scheme:  fakeClient.Scheme(),
+		Log:     logr.Discard(),
+		Context: newContextForTest(),
+		NamespacedName: types.NamespacedName{
+			Namespace: dpa.Namespace,
+			Name:      dpa.Name,
+		},
+		EventRecorder: record.NewFakeRecorder(10),
+		dpa:           dpa,
+	}
+
+	if _, err := r.ReconcileServiceMonitors(r.Log); err != nil {
+		t.Fatalf("ReconcileServiceMonitors() error = %v", err)
+	}
+	for name, want := range map[string]bool{
+		veleroServiceMonitorName:    true,
+		nodeAgentServiceMonitorName: true,
+		nonAdminServiceMonitorName:  false,
+	} {
+		err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: name}, &monitor.ServiceMonitor{})
+		if exists := err == nil; exists != want {
+			t.Errorf("ReconcileServiceMonitors() created service monitor %s = %v, want %v", name, exists, want)
+		}
+	}
+	nodeAgentSVC := &corev1.Service{}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: nodeAgentMetricsSVCName}, nodeAgentSVC); err != nil {
+		t.Fatalf("ReconcileServiceMonitors() did not create the node-agent metrics service: %v", err)
+	}
+	if !reflect.DeepEqual(nodeAgentSVC.Spec.Selector, nodeAgentMatchLabels) {
+		t.Errorf("ReconcileServiceMonitors() node-agent metrics service selector = %v, want %v", nodeAgentSVC.Spec.Selector, nodeAgentMatchLabels)
+	}
+
+	dpa.Spec.Monitoring = nil
+	if _, err := r.ReconcileServiceMonitors(r.Log); err != nil {
+		t.Fatalf("ReconcileServiceMonitors() error = %v", err)
+	}
+	serviceMonitors := &monitor.ServiceMonitorList{}
+	if err := fakeClient.List(r.Context, serviceMonitors, client.InNamespace(testNamespaceName)); err != nil {
+		t.Fatalf("unable to list service monitors: %v", err)
+	}
+	if len(serviceMonitors.Items) != 0 {
+		t.Errorf("ReconcileServiceMonitors() kept %d service monitors after monitoring was disabled", len(serviceMonitors.Items))
+	}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: nodeAgentMetricsSVCName}, &corev1.Service{}); err == nil {
+		t.Errorf("ReconcileServiceMonitors() kept the node-agent metrics service after monitoring was disabled")
+	}
+}
+
+func TestDPAReconciler_ReconcilePrometheusRule(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDpaName,
+			Namespace: testNamespaceName,
+		},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{},
+			},
+			Monitoring: &oadpv1alpha1.Monitoring{
+				PrometheusRule:         true,
+				StaleScheduleThreshold: &metav1.Duration{Duration: 2 * time.Hour},
+			},
+		},
+	}
+	fakeClient, err := getFakeClientFromObjectsForMonitor(dpa)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:  fakeClient,
+		Scheme:  fakeClient.Scheme(),
+		Log:     logr.Discard(),
+		Context: newContextForTest(),
+		NamespacedName: types.NamespacedName{
+			Namespace: dpa.Namespace,
+			Name:      dpa.Name,
+		},
+		EventRecorder: record.NewFakeRecorder(10),
+		dpa:           dpa,
+	}
+
+	if _, err := r.ReconcilePrometheusRule(r.Log); err != nil {
+		t.Fatalf("ReconcilePrometheusRule() error = %v", err)
+	}
+	rule := &monitor.PrometheusRule{}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: prometheusRuleName}, rule); err != nil {
+		t.Fatalf("ReconcilePrometheusRule() did not create the prometheus rule: %v", err)
+	}
+	alerts := map[string]string{}
+	for _, group := range rule.Spec.Groups {
+		for _, rule := range group.Rules {
+			alerts[rule.Alert] = rule.Expr.String()
+		}
+	}
+	// NodeAgent is disabled, so its readiness is not alerted on
+	wantAlerts := []string{"OADPBackupFailed", "OADPBackupStorageLocationUnavailable", "OADPCredentialsUnhealthy", "OADPScheduleStale", "OADPRestoreVerificationFailed"}
+	if len(alerts) != len(wantAlerts) {
+		t.Errorf("ReconcilePrometheusRule() alerts = %v, want %v", alerts, wantAlerts)
+	}
+	for _, alert := range wantAlerts {
+		if _, ok := alerts[alert]; !ok {
+			t.Errorf("ReconcilePrometheusRule() is missing alert %s", alert)
+		}
+	}
+	if want := `oadp_dpa_status_condition{namespace="test-ns",name="test-DPA-CR",type="BackupStorageLocationsAvailable"} == 0`; alerts["OADPBackupStorageLocationUnavailable"] != want {
+		t.Errorf("ReconcilePrometheusRule() OADPBackupStorageLocationUnavailable expr = %s, want %s", alerts["OADPBackupStorageLocationUnavailable"], want)
+	}
+	if want := `oadp_restore_verification_last_result{namespace="test-ns"} == 0`; alerts["OADPRestoreVerificationFailed"] != want {
+		t.Errorf("ReconcilePrometheusRule() OADPRestoreVerificationFailed expr = %s, want %s", alerts["OADPRestoreVerificationFailed"], want)
+	}
+	if want := `time() - velero_backup_last_successful_timestamp{namespace="test-ns",schedule!=""} > 7200`; alerts["OADPScheduleStale"] != want {
+		t.Errorf("ReconcilePrometheusRule() OADPScheduleStale expr = %s, want %s", alerts["OADPScheduleStale"], want)
+	}
+
+	dpa.Spec.Monitoring = nil
+	if _, err := r.ReconcilePrometheusRule(r.Log); err != nil {
+		t.Fatalf("ReconcilePrometheusRule() error = %v", err)
+	}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: prometheusRuleName}, rule); err == nil {
+		t.Errorf("ReconcilePrometheusRule() kept the prometheus rule after it was disabled")
+	}
+}
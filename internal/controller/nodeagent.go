@@ -14,6 +14,7 @@ import (
 	"github.com/vmware-tanzu/velero/pkg/util/kube"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -209,7 +210,14 @@ func (r *DataProtectionApplicationReconciler) ReconcileNodeAgentDaemonset(log lo
 			return false, err
 		}
 		// no errors means there is already an existing DaemonSet.
-		// TODO: Check if NodeAgent is in use, a backup is running, so don't blindly delete NodeAgent.
+		// Keep it while operations are in progress, as they may use NodeAgent.
+		deferred, err := r.deferRollout(common.NodeAgent)
+		if err != nil {
+			return false, err
+		}
+		if deferred {
+			return true, nil
+		}
 		if err := r.Delete(deleteContext, ds, &client.DeleteOptions{PropagationPolicy: ptr.To(metav1.DeletePropagationForeground)}); err != nil {
 			// TODO: Come back and fix event recording to be consistent
 			r.EventRecorder.Event(ds, corev1.EventTypeNormal, "DeleteDaemonSetFailed", "Got DaemonSet to delete but could not delete err:"+err.Error())
@@ -221,6 +229,10 @@ func (r *DataProtectionApplicationReconciler) ReconcileNodeAgentDaemonset(log lo
 	}
 
 	op, err := controllerutil.CreateOrPatch(r.Context, r.Client, ds, func() error {
+		var liveTemplate *corev1.PodTemplateSpec
+		if !ds.ObjectMeta.CreationTimestamp.IsZero() {
+			liveTemplate = ds.Spec.Template.DeepCopy()
+		}
 		// Deployment selector is immutable so we set this value only if
 		// a new object is going to be created
 		if ds.ObjectMeta.CreationTimestamp.IsZero() {
@@ -257,6 +269,17 @@ func (r *DataProtectionApplicationReconciler) ReconcileNodeAgentDaemonset(log lo
 			affinity := kube.ToSystemAffinity(veleroAffinityStruct)
 			ds.Spec.Template.Spec.Affinity = affinity
 		}
+		// a template change restarts the NodeAgent pods, keep the running template while operations are in progress
+		if liveTemplate != nil && !equality.Semantic.DeepEqual(*liveTemplate, ds.Spec.Template) {
+			deferred, err := r.deferRollout(common.NodeAgent)
+			if err != nil {
+				return err
+			}
+			if deferred {
+				log.Info("Deferring NodeAgent DaemonSet rollout until in-progress operations finish")
+				ds.Spec.Template = *liveTemplate
+			}
+		}
 		return nil
 	})
 
@@ -265,12 +288,22 @@ func (r *DataProtectionApplicationReconciler) ReconcileNodeAgentDaemonset(log lo
 			cause, isStatusCause := errors.StatusCause(err, metav1.CauseTypeFieldValueInvalid)
 			if isStatusCause && cause.Field == "spec.selector" {
 				// recreate deployment
-				// TODO: check for in-progress backup/restore to wait for it to finish
+				deferred, err := r.deferRollout(common.NodeAgent)
+				if err != nil {
+					return false, err
+				}
+				if deferred {
+					return true, nil
+				}
 				log.Info("Found immutable selector from previous daemonset, recreating NodeAgent daemonset")
-				err := r.Delete(r.Context, ds)
+				err = r.Delete(r.Context, ds)
 				if err != nil {
 					return false, err
 				}
+				// a dry-run delete leaves the object in place, the plan records the delete
+				if r.isPlanning() {
+					return true, nil
+				}
 				return r.ReconcileNodeAgentDaemonset(log)
 			}
 		}
@@ -600,6 +633,9 @@ func (r *DataProtectionApplicationReconciler) customizeNodeAgentDaemonset(ds *ap
 	credentials.AppendCloudProviderVolumes(dpa, ds, providerNeedsDefaultCreds)
 
 	setPodTemplateSpecDefaults(&ds.Spec.Template)
+	if err := r.setCredentialsHashAnnotation(&ds.Spec.Template); err != nil {
+		return nil, err
+	}
 	if ds.Spec.UpdateStrategy.Type == appsv1.RollingUpdateDaemonSetStrategyType {
 		ds.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateDaemonSet{
 			MaxUnavailable: &intstr.IntOrString{
@@ -0,0 +1,361 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/notification"
+)
+
+const (
+	// notifiedEventAnnotation records the ID of the last event notified for a Backup, Restore or Schedule
+	notifiedEventAnnotation = "oadp.openshift.io/notified-event"
+	// notifiedSinksAnnotation lists the sinks done with the event of notifiedEventAnnotation,
+	// so a retry only notifies the sinks whose delivery failed
+	notifiedSinksAnnotation = "oadp.openshift.io/notified-sinks"
+
+	defaultScheduleMissedGracePeriod = time.Hour
+	notificationRequestTimeout       = 10 * time.Second
+)
+
+// NotificationReconciler notifies the sinks configured in the DPA of failed and partially failed backups and restores,
+// and of schedules missing a run
+type NotificationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+	HTTPClient    *http.Client
+}
+
+// phaseChangedPredicate passes the updates changing the phase of an object, so a backup or restore is only
+// notified once when it fails and not again for the failures that happened before the operator started
+func phaseChangedPredicate(phase func(client.Object) string) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return phase(e.ObjectOld) != phase(e.ObjectNew)
+		},
+	}
+}
+
+// SetupWithManager sets up the backup, restore and schedule notification controllers with the Manager.
+func (r *NotificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.HTTPClient == nil {
+		r.HTTPClient = &http.Client{Timeout: notificationRequestTimeout}
+	}
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("backup-notification").
+		For(&velerov1.Backup{}, builder.WithPredicates(phaseChangedPredicate(func(obj client.Object) string {
+			return string(obj.(*velerov1.Backup).Status.Phase)
+		}))).
+		Complete(reconcile.Func(r.ReconcileBackup)); err != nil {
+		return err
+	}
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("restore-notification").
+		For(&velerov1.Restore{}, builder.WithPredicates(phaseChangedPredicate(func(obj client.Object) string {
+			return string(obj.(*velerov1.Restore).Status.Phase)
+		}))).
+		Complete(reconcile.Func(r.ReconcileRestore)); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("schedule-notification").
+		For(&velerov1.Schedule{}).
+		// the schedules are checked again when the notifications configuration changes
+		Watches(&oadpv1alpha1.DataProtectionApplication{}, handler.EnqueueRequestsFromMapFunc(r.dpaSchedules)).
+		Complete(reconcile.Func(r.ReconcileSchedule))
+}
+
+func (r *NotificationReconciler) dpaSchedules(ctx context.Context, dpa client.Object) []reconcile.Request {
+	schedules := &velerov1.ScheduleList{}
+	if err := r.List(ctx, schedules, client.InNamespace(dpa.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list schedules")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, schedule := range schedules.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&schedule)})
+	}
+	return requests
+}
+
+// ReconcileBackup notifies a failed or partially failed backup
+func (r *NotificationReconciler) ReconcileBackup(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	backup := &velerov1.Backup{}
+	if err := r.Get(ctx, req.NamespacedName, backup); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	var notificationEvent oadpv1alpha1.NotificationEvent
+	switch backup.Status.Phase {
+	case velerov1.BackupPhaseFailed, velerov1.BackupPhaseFailedValidation:
+		notificationEvent = oadpv1alpha1.NotificationEventBackupFailed
+	case velerov1.BackupPhasePartiallyFailed:
+		notificationEvent = oadpv1alpha1.NotificationEventBackupPartiallyFailed
+	default:
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.notify(ctx, backup, notification.Event{
+		Event:     notificationEvent,
+		Kind:      "Backup",
+		Namespace: backup.Namespace,
+		Name:      backup.Name,
+		Phase:     string(backup.Status.Phase),
+		Schedule:  backup.Labels[velerov1.ScheduleNameLabel],
+		Reason:    failureReason(backup.Status.FailureReason, backup.Status.ValidationErrors),
+		Time:      completionTime(backup.Status.CompletionTimestamp),
+		ID:        fmt.Sprintf("%s/%s", backup.UID, notificationEvent),
+	})
+}
+
+// ReconcileRestore notifies a failed or partially failed restore
+func (r *NotificationReconciler) ReconcileRestore(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	restore := &velerov1.Restore{}
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	var notificationEvent oadpv1alpha1.NotificationEvent
+	switch restore.Status.Phase {
+	case velerov1.RestorePhaseFailed, velerov1.RestorePhaseFailedValidation:
+		notificationEvent = oadpv1alpha1.NotificationEventRestoreFailed
+	case velerov1.RestorePhasePartiallyFailed:
+		notificationEvent = oadpv1alpha1.NotificationEventRestorePartiallyFailed
+	default:
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.notify(ctx, restore, notification.Event{
+		Event:     notificationEvent,
+		Kind:      "Restore",
+		Namespace: restore.Namespace,
+		Name:      restore.Name,
+		Phase:     string(restore.Status.Phase),
+		Schedule:  restore.Spec.ScheduleName,
+		Reason:    failureReason(restore.Status.FailureReason, restore.Status.ValidationErrors),
+		Time:      completionTime(restore.Status.CompletionTimestamp),
+		ID:        fmt.Sprintf("%s/%s", restore.UID, notificationEvent),
+	})
+}
+
+// ReconcileSchedule notifies a schedule that did not start a backup within the grace period of its next run
+// after the last backup, and requeues the schedule for the time that run would be missed
+func (r *NotificationReconciler) ReconcileSchedule(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	schedule := &velerov1.Schedule{}
+	if err := r.Get(ctx, req.NamespacedName, schedule); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if schedule.Spec.Paused {
+		return ctrl.Result{}, nil
+	}
+	dpa, err := r.notificationsDPA(ctx, schedule.Namespace)
+	if err != nil || dpa == nil {
+		return ctrl.Result{}, err
+	}
+	// Velero reports invalid schedules in their status
+	cronSchedule, err := cron.ParseStandard(schedule.Spec.Schedule)
+	if err != nil {
+		return ctrl.Result{}, nil
+	}
+
+	lastRun := schedule.CreationTimestamp.Time
+	if schedule.Status.LastBackup != nil && schedule.Status.LastBackup.After(lastRun) {
+		lastRun = schedule.Status.LastBackup.Time
+	}
+	gracePeriod := defaultScheduleMissedGracePeriod
+	if dpa.Spec.Notifications.ScheduleMissedGracePeriod != nil {
+		gracePeriod = dpa.Spec.Notifications.ScheduleMissedGracePeriod.Duration
+	}
+	expectedRun := cronSchedule.Next(lastRun)
+	if missedAt := expectedRun.Add(gracePeriod); time.Now().Before(missedAt) {
+		return ctrl.Result{RequeueAfter: time.Until(missedAt)}, nil
+	}
+	// report the latest missed run only, each missed run is notified once
+	for next := cronSchedule.Next(expectedRun); !time.Now().Before(next.Add(gracePeriod)); next = cronSchedule.Next(next) {
+		expectedRun = next
+	}
+
+	err = r.notify(ctx, schedule, notification.Event{
+		Event:     oadpv1alpha1.NotificationEventScheduleMissed,
+		Kind:      "Schedule",
+		Namespace: schedule.Namespace,
+		Name:      schedule.Name,
+		Schedule:  schedule.Name,
+		Reason:    fmt.Sprintf("no backup started since %s", lastRun.UTC().Format(time.RFC3339)),
+		Time:      expectedRun,
+		ID:        fmt.Sprintf("%s/%s/%d", schedule.UID, oadpv1alpha1.NotificationEventScheduleMissed, expectedRun.Unix()),
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// check the schedule again once its next run is missed
+	return ctrl.Result{RequeueAfter: time.Until(cronSchedule.Next(expectedRun).Add(gracePeriod))}, nil
+}
+
+// notificationsDPA returns the DPA of the namespace if it configures notifications
+func (r *NotificationReconciler) notificationsDPA(ctx context.Context, namespace string) (*oadpv1alpha1.DataProtectionApplication, error) {
+	dpaList := &oadpv1alpha1.DataProtectionApplicationList{}
+	if err := r.List(ctx, dpaList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range dpaList.Items {
+		if dpaList.Items[i].Spec.Notifications != nil {
+			return &dpaList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// notify sends the event to the sinks of the DPA that are not done with it yet and records the delivery status.
+// Transient delivery failures are returned so the event is retried with the controller backoff.
+func (r *NotificationReconciler) notify(ctx context.Context, obj client.Object, event notification.Event) error {
+	dpa, err := r.notificationsDPA(ctx, obj.GetNamespace())
+	if err != nil || dpa == nil {
+		return err
+	}
+	notifications := dpa.Spec.Notifications
+	if len(notifications.Events) > 0 && !slices.Contains(notifications.Events, event.Event) {
+		return nil
+	}
+	event.DataProtectionApplication = dpa.Name
+
+	doneSinks := []string{}
+	if obj.GetAnnotations()[notifiedEventAnnotation] == event.ID && obj.GetAnnotations()[notifiedSinksAnnotation] != "" {
+		doneSinks = strings.Split(obj.GetAnnotations()[notifiedSinksAnnotation], ",")
+	}
+	statuses := []oadpv1alpha1.NotificationDeliveryStatus{}
+	var errs []error
+	for _, sink := range notifications.Sinks {
+		if slices.Contains(doneSinks, sink.Name) {
+			continue
+		}
+		now := metav1.Now()
+		status := oadpv1alpha1.NotificationDeliveryStatus{
+			Sink:            sink.Name,
+			LastEvent:       event.String(),
+			LastAttemptTime: now,
+		}
+		url, err := r.sinkURL(ctx, dpa.Namespace, sink)
+		if err == nil {
+			err = notification.Send(ctx, r.HTTPClient, sink, url, event)
+		}
+		if err != nil {
+			status.Error = err.Error()
+			r.EventRecorder.Event(dpa,
+				corev1.EventTypeWarning,
+				"NotificationFailed",
+				fmt.Sprintf("unable to notify %s to sink %s: %v", event, sink.Name, err),
+			)
+		} else {
+			status.LastSuccessTime = &now
+		}
+		// a permanent failure, like a rejected payload, is not retried
+		if err == nil || notification.IsPermanent(err) {
+			doneSinks = append(doneSinks, sink.Name)
+		} else {
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[notifiedEventAnnotation] = event.ID
+	annotations[notifiedSinksAnnotation] = strings.Join(doneSinks, ",")
+	obj.SetAnnotations(annotations)
+	if err := r.Patch(ctx, obj, patch); err != nil {
+		errs = append(errs, err)
+	}
+	if err := r.updateDeliveryStatus(ctx, client.ObjectKeyFromObject(dpa), statuses); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// sinkURL returns the URL of the sink, reading it from its secret if needed
+func (r *NotificationReconciler) sinkURL(ctx context.Context, namespace string, sink oadpv1alpha1.NotificationSink) (string, error) {
+	if sink.URLSecret == nil {
+		return sink.URL, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: sink.URLSecret.Name}, secret); err != nil {
+		return "", err
+	}
+	url, ok := secret.Data[sink.URLSecret.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %s for the URL of notification sink %s", namespace, sink.URLSecret.Name, sink.URLSecret.Key, sink.Name)
+	}
+	return strings.TrimSpace(string(url)), nil
+}
+
+// updateDeliveryStatus records the delivery status of the sinks in the DPA status, dropping the status of removed sinks
+func (r *NotificationReconciler) updateDeliveryStatus(ctx context.Context, key types.NamespacedName, statuses []oadpv1alpha1.NotificationDeliveryStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		dpa := &oadpv1alpha1.DataProtectionApplication{}
+		if err := r.Get(ctx, key, dpa); err != nil {
+			return err
+		}
+		current := map[string]oadpv1alpha1.NotificationDeliveryStatus{}
+		for _, status := range dpa.Status.Notifications {
+			current[status.Sink] = status
+		}
+		for _, status := range statuses {
+			if status.LastSuccessTime == nil {
+				status.LastSuccessTime = current[status.Sink].LastSuccessTime
+			}
+			current[status.Sink] = status
+		}
+		dpa.Status.Notifications = nil
+		if dpa.Spec.Notifications != nil {
+			for _, sink := range dpa.Spec.Notifications.Sinks {
+				if status, ok := current[sink.Name]; ok {
+					dpa.Status.Notifications = append(dpa.Status.Notifications, status)
+				}
+			}
+		}
+		return r.Status().Update(ctx, dpa)
+	})
+}
+
+func failureReason(reason string, validationErrors []string) string {
+	if reason != "" {
+		return reason
+	}
+	return strings.Join(validationErrors, "; ")
+}
+
+func completionTime(completionTimestamp *metav1.Time) time.Time {
+	if completionTimestamp != nil {
+		return completionTimestamp.Time
+	}
+	return time.Now()
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// notificationTestServer records the bodies it receives and answers with the status codes of its sink paths
+type notificationTestServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	received    map[string][]string
+	statusCodes map[string]int
+}
+
+func newNotificationTestServer(statusCodes map[string]int) *notificationTestServer {
+	s := &notificationTestServer{received: map[string][]string{}, statusCodes: statusCodes}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.received[r.URL.Path] = append(s.received[r.URL.Path], string(body))
+		if code, ok := s.statusCodes[r.URL.Path]; ok {
+			w.WriteHeader(code)
+		}
+	}))
+	return s
+}
+
+func newNotificationTestDPA(server *notificationTestServer) *oadpv1alpha1.DataProtectionApplication {
+	return &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Notifications: &oadpv1alpha1.Notifications{
+				Sinks: []oadpv1alpha1.NotificationSink{
+					{Name: "slack", Type: oadpv1alpha1.NotificationSinkSlack, URLSecret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "slack-webhook"},
+						Key:                  "url",
+					}},
+					{Name: "webhook", Type: oadpv1alpha1.NotificationSinkWebhook, URL: server.URL + "/webhook"},
+				},
+			},
+		},
+	}
+}
+
+func TestNotificationReconciler_ReconcileBackup(t *testing.T) {
+	server := newNotificationTestServer(map[string]int{"/webhook": http.StatusServiceUnavailable})
+	defer server.Close()
+	dpa := newNotificationTestDPA(server)
+	slackSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack-webhook", Namespace: testNamespaceName},
+		Data:       map[string][]byte{"url": []byte(server.URL + "/slack\n")},
+	}
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "daily-20250101000000",
+			Namespace: testNamespaceName,
+			UID:       "backup-uid",
+			Labels:    map[string]string{velerov1.ScheduleNameLabel: "daily"},
+		},
+		Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseFailed, FailureReason: "bucket not found"},
+	}
+	fakeClient, err := getFakeClientWithStatusFromObjects(dpa, slackSecret, backup)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &NotificationReconciler{
+		Client:        fakeClient,
+		Scheme:        fakeClient.Scheme(),
+		EventRecorder: record.NewFakeRecorder(10),
+		HTTPClient:    server.Client(),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(backup)}
+
+	if _, err := r.ReconcileBackup(newContextForTest(), req); err == nil {
+		t.Fatalf("ReconcileBackup() did not return the webhook delivery error to retry it")
+	}
+	if len(server.received["/slack"]) != 1 || !strings.Contains(server.received["/slack"][0], "bucket not found") {
+		t.Errorf("ReconcileBackup() slack messages = %v, want one with the failure reason", server.received["/slack"])
+	}
+	if err := fakeClient.Get(newContextForTest(), req.NamespacedName, backup); err != nil {
+		t.Fatalf("unable to get backup: %v", err)
+	}
+	if backup.Annotations[notifiedSinksAnnotation] != "slack" {
+		t.Errorf("ReconcileBackup() notified sinks = %q, want slack", backup.Annotations[notifiedSinksAnnotation])
+	}
+	if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(dpa), dpa); err != nil {
+		t.Fatalf("unable to get DPA: %v", err)
+	}
+	if len(dpa.Status.Notifications) != 2 || dpa.Status.Notifications[0].LastSuccessTime == nil || dpa.Status.Notifications[1].Error == "" {
+		t.Errorf("ReconcileBackup() delivery status = %+v, want slack delivered and webhook failed", dpa.Status.Notifications)
+	}
+
+	// the retry only notifies the sink whose delivery failed
+	delete(server.statusCodes, "/webhook")
+	if _, err := r.ReconcileBackup(newContextForTest(), req); err != nil {
+		t.Fatalf("ReconcileBackup() error = %v", err)
+	}
+	if len(server.received["/slack"]) != 1 {
+		t.Errorf("ReconcileBackup() notified slack %d times, want once", len(server.received["/slack"]))
+	}
+	if err := fakeClient.Get(newContextForTest(), client.ObjectKeyFromObject(dpa), dpa); err != nil {
+		t.Fatalf("unable to get DPA: %v", err)
+	}
+	if webhookStatus := dpa.Status.Notifications[1]; webhookStatus.Error != "" || webhookStatus.LastSuccessTime == nil {
+		t.Errorf("ReconcileBackup() webhook delivery status = %+v, want delivered", webhookStatus)
+	}
+}
+
+func TestNotificationReconciler_ReconcileSchedule(t *testing.T) {
+	server := newNotificationTestServer(nil)
+	defer server.Close()
+	dpa := newNotificationTestDPA(server)
+	dpa.Spec.Notifications.Sinks = dpa.Spec.Notifications.Sinks[1:]
+	tests := []struct {
+		name           string
+		lastBackup     time.Time
+		wantNotified   bool
+		wantRequeueMax time.Duration
+	}{
+		{
+			name:           "schedule with a recent backup is checked again after its next run",
+			lastBackup:     time.Now().Add(-30 * time.Minute),
+			wantRequeueMax: 2 * time.Hour,
+		},
+		{
+			name:           "schedule without a backup for its last run is notified and checked again after its next run",
+			lastBackup:     time.Now().Add(-3 * time.Hour),
+			wantNotified:   true,
+			wantRequeueMax: 2 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.received = map[string][]string{}
+			schedule := &velerov1.Schedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "hourly",
+					Namespace:         testNamespaceName,
+					UID:               "schedule-uid",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-24 * time.Hour)),
+				},
+				Spec:   velerov1.ScheduleSpec{Schedule: "@every 1h"},
+				Status: velerov1.ScheduleStatus{LastBackup: &metav1.Time{Time: tt.lastBackup}},
+			}
+			fakeClient, err := getFakeClientWithStatusFromObjects(dpa.DeepCopy(), schedule)
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			r := &NotificationReconciler{
+				Client:        fakeClient,
+				Scheme:        fakeClient.Scheme(),
+				EventRecorder: record.NewFakeRecorder(10),
+				HTTPClient:    server.Client(),
+			}
+
+			result, err := r.ReconcileSchedule(newContextForTest(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testNamespaceName, Name: "hourly"}})
+			if err != nil {
+				t.Fatalf("ReconcileSchedule() error = %v", err)
+			}
+			if notified := len(server.received["/webhook"]) == 1; notified != tt.wantNotified {
+				t.Errorf("ReconcileSchedule() notified = %v, want %v", notified, tt.wantNotified)
+			}
+			if tt.wantRequeueMax > 0 && (result.RequeueAfter <= 0 || result.RequeueAfter > tt.wantRequeueMax) {
+				t.Errorf("ReconcileSchedule() requeue after = %s, want at most %s", result.RequeueAfter, tt.wantRequeueMax)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+// planClient sends writes as dry-run requests and records the changes they would make
+type planClient struct {
+	client.Client
+	changes []oadpv1alpha1.PlannedChange
+}
+
+func newPlanClient(c client.Client) *planClient {
+	return &planClient{Client: client.NewDryRunClient(c)}
+}
+
+func (c *planClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	return c.record(obj, oadpv1alpha1.PlannedActionCreate, nil)
+}
+
+func (c *planClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	live, err := c.getLive(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	return c.recordUpdate(live, obj)
+}
+
+func (c *planClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	live, err := c.getLive(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		return err
+	}
+	return c.recordUpdate(live, obj)
+}
+
+func (c *planClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	return c.record(obj, oadpv1alpha1.PlannedActionDelete, nil)
+}
+
+// getLive returns the object as currently stored in the cluster
+func (c *planClient) getLive(ctx context.Context, obj client.Object) (client.Object, error) {
+	live, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("unable to copy %T", obj)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+func (c *planClient) recordUpdate(live, obj client.Object) error {
+	fields, err := changedFields(live, obj)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return c.record(obj, oadpv1alpha1.PlannedActionUpdate, fields)
+}
+
+func (c *planClient) record(obj client.Object, action oadpv1alpha1.PlannedAction, fields []string) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	c.changes = append(c.changes, oadpv1alpha1.PlannedChange{
+		Kind:   gvk.Kind,
+		Name:   obj.GetName(),
+		Action: action,
+		Fields: fields,
+	})
+	return nil
+}
+
+// changedFields returns the paths of the spec and metadata fields that differ between two objects,
+// ignoring the fields maintained by the API server
+func changedFields(before, after client.Object) ([]string, error) {
+	beforeMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(after)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range []map[string]interface{}{beforeMap, afterMap} {
+		delete(m, "status")
+		delete(m, "apiVersion")
+		delete(m, "kind")
+		if metadata, ok := m["metadata"].(map[string]interface{}); ok {
+			for _, key := range []string{"resourceVersion", "generation", "managedFields", "creationTimestamp", "uid"} {
+				delete(metadata, key)
+			}
+		}
+	}
+	fields := []string{}
+	diffPaths("", beforeMap, afterMap, &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func diffPaths(path string, before, after interface{}, fields *[]string) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := map[string]bool{}
+		for key := range beforeMap {
+			keys[key] = true
+		}
+		for key := range afterMap {
+			keys[key] = true
+		}
+		for key := range keys {
+			diffPaths(strings.TrimPrefix(path+"."+key, "."), beforeMap[key], afterMap[key], fields)
+		}
+		return
+	}
+	beforeSlice, beforeIsSlice := before.([]interface{})
+	afterSlice, afterIsSlice := after.([]interface{})
+	if beforeIsSlice && afterIsSlice && len(beforeSlice) == len(afterSlice) {
+		for i := range beforeSlice {
+			diffPaths(fmt.Sprintf("%s[%d]", path, i), beforeSlice[i], afterSlice[i], fields)
+		}
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		*fields = append(*fields, path)
+	}
+}
+
+// restartsPods returns true if a planned change would restart the Velero or NodeAgent pods
+func restartsPods(changes []oadpv1alpha1.PlannedChange) bool {
+	for _, change := range changes {
+		if !(change.Kind == "Deployment" && change.Name == common.Velero) && !(change.Kind == "DaemonSet" && change.Name == common.NodeAgent) {
+			continue
+		}
+		if change.Action != oadpv1alpha1.PlannedActionUpdate {
+			return true
+		}
+		for _, field := range change.Fields {
+			if strings.HasPrefix(field, "spec.template.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isPlanning returns true while the reconcile functions run against the dry-run client of planReconcile
+func (r *DataProtectionApplicationReconciler) isPlanning() bool {
+	_, planning := r.Client.(*planClient)
+	return planning
+}
+
+// planReconcile runs the reconcile steps against a dry-run client and returns the changes they would make
+func (r *DataProtectionApplicationReconciler) planReconcile(log logr.Logger, steps ...reconcileStep) (*oadpv1alpha1.DataProtectionApplicationPlan, error) {
+	liveClient, eventRecorder := r.Client, r.EventRecorder
+	planClient := newPlanClient(liveClient)
+	// events would report changes that are not applied
+	r.Client, r.EventRecorder = planClient, &record.FakeRecorder{}
+	_, reconcileErr := runReconcileSteps(log, steps...)
+	r.Client, r.EventRecorder = liveClient, eventRecorder
+
+	plan := &oadpv1alpha1.DataProtectionApplicationPlan{
+		ObservedGeneration: r.dpa.Generation,
+		LastComputed:       metav1.Now(),
+		Changes:            planClient.changes,
+		RestartsPods:       restartsPods(planClient.changes),
+	}
+	if reconcileErr != nil {
+		plan.Error = reconcileErr.Error()
+	}
+	if plan.RestartsPods {
+		operations, err := r.inProgressOperations()
+		if err != nil {
+			return nil, err
+		}
+		if len(operations) > 0 {
+			plan.DisruptedOperations = operations
+		}
+	}
+	return plan, reconcileErr
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+func TestChangedFields(t *testing.T) {
+	before := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: common.Velero, ResourceVersion: "1", Labels: map[string]string{"a": "b"}},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: common.Velero, Image: "velero:v1"}},
+				},
+			},
+		},
+	}
+	after := before.DeepCopy()
+	after.ResourceVersion = "2"
+	after.Labels["c"] = "d"
+	after.Spec.Template.Spec.Containers[0].Image = "velero:v2"
+	after.Status.Replicas = 1
+
+	got, err := changedFields(before, after)
+	if err != nil {
+		t.Fatalf("changedFields() error = %v", err)
+	}
+	want := []string{"metadata.labels.c", "spec.template.spec.containers[0].image"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields() = %v, want %v", got, want)
+	}
+}
+
+func TestRestartsPods(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []oadpv1alpha1.PlannedChange
+		want    bool
+	}{
+		{
+			name: "velero pod template update",
+			changes: []oadpv1alpha1.PlannedChange{
+				{Kind: "Deployment", Name: common.Velero, Action: oadpv1alpha1.PlannedActionUpdate, Fields: []string{"spec.template.spec.containers[0].image"}},
+			},
+			want: true,
+		},
+		{
+			name: "velero metadata update",
+			changes: []oadpv1alpha1.PlannedChange{
+				{Kind: "Deployment", Name: common.Velero, Action: oadpv1alpha1.PlannedActionUpdate, Fields: []string{"metadata.labels.a"}},
+			},
+			want: false,
+		},
+		{
+			name: "node agent deletion",
+			changes: []oadpv1alpha1.PlannedChange{
+				{Kind: "DaemonSet", Name: common.NodeAgent, Action: oadpv1alpha1.PlannedActionDelete},
+			},
+			want: true,
+		},
+		{
+			name: "config map update",
+			changes: []oadpv1alpha1.PlannedChange{
+				{Kind: "ConfigMap", Name: "node-agent-config", Action: oadpv1alpha1.PlannedActionUpdate, Fields: []string{"data.config"}},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restartsPods(tt.changes); got != tt.want {
+				t.Errorf("restartsPods() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDPAReconciler_planReconcile(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        testDpaName,
+			Namespace:   testNamespaceName,
+			Generation:  2,
+			Annotations: map[string]string{oadpv1alpha1.DryRunAnnotation: "true"},
+		},
+	}
+	liveDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: common.Velero, Namespace: testNamespaceName},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: common.Velero, Image: "velero:v1"}},
+				},
+			},
+		},
+	}
+	runningBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: testNamespaceName},
+		Status:     velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress},
+	}
+	completedBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "completed", Namespace: testNamespaceName},
+		Status:     velerov1.BackupStatus{Phase: velerov1.BackupPhaseCompleted},
+	}
+	fakeClient, err := getFakeClientFromObjects(dpa, liveDeployment, runningBackup, completedBackup)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	eventRecorder := record.NewFakeRecorder(10)
+	r := &DataProtectionApplicationReconciler{
+		Client:        fakeClient,
+		Scheme:        fakeClient.Scheme(),
+		Log:           logr.Discard(),
+		Context:       newContextForTest(),
+		EventRecorder: eventRecorder,
+		NamespacedName: types.NamespacedName{
+			Namespace: dpa.Namespace,
+			Name:      dpa.Name,
+		},
+		dpa: dpa,
+	}
+
+	updateDeployment := func(log logr.Logger) (bool, error) {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: common.Velero, Namespace: testNamespaceName}}
+		_, err := controllerutil.CreateOrPatch(r.Context, r.Client, deployment, func() error {
+			deployment.Spec.Template.Spec.Containers[0].Image = "velero:v2"
+			return nil
+		})
+		r.EventRecorder.Event(deployment, corev1.EventTypeNormal, "VeleroDeploymentReconciled", "updated")
+		return err == nil, err
+	}
+	createConfigMap := func(log logr.Logger) (bool, error) {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: testNamespaceName}}
+		_, err := controllerutil.CreateOrPatch(r.Context, r.Client, configMap, func() error {
+			configMap.Data = map[string]string{"key": "value"}
+			return nil
+		})
+		return err == nil, err
+	}
+
+	plan, err := r.planReconcile(r.Log,
+		reconcileStep{name: "UpdateDeployment", reconcile: updateDeployment},
+		reconcileStep{name: "CreateConfigMap", reconcile: createConfigMap},
+	)
+	if err != nil {
+		t.Fatalf("planReconcile() error = %v", err)
+	}
+	wantChanges := []oadpv1alpha1.PlannedChange{
+		{Kind: "Deployment", Name: common.Velero, Action: oadpv1alpha1.PlannedActionUpdate, Fields: []string{"spec.template.spec.containers[0].image"}},
+		{Kind: "ConfigMap", Name: "config", Action: oadpv1alpha1.PlannedActionCreate},
+	}
+	if !reflect.DeepEqual(plan.Changes, wantChanges) {
+		t.Errorf("planReconcile() changes = %v, want %v", plan.Changes, wantChanges)
+	}
+	if !plan.RestartsPods || !reflect.DeepEqual(plan.DisruptedOperations, []string{"backup/running"}) {
+		t.Errorf("planReconcile() restartsPods = %v, disruptedOperations = %v, want the running backup disrupted", plan.RestartsPods, plan.DisruptedOperations)
+	}
+	if plan.ObservedGeneration != 2 {
+		t.Errorf("planReconcile() observedGeneration = %d, want 2", plan.ObservedGeneration)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := fakeClient.Get(r.Context, client.ObjectKeyFromObject(liveDeployment), deployment); err != nil {
+		t.Fatalf("unable to get deployment: %v", err)
+	}
+	if deployment.Spec.Template.Spec.Containers[0].Image != "velero:v1" {
+		t.Errorf("planReconcile() applied the deployment change")
+	}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Name: "config", Namespace: testNamespaceName}, &corev1.ConfigMap{}); err == nil {
+		t.Errorf("planReconcile() created the config map")
+	}
+	if len(eventRecorder.Events) != 0 {
+		t.Errorf("planReconcile() recorded events for changes that were not applied")
+	}
+	if r.Client != fakeClient || r.EventRecorder != eventRecorder {
+		t.Errorf("planReconcile() did not restore the reconciler client and event recorder")
+	}
+}
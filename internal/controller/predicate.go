@@ -13,7 +13,9 @@ func veleroPredicate(scheme *runtime.Scheme) predicate.Predicate {
 	return predicate.Funcs{
 		// Update returns true if the Update event should be processed
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			if e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration() {
+			if e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration() && !readinessChanged(e.ObjectOld, e.ObjectNew) &&
+				e.ObjectOld.GetAnnotations()[oadpv1alpha1.DryRunAnnotation] == e.ObjectNew.GetAnnotations()[oadpv1alpha1.DryRunAnnotation] &&
+				e.ObjectOld.GetAnnotations()[oadpv1alpha1.GCDryRunAnnotation] == e.ObjectNew.GetAnnotations()[oadpv1alpha1.GCDryRunAnnotation] {
 				return false
 			}
 			return isObjectOurs(scheme, e.ObjectOld)
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+// updateReadinessConditions sets the per-component readiness conditions of the DPA status.
+// Conditions of disabled components are removed.
+func (r *DataProtectionApplicationReconciler) updateReadinessConditions() error {
+	dpa := r.dpa
+	if dpa.Spec.Configuration == nil || dpa.Spec.Configuration.Velero == nil {
+		return nil
+	}
+
+	veleroDeployment := &appsv1.Deployment{}
+	found, err := r.getOptional(common.Velero, veleroDeployment)
+	if err != nil {
+		return err
+	}
+	if !found {
+		veleroDeployment = nil
+	}
+	apimeta.SetStatusCondition(&dpa.Status.Conditions, deploymentReadyCondition(oadpv1alpha1.ConditionVeleroReady, common.Velero, veleroDeployment))
+
+	if isNodeAgentEnabled(dpa) {
+		nodeAgentDaemonSet := &appsv1.DaemonSet{}
+		found, err := r.getOptional(common.NodeAgent, nodeAgentDaemonSet)
+		if err != nil {
+			return err
+		}
+		if !found {
+			nodeAgentDaemonSet = nil
+		}
+		apimeta.SetStatusCondition(&dpa.Status.Conditions, daemonSetReadyCondition(oadpv1alpha1.ConditionNodeAgentReady, common.NodeAgent, nodeAgentDaemonSet))
+	} else {
+		apimeta.RemoveStatusCondition(&dpa.Status.Conditions, oadpv1alpha1.ConditionNodeAgentReady)
+	}
+
+	if r.checkNonAdminEnabled() {
+		nonAdminDeployment := &appsv1.Deployment{}
+		found, err := r.getOptional(nonAdminObjectName, nonAdminDeployment)
+		if err != nil {
+			return err
+		}
+		if !found {
+			nonAdminDeployment = nil
+		}
+		apimeta.SetStatusCondition(&dpa.Status.Conditions, deploymentReadyCondition(oadpv1alpha1.ConditionNonAdminReady, nonAdminObjectName, nonAdminDeployment))
+	} else {
+		apimeta.RemoveStatusCondition(&dpa.Status.Conditions, oadpv1alpha1.ConditionNonAdminReady)
+	}
+
+	if len(dpa.Spec.BackupLocations) > 0 {
+		bslList := &velerov1.BackupStorageLocationList{}
+		if err := r.List(r.Context, bslList, client.InNamespace(r.NamespacedName.Namespace)); err != nil {
+			return err
+		}
+		bslNames := []string{}
+		for i, bslSpec := range dpa.Spec.BackupLocations {
+			bslNames = append(bslNames, getBackupStorageLocationName(dpa.Name, i, bslSpec))
+		}
+		apimeta.SetStatusCondition(&dpa.Status.Conditions, backupStorageLocationsAvailableCondition(bslNames, bslList.Items))
+	} else {
+		apimeta.RemoveStatusCondition(&dpa.Status.Conditions, oadpv1alpha1.ConditionBackupStorageLocationsAvailable)
+	}
+
+	if len(dpa.Spec.SnapshotLocations) > 0 {
+		condition := metav1.Condition{
+			Type:    oadpv1alpha1.ConditionVolumeSnapshotLocationsValid,
+			Status:  metav1.ConditionTrue,
+			Reason:  oadpv1alpha1.ValidReasonValid,
+			Message: fmt.Sprintf("%d volume snapshot locations are valid", len(dpa.Spec.SnapshotLocations)),
+		}
+		if _, err := r.ValidateVolumeSnapshotLocations(); err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = oadpv1alpha1.ValidReasonInvalid
+			condition.Message = err.Error()
+		}
+		apimeta.SetStatusCondition(&dpa.Status.Conditions, condition)
+	} else {
+		apimeta.RemoveStatusCondition(&dpa.Status.Conditions, oadpv1alpha1.ConditionVolumeSnapshotLocationsValid)
+	}
+
+	return nil
+}
+
+// getOptional gets the object with the given name in the DPA namespace,
+// returning false if it does not exist
+func (r *DataProtectionApplicationReconciler) getOptional(name string, obj client.Object) (bool, error) {
+	err := r.Get(r.Context, types.NamespacedName{Namespace: r.NamespacedName.Namespace, Name: name}, obj)
+	if k8serror.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// deploymentReadyCondition returns whether the rollout of the Deployment is complete,
+// following the same rules as `oc rollout status`. A nil object is reported as not found
+func deploymentReadyCondition(conditionType string, name string, deployment *appsv1.Deployment) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   conditionType,
+		Status: metav1.ConditionFalse,
+		Reason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+	}
+	if deployment == nil {
+		condition.Reason = oadpv1alpha1.ReadyReasonNotFound
+		condition.Message = fmt.Sprintf("deployment %s not found", name)
+		return condition
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			condition.Reason = oadpv1alpha1.ReadyReasonProgressDeadlineExceeded
+			condition.Message = fmt.Sprintf("deployment %s exceeded its progress deadline", name)
+			return condition
+		}
+	}
+	switch {
+	case deployment.Generation > deployment.Status.ObservedGeneration:
+		condition.Message = fmt.Sprintf("waiting for deployment %s spec update to be observed", name)
+	case deployment.Status.UpdatedReplicas < replicas:
+		condition.Message = fmt.Sprintf("%d out of %d new replicas of deployment %s have been updated", deployment.Status.UpdatedReplicas, replicas, name)
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		condition.Message = fmt.Sprintf("%d old replicas of deployment %s are pending termination", deployment.Status.Replicas-deployment.Status.UpdatedReplicas, name)
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas:
+		condition.Message = fmt.Sprintf("%d of %d updated replicas of deployment %s are available", deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas, name)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = oadpv1alpha1.ReadyReasonRolloutComplete
+		condition.Message = fmt.Sprintf("%d of %d replicas of deployment %s are available", deployment.Status.AvailableReplicas, replicas, name)
+	}
+	return condition
+}
+
+// daemonSetReadyCondition returns whether the rollout of the DaemonSet is complete,
+// following the same rules as `oc rollout status`. A nil object is reported as not found
+func daemonSetReadyCondition(conditionType string, name string, daemonSet *appsv1.DaemonSet) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   conditionType,
+		Status: metav1.ConditionFalse,
+		Reason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+	}
+	if daemonSet == nil {
+		condition.Reason = oadpv1alpha1.ReadyReasonNotFound
+		condition.Message = fmt.Sprintf("daemonset %s not found", name)
+		return condition
+	}
+	switch {
+	case daemonSet.Generation > daemonSet.Status.ObservedGeneration:
+		condition.Message = fmt.Sprintf("waiting for daemonset %s spec update to be observed", name)
+	case daemonSet.Status.UpdatedNumberScheduled < daemonSet.Status.DesiredNumberScheduled:
+		condition.Message = fmt.Sprintf("%d out of %d new pods of daemonset %s have been updated", daemonSet.Status.UpdatedNumberScheduled, daemonSet.Status.DesiredNumberScheduled, name)
+	case daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled:
+		condition.Message = fmt.Sprintf("%d of %d updated pods of daemonset %s are available", daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled, name)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = oadpv1alpha1.ReadyReasonRolloutComplete
+		condition.Message = fmt.Sprintf("%d of %d pods of daemonset %s are available", daemonSet.Status.NumberAvailable, daemonSet.Status.DesiredNumberScheduled, name)
+	}
+	return condition
+}
+
+// backupStorageLocationsAvailableCondition returns whether all the BSLs with the given names are Available
+func backupStorageLocationsAvailableCondition(bslNames []string, bsls []velerov1.BackupStorageLocation) metav1.Condition {
+	phases := map[string]velerov1.BackupStorageLocationPhase{}
+	for _, bsl := range bsls {
+		phases[bsl.Name] = bsl.Status.Phase
+	}
+	unavailable := []string{}
+	pending := []string{}
+	for _, name := range bslNames {
+		switch phases[name] {
+		case velerov1.BackupStorageLocationPhaseAvailable:
+		case velerov1.BackupStorageLocationPhaseUnavailable:
+			unavailable = append(unavailable, name)
+		default:
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(unavailable)
+	sort.Strings(pending)
+	switch {
+	case len(unavailable) > 0:
+		return metav1.Condition{
+			Type:    oadpv1alpha1.ConditionBackupStorageLocationsAvailable,
+			Status:  metav1.ConditionFalse,
+			Reason:  oadpv1alpha1.AvailableReasonUnavailable,
+			Message: fmt.Sprintf("backup storage locations unavailable: %s", strings.Join(unavailable, ", ")),
+		}
+	case len(pending) > 0:
+		return metav1.Condition{
+			Type:    oadpv1alpha1.ConditionBackupStorageLocationsAvailable,
+			Status:  metav1.ConditionUnknown,
+			Reason:  oadpv1alpha1.AvailableReasonPendingValidation,
+			Message: fmt.Sprintf("backup storage locations not yet validated by Velero: %s", strings.Join(pending, ", ")),
+		}
+	default:
+		return metav1.Condition{
+			Type:    oadpv1alpha1.ConditionBackupStorageLocationsAvailable,
+			Status:  metav1.ConditionTrue,
+			Reason:  oadpv1alpha1.AvailableReasonAvailable,
+			Message: fmt.Sprintf("%d backup storage locations are available", len(bslNames)),
+		}
+	}
+}
+
+// readinessChanged returns true if an update changed the readiness of an object
+// backing a readiness condition, even if its generation is unchanged
+func readinessChanged(oldObj, newObj client.Object) bool {
+	switch oldTyped := oldObj.(type) {
+	case *appsv1.Deployment:
+		newTyped, ok := newObj.(*appsv1.Deployment)
+		return ok && (oldTyped.Status.ObservedGeneration != newTyped.Status.ObservedGeneration ||
+			oldTyped.Status.Replicas != newTyped.Status.Replicas ||
+			oldTyped.Status.UpdatedReplicas != newTyped.Status.UpdatedReplicas ||
+			oldTyped.Status.AvailableReplicas != newTyped.Status.AvailableReplicas ||
+			progressingReason(oldTyped) != progressingReason(newTyped))
+	case *appsv1.DaemonSet:
+		newTyped, ok := newObj.(*appsv1.DaemonSet)
+		return ok && (oldTyped.Status.ObservedGeneration != newTyped.Status.ObservedGeneration ||
+			oldTyped.Status.DesiredNumberScheduled != newTyped.Status.DesiredNumberScheduled ||
+			oldTyped.Status.UpdatedNumberScheduled != newTyped.Status.UpdatedNumberScheduled ||
+			oldTyped.Status.NumberAvailable != newTyped.Status.NumberAvailable)
+	case *velerov1.BackupStorageLocation:
+		newTyped, ok := newObj.(*velerov1.BackupStorageLocation)
+		return ok && oldTyped.Status.Phase != newTyped.Status.Phase
+	}
+	return false
+}
+
+func progressingReason(deployment *appsv1.Deployment) string {
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status != corev1.ConditionUnknown {
+			return c.Reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+func TestDeploymentReadyCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "deployment not found",
+			deployment: nil,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonNotFound,
+		},
+		{
+			name: "spec update not observed",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+		},
+		{
+			name: "old replicas pending termination",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+		},
+		{
+			name: "updated replicas not available",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+		},
+		{
+			name: "progress deadline exceeded",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1,
+					Conditions: []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+					},
+				},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonProgressDeadlineExceeded,
+		},
+		{
+			name: "rollout complete",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deploymentReadyCondition(oadpv1alpha1.ConditionVeleroReady, common.Velero, tt.deployment)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("deploymentReadyCondition() = %s/%s (%s), want %s/%s", got.Status, got.Reason, got.Message, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDaemonSetReadyCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		daemonSet  *appsv1.DaemonSet
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "daemonset not found",
+			daemonSet:  nil,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonNotFound,
+		},
+		{
+			name: "pods not updated",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 2, NumberAvailable: 3},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+		},
+		{
+			name: "pods not available",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutInProgress,
+		},
+		{
+			name: "rollout complete",
+			daemonSet: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: oadpv1alpha1.ReadyReasonRolloutComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := daemonSetReadyCondition(oadpv1alpha1.ConditionNodeAgentReady, common.NodeAgent, tt.daemonSet)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("daemonSetReadyCondition() = %s/%s (%s), want %s/%s", got.Status, got.Reason, got.Message, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDPAReconciler_updateReadinessConditions(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testDpaName,
+			Namespace: testNamespaceName,
+		},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{},
+			},
+			BackupLocations: []oadpv1alpha1.BackupLocation{
+				{Velero: &velerov1.BackupStorageLocationSpec{}},
+				{Name: "custom", Velero: &velerov1.BackupStorageLocationSpec{}},
+			},
+		},
+		Status: oadpv1alpha1.DataProtectionApplicationStatus{
+			Conditions: []metav1.Condition{
+				{Type: oadpv1alpha1.ConditionNodeAgentReady, Status: metav1.ConditionTrue, Reason: oadpv1alpha1.ReadyReasonRolloutComplete},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		objects    []client.Object
+		wantStatus map[string]metav1.ConditionStatus
+	}{
+		{
+			name: "velero ready and backup storage locations available",
+			objects: []client.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: common.Velero, Namespace: testNamespaceName},
+					Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+				},
+				&velerov1.BackupStorageLocation{
+					ObjectMeta: metav1.ObjectMeta{Name: testDpaName + "-1", Namespace: testNamespaceName},
+					Status:     velerov1.BackupStorageLocationStatus{Phase: velerov1.BackupStorageLocationPhaseAvailable},
+				},
+				&velerov1.BackupStorageLocation{
+					ObjectMeta: metav1.ObjectMeta{Name: "custom", Namespace: testNamespaceName},
+					Status:     velerov1.BackupStorageLocationStatus{Phase: velerov1.BackupStorageLocationPhaseAvailable},
+				},
+			},
+			wantStatus: map[string]metav1.ConditionStatus{
+				oadpv1alpha1.ConditionVeleroReady:                     metav1.ConditionTrue,
+				oadpv1alpha1.ConditionBackupStorageLocationsAvailable: metav1.ConditionTrue,
+			},
+		},
+		{
+			name: "backup storage location not yet validated",
+			objects: []client.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: common.Velero, Namespace: testNamespaceName},
+					Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+				},
+				&velerov1.BackupStorageLocation{
+					ObjectMeta: metav1.ObjectMeta{Name: testDpaName + "-1", Namespace: testNamespaceName},
+					Status:     velerov1.BackupStorageLocationStatus{Phase: velerov1.BackupStorageLocationPhaseAvailable},
+				},
+				&velerov1.BackupStorageLocation{
+					ObjectMeta: metav1.ObjectMeta{Name: "custom", Namespace: testNamespaceName},
+				},
+			},
+			wantStatus: map[string]metav1.ConditionStatus{
+				oadpv1alpha1.ConditionVeleroReady:                     metav1.ConditionTrue,
+				oadpv1alpha1.ConditionBackupStorageLocationsAvailable: metav1.ConditionUnknown,
+			},
+		},
+		{
+			name: "velero missing and backup storage location unavailable",
+			objects: []client.Object{
+				&velerov1.BackupStorageLocation{
+					ObjectMeta: metav1.ObjectMeta{Name: testDpaName + "-1", Namespace: testNamespaceName},
+					Status:     velerov1.BackupStorageLocationStatus{Phase: velerov1.BackupStorageLocationPhaseUnavailable},
+				},
+			},
+			wantStatus: map[string]metav1.ConditionStatus{
+				oadpv1alpha1.ConditionVeleroReady:                     metav1.ConditionFalse,
+				oadpv1alpha1.ConditionBackupStorageLocationsAvailable: metav1.ConditionFalse,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient, err := getFakeClientFromObjects(tt.objects...)
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			r := &DataProtectionApplicationReconciler{
+				Client:  fakeClient,
+				Scheme:  fakeClient.Scheme(),
+				Log:     logr.Discard(),
+				Context: newContextForTest(),
+				NamespacedName: types.NamespacedName{
+					Namespace: dpa.Namespace,
+					Name:      dpa.Name,
+				},
+				dpa: dpa.DeepCopy(),
+			}
+			if err := r.updateReadinessConditions(); err != nil {
+				t.Fatalf("updateReadinessConditions() error = %v", err)
+			}
+			for _, conditionType := range []string{
+				oadpv1alpha1.ConditionVeleroReady,
+				oadpv1alpha1.ConditionNodeAgentReady,
+				oadpv1alpha1.ConditionNonAdminReady,
+				oadpv1alpha1.ConditionBackupStorageLocationsAvailable,
+				oadpv1alpha1.ConditionVolumeSnapshotLocationsValid,
+			} {
+				condition := apimeta.FindStatusCondition(r.dpa.Status.Conditions, conditionType)
+				wantStatus, want := tt.wantStatus[conditionType]
+				if !want {
+					if condition != nil {
+						t.Errorf("condition %s = %s, want it removed", conditionType, condition.Status)
+					}
+					continue
+				}
+				if condition == nil {
+					t.Errorf("condition %s not set, want %s", conditionType, wantStatus)
+				} else if condition.Status != wantStatus {
+					t.Errorf("condition %s = %s (%s), want %s", conditionType, condition.Status, condition.Message, wantStatus)
+				}
+			}
+		})
+	}
+}
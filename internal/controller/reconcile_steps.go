@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// validateStepName is the name of the reconcile step validating the DPA, which every other step depends on
+const validateStepName = "ValidateDataProtectionCR"
+
+// reconcileStep is a ReconcileFunc of the DPA reconcile and the steps whose objects it needs
+type reconcileStep struct {
+	name      string
+	reconcile ReconcileFunc
+	dependsOn []string
+}
+
+// reconcileSteps returns the steps of the DPA reconcile, each declared after the steps it depends on
+func (r *DataProtectionApplicationReconciler) reconcileSteps() []reconcileStep {
+	const (
+		validate               = validateStepName
+		bslConnectivity        = "BackupLocationConnectivity"
+		backupStorageLocations = "BackupStorageLocations"
+		registrySecrets        = "RegistrySecrets"
+		registries             = "Registries"
+		registryServices       = "RegistryServices"
+		registryRoutes         = "RegistryRoutes"
+		vslSecretLabels        = "VolumeSnapshotLocationSecretLabels"
+		volumeSnapshotLocation = "VolumeSnapshotLocations"
+		veleroDeployment       = "VeleroDeployment"
+		nodeAgentConfigMap     = "NodeAgentConfigMap"
+		backupRepoConfigMap    = "BackupRepositoryConfigMap"
+		repoMaintConfigMap     = "RepositoryMaintenanceConfigMap"
+	)
+	return []reconcileStep{
+		{name: validate, reconcile: r.ValidateDataProtectionCR},
+		{name: "FsRestoreHelperConfig", reconcile: r.ReconcileFsRestoreHelperConfig, dependsOn: []string{validate}},
+		{name: bslConnectivity, reconcile: r.CheckBackupLocationConnectivity, dependsOn: []string{validate}},
+		{name: backupStorageLocations, reconcile: r.ReconcileBackupStorageLocations, dependsOn: []string{bslConnectivity}},
+		{name: registrySecrets, reconcile: r.ReconcileRegistrySecrets, dependsOn: []string{backupStorageLocations}},
+		{name: registries, reconcile: r.ReconcileRegistries, dependsOn: []string{registrySecrets}},
+		{name: registryServices, reconcile: r.ReconcileRegistrySVCs, dependsOn: []string{registries}},
+		{name: registryRoutes, reconcile: r.ReconcileRegistryRoutes, dependsOn: []string{registryServices}},
+		{name: "RegistryRouteConfigs", reconcile: r.ReconcileRegistryRouteConfigs, dependsOn: []string{registryRoutes}},
+		{name: vslSecretLabels, reconcile: r.LabelVSLSecrets, dependsOn: []string{validate}},
+		{name: volumeSnapshotLocation, reconcile: r.ReconcileVolumeSnapshotLocations, dependsOn: []string{vslSecretLabels}},
+		// the Velero pod mounts the credentials of the locations
+		{name: veleroDeployment, reconcile: r.ReconcileVeleroDeployment, dependsOn: []string{backupStorageLocations, volumeSnapshotLocation}},
+		{name: nodeAgentConfigMap, reconcile: r.ReconcileNodeAgentConfigMap, dependsOn: []string{validate}},
+		{name: backupRepoConfigMap, reconcile: r.ReconcileBackupRepositoryConfigMap, dependsOn: []string{validate}},
+		{name: repoMaintConfigMap, reconcile: r.ReconcileRepositoryMaintenanceConfigMap, dependsOn: []string{validate}},
+		{name: "NodeAgentDaemonSet", reconcile: r.ReconcileNodeAgentDaemonset, dependsOn: []string{nodeAgentConfigMap}},
+		{name: "VeleroMetricsService", reconcile: r.ReconcileVeleroMetricsSVC, dependsOn: []string{veleroDeployment}},
+		{name: "NonAdminController", reconcile: r.ReconcileNonAdminController, dependsOn: []string{validate}},
+		{name: "ServiceMonitors", reconcile: r.ReconcileServiceMonitors, dependsOn: []string{validate}},
+		{name: "PrometheusRule", reconcile: r.ReconcilePrometheusRule, dependsOn: []string{validate}},
+		{name: "Dashboards", reconcile: r.ReconcileDashboards, dependsOn: []string{validate}},
+		// orphans are only collected once every step creating the objects that replace them succeeded
+		{name: "OrphanedResources", reconcile: r.ReconcileOrphanedResources, dependsOn: []string{
+			backupStorageLocations, registrySecrets, volumeSnapshotLocation, nodeAgentConfigMap, backupRepoConfigMap, repoMaintConfigMap,
+		}},
+	}
+}
+
+// runReconcileSteps runs the steps in order, skipping the steps that depend on a step that did not succeed,
+// so a failing step only holds back the steps that need its objects.
+// It returns the result of every step and the errors of the failed steps, including the steps that did not complete.
+func runReconcileSteps(log logr.Logger, steps ...reconcileStep) ([]oadpv1alpha1.ReconcileStepStatus, error) {
+	results := map[string]oadpv1alpha1.ReconcileStepResult{}
+	statuses := make([]oadpv1alpha1.ReconcileStepStatus, 0, len(steps))
+	var errs []error
+	for _, step := range steps {
+		status := oadpv1alpha1.ReconcileStepStatus{Name: step.name}
+		blockedBy := []string{}
+		for _, dependency := range step.dependsOn {
+			result, ok := results[dependency]
+			if !ok {
+				return nil, fmt.Errorf("reconcile step %s depends on %s, which is not declared before it", step.name, dependency)
+			}
+			if result != oadpv1alpha1.ReconcileStepResultSucceeded {
+				blockedBy = append(blockedBy, dependency)
+			}
+		}
+
+		if len(blockedBy) > 0 {
+			status.Result = oadpv1alpha1.ReconcileStepResultSkipped
+			status.Error = fmt.Sprintf("waiting for %s to succeed", strings.Join(blockedBy, ", "))
+		} else {
+			start := time.Now()
+			cont, err := step.reconcile(log)
+			status.LastRunTime = metav1.NewTime(start)
+			status.Duration = metav1.Duration{Duration: time.Since(start)}
+			switch {
+			case err != nil:
+				status.Result = oadpv1alpha1.ReconcileStepResultFailed
+				status.Error = err.Error()
+				errs = append(errs, err)
+			case !cont:
+				status.Result = oadpv1alpha1.ReconcileStepResultFailed
+				status.Error = "step did not complete"
+				errs = append(errs, fmt.Errorf("reconcile step %s did not complete", step.name))
+			default:
+				status.Result = oadpv1alpha1.ReconcileStepResultSucceeded
+			}
+		}
+		results[step.name] = status.Result
+		statuses = append(statuses, status)
+	}
+	return statuses, errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestRunReconcileSteps(t *testing.T) {
+	ran := map[string]bool{}
+	step := func(name string, err error, dependsOn ...string) reconcileStep {
+		return reconcileStep{
+			name: name,
+			reconcile: func(log logr.Logger) (bool, error) {
+				ran[name] = true
+				return err == nil, err
+			},
+			dependsOn: dependsOn,
+		}
+	}
+	vslErr := errors.New("invalid VSL")
+
+	statuses, err := runReconcileSteps(logr.Discard(),
+		step("Validate", nil),
+		step("VolumeSnapshotLocations", vslErr, "Validate"),
+		step("OrphanedResources", nil, "VolumeSnapshotLocations"),
+		step("NodeAgentDaemonSet", nil, "Validate"),
+		step("NonAdminController", nil, "Validate"),
+	)
+	if !errors.Is(err, vslErr) {
+		t.Errorf("runReconcileSteps() error = %v, want %v", err, vslErr)
+	}
+	wantResults := map[string]oadpv1alpha1.ReconcileStepResult{
+		"Validate":                oadpv1alpha1.ReconcileStepResultSucceeded,
+		"VolumeSnapshotLocations": oadpv1alpha1.ReconcileStepResultFailed,
+		"OrphanedResources":       oadpv1alpha1.ReconcileStepResultSkipped,
+		"NodeAgentDaemonSet":      oadpv1alpha1.ReconcileStepResultSucceeded,
+		"NonAdminController":      oadpv1alpha1.ReconcileStepResultSucceeded,
+	}
+	if len(statuses) != len(wantResults) {
+		t.Fatalf("runReconcileSteps() returned %d statuses, want %d", len(statuses), len(wantResults))
+	}
+	for _, status := range statuses {
+		if status.Result != wantResults[status.Name] {
+			t.Errorf("runReconcileSteps() step %s result = %s, want %s", status.Name, status.Result, wantResults[status.Name])
+		}
+		if ran[status.Name] != (status.Result != oadpv1alpha1.ReconcileStepResultSkipped) {
+			t.Errorf("runReconcileSteps() step %s ran = %v with result %s", status.Name, ran[status.Name], status.Result)
+		}
+		if status.Result != oadpv1alpha1.ReconcileStepResultSucceeded && status.Error == "" {
+			t.Errorf("runReconcileSteps() step %s has no error", status.Name)
+		}
+	}
+
+	incomplete := reconcileStep{name: "Registries", reconcile: func(log logr.Logger) (bool, error) { return false, nil }}
+	statuses, err = runReconcileSteps(logr.Discard(), incomplete)
+	if err == nil || len(statuses) != 1 || statuses[0].Result != oadpv1alpha1.ReconcileStepResultFailed {
+		t.Errorf("runReconcileSteps() = %+v, %v, want the step that did not complete failed with an error", statuses, err)
+	}
+
+	if _, err := runReconcileSteps(logr.Discard(), step("VeleroDeployment", nil, "Validate")); err == nil {
+		t.Errorf("runReconcileSteps() did not fail on a dependency declared after the step")
+	}
+}
+
+func TestDPAReconciler_reconcileSteps(t *testing.T) {
+	r := &DataProtectionApplicationReconciler{}
+	declared := map[string]bool{}
+	for _, step := range r.reconcileSteps() {
+		if declared[step.name] {
+			t.Errorf("reconcileSteps() declares step %s twice", step.name)
+		}
+		for _, dependency := range step.dependsOn {
+			if !declared[dependency] {
+				t.Errorf("reconcileSteps() step %s depends on %s, which is not declared before it", step.name, dependency)
+			}
+		}
+		declared[step.name] = true
+	}
+}
@@ -22,6 +22,7 @@ import (
 	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
 	"github.com/openshift/oadp-operator/pkg/common"
 	"github.com/openshift/oadp-operator/pkg/credentials"
+	"github.com/openshift/oadp-operator/pkg/utils"
 )
 
 // Registry Env var keys
@@ -222,7 +223,10 @@ func (r *DataProtectionApplicationReconciler) getProviderSecret(secretName strin
 	originalSecret := secret.DeepCopy()
 	// replace carriage return with new line
 	secret.Data = replaceCarriageReturn(secret.Data, r.Log)
-	r.Client.Patch(r.Context, &secret, client.MergeFrom(originalSecret))
+	// the webhook has no side effects
+	if !r.admission {
+		r.Client.Patch(r.Context, &secret, client.MergeFrom(originalSecret))
+	}
 	return secret, nil
 }
 
@@ -288,142 +292,19 @@ func (r *DataProtectionApplicationReconciler) getSecretNameAndKey(config map[str
 	return secretName, secretKey, nil
 }
 
-func (r *DataProtectionApplicationReconciler) parseAWSSecret(secret corev1.Secret, secretKey string, matchProfile string) (string, string, error) {
-
-	AWSAccessKey, AWSSecretKey, profile := "", "", ""
-	splitString := strings.Split(string(secret.Data[secretKey]), "\n")
-	keyNameRegex, err := regexp.Compile(`\[.*\]`)
-	const (
-		accessKeyKey = "aws_access_key_id"
-		secretKeyKey = "aws_secret_access_key"
-		// STS specific fields
-		roleArnKey              = "role_arn"
-		webIdentityTokenFileKey = "web_identity_token_file"
-		stsRegionalEndpointsKey = "sts_regional_endpoints"
-	)
-	if err != nil {
-		return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: keyNameRegex")
-	}
-	awsAccessKeyRegex, err := regexp.Compile(`\b` + accessKeyKey + `\b`)
-	if err != nil {
-		return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: awsAccessKeyRegex")
-	}
-	awsSecretKeyRegex, err := regexp.Compile(`\b` + secretKeyKey + `\b`)
-	if err != nil {
-		return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: awsSecretKeyRegex")
-	}
-	// Compile regex for STS fields
-	roleArnRegex, err := regexp.Compile(`\b` + roleArnKey + `\b`)
+// parseAWSSecret returns the keys of a profile of an AWS credentials file. The keys are empty for the profiles Velero
+// gets temporary credentials for itself, like STS web identity, assumed roles, SSO and credential processes.
+func (r *DataProtectionApplicationReconciler) parseAWSSecret(secret corev1.Secret, secretKey string, matchProfile string) (utils.AWSProfileCredentials, error) {
+	creds, err := utils.ResolveAWSCredentials(secret.Data[secretKey], matchProfile)
 	if err != nil {
-		return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: roleArnRegex")
+		r.Log.Info(fmt.Sprintf("Error resolving AWS profile %s of the supplied AWS credential", matchProfile))
+		return creds, err
 	}
-	webIdentityTokenFileRegex, err := regexp.Compile(`\b` + webIdentityTokenFileKey + `\b`)
-	if err != nil {
-		return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: webIdentityTokenFileRegex")
-	}
-	stsRegionalEndpointsRegex, err := regexp.Compile(`\b` + stsRegionalEndpointsKey + `\b`)
-	if err != nil {
-		return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: stsRegionalEndpointsRegex")
+	if !creds.HasKeys() {
+		r.Log.Info(fmt.Sprintf("Detected %s credentials in profile %s", creds.Source, matchProfile))
+		return utils.AWSProfileCredentials{Source: creds.Source}, nil
 	}
-	for index, line := range splitString {
-		if line == "" {
-			continue
-		}
-		if keyNameRegex.MatchString(line) {
-			awsProfileRegex, err := regexp.Compile(`\[|\]`)
-			if err != nil {
-				return AWSAccessKey, AWSSecretKey, errors.New("parseAWSSecret faulty regex: keyNameRegex")
-			}
-			cleanedLine := strings.ReplaceAll(line, " ", "")
-			parsedProfile := awsProfileRegex.ReplaceAllString(cleanedLine, "")
-			if parsedProfile == matchProfile {
-				profile = matchProfile
-				// check for end of arr
-				if index+1 >= len(splitString) {
-					break
-				}
-
-				// Check if this profile contains STS fields
-				hasStsFields := false
-				for _, profLine := range splitString[index+1:] {
-					if profLine == "" {
-						continue
-					}
-					if keyNameRegex.MatchString(profLine) {
-						// We've reached the next profile
-						break
-					}
-
-					// Check for STS-specific fields
-					if roleArnRegex.MatchString(profLine) ||
-						webIdentityTokenFileRegex.MatchString(profLine) ||
-						stsRegionalEndpointsRegex.MatchString(profLine) {
-						hasStsFields = true
-						r.Log.Info(fmt.Sprintf("Detected STS authentication in profile %s", matchProfile))
-						// For STS profiles, we return empty strings for access key and secret key
-						// but don't error out
-						return "", "", nil
-					}
-				}
-
-				// If not an STS profile, continue with normal AWS credential parsing
-				if !hasStsFields {
-					for _, profLine := range splitString[index+1:] {
-						if profLine == "" {
-							continue
-						}
-						if keyNameRegex.MatchString(profLine) {
-							// We've reached the next profile
-							break
-						}
-
-						matchedAccessKey := awsAccessKeyRegex.MatchString(profLine)
-						matchedSecretKey := awsSecretKeyRegex.MatchString(profLine)
-
-						if matchedAccessKey { // check for access key
-							AWSAccessKey, err = r.getMatchedKeyValue(accessKeyKey, profLine)
-							if err != nil {
-								r.Log.Info("Error processing access key id for the supplied AWS credential")
-								return AWSAccessKey, AWSSecretKey, err
-							}
-							continue
-						} else if matchedSecretKey { // check for secret key
-							AWSSecretKey, err = r.getMatchedKeyValue(secretKeyKey, profLine)
-							if err != nil {
-								r.Log.Info("Error processing secret key id for the supplied AWS credential")
-								return AWSAccessKey, AWSSecretKey, err
-							}
-							continue
-						}
-					}
-				}
-			}
-		}
-	}
-
-	if profile == "" {
-		r.Log.Info("Error finding AWS Profile for the supplied AWS credential")
-		return AWSAccessKey, AWSSecretKey, errors.New("error finding AWS Profile for the supplied AWS credential")
-	}
-
-	// If we get here and both access key and secret key are empty, it means we found the profile
-	// but it didn't have the expected credentials or STS fields
-	if AWSAccessKey == "" && AWSSecretKey == "" {
-		r.Log.Info(fmt.Sprintf("Profile %s found but no credentials or STS fields detected", matchProfile))
-		return AWSAccessKey, AWSSecretKey, errors.New("no credentials or STS fields found in profile " + matchProfile)
-	}
-
-	// For regular AWS credentials, both access key and secret key must be present
-	if AWSAccessKey == "" {
-		r.Log.Info("Error finding access key id for the supplied AWS credential")
-		return AWSAccessKey, AWSSecretKey, errors.New("error finding access key id for the supplied AWS credential")
-	}
-	if AWSSecretKey == "" {
-		r.Log.Info("Error finding secret access key for the supplied AWS credential")
-		return AWSAccessKey, AWSSecretKey, errors.New("error finding secret access key for the supplied AWS credential")
-	}
-
-	return AWSAccessKey, AWSSecretKey, nil
+	return creds, nil
 }
 
 func (r *DataProtectionApplicationReconciler) parseAzureSecret(secret corev1.Secret, secretKey string) (azureCredentials, error) {
@@ -655,6 +536,11 @@ func (r *DataProtectionApplicationReconciler) ReconcileRegistryRouteConfigs(log
 	return true, nil
 }
 
+// registrySecretName returns the name of the registry secret created for a BSL
+func registrySecretName(bsl *velerov1.BackupStorageLocation) string {
+	return "oadp-" + bsl.Name + "-" + bsl.Spec.Provider + "-registry-secret"
+}
+
 // Create secret for registry to be parsed by openshift-velero-plugin
 func (r *DataProtectionApplicationReconciler) ReconcileRegistrySecrets(log logr.Logger) (bool, error) {
 	dpa := r.dpa
@@ -677,7 +563,7 @@ func (r *DataProtectionApplicationReconciler) ReconcileRegistrySecrets(log logr.
 		}
 		secret := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "oadp-" + bsl.Name + "-" + bsl.Spec.Provider + "-registry-secret",
+				Name:      registrySecretName(&bsl),
 				Namespace: r.NamespacedName.Namespace,
 				Labels: map[string]string{
 					oadpv1alpha1.OadpOperatorLabel: "True",
@@ -755,44 +641,43 @@ func (r *DataProtectionApplicationReconciler) patchRegistrySecret(secret *corev1
 }
 
 func (r *DataProtectionApplicationReconciler) populateAWSRegistrySecret(bsl *velerov1.BackupStorageLocation, registrySecret *corev1.Secret) error {
-	// Check for secret name
-	secretName, secretKey, _ := r.getSecretNameAndKey(bsl.Spec.Config, bsl.Spec.Credential, oadpv1alpha1.DefaultPluginAWS)
-
 	// fetch secret and error
-	secret, err := r.getProviderSecret(secretName)
+	secret, secretKey, err := r.getBSLProviderSecret(bsl, oadpv1alpha1.DefaultPluginAWS)
 	if err != nil {
-		r.Log.Info(fmt.Sprintf("Error fetching provider secret %s for backupstoragelocation %s/%s", secretName, bsl.Namespace, bsl.Name))
+		r.Log.Info(fmt.Sprintf("Error fetching provider secret for backupstoragelocation %s/%s", bsl.Namespace, bsl.Name))
 		return err
 	}
+	secretName := secret.Name
 	awsProfile := "default"
 	if value, exists := bsl.Spec.Config[Profile]; exists {
 		awsProfile = value
 	}
-	// parse the secret and get aws access_key and aws secret_key
-	AWSAccessKey, AWSSecretKey, err := r.parseAWSSecret(secret, secretKey, awsProfile)
+	// parse the secret and get aws access_key, secret_key and session_token
+	creds, err := r.parseAWSSecret(secret, secretKey, awsProfile)
 	if err != nil {
 		r.Log.Info(fmt.Sprintf("Error parsing provider secret %s for backupstoragelocation %s/%s", secretName, bsl.Namespace, bsl.Name))
 		return err
 	}
 
 	registrySecret.Data = map[string][]byte{
-		"access_key": []byte(AWSAccessKey),
-		"secret_key": []byte(AWSSecretKey),
+		"access_key": []byte(creds.AccessKeyID),
+		"secret_key": []byte(creds.SecretAccessKey),
+	}
+	if creds.SessionToken != "" {
+		registrySecret.Data["session_token"] = []byte(creds.SessionToken)
 	}
 
 	return nil
 }
 
 func (r *DataProtectionApplicationReconciler) populateAzureRegistrySecret(bsl *velerov1.BackupStorageLocation, registrySecret *corev1.Secret) error {
-	// Check for secret name
-	secretName, secretKey, _ := r.getSecretNameAndKey(bsl.Spec.Config, bsl.Spec.Credential, oadpv1alpha1.DefaultPluginMicrosoftAzure)
-
 	// fetch secret and error
-	secret, err := r.getProviderSecret(secretName)
+	secret, secretKey, err := r.getBSLProviderSecret(bsl, oadpv1alpha1.DefaultPluginMicrosoftAzure)
 	if err != nil {
-		r.Log.Info(fmt.Sprintf("Error fetching provider secret %s for backupstoragelocation %s/%s", secretName, bsl.Namespace, bsl.Name))
+		r.Log.Info(fmt.Sprintf("Error fetching provider secret for backupstoragelocation %s/%s", bsl.Namespace, bsl.Name))
 		return err
 	}
+	secretName := secret.Name
 
 	// parse the secret and get azure storage account key
 	azcreds, err := r.parseAzureSecret(secret, secretKey)
@@ -828,6 +713,17 @@ func (r *DataProtectionApplicationReconciler) populateAzureRegistrySecret(bsl *v
 	return nil
 }
 
+// getBSLProviderSecret returns the provider secret of a BSL and the key of its credentials, the credentials file of a
+// SecretProviderClass is read from the operator pod
+func (r *DataProtectionApplicationReconciler) getBSLProviderSecret(bsl *velerov1.BackupStorageLocation, plugin oadpv1alpha1.DefaultPlugin) (corev1.Secret, string, error) {
+	if credentialsFile := bsl.Spec.Config[CredentialsFileKey]; credentials.IsSecretProviderClassFilePath(credentialsFile) {
+		return credentials.GetSecretProviderClassFileSecret(credentialsFile)
+	}
+	secretName, secretKey, _ := r.getSecretNameAndKey(bsl.Spec.Config, bsl.Spec.Credential, plugin)
+	secret, err := r.getProviderSecret(secretName)
+	return secret, secretKey, err
+}
+
 func (r *DataProtectionApplicationReconciler) verifySecretContent(secretName string, secretKey string) error {
 	secret, err := r.getProviderSecret(secretName)
 	if err != nil {
@@ -524,13 +524,14 @@ func TestDPAReconciler_populateAzureRegistrySecret(t *testing.T) {
 
 func TestDPAReconciler_parseAWSSecret(t *testing.T) {
 	tests := []struct {
-		name          string
-		secret        corev1.Secret
-		secretKey     string
-		matchProfile  string
-		wantAccessKey string
-		wantSecretKey string
-		wantErr       bool
+		name             string
+		secret           corev1.Secret
+		secretKey        string
+		matchProfile     string
+		wantAccessKey    string
+		wantSecretKey    string
+		wantSessionToken string
+		wantErr          bool
 	}{
 		{
 			name: "successful parse with bslProfile",
@@ -628,6 +629,41 @@ func TestDPAReconciler_parseAWSSecret(t *testing.T) {
 			wantSecretKey: "",
 			wantErr:       false,
 		},
+		{
+			name: "successful parse with role chained from the default profile",
+			secret: corev1.Secret{
+				Data: map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=" + testAccessKey + "\naws_secret_access_key=" + testSecretAccessKey +
+					"\n[profile backups]\nrole_arn = arn:aws:iam::123456789012:role/velero\nsource_profile = default\n")},
+			},
+			secretKey:     "cloud",
+			matchProfile:  "backups",
+			wantAccessKey: "",
+			wantSecretKey: "",
+			wantErr:       false,
+		},
+		{
+			name: "successful parse with session token",
+			secret: corev1.Secret{
+				Data: map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=" + testAccessKey + "\naws_secret_access_key=" + testSecretAccessKey +
+					"\naws_session_token=token\n")},
+			},
+			secretKey:        "cloud",
+			matchProfile:     "default",
+			wantAccessKey:    testAccessKey,
+			wantSecretKey:    testSecretAccessKey,
+			wantSessionToken: "token",
+			wantErr:          false,
+		},
+		{
+			name: "error with role chain loop",
+			secret: corev1.Secret{
+				Data: map[string][]byte{"cloud": []byte("[a]\nrole_arn = arn:aws:iam::123456789012:role/a\nsource_profile = b\n" +
+					"[b]\nrole_arn = arn:aws:iam::123456789012:role/b\nsource_profile = a\n")},
+			},
+			secretKey:    "cloud",
+			matchProfile: "a",
+			wantErr:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -635,17 +671,20 @@ func TestDPAReconciler_parseAWSSecret(t *testing.T) {
 			r := &DataProtectionApplicationReconciler{
 				Log: logr.Discard(),
 			}
-			gotAccessKey, gotSecretKey, err := r.parseAWSSecret(tt.secret, tt.secretKey, tt.matchProfile)
+			gotCreds, err := r.parseAWSSecret(tt.secret, tt.secretKey, tt.matchProfile)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseAWSSecret() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !tt.wantErr {
-				if gotAccessKey != tt.wantAccessKey {
-					t.Errorf("parseAWSSecret() gotAccessKey = %v, want %v", gotAccessKey, tt.wantAccessKey)
+				if gotCreds.AccessKeyID != tt.wantAccessKey {
+					t.Errorf("parseAWSSecret() gotAccessKey = %v, want %v", gotCreds.AccessKeyID, tt.wantAccessKey)
+				}
+				if gotCreds.SecretAccessKey != tt.wantSecretKey {
+					t.Errorf("parseAWSSecret() gotSecretKey = %v, want %v", gotCreds.SecretAccessKey, tt.wantSecretKey)
 				}
-				if gotSecretKey != tt.wantSecretKey {
-					t.Errorf("parseAWSSecret() gotSecretKey = %v, want %v", gotSecretKey, tt.wantSecretKey)
+				if gotCreds.SessionToken != tt.wantSessionToken {
+					t.Errorf("parseAWSSecret() gotSessionToken = %v, want %v", gotCreds.SessionToken, tt.wantSessionToken)
 				}
 			}
 		})
@@ -0,0 +1,887 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"github.com/vmware-tanzu/velero/pkg/label"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const (
+	// restoreVerificationLabel is set to the name of the RestoreVerification on its Restores, resource modifier
+	// ConfigMaps, check Jobs and scratch namespaces
+	restoreVerificationLabel = "oadp.openshift.io/restore-verification"
+	// restoreVerificationFinalizer deletes the scratch namespaces of a RestoreVerification when it is deleted
+	restoreVerificationFinalizer = "oadp.openshift.io/restore-verification"
+
+	defaultRestoreVerificationTimeout = time.Hour
+	// restoreVerificationPollPeriod is how often the restore and the checks of a drill are polled
+	restoreVerificationPollPeriod     = 10 * time.Second
+	restoreVerificationRequestTimeout = 10 * time.Second
+)
+
+// RestoreVerificationReconciler reconciles a RestoreVerification object
+type RestoreVerificationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+	// ClusterWideClient reads and deletes the scratch namespaces and their objects,
+	// which are outside of the namespace cached by the manager
+	ClusterWideClient client.Client
+	HTTPClient        *http.Client
+}
+
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=restoreverifications,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=restoreverifications/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=oadp.openshift.io,resources=restoreverifications/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+
+// Reconcile runs the restore drills of a RestoreVerification: it restores the latest completed backup of the schedule
+// into the scratch namespaces, runs the checks, and deletes the scratch namespaces
+func (r *RestoreVerificationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("restoreverification", req.NamespacedName)
+	verification := &oadpv1alpha1.RestoreVerification{}
+	if err := r.Get(ctx, req.NamespacedName, verification); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteRestoreVerificationMetrics(req.Namespace, req.Name)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !verification.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, verification)
+	}
+	if controllerutil.AddFinalizer(verification, restoreVerificationFinalizer) {
+		// the update does not change the generation, requeue to start the drills
+		return ctrl.Result{Requeue: true}, r.Update(ctx, verification)
+	}
+
+	now := time.Now()
+	if err := validateRestoreVerification(verification); err != nil {
+		logger.Error(err, "invalid RestoreVerification")
+		phase := verification.Status.Phase
+		if phase == oadpv1alpha1.RestoreVerificationPhaseRestoring || phase == oadpv1alpha1.RestoreVerificationPhaseVerifying {
+			// the drill in progress is failed and cleaned up, the next one starts once the spec is fixed
+			completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultFailed,
+				fmt.Sprintf("invalid RestoreVerification: %v", err))
+		}
+		if verification.Status.Phase != oadpv1alpha1.RestoreVerificationPhaseCleaningUp {
+			return ctrl.Result{}, r.updateRestoreVerificationStatus(ctx, verification, err)
+		}
+	}
+
+	var result ctrl.Result
+	var err error
+	switch verification.Status.Phase {
+	case oadpv1alpha1.RestoreVerificationPhaseRestoring:
+		result, err = r.waitForRestore(ctx, verification, now)
+	case oadpv1alpha1.RestoreVerificationPhaseVerifying:
+		result, err = r.verify(ctx, verification, now)
+	case oadpv1alpha1.RestoreVerificationPhaseCleaningUp:
+		result, err = r.cleanUp(ctx, verification)
+	default:
+		result, err = r.startRun(ctx, verification, now)
+	}
+	if err != nil {
+		r.EventRecorder.Event(verification, corev1.EventTypeWarning, "RestoreVerificationReconcileFailed", err.Error())
+		return ctrl.Result{}, errors.Join(err, r.updateRestoreVerificationStatus(ctx, verification, err))
+	}
+	return result, r.updateRestoreVerificationStatus(ctx, verification, nil)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RestoreVerificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.HTTPClient == nil {
+		r.HTTPClient = &http.Client{
+			Timeout: restoreVerificationRequestTimeout,
+			// a service of a scratch namespace must not redirect the operator anywhere else
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&oadpv1alpha1.RestoreVerification{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&velerov1.Restore{}).
+		Complete(r)
+}
+
+func validateRestoreVerification(verification *oadpv1alpha1.RestoreVerification) error {
+	spec := verification.Spec
+	if _, err := cron.ParseStandard(spec.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", spec.Schedule, err)
+	}
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", spec.Timeout.Duration)
+	}
+	if len(spec.NamespaceMapping) == 0 {
+		return errors.New("namespaceMapping must map at least one namespace to a scratch namespace")
+	}
+	scratchNamespaces := map[string]bool{}
+	for _, source := range slices.Sorted(maps.Keys(spec.NamespaceMapping)) {
+		scratch := spec.NamespaceMapping[source]
+		if errs := validation.IsDNS1123Label(scratch); len(errs) > 0 {
+			return fmt.Errorf("invalid scratch namespace %q: %s", scratch, strings.Join(errs, ", "))
+		}
+		if scratchNamespaces[scratch] {
+			return fmt.Errorf("namespaces are restored into the same scratch namespace %s", scratch)
+		}
+		scratchNamespaces[scratch] = true
+	}
+	// the scratch namespaces are deleted at the end of every drill
+	for _, source := range slices.Sorted(maps.Keys(spec.NamespaceMapping)) {
+		if scratchNamespaces[source] {
+			return fmt.Errorf("namespace %s is both restored and a scratch namespace", source)
+		}
+	}
+	if scratchNamespaces[verification.Namespace] {
+		return fmt.Errorf("namespace %s of the RestoreVerification cannot be a scratch namespace", verification.Namespace)
+	}
+
+	checkNames := map[string]bool{}
+	for _, check := range spec.Checks {
+		// the name of a check names its job
+		if errs := validation.IsDNS1123Label(check.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid check name %q: %s", check.Name, strings.Join(errs, ", "))
+		}
+		if checkNames[check.Name] {
+			return fmt.Errorf("duplicate check %s", check.Name)
+		}
+		checkNames[check.Name] = true
+
+		kinds := 0
+		if check.PodsReady != nil {
+			kinds++
+			if !scratchNamespaces[check.PodsReady.Namespace] {
+				return fmt.Errorf("check %s: namespace %s is not a scratch namespace", check.Name, check.PodsReady.Namespace)
+			}
+			if _, err := metav1.LabelSelectorAsSelector(check.PodsReady.Selector); err != nil {
+				return fmt.Errorf("check %s: invalid selector: %w", check.Name, err)
+			}
+		}
+		if check.HTTPGet != nil {
+			kinds++
+			checkURL, err := url.ParseRequestURI(check.HTTPGet.URL)
+			if err != nil {
+				return fmt.Errorf("check %s: invalid url: %w", check.Name, err)
+			}
+			if checkURL.Scheme != "http" && checkURL.Scheme != "https" {
+				return fmt.Errorf("check %s: url scheme must be http or https, got %q", check.Name, checkURL.Scheme)
+			}
+			// the operator only gets the services restored by the drill
+			if namespace, ok := serviceNamespace(checkURL.Hostname()); !ok || !scratchNamespaces[namespace] {
+				return fmt.Errorf("check %s: url host %s is not a service of a scratch namespace", check.Name, checkURL.Hostname())
+			}
+		}
+		if check.Job != nil {
+			kinds++
+			if !scratchNamespaces[check.Job.Namespace] {
+				return fmt.Errorf("check %s: namespace %s is not a scratch namespace", check.Name, check.Job.Namespace)
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("check %s must set exactly one of podsReady, httpGet and job", check.Name)
+		}
+	}
+	return nil
+}
+
+// serviceNamespace returns the namespace of a service host name, <service>.<namespace>.svc or
+// <service>.<namespace>.svc.cluster.local
+func serviceNamespace(host string) (string, bool) {
+	parts := strings.Split(strings.TrimSuffix(strings.ToLower(host), "."), ".")
+	if len(parts) == 5 && parts[3] == "cluster" && parts[4] == "local" {
+		parts = parts[:3]
+	}
+	if len(parts) != 3 || parts[2] != "svc" || parts[0] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// nextRestoreVerificationRun returns when the drill after the last one starts
+func nextRestoreVerificationRun(verification *oadpv1alpha1.RestoreVerification) (time.Time, error) {
+	schedule, err := cron.ParseStandard(verification.Spec.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	from := verification.CreationTimestamp.Time
+	if verification.Status.LastRun != nil {
+		from = verification.Status.LastRun.StartTime.Time
+	}
+	return schedule.Next(from), nil
+}
+
+// startRun waits for the next drill, and starts it by restoring the latest completed backup of the schedule
+// into the scratch namespaces
+func (r *RestoreVerificationReconciler) startRun(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, now time.Time) (ctrl.Result, error) {
+	verification.Status.Phase = oadpv1alpha1.RestoreVerificationPhaseWaiting
+	if verification.Spec.Paused {
+		verification.Status.NextRunTime = nil
+		return ctrl.Result{}, nil
+	}
+	next, err := nextRestoreVerificationRun(verification)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	verification.Status.NextRunTime = &metav1.Time{Time: next}
+	if now.Before(next) {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
+	run := oadpv1alpha1.RestoreVerificationRun{StartTime: metav1.NewTime(now)}
+	for _, source := range slices.Sorted(maps.Keys(verification.Spec.NamespaceMapping)) {
+		run.Namespaces = append(run.Namespaces, verification.Spec.NamespaceMapping[source])
+	}
+	// the scratch namespaces left by a previous drill are deleted, any other namespace is never touched
+	deleted, err := r.deleteScratchNamespaces(ctx, verification, run.Namespaces, true)
+	if err != nil {
+		var inUse *scratchNamespaceInUseError
+		if errors.As(err, &inUse) {
+			run.Message = err.Error()
+			r.finishRun(verification, run, oadpv1alpha1.RestoreVerificationResultFailed, now)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !deleted {
+		return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+	}
+
+	backup, err := r.latestCompletedBackup(ctx, verification)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if backup == nil {
+		run.Message = fmt.Sprintf("schedule %s has no completed backup", verification.Spec.ScheduleName)
+		r.finishRun(verification, run, oadpv1alpha1.RestoreVerificationResultFailed, now)
+		return ctrl.Result{Requeue: true}, nil
+	}
+	if err := r.deletePreviousRestores(ctx, verification); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	restore := &velerov1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", verification.Name, now.UTC().Format("20060102150405")),
+			Namespace: verification.Namespace,
+			Labels:    map[string]string{restoreVerificationLabel: label.GetValidName(verification.Name)},
+		},
+		Spec: velerov1.RestoreSpec{
+			BackupName:         backup.Name,
+			IncludedNamespaces: slices.Sorted(maps.Keys(verification.Spec.NamespaceMapping)),
+			NamespaceMapping:   verification.Spec.NamespaceMapping,
+		},
+	}
+	if len(verification.Spec.StorageClassMapping) > 0 {
+		resourceModifiers, err := r.createStorageClassResourceModifiers(ctx, verification, restore.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		restore.Spec.ResourceModifier = &corev1.TypedLocalObjectReference{Kind: "configmap", Name: resourceModifiers.Name}
+	}
+	if err := controllerutil.SetControllerReference(verification, restore, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, restore); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to create restore %s: %w", restore.Name, err)
+	}
+	r.EventRecorder.Event(verification, corev1.EventTypeNormal, "RestoreVerificationStarted",
+		fmt.Sprintf("restoring backup %s into namespaces %s with restore %s", backup.Name, strings.Join(run.Namespaces, ", "), restore.Name))
+
+	run.Backup = backup.Name
+	run.Restore = restore.Name
+	verification.Status.CurrentRun = &run
+	verification.Status.Phase = oadpv1alpha1.RestoreVerificationPhaseRestoring
+	verification.Status.NextRunTime = nil
+	return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+}
+
+// latestCompletedBackup returns the last completed backup of the schedule, nil without one
+func (r *RestoreVerificationReconciler) latestCompletedBackup(ctx context.Context, verification *oadpv1alpha1.RestoreVerification) (*velerov1.Backup, error) {
+	backups := &velerov1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(verification.Namespace),
+		client.MatchingLabels{velerov1.ScheduleNameLabel: label.GetValidName(verification.Spec.ScheduleName)}); err != nil {
+		return nil, err
+	}
+	var latest *velerov1.Backup
+	for i := range backups.Items {
+		backup := &backups.Items[i]
+		if backup.Status.Phase != velerov1.BackupPhaseCompleted || backup.Status.CompletionTimestamp == nil {
+			continue
+		}
+		if latest == nil || backup.Status.CompletionTimestamp.After(latest.Status.CompletionTimestamp.Time) {
+			latest = backup
+		}
+	}
+	return latest, nil
+}
+
+// deletePreviousRestores deletes the restores and resource modifiers of the previous drills
+func (r *RestoreVerificationReconciler) deletePreviousRestores(ctx context.Context, verification *oadpv1alpha1.RestoreVerification) error {
+	selector := client.MatchingLabels{restoreVerificationLabel: label.GetValidName(verification.Name)}
+	restores := &velerov1.RestoreList{}
+	if err := r.List(ctx, restores, client.InNamespace(verification.Namespace), selector); err != nil {
+		return err
+	}
+	for i := range restores.Items {
+		if err := r.Delete(ctx, &restores.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("unable to delete restore %s: %w", restores.Items[i].Name, err)
+		}
+	}
+	return client.IgnoreNotFound(r.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace(verification.Namespace), selector))
+}
+
+// resourceModifiers is the resource modifiers ConfigMap format of Velero
+type resourceModifiers struct {
+	Version               string                 `json:"version"`
+	ResourceModifierRules []resourceModifierRule `json:"resourceModifierRules"`
+}
+
+type resourceModifierRule struct {
+	Conditions resourceModifierConditions `json:"conditions"`
+	Patches    []resourceModifierPatch    `json:"patches"`
+}
+
+type resourceModifierConditions struct {
+	GroupResource string                  `json:"groupResource"`
+	Matches       []resourceModifierMatch `json:"matches,omitempty"`
+}
+
+type resourceModifierMatch struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+type resourceModifierPatch struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Value     string `json:"value"`
+}
+
+// createStorageClassResourceModifiers creates the resource modifiers changing the storage class of the restored
+// persistent volume claims
+func (r *RestoreVerificationReconciler) createStorageClassResourceModifiers(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, restoreName string) (*corev1.ConfigMap, error) {
+	modifiers := resourceModifiers{Version: "v1"}
+	for _, from := range slices.Sorted(maps.Keys(verification.Spec.StorageClassMapping)) {
+		rule := resourceModifierRule{
+			Conditions: resourceModifierConditions{
+				GroupResource: "persistentvolumeclaims",
+				Matches:       []resourceModifierMatch{{Path: "/spec/storageClassName", Value: from}},
+			},
+			Patches: []resourceModifierPatch{
+				{Operation: "replace", Path: "/spec/storageClassName", Value: verification.Spec.StorageClassMapping[from]},
+			},
+		}
+		modifiers.ResourceModifierRules = append(modifiers.ResourceModifierRules, rule)
+	}
+	data, err := yaml.Marshal(modifiers)
+	if err != nil {
+		return nil, err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      restoreName + "-resource-modifiers",
+			Namespace: verification.Namespace,
+			Labels:    map[string]string{restoreVerificationLabel: label.GetValidName(verification.Name)},
+		},
+		Data: map[string]string{"resource-modifiers.yaml": string(data)},
+	}
+	if err := controllerutil.SetControllerReference(verification, configMap, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, configMap); err != nil {
+		return nil, fmt.Errorf("unable to create resource modifiers %s: %w", configMap.Name, err)
+	}
+	return configMap, nil
+}
+
+// waitForRestore waits for the restore of the drill to finish, and labels the scratch namespaces it created
+func (r *RestoreVerificationReconciler) waitForRestore(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, now time.Time) (ctrl.Result, error) {
+	run := verification.Status.CurrentRun
+	restore := &velerov1.Restore{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: verification.Namespace, Name: run.Restore}, restore); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultFailed,
+			fmt.Sprintf("restore %s was deleted", run.Restore))
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if !restoreFinished(restore) {
+		if restoreVerificationTimedOut(verification, now) {
+			completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultFailed,
+				fmt.Sprintf("restore %s did not finish within %s", restore.Name, restoreVerificationTimeout(verification)))
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+	}
+
+	run.RestoreCompletionTime = &metav1.Time{Time: now}
+	if restore.Status.CompletionTimestamp != nil {
+		run.RestoreCompletionTime = restore.Status.CompletionTimestamp
+	}
+	if err := r.labelScratchNamespaces(ctx, verification, run.Namespaces); err != nil {
+		return ctrl.Result{}, err
+	}
+	if restore.Status.Phase != velerov1.RestorePhaseCompleted {
+		message := fmt.Sprintf("restore %s %s", restore.Name, restore.Status.Phase)
+		switch {
+		case restore.Status.FailureReason != "":
+			message += ": " + restore.Status.FailureReason
+		case len(restore.Status.ValidationErrors) > 0:
+			message += ": " + strings.Join(restore.Status.ValidationErrors, ", ")
+		case restore.Status.Errors > 0:
+			message += fmt.Sprintf(" with %d errors", restore.Status.Errors)
+		}
+		completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultFailed, message)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	run.Checks = nil
+	for _, check := range verification.Spec.Checks {
+		run.Checks = append(run.Checks, oadpv1alpha1.RestoreVerificationCheckResult{Name: check.Name, Message: "not run yet"})
+	}
+	verification.Status.Phase = oadpv1alpha1.RestoreVerificationPhaseVerifying
+	return ctrl.Result{Requeue: true}, nil
+}
+
+func restoreFinished(restore *velerov1.Restore) bool {
+	switch restore.Status.Phase {
+	case velerov1.RestorePhaseCompleted, velerov1.RestorePhasePartiallyFailed, velerov1.RestorePhaseFailed, velerov1.RestorePhaseFailedValidation:
+		return true
+	}
+	return false
+}
+
+// stopRestore deletes the restore of the drill if it is still running, and returns whether it is still running.
+// The scratch namespaces are only deleted once it stopped, or the restore would recreate them.
+func (r *RestoreVerificationReconciler) stopRestore(ctx context.Context, verification *oadpv1alpha1.RestoreVerification) (bool, error) {
+	run := verification.Status.CurrentRun
+	if run == nil || run.Restore == "" {
+		return false, nil
+	}
+	restore := &velerov1.Restore{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: verification.Namespace, Name: run.Restore}, restore); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if restoreFinished(restore) {
+		return false, nil
+	}
+	if restore.DeletionTimestamp.IsZero() {
+		if err := r.Delete(ctx, restore); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("unable to delete restore %s: %w", restore.Name, err)
+		}
+	}
+	return true, nil
+}
+
+// verify runs the checks not passed yet, until they all pass, one fails or the drill times out
+func (r *RestoreVerificationReconciler) verify(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, now time.Time) (ctrl.Result, error) {
+	run := verification.Status.CurrentRun
+	passed := map[string]bool{}
+	for _, result := range run.Checks {
+		passed[result.Name] = result.Passed
+	}
+
+	run.Checks = nil
+	failure := ""
+	pending := []string{}
+	for _, check := range verification.Spec.Checks {
+		result := oadpv1alpha1.RestoreVerificationCheckResult{Name: check.Name, Passed: passed[check.Name]}
+		if !result.Passed {
+			var failed bool
+			var err error
+			result.Passed, failed, result.Message, err = r.runCheck(ctx, verification, check)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("unable to run check %s: %w", check.Name, err)
+			}
+			if failed && failure == "" {
+				failure = fmt.Sprintf("check %s failed: %s", check.Name, result.Message)
+			}
+			if !result.Passed {
+				pending = append(pending, check.Name)
+			}
+		}
+		run.Checks = append(run.Checks, result)
+	}
+
+	switch {
+	case failure != "":
+		completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultFailed, failure)
+	case len(pending) == 0:
+		completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultPassed, "")
+	case restoreVerificationTimedOut(verification, now):
+		completeRestoreVerificationRun(verification, now, oadpv1alpha1.RestoreVerificationResultFailed,
+			fmt.Sprintf("checks %s did not pass within %s", strings.Join(pending, ", "), restoreVerificationTimeout(verification)))
+	default:
+		return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// runCheck runs a check once, and returns whether it passed, whether it failed for good, and why it has not passed
+func (r *RestoreVerificationReconciler) runCheck(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, check oadpv1alpha1.RestoreVerificationCheck) (passed bool, failed bool, message string, err error) {
+	switch {
+	case check.PodsReady != nil:
+		passed, message, err = r.podsReady(ctx, check.PodsReady)
+		return passed, false, message, err
+	case check.HTTPGet != nil:
+		passed, message = r.httpGet(ctx, check.HTTPGet)
+		return passed, false, message, nil
+	case check.Job != nil:
+		return r.jobSucceeded(ctx, verification, check)
+	}
+	return false, true, "no check set", nil
+}
+
+func (r *RestoreVerificationReconciler) podsReady(ctx context.Context, check *oadpv1alpha1.PodsReadyCheck) (bool, string, error) {
+	selector := labels.Everything()
+	if check.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(check.Selector); err != nil {
+			return false, "", err
+		}
+	}
+	pods := &corev1.PodList{}
+	if err := r.ClusterWideClient.List(ctx, pods, client.InNamespace(check.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, "", err
+	}
+	if len(pods.Items) == 0 {
+		return false, fmt.Sprintf("no pod in namespace %s matches the selector", check.Namespace), nil
+	}
+	notReady := []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		ready := false
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				ready = condition.Status == corev1.ConditionTrue
+			}
+		}
+		if !ready {
+			notReady = append(notReady, pod.Name)
+		}
+	}
+	if len(notReady) > 0 {
+		return false, fmt.Sprintf("pods %s are not ready", strings.Join(notReady, ", ")), nil
+	}
+	return true, "", nil
+}
+
+func (r *RestoreVerificationReconciler) httpGet(ctx context.Context, check *oadpv1alpha1.HTTPGetCheck) (bool, string) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	response, err := r.HTTPClient.Do(request)
+	if err != nil {
+		return false, err.Error()
+	}
+	defer response.Body.Close()
+	if check.ExpectedStatusCode != 0 && response.StatusCode != int(check.ExpectedStatusCode) ||
+		check.ExpectedStatusCode == 0 && (response.StatusCode < 200 || response.StatusCode > 299) {
+		return false, fmt.Sprintf("GET %s returned %s", check.URL, response.Status)
+	}
+	return true, ""
+}
+
+// jobSucceeded creates the job of the check, and returns whether it succeeded or failed
+func (r *RestoreVerificationReconciler) jobSucceeded(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, check oadpv1alpha1.RestoreVerificationCheck) (bool, bool, string, error) {
+	job := &batchv1.Job{}
+	key := client.ObjectKey{Namespace: check.Job.Namespace, Name: label.GetValidName(verification.Name + "-" + check.Name)}
+	if err := r.ClusterWideClient.Get(ctx, key, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, false, "", err
+		}
+		job = &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{restoreVerificationLabel: label.GetValidName(verification.Name)},
+			},
+			Spec: batchv1.JobSpec{
+				BackoffLimit: ptr.To(int32(0)),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers: []corev1.Container{{
+							Name:    "check",
+							Image:   check.Job.Image,
+							Command: check.Job.Command,
+							Args:    check.Job.Args,
+						}},
+					},
+				},
+			},
+		}
+		if err := r.ClusterWideClient.Create(ctx, job); err != nil {
+			return false, false, "", err
+		}
+		return false, false, fmt.Sprintf("job %s created", job.Name), nil
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, false, "", nil
+		case batchv1.JobFailed:
+			exitCode, err := r.jobExitCode(ctx, job)
+			if err != nil {
+				return false, false, "", err
+			}
+			if exitCode != nil {
+				return false, true, fmt.Sprintf("job %s exited with %d", job.Name, *exitCode), nil
+			}
+			return false, true, fmt.Sprintf("job %s failed: %s", job.Name, condition.Message), nil
+		}
+	}
+	return false, false, fmt.Sprintf("job %s is running", job.Name), nil
+}
+
+// jobExitCode returns the non zero exit code of the container of a failed job, nil if no pod of the job terminated
+func (r *RestoreVerificationReconciler) jobExitCode(ctx context.Context, job *batchv1.Job) (*int32, error) {
+	pods := &corev1.PodList{}
+	if err := r.ClusterWideClient.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+				return &status.State.Terminated.ExitCode, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func restoreVerificationTimeout(verification *oadpv1alpha1.RestoreVerification) time.Duration {
+	if verification.Spec.Timeout != nil {
+		return verification.Spec.Timeout.Duration
+	}
+	return defaultRestoreVerificationTimeout
+}
+
+func restoreVerificationTimedOut(verification *oadpv1alpha1.RestoreVerification, now time.Time) bool {
+	return now.Sub(verification.Status.CurrentRun.StartTime.Time) > restoreVerificationTimeout(verification)
+}
+
+// completeRestoreVerificationRun records the result of the drill in progress, whose scratch namespaces are then cleaned up
+func completeRestoreVerificationRun(verification *oadpv1alpha1.RestoreVerification, now time.Time, result oadpv1alpha1.RestoreVerificationResult, message string) {
+	run := verification.Status.CurrentRun
+	run.Result = result
+	run.Message = message
+	run.CompletionTime = &metav1.Time{Time: now}
+	if result == oadpv1alpha1.RestoreVerificationResultPassed {
+		run.RTO = &metav1.Duration{Duration: now.Sub(run.StartTime.Time)}
+	}
+	verification.Status.Phase = oadpv1alpha1.RestoreVerificationPhaseCleaningUp
+}
+
+// cleanUp stops the restore of the completed drill, deletes its scratch namespaces unless they are preserved,
+// and finishes the drill
+func (r *RestoreVerificationReconciler) cleanUp(ctx context.Context, verification *oadpv1alpha1.RestoreVerification) (ctrl.Result, error) {
+	run := verification.Status.CurrentRun
+	if run == nil {
+		verification.Status.Phase = oadpv1alpha1.RestoreVerificationPhaseWaiting
+		return ctrl.Result{Requeue: true}, nil
+	}
+	running, err := r.stopRestore(ctx, verification)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if running {
+		return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+	}
+	if run.Result == oadpv1alpha1.RestoreVerificationResultFailed && verification.Spec.PreserveOnFailure {
+		// labelled so the next drill deletes them
+		if err := r.labelScratchNamespaces(ctx, verification, run.Namespaces); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		deleted, err := r.deleteScratchNamespaces(ctx, verification, run.Namespaces, false)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !deleted {
+			return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+		}
+	}
+	r.finishRun(verification, *run, run.Result, run.CompletionTime.Time)
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// finishRun records a finished drill as the last drill
+func (r *RestoreVerificationReconciler) finishRun(verification *oadpv1alpha1.RestoreVerification, run oadpv1alpha1.RestoreVerificationRun, result oadpv1alpha1.RestoreVerificationResult, completed time.Time) {
+	run.Result = result
+	if run.CompletionTime == nil {
+		run.CompletionTime = &metav1.Time{Time: completed}
+	}
+	verification.Status.LastRun = &run
+	verification.Status.CurrentRun = nil
+	verification.Status.Phase = oadpv1alpha1.RestoreVerificationPhaseWaiting
+	if result == oadpv1alpha1.RestoreVerificationResultPassed {
+		verification.Status.LastSuccessfulRunTime = run.StartTime.DeepCopy()
+		r.EventRecorder.Event(verification, corev1.EventTypeNormal, "RestoreVerificationPassed",
+			fmt.Sprintf("restore drill of backup %s passed, recovery time %s", run.Backup, run.RTO.Duration))
+	} else {
+		r.EventRecorder.Event(verification, corev1.EventTypeWarning, "RestoreVerificationFailed", run.Message)
+	}
+	observeRestoreVerification(verification.Namespace, verification.Name, &run)
+}
+
+// scratchNamespaceInUseError is returned when a scratch namespace exists and was not created by a drill
+type scratchNamespaceInUseError struct {
+	namespace string
+}
+
+func (e *scratchNamespaceInUseError) Error() string {
+	return fmt.Sprintf("scratch namespace %s already exists and was not created by a restore drill", e.namespace)
+}
+
+// deleteScratchNamespaces deletes the scratch namespaces and returns whether they are all gone. With onlyLabelled,
+// a namespace without the label of the RestoreVerification is not deleted and a scratchNamespaceInUseError is returned.
+func (r *RestoreVerificationReconciler) deleteScratchNamespaces(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, namespaces []string, onlyLabelled bool) (bool, error) {
+	deleted := true
+	for _, name := range namespaces {
+		namespace := &corev1.Namespace{}
+		if err := r.ClusterWideClient.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if onlyLabelled && namespace.Labels[restoreVerificationLabel] != label.GetValidName(verification.Name) {
+			return false, &scratchNamespaceInUseError{namespace: name}
+		}
+		deleted = false
+		if namespace.DeletionTimestamp.IsZero() {
+			if err := r.ClusterWideClient.Delete(ctx, namespace); client.IgnoreNotFound(err) != nil {
+				return false, fmt.Errorf("unable to delete scratch namespace %s: %w", name, err)
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// labelScratchNamespaces labels the scratch namespaces created by the restore with the name of the RestoreVerification
+func (r *RestoreVerificationReconciler) labelScratchNamespaces(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, namespaces []string) error {
+	for _, name := range namespaces {
+		namespace := &corev1.Namespace{}
+		if err := r.ClusterWideClient.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if namespace.Labels[restoreVerificationLabel] == label.GetValidName(verification.Name) {
+			continue
+		}
+		original := namespace.DeepCopy()
+		if namespace.Labels == nil {
+			namespace.Labels = map[string]string{}
+		}
+		namespace.Labels[restoreVerificationLabel] = label.GetValidName(verification.Name)
+		if err := r.ClusterWideClient.Patch(ctx, namespace, client.MergeFrom(original)); err != nil {
+			return fmt.Errorf("unable to label scratch namespace %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// finalize deletes the scratch namespaces of the drill in progress and the preserved scratch namespaces
+// before the RestoreVerification is deleted
+func (r *RestoreVerificationReconciler) finalize(ctx context.Context, verification *oadpv1alpha1.RestoreVerification) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(verification, restoreVerificationFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	running, err := r.stopRestore(ctx, verification)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if running {
+		return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := r.ClusterWideClient.List(ctx, namespaces, client.MatchingLabels{restoreVerificationLabel: label.GetValidName(verification.Name)}); err != nil {
+		return ctrl.Result{}, err
+	}
+	scratchNamespaces := []string{}
+	if verification.Status.CurrentRun != nil {
+		scratchNamespaces = append(scratchNamespaces, verification.Status.CurrentRun.Namespaces...)
+	}
+	for _, namespace := range namespaces.Items {
+		if !slices.Contains(scratchNamespaces, namespace.Name) {
+			scratchNamespaces = append(scratchNamespaces, namespace.Name)
+		}
+	}
+	deleted, err := r.deleteScratchNamespaces(ctx, verification, scratchNamespaces, false)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !deleted {
+		return ctrl.Result{RequeueAfter: restoreVerificationPollPeriod}, nil
+	}
+	deleteRestoreVerificationMetrics(verification.Namespace, verification.Name)
+	controllerutil.RemoveFinalizer(verification, restoreVerificationFinalizer)
+	return ctrl.Result{}, r.Update(ctx, verification)
+}
+
+func (r *RestoreVerificationReconciler) updateRestoreVerificationStatus(ctx context.Context, verification *oadpv1alpha1.RestoreVerification, reconcileErr error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &oadpv1alpha1.RestoreVerification{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(verification), latest); err != nil {
+			return err
+		}
+		condition := metav1.Condition{
+			Type:               oadpv1alpha1.ConditionReconciled,
+			Status:             metav1.ConditionTrue,
+			Reason:             oadpv1alpha1.ReconciledReasonComplete,
+			Message:            "Reconcile complete",
+			ObservedGeneration: latest.Generation,
+		}
+		if reconcileErr != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = oadpv1alpha1.ReconciledReasonError
+			condition.Message = reconcileErr.Error()
+		}
+		conditions := latest.Status.Conditions
+		latest.Status = *verification.Status.DeepCopy()
+		latest.Status.Conditions = conditions
+		meta.SetStatusCondition(&latest.Status.Conditions, condition)
+		return r.Status().Update(ctx, latest)
+	})
+}
@@ -0,0 +1,434 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func newRestoreVerificationForTest(checks ...oadpv1alpha1.RestoreVerificationCheck) *oadpv1alpha1.RestoreVerification {
+	return &oadpv1alpha1.RestoreVerification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "drill",
+			Namespace:         testNamespaceName,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+			Finalizers:        []string{restoreVerificationFinalizer},
+		},
+		Spec: oadpv1alpha1.RestoreVerificationSpec{
+			ScheduleName:        "daily",
+			Schedule:            "@daily",
+			NamespaceMapping:    map[string]string{"app": "app-drill"},
+			StorageClassMapping: map[string]string{"gp2": "gp3"},
+			Checks:              checks,
+		},
+	}
+}
+
+func reconcileRestoreVerificationForTest(t *testing.T, r *RestoreVerificationReconciler, verification *oadpv1alpha1.RestoreVerification) ctrl.Result {
+	t.Helper()
+	result, err := r.Reconcile(newContextForTest(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(verification)})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := r.Get(newContextForTest(), client.ObjectKeyFromObject(verification), verification); err != nil {
+		t.Fatalf("unable to get restore verification: %v", err)
+	}
+	return result
+}
+
+func TestRestoreVerificationReconciler_Reconcile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	verification := newRestoreVerificationForTest(
+		oadpv1alpha1.RestoreVerificationCheck{Name: "pods", PodsReady: &oadpv1alpha1.PodsReadyCheck{Namespace: "app-drill"}},
+		oadpv1alpha1.RestoreVerificationCheck{Name: "http", HTTPGet: &oadpv1alpha1.HTTPGetCheck{URL: "http://app.app-drill.svc:8080/healthz"}},
+		oadpv1alpha1.RestoreVerificationCheck{Name: "job", Job: &oadpv1alpha1.JobCheck{Namespace: "app-drill", Image: "busybox", Command: []string{"true"}}},
+	)
+	now := time.Now()
+	fakeClient, err := getFakeClientWithStatusFromObjects(
+		verification,
+		newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-30*time.Hour)),
+		newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-6*time.Hour)),
+		newCoverageTestBackup("daily", velerov1.BackupPhaseFailed, now.Add(-time.Hour)),
+	)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &RestoreVerificationReconciler{
+		Client:            fakeClient,
+		Scheme:            fakeClient.Scheme(),
+		EventRecorder:     record.NewFakeRecorder(20),
+		ClusterWideClient: fakeClient,
+		// the service of the scratch namespace is served by the test server
+		HTTPClient: &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+		}},
+	}
+	ctx := newContextForTest()
+
+	// the drill starts by restoring the latest completed backup into the scratch namespace
+	reconcileRestoreVerificationForTest(t, r, verification)
+	if verification.Status.Phase != oadpv1alpha1.RestoreVerificationPhaseRestoring || verification.Status.CurrentRun == nil {
+		t.Fatalf("Reconcile() status = %+v, want a drill restoring", verification.Status)
+	}
+	run := verification.Status.CurrentRun
+	restore := &velerov1.Restore{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespaceName, Name: run.Restore}, restore); err != nil {
+		t.Fatalf("Reconcile() did not create the restore: %v", err)
+	}
+	wantBackup := newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-6*time.Hour)).Name
+	if restore.Spec.BackupName != wantBackup || run.Backup != wantBackup {
+		t.Errorf("Reconcile() restored backup %s, want %s", restore.Spec.BackupName, wantBackup)
+	}
+	if restore.Spec.NamespaceMapping["app"] != "app-drill" || restore.Spec.ResourceModifier == nil {
+		t.Errorf("Reconcile() restore spec = %+v, want namespace mapping and resource modifiers", restore.Spec)
+	}
+	resourceModifiers := &corev1.ConfigMap{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespaceName, Name: restore.Spec.ResourceModifier.Name}, resourceModifiers); err != nil {
+		t.Fatalf("Reconcile() did not create the resource modifiers: %v", err)
+	}
+	if data := resourceModifiers.Data["resource-modifiers.yaml"]; !strings.Contains(data, "value: gp2") || !strings.Contains(data, "value: gp3") {
+		t.Errorf("Reconcile() resource modifiers = %s, want gp2 replaced with gp3", data)
+	}
+
+	// the restore completes, creating the scratch namespace and a ready pod
+	restore.Status.Phase = velerov1.RestorePhaseCompleted
+	restore.Status.CompletionTimestamp = &metav1.Time{Time: now}
+	if err := fakeClient.Status().Update(ctx, restore); err != nil {
+		t.Fatalf("unable to update restore: %v", err)
+	}
+	for _, obj := range []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-drill"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "app-drill"},
+			Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+		},
+	} {
+		if err := fakeClient.Create(ctx, obj); err != nil {
+			t.Fatalf("unable to create %s: %v", obj.GetName(), err)
+		}
+	}
+	reconcileRestoreVerificationForTest(t, r, verification)
+	if verification.Status.Phase != oadpv1alpha1.RestoreVerificationPhaseVerifying {
+		t.Fatalf("Reconcile() phase = %s, want Verifying", verification.Status.Phase)
+	}
+	namespace := &corev1.Namespace{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Name: "app-drill"}, namespace); err != nil || namespace.Labels[restoreVerificationLabel] != "drill" {
+		t.Errorf("Reconcile() did not label the scratch namespace: %v", err)
+	}
+
+	// the pods and http checks pass, the job is created
+	result := reconcileRestoreVerificationForTest(t, r, verification)
+	if result.RequeueAfter != restoreVerificationPollPeriod {
+		t.Errorf("Reconcile() requeue after = %s, want %s", result.RequeueAfter, restoreVerificationPollPeriod)
+	}
+	for _, check := range verification.Status.CurrentRun.Checks {
+		if check.Passed != (check.Name != "job") {
+			t.Errorf("Reconcile() check %s passed = %t, message %q", check.Name, check.Passed, check.Message)
+		}
+	}
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "app-drill", Name: "drill-job"}, job); err != nil {
+		t.Fatalf("Reconcile() did not create the job: %v", err)
+	}
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if err := fakeClient.Status().Update(ctx, job); err != nil {
+		t.Fatalf("unable to update job: %v", err)
+	}
+
+	// the drill passes and its scratch namespace is deleted
+	reconcileRestoreVerificationForTest(t, r, verification)
+	if verification.Status.Phase != oadpv1alpha1.RestoreVerificationPhaseCleaningUp {
+		t.Fatalf("Reconcile() phase = %s, want CleaningUp", verification.Status.Phase)
+	}
+	reconcileRestoreVerificationForTest(t, r, verification)
+	if err := fakeClient.Get(ctx, client.ObjectKey{Name: "app-drill"}, namespace); !apierrors.IsNotFound(err) {
+		t.Errorf("Reconcile() did not delete the scratch namespace: %v", err)
+	}
+	if verification.Status.Phase != oadpv1alpha1.RestoreVerificationPhaseCleaningUp {
+		t.Fatalf("Reconcile() phase = %s, want CleaningUp until the scratch namespace is gone", verification.Status.Phase)
+	}
+	reconcileRestoreVerificationForTest(t, r, verification)
+	status := verification.Status
+	if status.Phase != oadpv1alpha1.RestoreVerificationPhaseWaiting || status.CurrentRun != nil || status.LastRun == nil {
+		t.Fatalf("Reconcile() status = %+v, want the drill finished", status)
+	}
+	if status.LastRun.Result != oadpv1alpha1.RestoreVerificationResultPassed || status.LastRun.RTO == nil || status.LastSuccessfulRunTime == nil {
+		t.Errorf("Reconcile() last run = %+v, want Passed with an RTO", status.LastRun)
+	}
+
+	// the next drill waits for the next day
+	result = reconcileRestoreVerificationForTest(t, r, verification)
+	if verification.Status.NextRunTime == nil || result.RequeueAfter <= 0 || result.RequeueAfter > 24*time.Hour {
+		t.Errorf("Reconcile() next run = %v, requeue after %s, want the next day", verification.Status.NextRunTime, result.RequeueAfter)
+	}
+}
+
+func TestRestoreVerificationReconciler_ReconcileFailures(t *testing.T) {
+	now := time.Now()
+	t.Run("restore partially failed", func(t *testing.T) {
+		verification := newRestoreVerificationForTest()
+		verification.Spec.PreserveOnFailure = true
+		fakeClient, err := getFakeClientWithStatusFromObjects(
+			verification,
+			newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-time.Hour)),
+		)
+		if err != nil {
+			t.Fatalf("error in creating fake client, likely programmer error")
+		}
+		r := &RestoreVerificationReconciler{
+			Client:            fakeClient,
+			Scheme:            fakeClient.Scheme(),
+			EventRecorder:     record.NewFakeRecorder(20),
+			ClusterWideClient: fakeClient,
+			HTTPClient:        http.DefaultClient,
+		}
+		ctx := newContextForTest()
+		reconcileRestoreVerificationForTest(t, r, verification)
+		restore := &velerov1.Restore{}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespaceName, Name: verification.Status.CurrentRun.Restore}, restore); err != nil {
+			t.Fatalf("Reconcile() did not create the restore: %v", err)
+		}
+		restore.Status.Phase = velerov1.RestorePhasePartiallyFailed
+		restore.Status.Errors = 2
+		if err := fakeClient.Status().Update(ctx, restore); err != nil {
+			t.Fatalf("unable to update restore: %v", err)
+		}
+		if err := fakeClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-drill"}}); err != nil {
+			t.Fatalf("unable to create namespace: %v", err)
+		}
+
+		reconcileRestoreVerificationForTest(t, r, verification)
+		reconcileRestoreVerificationForTest(t, r, verification)
+		lastRun := verification.Status.LastRun
+		if lastRun == nil || lastRun.Result != oadpv1alpha1.RestoreVerificationResultFailed || !strings.Contains(lastRun.Message, "with 2 errors") {
+			t.Fatalf("Reconcile() last run = %+v, want Failed with the restore errors", lastRun)
+		}
+		// preserved for troubleshooting, and labelled so the next drill deletes it
+		namespace := &corev1.Namespace{}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Name: "app-drill"}, namespace); err != nil || namespace.Labels[restoreVerificationLabel] != "drill" {
+			t.Errorf("Reconcile() did not preserve the scratch namespace: %v", err)
+		}
+	})
+
+	t.Run("restore timed out", func(t *testing.T) {
+		verification := newRestoreVerificationForTest()
+		fakeClient, err := getFakeClientWithStatusFromObjects(
+			verification,
+			newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-time.Hour)),
+		)
+		if err != nil {
+			t.Fatalf("error in creating fake client, likely programmer error")
+		}
+		r := &RestoreVerificationReconciler{
+			Client:            fakeClient,
+			Scheme:            fakeClient.Scheme(),
+			EventRecorder:     record.NewFakeRecorder(20),
+			ClusterWideClient: fakeClient,
+			HTTPClient:        http.DefaultClient,
+		}
+		ctx := newContextForTest()
+		reconcileRestoreVerificationForTest(t, r, verification)
+		verification.Status.CurrentRun.StartTime = metav1.NewTime(now.Add(-2 * defaultRestoreVerificationTimeout))
+		if err := fakeClient.Status().Update(ctx, verification); err != nil {
+			t.Fatalf("unable to update restore verification: %v", err)
+		}
+		// the restore is still running and has created the scratch namespace
+		restore := &velerov1.Restore{}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: testNamespaceName, Name: verification.Status.CurrentRun.Restore}, restore); err != nil {
+			t.Fatalf("Reconcile() did not create the restore: %v", err)
+		}
+		restore.Finalizers = []string{"restores.velero.io/external-resources-finalizer"}
+		restore.Status.Phase = velerov1.RestorePhaseInProgress
+		if err := fakeClient.Update(ctx, restore); err != nil {
+			t.Fatalf("unable to update restore: %v", err)
+		}
+		if err := fakeClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-drill"}}); err != nil {
+			t.Fatalf("unable to create namespace: %v", err)
+		}
+
+		reconcileRestoreVerificationForTest(t, r, verification)
+		if verification.Status.Phase != oadpv1alpha1.RestoreVerificationPhaseCleaningUp {
+			t.Fatalf("Reconcile() phase = %s, want CleaningUp", verification.Status.Phase)
+		}
+		// the scratch namespace is kept until the restore is gone, or the restore would recreate it
+		reconcileRestoreVerificationForTest(t, r, verification)
+		if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(restore), restore); err != nil || restore.DeletionTimestamp.IsZero() {
+			t.Fatalf("Reconcile() did not delete the running restore: %v", err)
+		}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Name: "app-drill"}, &corev1.Namespace{}); err != nil {
+			t.Errorf("Reconcile() deleted the scratch namespace while the restore is running: %v", err)
+		}
+
+		restore.Finalizers = nil
+		if err := fakeClient.Update(ctx, restore); err != nil {
+			t.Fatalf("unable to update restore: %v", err)
+		}
+		reconcileRestoreVerificationForTest(t, r, verification)
+		reconcileRestoreVerificationForTest(t, r, verification)
+		if err := fakeClient.Get(ctx, client.ObjectKey{Name: "app-drill"}, &corev1.Namespace{}); !apierrors.IsNotFound(err) {
+			t.Errorf("Reconcile() did not delete the scratch namespace: %v", err)
+		}
+		lastRun := verification.Status.LastRun
+		if lastRun == nil || lastRun.Result != oadpv1alpha1.RestoreVerificationResultFailed || !strings.Contains(lastRun.Message, "did not finish") {
+			t.Fatalf("Reconcile() last run = %+v, want Failed with the restore timeout", lastRun)
+		}
+	})
+
+	t.Run("scratch namespace in use", func(t *testing.T) {
+		verification := newRestoreVerificationForTest()
+		fakeClient, err := getFakeClientWithStatusFromObjects(
+			verification,
+			newCoverageTestBackup("daily", velerov1.BackupPhaseCompleted, now.Add(-time.Hour)),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app-drill"}},
+		)
+		if err != nil {
+			t.Fatalf("error in creating fake client, likely programmer error")
+		}
+		r := &RestoreVerificationReconciler{
+			Client:            fakeClient,
+			Scheme:            fakeClient.Scheme(),
+			EventRecorder:     record.NewFakeRecorder(20),
+			ClusterWideClient: fakeClient,
+			HTTPClient:        http.DefaultClient,
+		}
+		reconcileRestoreVerificationForTest(t, r, verification)
+		lastRun := verification.Status.LastRun
+		if lastRun == nil || lastRun.Result != oadpv1alpha1.RestoreVerificationResultFailed || !strings.Contains(lastRun.Message, "already exists") {
+			t.Fatalf("Reconcile() last run = %+v, want Failed with the scratch namespace in use", lastRun)
+		}
+		if err := fakeClient.Get(newContextForTest(), client.ObjectKey{Name: "app-drill"}, &corev1.Namespace{}); err != nil {
+			t.Errorf("Reconcile() deleted a namespace it did not create: %v", err)
+		}
+		restores := &velerov1.RestoreList{}
+		if err := fakeClient.List(newContextForTest(), restores); err != nil || len(restores.Items) != 0 {
+			t.Errorf("Reconcile() created restores %v, want none", restores.Items)
+		}
+	})
+
+	t.Run("no completed backup", func(t *testing.T) {
+		verification := newRestoreVerificationForTest()
+		fakeClient, err := getFakeClientWithStatusFromObjects(
+			verification,
+			newCoverageTestBackup("daily", velerov1.BackupPhaseFailed, now.Add(-time.Hour)),
+		)
+		if err != nil {
+			t.Fatalf("error in creating fake client, likely programmer error")
+		}
+		r := &RestoreVerificationReconciler{
+			Client:            fakeClient,
+			Scheme:            fakeClient.Scheme(),
+			EventRecorder:     record.NewFakeRecorder(20),
+			ClusterWideClient: fakeClient,
+			HTTPClient:        http.DefaultClient,
+		}
+		reconcileRestoreVerificationForTest(t, r, verification)
+		lastRun := verification.Status.LastRun
+		if lastRun == nil || lastRun.Result != oadpv1alpha1.RestoreVerificationResultFailed || !strings.Contains(lastRun.Message, "no completed backup") {
+			t.Fatalf("Reconcile() last run = %+v, want Failed without a backup", lastRun)
+		}
+	})
+}
+
+func TestValidateRestoreVerification(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*oadpv1alpha1.RestoreVerification)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*oadpv1alpha1.RestoreVerification) {},
+		},
+		{
+			name:    "invalid schedule",
+			mutate:  func(v *oadpv1alpha1.RestoreVerification) { v.Spec.Schedule = "every day" },
+			wantErr: "invalid schedule",
+		},
+		{
+			name:    "restored namespace is a scratch namespace",
+			mutate:  func(v *oadpv1alpha1.RestoreVerification) { v.Spec.NamespaceMapping["app-drill"] = "other-drill" },
+			wantErr: "both restored and a scratch namespace",
+		},
+		{
+			name:    "operator namespace is a scratch namespace",
+			mutate:  func(v *oadpv1alpha1.RestoreVerification) { v.Spec.NamespaceMapping["app"] = testNamespaceName },
+			wantErr: "cannot be a scratch namespace",
+		},
+		{
+			name: "check outside of the scratch namespaces",
+			mutate: func(v *oadpv1alpha1.RestoreVerification) {
+				v.Spec.Checks = []oadpv1alpha1.RestoreVerificationCheck{{Name: "pods", PodsReady: &oadpv1alpha1.PodsReadyCheck{Namespace: "app"}}}
+			},
+			wantErr: "not a scratch namespace",
+		},
+		{
+			name: "check of two kinds",
+			mutate: func(v *oadpv1alpha1.RestoreVerification) {
+				v.Spec.Checks = []oadpv1alpha1.RestoreVerificationCheck{{
+					Name:      "pods",
+					PodsReady: &oadpv1alpha1.PodsReadyCheck{Namespace: "app-drill"},
+					HTTPGet:   &oadpv1alpha1.HTTPGetCheck{URL: "http://app.app-drill.svc"},
+				}}
+			},
+			wantErr: "exactly one of",
+		},
+		{
+			name: "invalid url",
+			mutate: func(v *oadpv1alpha1.RestoreVerification) {
+				v.Spec.Checks = []oadpv1alpha1.RestoreVerificationCheck{{Name: "http", HTTPGet: &oadpv1alpha1.HTTPGetCheck{URL: "ftp://app.app-drill.svc"}}}
+			},
+			wantErr: "url scheme",
+		},
+		{
+			name: "url of a service of a scratch namespace",
+			mutate: func(v *oadpv1alpha1.RestoreVerification) {
+				v.Spec.Checks = []oadpv1alpha1.RestoreVerificationCheck{{Name: "http", HTTPGet: &oadpv1alpha1.HTTPGetCheck{URL: "https://app.app-drill.svc.cluster.local:8443/healthz"}}}
+			},
+		},
+		{
+			name: "url of a service outside of the scratch namespaces",
+			mutate: func(v *oadpv1alpha1.RestoreVerification) {
+				v.Spec.Checks = []oadpv1alpha1.RestoreVerificationCheck{{Name: "http", HTTPGet: &oadpv1alpha1.HTTPGetCheck{URL: "http://kubernetes.default.svc/api"}}}
+			},
+			wantErr: "not a service of a scratch namespace",
+		},
+		{
+			name: "url outside of the cluster",
+			mutate: func(v *oadpv1alpha1.RestoreVerification) {
+				v.Spec.Checks = []oadpv1alpha1.RestoreVerificationCheck{{Name: "http", HTTPGet: &oadpv1alpha1.HTTPGetCheck{URL: "http://169.254.169.254/latest/meta-data"}}}
+			},
+			wantErr: "not a service of a scratch namespace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := newRestoreVerificationForTest()
+			tt.mutate(verification)
+			err := validateRestoreVerification(verification)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("validateRestoreVerification() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	velerov2alpha1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v2alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// defaultRolloutGateTimeout is how long a disruptive change waits for in-progress operations
+// when spec.rolloutGate.timeout is not set
+const defaultRolloutGateTimeout = time.Hour
+
+// rolloutGateRequeueInterval is how often a change deferred by in-progress operations is retried,
+// as operations finishing do not trigger a reconcile
+const rolloutGateRequeueInterval = time.Minute
+
+// maxReportedOperations limits the operations listed in the RolloutDeferred condition message
+const maxReportedOperations = 10
+
+func (r *DataProtectionApplicationReconciler) rolloutGateTimeout() time.Duration {
+	if r.dpa.Spec.RolloutGate != nil && r.dpa.Spec.RolloutGate.Timeout != nil {
+		return r.dpa.Spec.RolloutGate.Timeout.Duration
+	}
+	return defaultRolloutGateTimeout
+}
+
+// inProgressOperations returns the backups, restores and data movements running in the DPA namespace,
+// which a restart of the Velero or NodeAgent pods would fail
+func (r *DataProtectionApplicationReconciler) inProgressOperations() ([]string, error) {
+	namespace := client.InNamespace(r.NamespacedName.Namespace)
+	operations := []string{}
+
+	backups := &velerov1.BackupList{}
+	if err := r.List(r.Context, backups, namespace); err != nil {
+		return nil, err
+	}
+	for _, backup := range backups.Items {
+		// a backup waiting for plugin operations or finalizing still needs the Velero pod
+		switch backup.Status.Phase {
+		case velerov1.BackupPhaseInProgress,
+			velerov1.BackupPhaseWaitingForPluginOperations, velerov1.BackupPhaseWaitingForPluginOperationsPartiallyFailed,
+			velerov1.BackupPhaseFinalizing, velerov1.BackupPhaseFinalizingPartiallyFailed:
+			operations = append(operations, "backup/"+backup.Name)
+		}
+	}
+
+	restores := &velerov1.RestoreList{}
+	if err := r.List(r.Context, restores, namespace); err != nil {
+		return nil, err
+	}
+	for _, restore := range restores.Items {
+		switch restore.Status.Phase {
+		case velerov1.RestorePhaseInProgress,
+			velerov1.RestorePhaseWaitingForPluginOperations, velerov1.RestorePhaseWaitingForPluginOperationsPartiallyFailed,
+			velerov1.RestorePhaseFinalizing, velerov1.RestorePhaseFinalizingPartiallyFailed:
+			operations = append(operations, "restore/"+restore.Name)
+		}
+	}
+
+	podVolumeBackups := &velerov1.PodVolumeBackupList{}
+	if err := r.List(r.Context, podVolumeBackups, namespace); err != nil {
+		return nil, err
+	}
+	for _, podVolumeBackup := range podVolumeBackups.Items {
+		if podVolumeBackup.Status.Phase == velerov1.PodVolumeBackupPhaseInProgress {
+			operations = append(operations, "podvolumebackup/"+podVolumeBackup.Name)
+		}
+	}
+
+	podVolumeRestores := &velerov1.PodVolumeRestoreList{}
+	if err := r.List(r.Context, podVolumeRestores, namespace); err != nil {
+		return nil, err
+	}
+	for _, podVolumeRestore := range podVolumeRestores.Items {
+		if podVolumeRestore.Status.Phase == velerov1.PodVolumeRestorePhaseInProgress {
+			operations = append(operations, "podvolumerestore/"+podVolumeRestore.Name)
+		}
+	}
+
+	dataUploads := &velerov2alpha1.DataUploadList{}
+	if err := r.List(r.Context, dataUploads, namespace); err != nil {
+		return nil, err
+	}
+	for _, dataUpload := range dataUploads.Items {
+		switch dataUpload.Status.Phase {
+		case velerov2alpha1.DataUploadPhaseAccepted, velerov2alpha1.DataUploadPhasePrepared, velerov2alpha1.DataUploadPhaseInProgress:
+			operations = append(operations, "dataupload/"+dataUpload.Name)
+		}
+	}
+
+	dataDownloads := &velerov2alpha1.DataDownloadList{}
+	if err := r.List(r.Context, dataDownloads, namespace); err != nil {
+		return nil, err
+	}
+	for _, dataDownload := range dataDownloads.Items {
+		switch dataDownload.Status.Phase {
+		case velerov2alpha1.DataDownloadPhaseAccepted, velerov2alpha1.DataDownloadPhasePrepared, velerov2alpha1.DataDownloadPhaseInProgress:
+			operations = append(operations, "datadownload/"+dataDownload.Name)
+		}
+	}
+	return operations, nil
+}
+
+// maintenanceWindowOpen returns true if now is inside the maintenance window, or else the time the window next opens
+func maintenanceWindowOpen(window *oadpv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	if window == nil {
+		return true, time.Time{}, nil
+	}
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	// the first window opening after now-duration is either the open window or the next one
+	opensAt := schedule.Next(now.Add(-window.Duration.Duration))
+	if !opensAt.After(now) {
+		return true, time.Time{}, nil
+	}
+	return false, opensAt, nil
+}
+
+// deferRollout returns true if a change restarting the pods of component must wait, either for the maintenance
+// window to open or for in-progress operations to finish.
+// The deferral is reported in the RolloutDeferred condition, whose transition time starts the rollout gate timeout;
+// once the timeout passes, the change is applied and a warning event is recorded.
+func (r *DataProtectionApplicationReconciler) deferRollout(component string) (bool, error) {
+	// the plan reports the operations a change would disrupt instead
+	if r.isPlanning() {
+		return false, nil
+	}
+
+	now := r.now()
+	open, opensAt, err := maintenanceWindowOpen(r.dpa.Spec.MaintenanceWindow, now)
+	if err != nil {
+		return false, err
+	}
+	if !open {
+		r.deferredRollouts = append(r.deferredRollouts, component)
+		r.retryRolloutAfter(opensAt.Sub(now))
+		r.setRolloutDeferredCondition(oadpv1alpha1.RolloutDeferredReasonOutsideMaintenanceWindow,
+			fmt.Sprintf("Changes to %s are deferred until the maintenance window opens at %s",
+				strings.Join(r.deferredRollouts, ", "), opensAt.UTC().Format(time.RFC3339)),
+		)
+		return true, nil
+	}
+
+	timeout := r.rolloutGateTimeout()
+	if timeout <= 0 {
+		return false, nil
+	}
+	operations, err := r.inProgressOperations()
+	if err != nil {
+		return false, err
+	}
+	if len(operations) == 0 {
+		return false, nil
+	}
+
+	deferredSince := now
+	condition := apimeta.FindStatusCondition(r.dpa.Status.Conditions, oadpv1alpha1.ConditionRolloutDeferred)
+	if condition != nil && condition.Status == metav1.ConditionTrue && condition.Reason == oadpv1alpha1.RolloutDeferredReasonOperationsInProgress {
+		deferredSince = condition.LastTransitionTime.Time
+	}
+	deadline := deferredSince.Add(timeout)
+	if !now.Before(deadline) {
+		r.EventRecorder.Event(r.dpa,
+			corev1.EventTypeWarning,
+			"RolloutGateTimeoutExceeded",
+			fmt.Sprintf("applying change to %s after waiting %s for in-progress operations: %s", component, timeout, summarizeOperations(operations)),
+		)
+		return false, nil
+	}
+
+	r.deferredRollouts = append(r.deferredRollouts, component)
+	r.retryRolloutAfter(rolloutGateRequeueInterval)
+	r.setRolloutDeferredCondition(oadpv1alpha1.RolloutDeferredReasonOperationsInProgress,
+		fmt.Sprintf("Changes to %s are deferred until %s or until these operations finish: %s",
+			strings.Join(r.deferredRollouts, ", "), deadline.UTC().Format(time.RFC3339), summarizeOperations(operations)),
+	)
+	return true, nil
+}
+
+// setRolloutDeferredCondition sets the RolloutDeferred condition, restarting its transition time when the reason changes
+func (r *DataProtectionApplicationReconciler) setRolloutDeferredCondition(reason, message string) {
+	condition := apimeta.FindStatusCondition(r.dpa.Status.Conditions, oadpv1alpha1.ConditionRolloutDeferred)
+	if condition != nil && condition.Reason != reason {
+		apimeta.RemoveStatusCondition(&r.dpa.Status.Conditions, oadpv1alpha1.ConditionRolloutDeferred)
+	}
+	apimeta.SetStatusCondition(&r.dpa.Status.Conditions,
+		metav1.Condition{
+			Type:    oadpv1alpha1.ConditionRolloutDeferred,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: message,
+		},
+	)
+}
+
+// retryRolloutAfter requeues the DPA after d, unless a deferred rollout is already retried sooner
+func (r *DataProtectionApplicationReconciler) retryRolloutAfter(d time.Duration) {
+	if r.rolloutRetryAfter == 0 || d < r.rolloutRetryAfter {
+		r.rolloutRetryAfter = d
+	}
+}
+
+func summarizeOperations(operations []string) string {
+	if len(operations) <= maxReportedOperations {
+		return strings.Join(operations, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(operations[:maxReportedOperations], ", "), len(operations)-maxReportedOperations)
+}
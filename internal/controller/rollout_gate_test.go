@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	velerov2alpha1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v2alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+func TestDPAReconciler_inProgressOperations(t *testing.T) {
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: testNamespaceName}
+	}
+	dpa := &oadpv1alpha1.DataProtectionApplication{ObjectMeta: objectMeta(testDpaName)}
+	fakeClient, err := getFakeClientFromObjects(
+		dpa,
+		&velerov1.Backup{ObjectMeta: objectMeta("running"), Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress}},
+		&velerov1.Backup{ObjectMeta: objectMeta("completed"), Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseCompleted}},
+		&velerov1.Backup{ObjectMeta: objectMeta("waiting"), Status: velerov1.BackupStatus{Phase: velerov1.BackupPhaseWaitingForPluginOperations}},
+		&velerov1.Restore{ObjectMeta: objectMeta("running"), Status: velerov1.RestoreStatus{Phase: velerov1.RestorePhaseInProgress}},
+		&velerov1.Restore{ObjectMeta: objectMeta("finalizing"), Status: velerov1.RestoreStatus{Phase: velerov1.RestorePhaseFinalizingPartiallyFailed}},
+		&velerov1.PodVolumeBackup{ObjectMeta: objectMeta("running"), Status: velerov1.PodVolumeBackupStatus{Phase: velerov1.PodVolumeBackupPhaseInProgress}},
+		&velerov1.PodVolumeRestore{ObjectMeta: objectMeta("failed"), Status: velerov1.PodVolumeRestoreStatus{Phase: velerov1.PodVolumeRestorePhaseFailed}},
+		&velerov2alpha1.DataUpload{ObjectMeta: objectMeta("accepted"), Status: velerov2alpha1.DataUploadStatus{Phase: velerov2alpha1.DataUploadPhaseAccepted}},
+		&velerov2alpha1.DataUpload{ObjectMeta: objectMeta("new"), Status: velerov2alpha1.DataUploadStatus{Phase: velerov2alpha1.DataUploadPhaseNew}},
+		&velerov2alpha1.DataDownload{ObjectMeta: objectMeta("running"), Status: velerov2alpha1.DataDownloadStatus{Phase: velerov2alpha1.DataDownloadPhaseInProgress}},
+		&velerov1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other-ns"},
+			Status:     velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress},
+		},
+	)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:         fakeClient,
+		Scheme:         fakeClient.Scheme(),
+		Log:            logr.Discard(),
+		Context:        newContextForTest(),
+		NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+		EventRecorder:  record.NewFakeRecorder(10),
+		dpa:            dpa,
+	}
+
+	got, err := r.inProgressOperations()
+	if err != nil {
+		t.Fatalf("inProgressOperations() error = %v", err)
+	}
+	want := []string{"backup/running", "backup/waiting", "restore/finalizing", "restore/running", "podvolumebackup/running", "dataupload/accepted", "datadownload/running"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inProgressOperations() = %v, want %v", got, want)
+	}
+}
+
+func TestDPAReconciler_deferRollout(t *testing.T) {
+	runningBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: testNamespaceName},
+		Status:     velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress},
+	}
+	now := time.Date(2024, 6, 3, 12, 0, 30, 0, time.UTC)
+	// opens at the start of the next minute, so the window is closed now
+	closedWindow := &oadpv1alpha1.MaintenanceWindow{
+		Schedule: "1 12 * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+	tests := []struct {
+		name              string
+		rolloutGate       *oadpv1alpha1.RolloutGate
+		maintenanceWindow *oadpv1alpha1.MaintenanceWindow
+		conditions        []metav1.Condition
+		objects           []client.Object
+		wantDeferred      bool
+		wantCondition     bool
+		wantEvent         bool
+		wantReason        string
+	}{
+		{
+			name: "no operations in progress",
+		},
+		{
+			name:          "backup in progress",
+			objects:       []client.Object{runningBackup},
+			wantDeferred:  true,
+			wantCondition: true,
+			wantReason:    oadpv1alpha1.RolloutDeferredReasonOperationsInProgress,
+		},
+		{
+			name:        "rollout gate disabled",
+			rolloutGate: &oadpv1alpha1.RolloutGate{Timeout: &metav1.Duration{Duration: 0}},
+			objects:     []client.Object{runningBackup},
+		},
+		{
+			name:        "deferred for longer than the timeout",
+			rolloutGate: &oadpv1alpha1.RolloutGate{Timeout: &metav1.Duration{Duration: 30 * time.Minute}},
+			conditions: []metav1.Condition{
+				{
+					Type:               oadpv1alpha1.ConditionRolloutDeferred,
+					Status:             metav1.ConditionTrue,
+					Reason:             oadpv1alpha1.RolloutDeferredReasonOperationsInProgress,
+					LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
+				},
+			},
+			objects:       []client.Object{runningBackup},
+			wantCondition: true,
+			wantEvent:     true,
+		},
+		{
+			name:              "outside the maintenance window",
+			maintenanceWindow: closedWindow,
+			wantDeferred:      true,
+			wantCondition:     true,
+			wantReason:        oadpv1alpha1.RolloutDeferredReasonOutsideMaintenanceWindow,
+		},
+		{
+			name:              "backup in progress after the maintenance window opened",
+			maintenanceWindow: &oadpv1alpha1.MaintenanceWindow{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			conditions: []metav1.Condition{
+				{
+					Type:               oadpv1alpha1.ConditionRolloutDeferred,
+					Status:             metav1.ConditionTrue,
+					Reason:             oadpv1alpha1.RolloutDeferredReasonOutsideMaintenanceWindow,
+					LastTransitionTime: metav1.NewTime(now.Add(-48 * time.Hour)),
+				},
+			},
+			objects:       []client.Object{runningBackup},
+			wantDeferred:  true,
+			wantCondition: true,
+			wantReason:    oadpv1alpha1.RolloutDeferredReasonOperationsInProgress,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpa := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					RolloutGate:       tt.rolloutGate,
+					MaintenanceWindow: tt.maintenanceWindow,
+				},
+				Status: oadpv1alpha1.DataProtectionApplicationStatus{Conditions: tt.conditions},
+			}
+			eventRecorder := record.NewFakeRecorder(10)
+			fakeClient, err := getFakeClientFromObjects(append(tt.objects, dpa)...)
+			if err != nil {
+				t.Fatalf("error in creating fake client, likely programmer error")
+			}
+			r := &DataProtectionApplicationReconciler{
+				Client:         fakeClient,
+				Scheme:         fakeClient.Scheme(),
+				Log:            logr.Discard(),
+				Context:        newContextForTest(),
+				NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+				EventRecorder:  eventRecorder,
+				dpa:            dpa,
+				clock:          testingclock.NewFakePassiveClock(now),
+			}
+
+			deferred, err := r.deferRollout(common.Velero)
+			if err != nil {
+				t.Fatalf("deferRollout() error = %v", err)
+			}
+			if deferred != tt.wantDeferred {
+				t.Errorf("deferRollout() = %v, want %v", deferred, tt.wantDeferred)
+			}
+			if deferred != (len(r.deferredRollouts) == 1) {
+				t.Errorf("deferRollout() deferredRollouts = %v", r.deferredRollouts)
+			}
+			condition := apimeta.FindStatusCondition(dpa.Status.Conditions, oadpv1alpha1.ConditionRolloutDeferred)
+			if (condition != nil) != tt.wantCondition {
+				t.Errorf("deferRollout() condition = %v, want condition %v", condition, tt.wantCondition)
+			}
+			if tt.wantDeferred && condition.Reason != tt.wantReason {
+				t.Errorf("deferRollout() condition reason = %s, want %s", condition.Reason, tt.wantReason)
+			}
+			if tt.wantReason == oadpv1alpha1.RolloutDeferredReasonOperationsInProgress && !strings.Contains(condition.Message, "backup/running") {
+				t.Errorf("deferRollout() condition message = %q, want the running backup listed", condition.Message)
+			}
+			if tt.wantDeferred && (r.rolloutRetryAfter <= 0 || r.rolloutRetryAfter > rolloutGateRequeueInterval) {
+				t.Errorf("deferRollout() rolloutRetryAfter = %s, want a retry within %s", r.rolloutRetryAfter, rolloutGateRequeueInterval)
+			}
+			if (len(eventRecorder.Events) > 0) != tt.wantEvent {
+				t.Errorf("deferRollout() recorded %d events, want event %v", len(eventRecorder.Events), tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowOpen(t *testing.T) {
+	saturdayNights := &oadpv1alpha1.MaintenanceWindow{
+		Schedule: "0 2 * * 6",
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}
+	tests := []struct {
+		name        string
+		window      *oadpv1alpha1.MaintenanceWindow
+		now         time.Time
+		wantOpen    bool
+		wantOpensAt time.Time
+		wantErr     bool
+	}{
+		{
+			name:     "no maintenance window",
+			now:      time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC),
+			wantOpen: true,
+		},
+		{
+			name:     "inside the window",
+			window:   saturdayNights,
+			now:      time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC),
+			wantOpen: true,
+		},
+		{
+			name:        "after the window closed",
+			window:      saturdayNights,
+			now:         time.Date(2024, 6, 8, 4, 0, 0, 0, time.UTC),
+			wantOpensAt: time.Date(2024, 6, 15, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "before the window opens",
+			window:      saturdayNights,
+			now:         time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC),
+			wantOpensAt: time.Date(2024, 6, 8, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid schedule",
+			window:  &oadpv1alpha1.MaintenanceWindow{Schedule: "every saturday"},
+			now:     time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, opensAt, err := maintenanceWindowOpen(tt.window, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("maintenanceWindowOpen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if open != tt.wantOpen || !opensAt.Equal(tt.wantOpensAt) {
+				t.Errorf("maintenanceWindowOpen() = %v, %s, want %v, %s", open, opensAt, tt.wantOpen, tt.wantOpensAt)
+			}
+		})
+	}
+}
+
+func TestDPAReconciler_ReconcileNodeAgentDaemonset_deferredDelete(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{},
+			},
+		},
+	}
+	nodeAgent := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: common.NodeAgent, Namespace: testNamespaceName},
+	}
+	dataUpload := &velerov2alpha1.DataUpload{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: testNamespaceName},
+		Status:     velerov2alpha1.DataUploadStatus{Phase: velerov2alpha1.DataUploadPhaseInProgress},
+	}
+	fakeClient, err := getFakeClientFromObjects(dpa, nodeAgent, dataUpload)
+	if err != nil {
+		t.Fatalf("error in creating fake client, likely programmer error")
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:         fakeClient,
+		Scheme:         fakeClient.Scheme(),
+		Log:            logr.Discard(),
+		Context:        newContextForTest(),
+		NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+		EventRecorder:  record.NewFakeRecorder(10),
+		dpa:            dpa,
+	}
+
+	if _, err := r.ReconcileNodeAgentDaemonset(r.Log); err != nil {
+		t.Fatalf("ReconcileNodeAgentDaemonset() error = %v", err)
+	}
+	if err := r.Get(r.Context, client.ObjectKeyFromObject(nodeAgent), &appsv1.DaemonSet{}); err != nil {
+		t.Errorf("ReconcileNodeAgentDaemonset() deleted the NodeAgent DaemonSet while a data upload is in progress: %v", err)
+	}
+	if !apimeta.IsStatusConditionTrue(dpa.Status.Conditions, oadpv1alpha1.ConditionRolloutDeferred) {
+		t.Errorf("ReconcileNodeAgentDaemonset() did not report the deferred deletion")
+	}
+
+	dataUpload.Status.Phase = velerov2alpha1.DataUploadPhaseCompleted
+	if err := r.Update(r.Context, dataUpload); err != nil {
+		t.Fatalf("unable to update data upload: %v", err)
+	}
+	if _, err := r.ReconcileNodeAgentDaemonset(r.Log); err != nil {
+		t.Fatalf("ReconcileNodeAgentDaemonset() error = %v", err)
+	}
+	if err := r.Get(r.Context, client.ObjectKeyFromObject(nodeAgent), &appsv1.DaemonSet{}); err == nil {
+		t.Errorf("ReconcileNodeAgentDaemonset() kept the NodeAgent DaemonSet after the data upload completed")
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// s3VendorProfile is the config an S3 compatible storage vendor requires from an aws backup location
+type s3VendorProfile struct {
+	// requiresS3URL is true for the vendors other than AWS, whose endpoint can not be derived from the region
+	requiresS3URL bool
+	// pathStyle is true for the vendors that only serve path-style requests, s3ForcePathStyle defaults to true
+	pathStyle bool
+	// region is the default region, the AWS SDK signs requests with a region even when the vendor ignores it
+	region string
+	// noChecksum is true for the vendors rejecting the checksums of the AWS SDK, checksumAlgorithm defaults to empty,
+	// as the aws plugin uses CRC32 without it
+	noChecksum bool
+	// serviceCA is true for the vendors whose in-cluster endpoint is signed by the OpenShift service CA
+	serviceCA bool
+}
+
+var s3VendorProfiles = map[oadpv1alpha1.S3Vendor]s3VendorProfile{
+	oadpv1alpha1.S3VendorAWS:         {},
+	oadpv1alpha1.S3VendorMinIO:       {requiresS3URL: true, pathStyle: true, region: "minio"},
+	oadpv1alpha1.S3VendorCeph:        {requiresS3URL: true, pathStyle: true, region: "default"},
+	oadpv1alpha1.S3VendorNooBaa:      {requiresS3URL: true, pathStyle: true, region: "noobaa", noChecksum: true, serviceCA: true},
+	oadpv1alpha1.S3VendorStorageGRID: {requiresS3URL: true, pathStyle: true, region: "us-east-1"},
+}
+
+// isAWSBackupLocation returns true for the velero backup locations of the aws provider
+func isAWSBackupLocation(location oadpv1alpha1.BackupLocation) bool {
+	return location.Velero != nil && (location.Velero.Provider == AWSProvider || location.Velero.Provider == "velero.io/aws")
+}
+
+// guessS3Vendor returns the vendor of an aws backup location guessed from the host of s3Url.
+// The guess is only a hint, as host names do not identify the storage behind them.
+func guessS3Vendor(location oadpv1alpha1.BackupLocation) oadpv1alpha1.S3Vendor {
+	s3URL := location.Velero.Config[S3URL]
+	if s3URL == "" {
+		return oadpv1alpha1.S3VendorAWS
+	}
+	u, err := url.Parse(s3URL)
+	if err != nil {
+		return oadpv1alpha1.S3VendorGeneric
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.HasSuffix(host, ".amazonaws.com") || strings.HasSuffix(host, ".amazonaws.com.cn"):
+		return oadpv1alpha1.S3VendorAWS
+	// the route and the service of the Multicloud Object Gateway of OpenShift Data Foundation
+	case strings.Contains(host, "noobaa") || strings.HasPrefix(host, "s3-openshift-storage.") || strings.HasPrefix(host, "s3.openshift-storage.svc"):
+		return oadpv1alpha1.S3VendorNooBaa
+	case strings.Contains(host, "minio"):
+		return oadpv1alpha1.S3VendorMinIO
+	case strings.Contains(host, "rgw") || strings.Contains(host, "ceph"):
+		return oadpv1alpha1.S3VendorCeph
+	case strings.Contains(host, "storagegrid"):
+		return oadpv1alpha1.S3VendorStorageGRID
+	}
+	return oadpv1alpha1.S3VendorGeneric
+}
+
+// s3VendorHint returns a hint to set s3Vendor for an aws backup location without it whose s3Url looks like
+// the endpoint of a vendor with requirements, or else an empty string
+func s3VendorHint(location oadpv1alpha1.BackupLocation) string {
+	if location.S3Vendor != "" {
+		return ""
+	}
+	vendor := guessS3Vendor(location)
+	if !s3VendorProfiles[vendor].requiresS3URL {
+		return ""
+	}
+	return fmt.Sprintf("s3Url %s looks like the endpoint of %s storage, set s3Vendor to %s to apply its config defaults and checks",
+		location.Velero.Config[S3URL], vendor, vendor)
+}
+
+// s3VendorSpec returns a copy of the velero spec of a backup location, with the config defaults of the vendor
+// set in s3Vendor for aws locations
+func s3VendorSpec(location oadpv1alpha1.BackupLocation) velerov1.BackupStorageLocationSpec {
+	spec := *location.Velero.DeepCopy()
+	if !isAWSBackupLocation(location) || location.S3Vendor == "" {
+		return spec
+	}
+	profile := s3VendorProfiles[location.S3Vendor]
+	if spec.Config == nil {
+		spec.Config = map[string]string{}
+	}
+	if _, ok := spec.Config[S3ForcePathStyle]; !ok && profile.pathStyle {
+		spec.Config[S3ForcePathStyle] = "true"
+	}
+	if spec.Config[Region] == "" && profile.region != "" {
+		spec.Config[Region] = profile.region
+	}
+	if _, ok := spec.Config[checksumAlgorithm]; !ok && profile.noChecksum {
+		spec.Config[checksumAlgorithm] = ""
+	}
+	return spec
+}
+
+// validateS3Vendor returns an error when the config of an aws backup location conflicts with the vendor set in s3Vendor
+func validateS3Vendor(location oadpv1alpha1.BackupLocation, path *field.Path) error {
+	vendor := location.S3Vendor
+	if vendor == "" {
+		return nil
+	}
+	profile := s3VendorProfiles[vendor]
+	config := location.Velero.Config
+	configPath := path.Child("velero", "config")
+
+	s3URL := config[S3URL]
+	if s3URL == "" {
+		if profile.requiresS3URL {
+			return newFieldError(field.Required(configPath.Key(S3URL),
+				fmt.Sprintf("%s storage requires the URL of its S3 endpoint in %s", vendor, S3URL)))
+		}
+		return nil
+	}
+	u, err := url.Parse(s3URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return newFieldError(field.Invalid(configPath.Key(S3URL), s3URL, "must be a URL like https://s3.example.com"))
+	}
+
+	if value, ok := config[S3ForcePathStyle]; ok && profile.pathStyle && value != "true" {
+		return newFieldError(field.Invalid(configPath.Key(S3ForcePathStyle), value,
+			fmt.Sprintf("%s storage only serves path-style requests, set %s to \"true\" or remove it, or set s3Vendor to %s",
+				vendor, S3ForcePathStyle, oadpv1alpha1.S3VendorGeneric)))
+	}
+	if value := config[checksumAlgorithm]; profile.noChecksum && value != "" {
+		return newFieldError(field.Invalid(configPath.Key(checksumAlgorithm), value,
+			fmt.Sprintf("%s storage does not support %s checksums, set %s to \"\"", vendor, value, checksumAlgorithm)))
+	}
+	host := strings.ToLower(u.Hostname())
+	inCluster := strings.HasSuffix(host, ".svc") || strings.HasSuffix(host, ".svc.cluster.local")
+	if profile.serviceCA && inCluster && u.Scheme == "https" &&
+		len(location.Velero.ObjectStorage.CACert) == 0 && config[InsecureSkipTLSVerify] != "true" {
+		return newFieldError(field.Required(path.Child("velero", "objectStorage", "caCert"),
+			fmt.Sprintf("the certificate of the %s service %s is signed by the OpenShift service CA, which Velero does not trust, "+
+				"set caCert to the service CA bundle, or %s to \"true\" in the config", vendor, host, InsecureSkipTLSVerify)))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func newS3VendorTestLocation(vendor oadpv1alpha1.S3Vendor, config map[string]string) oadpv1alpha1.BackupLocation {
+	return oadpv1alpha1.BackupLocation{
+		S3Vendor: vendor,
+		Velero: &velerov1.BackupStorageLocationSpec{
+			Provider: AWSProvider,
+			Config:   config,
+			StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+				Bucket: "bucket",
+			}},
+		},
+	}
+}
+
+func TestGuessS3Vendor(t *testing.T) {
+	tests := []struct {
+		name  string
+		s3URL string
+		want  oadpv1alpha1.S3Vendor
+	}{
+		{name: "no s3Url", want: oadpv1alpha1.S3VendorAWS},
+		{name: "AWS endpoint", s3URL: "https://s3.eu-west-1.amazonaws.com", want: oadpv1alpha1.S3VendorAWS},
+		{name: "NooBaa service", s3URL: "https://s3.openshift-storage.svc:443", want: oadpv1alpha1.S3VendorNooBaa},
+		{name: "NooBaa route", s3URL: "https://s3-openshift-storage.apps.example.com", want: oadpv1alpha1.S3VendorNooBaa},
+		{name: "MinIO", s3URL: "http://minio.minio.svc:9000", want: oadpv1alpha1.S3VendorMinIO},
+		{name: "Ceph RGW", s3URL: "http://rook-ceph-rgw-my-store.rook-ceph.svc", want: oadpv1alpha1.S3VendorCeph},
+		{name: "StorageGRID", s3URL: "https://storagegrid.example.com:10443", want: oadpv1alpha1.S3VendorStorageGRID},
+		{name: "unknown endpoint", s3URL: "https://s3.example.com", want: oadpv1alpha1.S3VendorGeneric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := newS3VendorTestLocation("", map[string]string{})
+			if tt.s3URL != "" {
+				location.Velero.Config[S3URL] = tt.s3URL
+			}
+			if got := guessS3Vendor(location); got != tt.want {
+				t.Errorf("guessS3Vendor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestS3VendorHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		vendor oadpv1alpha1.S3Vendor
+		s3URL  string
+		want   string
+	}{
+		{
+			name:  "undeclared MinIO endpoint",
+			s3URL: "http://minio.minio.svc:9000",
+			want:  "s3Url http://minio.minio.svc:9000 looks like the endpoint of MinIO storage, set s3Vendor to MinIO to apply its config defaults and checks",
+		},
+		{name: "declared vendor", vendor: oadpv1alpha1.S3VendorGeneric, s3URL: "http://minio.minio.svc:9000"},
+		{name: "AWS endpoint", s3URL: "https://s3.eu-west-1.amazonaws.com"},
+		{name: "unknown endpoint", s3URL: "https://s3.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := newS3VendorTestLocation(tt.vendor, map[string]string{S3URL: tt.s3URL})
+			if got := s3VendorHint(location); got != tt.want {
+				t.Errorf("s3VendorHint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestS3VendorSpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		vendor oadpv1alpha1.S3Vendor
+		config map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "AWS config is unchanged",
+			config: map[string]string{Region: "us-east-1"},
+			want:   map[string]string{Region: "us-east-1"},
+		},
+		{
+			name:   "MinIO defaults path-style and region",
+			vendor: oadpv1alpha1.S3VendorMinIO,
+			config: map[string]string{S3URL: "http://minio.minio.svc:9000"},
+			want:   map[string]string{S3URL: "http://minio.minio.svc:9000", S3ForcePathStyle: "true", Region: "minio"},
+		},
+		{
+			name:   "NooBaa defaults path-style, region and no checksums",
+			vendor: oadpv1alpha1.S3VendorNooBaa,
+			config: map[string]string{S3URL: "https://s3-openshift-storage.apps.example.com"},
+			want:   map[string]string{S3URL: "https://s3-openshift-storage.apps.example.com", S3ForcePathStyle: "true", Region: "noobaa", checksumAlgorithm: ""},
+		},
+		{
+			name:   "undeclared MinIO endpoint is unchanged",
+			config: map[string]string{S3URL: "http://minio.minio.svc:9000"},
+			want:   map[string]string{S3URL: "http://minio.minio.svc:9000"},
+		},
+		{
+			name:   "set keys are kept",
+			vendor: oadpv1alpha1.S3VendorCeph,
+			config: map[string]string{S3URL: "https://s3.example.com", S3ForcePathStyle: "true", Region: "zonegroup-a"},
+			want:   map[string]string{S3URL: "https://s3.example.com", S3ForcePathStyle: "true", Region: "zonegroup-a"},
+		},
+		{
+			name:   "Generic config is unchanged",
+			vendor: oadpv1alpha1.S3VendorGeneric,
+			config: map[string]string{S3URL: "https://s3.example.com"},
+			want:   map[string]string{S3URL: "https://s3.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := newS3VendorTestLocation(tt.vendor, tt.config)
+			before := len(tt.config)
+			spec := s3VendorSpec(location)
+			if !reflect.DeepEqual(spec.Config, tt.want) {
+				t.Errorf("s3VendorSpec() config = %v, want %v", spec.Config, tt.want)
+			}
+			if len(location.Velero.Config) != before {
+				t.Errorf("s3VendorSpec() changed the config of the DPA: %v", location.Velero.Config)
+			}
+		})
+	}
+}
+
+func TestValidateS3Vendor(t *testing.T) {
+	tests := []struct {
+		name    string
+		vendor  oadpv1alpha1.S3Vendor
+		config  map[string]string
+		caCert  []byte
+		wantErr string
+	}{
+		{
+			name:   "AWS without s3Url",
+			config: map[string]string{Region: "us-east-1"},
+		},
+		{
+			name:    "MinIO without s3Url",
+			vendor:  oadpv1alpha1.S3VendorMinIO,
+			config:  map[string]string{},
+			wantErr: "spec.backupLocations[0].velero.config[s3Url]: Required value: MinIO storage requires the URL of its S3 endpoint in s3Url",
+		},
+		{
+			name:    "s3Url without scheme",
+			vendor:  oadpv1alpha1.S3VendorMinIO,
+			config:  map[string]string{S3URL: "minio:9000"},
+			wantErr: "must be a URL like https://s3.example.com",
+		},
+		{
+			name:    "Ceph with virtual-hosted style",
+			vendor:  oadpv1alpha1.S3VendorCeph,
+			config:  map[string]string{S3URL: "http://rook-ceph-rgw-my-store.rook-ceph.svc", S3ForcePathStyle: "false"},
+			wantErr: `Ceph storage only serves path-style requests, set s3ForcePathStyle to "true" or remove it, or set s3Vendor to Generic`,
+		},
+		{
+			name:   "undeclared vendor is not validated",
+			config: map[string]string{S3URL: "http://rook-ceph-rgw-my-store.rook-ceph.svc", S3ForcePathStyle: "false"},
+		},
+		{
+			name:   "Generic with virtual-hosted style",
+			vendor: oadpv1alpha1.S3VendorGeneric,
+			config: map[string]string{S3URL: "http://rook-ceph-rgw-my-store.rook-ceph.svc", S3ForcePathStyle: "false"},
+		},
+		{
+			name:    "NooBaa with CRC32 checksums",
+			vendor:  oadpv1alpha1.S3VendorNooBaa,
+			config:  map[string]string{S3URL: "https://s3-openshift-storage.apps.example.com", checksumAlgorithm: "CRC32"},
+			wantErr: `NooBaa storage does not support CRC32 checksums, set checksumAlgorithm to ""`,
+		},
+		{
+			name:    "NooBaa service without caCert",
+			vendor:  oadpv1alpha1.S3VendorNooBaa,
+			config:  map[string]string{S3URL: "https://s3.openshift-storage.svc"},
+			wantErr: "spec.backupLocations[0].velero.objectStorage.caCert: Required value: the certificate of the NooBaa service s3.openshift-storage.svc is signed by the OpenShift service CA",
+		},
+		{
+			name:   "NooBaa service with caCert",
+			vendor: oadpv1alpha1.S3VendorNooBaa,
+			config: map[string]string{S3URL: "https://s3.openshift-storage.svc"},
+			caCert: []byte("service CA"),
+		},
+		{
+			name:   "NooBaa service with insecureSkipTLSVerify",
+			vendor: oadpv1alpha1.S3VendorNooBaa,
+			config: map[string]string{S3URL: "https://s3.openshift-storage.svc", InsecureSkipTLSVerify: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := newS3VendorTestLocation(tt.vendor, tt.config)
+			location.Velero.ObjectStorage.CACert = tt.caCert
+			err := validateS3Vendor(location, field.NewPath("spec", "backupLocations").Index(0))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateS3Vendor() error = %v", err)
+				}
+				return
+			}
+			var fieldErr fieldError
+			if !errors.As(err, &fieldErr) || !strings.Contains(fieldErr.fieldErr.Error(), tt.wantErr) {
+				t.Errorf("validateS3Vendor() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/credentials"
+)
+
+// backupLocationVeleroSpec returns the spec of the BSL of a velero backup location: the config defaults of its S3 vendor,
+// and the credentials file of its SecretProviderClass, or of its mounted secret with mountLocationCredentials
+func backupLocationVeleroSpec(location oadpv1alpha1.BackupLocation, mountCredentials bool) velerov1.BackupStorageLocationSpec {
+	spec := s3VendorSpec(location)
+	if location.SecretProviderClass != nil {
+		if spec.Config == nil {
+			spec.Config = map[string]string{}
+		}
+		spec.Config[CredentialsFileKey] = credentials.SecretProviderClassFilePath(location.SecretProviderClass)
+	}
+	if mountCredentials {
+		spec.Config, spec.Credential = mountedLocationCredential(spec.Config, spec.Credential)
+	}
+	return spec
+}
+
+// snapshotLocationVeleroSpec returns the spec of the VSL of a snapshot location, with the credentials file of its SecretProviderClass,
+// or of its mounted secret with mountLocationCredentials
+func snapshotLocationVeleroSpec(location oadpv1alpha1.SnapshotLocation, mountCredentials bool) velerov1.VolumeSnapshotLocationSpec {
+	spec := *location.Velero.DeepCopy()
+	if location.SecretProviderClass != nil {
+		if spec.Config == nil {
+			spec.Config = map[string]string{}
+		}
+		spec.Config[CredentialsFileKey] = credentials.SecretProviderClassFilePath(location.SecretProviderClass)
+	}
+	if mountCredentials {
+		spec.Config, spec.Credential = mountedLocationCredential(spec.Config, spec.Credential)
+	}
+	return spec
+}
+
+// mountedLocationCredential replaces the credential of the copied spec of a location with the credentials file of its secret
+// mounted in the Velero and NodeAgent pods, Velero would otherwise copy the secret into a file of its own
+func mountedLocationCredential(config map[string]string, credential *corev1.SecretKeySelector) (map[string]string, *corev1.SecretKeySelector) {
+	if credential == nil || credential.Name == "" || credential.Key == "" || config[CredentialsFileKey] != "" {
+		return config, credential
+	}
+	if config == nil {
+		config = map[string]string{}
+	}
+	config[CredentialsFileKey] = credentials.LocationSecretFilePath(credential)
+	return config, nil
+}
+
+// validateSecretProviderClass returns an error when a location with a SecretProviderClass also sets other credentials
+func validateSecretProviderClass(spc *oadpv1alpha1.SecretProviderClassCredential, credential *corev1.SecretKeySelector, config map[string]string, path *field.Path) error {
+	if spc == nil {
+		return nil
+	}
+	if spc.Name == "" {
+		return newFieldError(field.Required(path.Child("secretProviderClass", "name"), "name of the SecretProviderClass cannot be empty"))
+	}
+	if spc.File == "" {
+		return newFieldError(field.Required(path.Child("secretProviderClass", "file"), "credentials file of the SecretProviderClass cannot be empty"))
+	}
+	if credential != nil {
+		return newFieldError(field.Forbidden(path.Child("velero", "credential"), "credential and secretProviderClass are mutually exclusive"))
+	}
+	if _, ok := config[CredentialsFileKey]; ok {
+		return newFieldError(field.Forbidden(path.Child("velero", "config").Key(CredentialsFileKey), "credentialsFile and secretProviderClass are mutually exclusive"))
+	}
+	return nil
+}
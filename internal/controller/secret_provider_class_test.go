@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestValidateSecretProviderClass(t *testing.T) {
+	spc := &oadpv1alpha1.SecretProviderClassCredential{Name: "vault-aws", File: "cloud"}
+	tests := []struct {
+		name       string
+		spc        *oadpv1alpha1.SecretProviderClassCredential
+		credential *corev1.SecretKeySelector
+		config     map[string]string
+		wantField  string
+	}{
+		{
+			name: "no SecretProviderClass",
+			credential: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "cloud-credentials"},
+				Key:                  "cloud",
+			},
+		},
+		{
+			name: "SecretProviderClass",
+			spc:  spc,
+		},
+		{
+			name:      "SecretProviderClass without file",
+			spc:       &oadpv1alpha1.SecretProviderClassCredential{Name: "vault-aws"},
+			wantField: "spec.backupLocations[0].secretProviderClass.file",
+		},
+		{
+			name: "SecretProviderClass and credential",
+			spc:  spc,
+			credential: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "cloud-credentials"},
+				Key:                  "cloud",
+			},
+			wantField: "spec.backupLocations[0].velero.credential",
+		},
+		{
+			name:      "SecretProviderClass and credentialsFile",
+			spc:       spc,
+			config:    map[string]string{CredentialsFileKey: "cloud-credentials/cloud"},
+			wantField: "spec.backupLocations[0].velero.config[credentialsFile]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSecretProviderClass(tt.spc, tt.credential, tt.config, field.NewPath("spec", "backupLocations").Index(0))
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("validateSecretProviderClass() error = %v", err)
+				}
+				return
+			}
+			var fieldErr fieldError
+			if !errors.As(err, &fieldErr) || fieldErr.fieldErr.Field != tt.wantField {
+				t.Errorf("validateSecretProviderClass() error = %v, want an error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestDPAReconciler_SecretProviderClassLocations(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS}},
+			},
+			BackupImages: ptr.To(false),
+			BackupLocations: []oadpv1alpha1.BackupLocation{{
+				Name: "aws",
+				Velero: &velerov1.BackupStorageLocationSpec{
+					Provider: "aws",
+					Default:  true,
+					Config:   map[string]string{Region: "us-east-1"},
+					StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+						Bucket: "bucket",
+						Prefix: "velero",
+					}},
+				},
+				SecretProviderClass: &oadpv1alpha1.SecretProviderClassCredential{Name: "vault-aws", File: "cloud"},
+			}},
+			SnapshotLocations: []oadpv1alpha1.SnapshotLocation{{
+				Velero: &velerov1.VolumeSnapshotLocationSpec{
+					Provider: "aws",
+					Config:   map[string]string{AWSRegion: "us-east-1"},
+				},
+				SecretProviderClass: &oadpv1alpha1.SecretProviderClassCredential{Name: "vault-aws", File: "cloud"},
+			}},
+		},
+	}
+	// no credentials secret exists
+	fakeClient, err := getFakeClientFromObjects(dpa)
+	if err != nil {
+		t.Fatalf("error creating fake client: %v", err)
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:         fakeClient,
+		Scheme:         fakeClient.Scheme(),
+		Log:            logr.Discard(),
+		Context:        newContextForTest(),
+		NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+		EventRecorder:  record.NewFakeRecorder(10),
+		dpa:            dpa,
+	}
+
+	if _, err := r.ValidateBackupStorageLocations(); err != nil {
+		t.Errorf("ValidateBackupStorageLocations() error = %v", err)
+	}
+	if _, err := r.ValidateVolumeSnapshotLocations(); err != nil {
+		t.Errorf("ValidateVolumeSnapshotLocations() error = %v", err)
+	}
+	if _, err := r.ValidateVeleroPlugins(); err != nil {
+		t.Errorf("ValidateVeleroPlugins() error = %v", err)
+	}
+	providerNeedsDefaultCreds, err := r.noDefaultCredentials()
+	if err != nil || providerNeedsDefaultCreds["aws"] {
+		t.Errorf("noDefaultCredentials() = %v, %v, want aws not to need the default credentials", providerNeedsDefaultCreds, err)
+	}
+
+	if _, err := r.ReconcileBackupStorageLocations(logr.Discard()); err != nil {
+		t.Fatalf("ReconcileBackupStorageLocations() error = %v", err)
+	}
+	bsl := &velerov1.BackupStorageLocation{}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: "aws"}, bsl); err != nil {
+		t.Fatalf("error getting BSL: %v", err)
+	}
+	if bsl.Spec.Config[CredentialsFileKey] != "/credentials-spc/vault-aws/cloud" || bsl.Spec.Credential != nil {
+		t.Errorf("BSL credentialsFile = %q, credential = %v, want the credentials file of the SecretProviderClass", bsl.Spec.Config[CredentialsFileKey], bsl.Spec.Credential)
+	}
+	if _, ok := dpa.Spec.BackupLocations[0].Velero.Config[CredentialsFileKey]; ok {
+		t.Errorf("ReconcileBackupStorageLocations() changed the config of the DPA: %v", dpa.Spec.BackupLocations[0].Velero.Config)
+	}
+
+	if _, err := r.ReconcileVolumeSnapshotLocations(logr.Discard()); err != nil {
+		t.Fatalf("ReconcileVolumeSnapshotLocations() error = %v", err)
+	}
+	vsl := &velerov1.VolumeSnapshotLocation{}
+	if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: testDpaName + "-1"}, vsl); err != nil {
+		t.Fatalf("error getting VSL: %v", err)
+	}
+	if vsl.Spec.Config[CredentialsFileKey] != "/credentials-spc/vault-aws/cloud" {
+		t.Errorf("VSL credentialsFile = %q, want the credentials file of the SecretProviderClass", vsl.Spec.Config[CredentialsFileKey])
+	}
+
+	// backing up images needs the credentials in the operator pod
+	dpa.Spec.BackupImages = ptr.To(true)
+	if _, err := r.ValidateBackupStorageLocations(); err == nil || !strings.Contains(err.Error(), "mount the SecretProviderClass in the operator pod") {
+		t.Errorf("ValidateBackupStorageLocations() error = %v, want the SecretProviderClass not mounted in the operator pod", err)
+	}
+}
+
+func TestDPAReconciler_MountLocationCredentials(t *testing.T) {
+	secret := func(name, content string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespaceName},
+			Data:       map[string][]byte{"cloud": []byte(content)},
+		}
+	}
+	location := func(name, secretName, profile string) oadpv1alpha1.BackupLocation {
+		return oadpv1alpha1.BackupLocation{
+			Name: name,
+			Velero: &velerov1.BackupStorageLocationSpec{
+				Provider: "aws",
+				Default:  name == "team-a",
+				Config:   map[string]string{Region: "us-east-1", Profile: profile},
+				StorageType: velerov1.StorageType{ObjectStorage: &velerov1.ObjectStorageLocation{
+					Bucket: name,
+					Prefix: "velero",
+				}},
+				Credential: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: "cloud"},
+			},
+		}
+	}
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{
+				Velero: &oadpv1alpha1.VeleroConfig{
+					DefaultPlugins:           []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS},
+					MountLocationCredentials: ptr.To(true),
+				},
+			},
+			BackupImages:    ptr.To(false),
+			BackupLocations: []oadpv1alpha1.BackupLocation{location("team-a", "team-a", "default"), location("team-b", "team-b", "backups")},
+		},
+	}
+	fakeClient, err := getFakeClientFromObjects(dpa,
+		secret("team-a", "[default]\naws_access_key_id=a\naws_secret_access_key=a\n"),
+		secret("team-b", "[backups]\nrole_arn = arn:aws:iam::123456789012:role/velero\nweb_identity_token_file = /var/run/secrets/openshift/serviceaccount/token\n"))
+	if err != nil {
+		t.Fatalf("error creating fake client: %v", err)
+	}
+	r := &DataProtectionApplicationReconciler{
+		Client:         fakeClient,
+		Scheme:         fakeClient.Scheme(),
+		Log:            logr.Discard(),
+		Context:        newContextForTest(),
+		NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+		EventRecorder:  record.NewFakeRecorder(10),
+		dpa:            dpa,
+	}
+
+	if _, err := r.ValidateBackupStorageLocations(); err != nil {
+		t.Fatalf("ValidateBackupStorageLocations() error = %v", err)
+	}
+	if _, err := r.ReconcileBackupStorageLocations(logr.Discard()); err != nil {
+		t.Fatalf("ReconcileBackupStorageLocations() error = %v", err)
+	}
+	for _, name := range []string{"team-a", "team-b"} {
+		bsl := &velerov1.BackupStorageLocation{}
+		if err := fakeClient.Get(r.Context, types.NamespacedName{Namespace: testNamespaceName, Name: name}, bsl); err != nil {
+			t.Fatalf("error getting BSL: %v", err)
+		}
+		if bsl.Spec.Config[CredentialsFileKey] != "/credentials-secrets/"+name+"/cloud" || bsl.Spec.Credential != nil {
+			t.Errorf("BSL %s credentialsFile = %q, credential = %v, want the credentials file of the mounted secret", name, bsl.Spec.Config[CredentialsFileKey], bsl.Spec.Credential)
+		}
+	}
+	if _, ok := dpa.Spec.BackupLocations[0].Velero.Config[CredentialsFileKey]; ok || dpa.Spec.BackupLocations[0].Velero.Credential == nil {
+		t.Errorf("ReconcileBackupStorageLocations() changed the DPA: %+v", dpa.Spec.BackupLocations[0].Velero)
+	}
+
+	// the mounted secrets are read as is, each location must resolve to credentials of its secret
+	dpa.Spec.BackupLocations[1].Velero.Config[Profile] = "team-b"
+	if _, err := r.ValidateBackupStorageLocations(); err == nil || !strings.Contains(err.Error(), "profile team-b not found") {
+		t.Errorf("ValidateBackupStorageLocations() error = %v, want the profile of team-b not found", err)
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// snapshotProvider validates the config of the VolumeSnapshotLocations of a provider
+type snapshotProvider struct {
+	// validKeys are the valid config keys, any key is valid when nil
+	validKeys map[string]bool
+	// requiredKeys must be set in the config
+	requiredKeys []string
+	// defaultPlugin is true for the providers of the default plugins, which must be in defaultPlugins
+	defaultPlugin bool
+}
+
+// builtinSnapshotProviders are the providers of the default plugins, with strict config key checks
+var builtinSnapshotProviders = map[string]snapshotProvider{
+	AWSProvider: {
+		validKeys:     validAWSKeys,
+		requiredKeys:  []string{AWSRegion},
+		defaultPlugin: true,
+	},
+	GCPProvider: {
+		validKeys:     validGCPKeys,
+		defaultPlugin: true,
+	},
+	AzureProvider: {
+		validKeys:     validAzureKeys,
+		defaultPlugin: true,
+	},
+}
+
+// snapshotProviders returns the VolumeSnapshotLocation providers of the DPA by name: the providers of the default
+// plugins, and the providers declared by its custom plugins
+func snapshotProviders(dpa *oadpv1alpha1.DataProtectionApplication) (map[string]snapshotProvider, error) {
+	providers := make(map[string]snapshotProvider, len(builtinSnapshotProviders))
+	for name, provider := range builtinSnapshotProviders {
+		providers[name] = provider
+	}
+	declared := map[string]bool{}
+	customPluginsPath := field.NewPath("spec", "configuration", "velero", "customPlugins")
+	for i, plugin := range dpa.Spec.Configuration.Velero.CustomPlugins {
+		for j, custom := range plugin.SnapshotProviders {
+			namePath := customPluginsPath.Index(i).Child("snapshotProviders").Index(j).Child("name")
+			if custom.Name == "" {
+				return nil, newFieldError(field.Required(namePath, "name of the snapshot provider cannot be empty"))
+			}
+			if _, ok := builtinSnapshotProviders[custom.Name]; ok {
+				return nil, newFieldError(field.Invalid(namePath, custom.Name, fmt.Sprintf("snapshot provider %s is implemented by the %s default plugin", custom.Name, custom.Name)))
+			}
+			if declared[custom.Name] {
+				return nil, newFieldError(field.Duplicate(namePath, custom.Name))
+			}
+			declared[custom.Name] = true
+			provider := snapshotProvider{requiredKeys: custom.RequiredConfigKeys}
+			if len(custom.ConfigKeys) > 0 {
+				// the credentials file of a location is valid for every provider
+				provider.validKeys = map[string]bool{CredentialsFileKey: true}
+				for _, key := range append(slices.Clone(custom.ConfigKeys), custom.RequiredConfigKeys...) {
+					provider.validKeys[key] = true
+				}
+			}
+			providers[custom.Name] = provider
+		}
+	}
+	return providers, nil
+}
+
+// snapshotProviderNames returns the sorted names of the snapshot providers
+func snapshotProviderNames(providers map[string]snapshotProvider) []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestDPAReconciler_ValidateCustomSnapshotProviders(t *testing.T) {
+	vsphere := oadpv1alpha1.CustomPlugin{
+		Name:  "velero-plugin-for-vsphere",
+		Image: "vsphereveleroplugin/velero-plugin-for-vsphere:v1.5.1",
+		SnapshotProviders: []oadpv1alpha1.SnapshotProvider{{
+			Name:               "velero.io/vsphere",
+			ConfigKeys:         []string{"vSphereSecretNamespace"},
+			RequiredConfigKeys: []string{"vSphereSecretName"},
+		}},
+	}
+	anyKeys := oadpv1alpha1.CustomPlugin{
+		Name:              "snapshot-plugin",
+		Image:             "quay.io/example/snapshot-plugin:latest",
+		SnapshotProviders: []oadpv1alpha1.SnapshotProvider{{Name: "example.com/snapshots"}},
+	}
+	tests := []struct {
+		name          string
+		customPlugins []oadpv1alpha1.CustomPlugin
+		location      velerov1.VolumeSnapshotLocationSpec
+		wantField     string
+		wantErr       bool
+	}{
+		{
+			name:          "provider of a custom plugin",
+			customPlugins: []oadpv1alpha1.CustomPlugin{vsphere},
+			location: velerov1.VolumeSnapshotLocationSpec{
+				Provider: "velero.io/vsphere",
+				Config:   map[string]string{"vSphereSecretName": "velero-vsphere-config-secret", "vSphereSecretNamespace": "openshift-adp"},
+			},
+		},
+		{
+			name:          "provider of a custom plugin with any config key",
+			customPlugins: []oadpv1alpha1.CustomPlugin{anyKeys},
+			location: velerov1.VolumeSnapshotLocationSpec{
+				Provider: "example.com/snapshots",
+				Config:   map[string]string{"anything": "goes"},
+			},
+		},
+		{
+			name:          "invalid config key of a custom plugin provider",
+			customPlugins: []oadpv1alpha1.CustomPlugin{vsphere},
+			location: velerov1.VolumeSnapshotLocationSpec{
+				Provider: "velero.io/vsphere",
+				Config:   map[string]string{"vSphereSecretName": "velero-vsphere-config-secret", "region": "us-east-1"},
+			},
+			wantField: "spec.snapshotLocations[0].velero.config[region]",
+		},
+		{
+			name:          "missing required config key of a custom plugin provider",
+			customPlugins: []oadpv1alpha1.CustomPlugin{vsphere},
+			location:      velerov1.VolumeSnapshotLocationSpec{Provider: "velero.io/vsphere"},
+			wantField:     "spec.snapshotLocations[0].velero.config[vSphereSecretName]",
+		},
+		{
+			name:      "provider not declared",
+			location:  velerov1.VolumeSnapshotLocationSpec{Provider: "velero.io/vsphere"},
+			wantField: "spec.snapshotLocations[0].velero.provider",
+		},
+		{
+			name:          "custom plugin declaring a provider of a default plugin",
+			customPlugins: []oadpv1alpha1.CustomPlugin{{Name: "aws", Image: "aws", SnapshotProviders: []oadpv1alpha1.SnapshotProvider{{Name: AWSProvider}}}},
+			location:      velerov1.VolumeSnapshotLocationSpec{Provider: AWSProvider, Config: map[string]string{AWSRegion: "us-east-1"}},
+			wantField:     "spec.configuration.velero.customPlugins[0].snapshotProviders[0].name",
+		},
+		{
+			name:          "provider declared twice",
+			customPlugins: []oadpv1alpha1.CustomPlugin{anyKeys, anyKeys},
+			location:      velerov1.VolumeSnapshotLocationSpec{Provider: "example.com/snapshots"},
+			wantField:     "spec.configuration.velero.customPlugins[1].snapshotProviders[0].name",
+		},
+		{
+			name:          "credential of a custom plugin provider not found",
+			customPlugins: []oadpv1alpha1.CustomPlugin{anyKeys},
+			location: velerov1.VolumeSnapshotLocationSpec{
+				Provider:   "example.com/snapshots",
+				Credential: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "snapshot-credentials"}, Key: "cloud"},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "default plugin provider keeps strict checks",
+			location:  velerov1.VolumeSnapshotLocationSpec{Provider: AWSProvider, Config: map[string]string{AWSRegion: "us-east-1", "vSphereSecretName": "secret"}},
+			wantField: "spec.snapshotLocations[0].velero.config[vSphereSecretName]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpa := &oadpv1alpha1.DataProtectionApplication{
+				ObjectMeta: metav1.ObjectMeta{Name: testDpaName, Namespace: testNamespaceName},
+				Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+					Configuration: &oadpv1alpha1.ApplicationConfig{
+						Velero: &oadpv1alpha1.VeleroConfig{
+							DefaultPlugins: []oadpv1alpha1.DefaultPlugin{oadpv1alpha1.DefaultPluginAWS},
+							CustomPlugins:  tt.customPlugins,
+						},
+					},
+					SnapshotLocations: []oadpv1alpha1.SnapshotLocation{{Velero: &tt.location}},
+				},
+			}
+			fakeClient, err := getFakeClientFromObjects(dpa, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cloud-credentials", Namespace: testNamespaceName},
+				Data:       map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=access\naws_secret_access_key=secret\n")},
+			})
+			if err != nil {
+				t.Fatalf("error creating fake client: %v", err)
+			}
+			r := &DataProtectionApplicationReconciler{
+				Client:         fakeClient,
+				Scheme:         fakeClient.Scheme(),
+				Log:            logr.Discard(),
+				Context:        newContextForTest(),
+				NamespacedName: types.NamespacedName{Namespace: dpa.Namespace, Name: dpa.Name},
+				EventRecorder:  record.NewFakeRecorder(10),
+				dpa:            dpa,
+			}
+
+			_, err = r.ValidateVolumeSnapshotLocations()
+			switch {
+			case tt.wantField != "":
+				var fieldErr fieldError
+				if !errors.As(err, &fieldErr) || fieldErr.fieldErr.Field != tt.wantField {
+					t.Errorf("ValidateVolumeSnapshotLocations() error = %v, want an error on %s", err, tt.wantField)
+				}
+			case (err != nil) != tt.wantErr:
+				t.Errorf("ValidateVolumeSnapshotLocations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
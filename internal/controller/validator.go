@@ -3,61 +3,157 @@ package controller
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
 	"github.com/openshift/oadp-operator/pkg/common"
 	"github.com/openshift/oadp-operator/pkg/credentials"
+	"github.com/openshift/oadp-operator/pkg/notification"
 )
 
 const NACNonEnforceableErr = "DPA %s is non-enforceable by admins"
 
+const resticDeprecationWarning = "(Deprecation Warning) Use kopia instead of restic in spec.configuration.nodeAgent.uploaderType, which is deprecated and will be removed in the future"
+
 var wasRestic bool
 
+// fieldError is a DPA validation error tied to a field of the DPA.
+// Its message is the field error detail, the admission webhook reports the full field error.
+type fieldError struct {
+	fieldErr *field.Error
+}
+
+func (e fieldError) Error() string {
+	return e.fieldErr.Detail
+}
+
+func newFieldError(err *field.Error) error {
+	return fieldError{fieldErr: err}
+}
+
 // ValidateDataProtectionCR function validates the DPA CR, returns true if valid, false otherwise
 // it calls other validation functions to validate the DPA CR
 func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.Logger) (bool, error) {
+	// DEPRECATIONS -----------------------------------------------------------
+	if usesRestic(r.dpa) {
+		if !wasRestic {
+			// V(-1) corresponds to the warn level
+			log.V(-1).Info(resticDeprecationWarning)
+			r.EventRecorder.Event(r.dpa, corev1.EventTypeWarning, "DeprecationResticFileSystemBackup", resticDeprecationWarning)
+		}
+		wasRestic = true
+	} else {
+		wasRestic = false
+	}
+	// DEPRECATIONS -----------------------------------------------------------
+
+	if err := r.validateDataProtectionApplication(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// usesRestic returns true if the DPA uses the deprecated restic uploader
+func usesRestic(dpa *oadpv1alpha1.DataProtectionApplication) bool {
+	return dpa.Spec.Configuration != nil && dpa.Spec.Configuration.NodeAgent != nil && dpa.Spec.Configuration.NodeAgent.UploaderType == "restic"
+}
+
+// resourceCheck returns the error of a check of another resource, like a missing credentials secret. At admission,
+// the resource may be created after the DPA, so the error is recorded as a warning and validation goes on with the
+// rules of the spec; the reconciler reports it.
+func (r *DataProtectionApplicationReconciler) resourceCheck(err error) error {
+	var fieldErr fieldError
+	if err == nil || !r.admission || errors.As(err, &fieldErr) {
+		return err
+	}
+	// the checks of a secret shared by several locations fail alike
+	if !slices.Contains(r.admissionWarnings, err.Error()) {
+		r.admissionWarnings = append(r.admissionWarnings, err.Error())
+	}
+	return nil
+}
+
+// validateDataProtectionApplication runs every DPA validation rule and returns the first failure.
+// It is shared by the reconciler and the validating admission webhook.
+func (r *DataProtectionApplicationReconciler) validateDataProtectionApplication() error {
 	dpaList := &oadpv1alpha1.DataProtectionApplicationList{}
 	err := r.List(r.Context, dpaList, &client.ListOptions{Namespace: r.NamespacedName.Namespace})
 	if err != nil {
-		return false, err
+		return err
 	}
-	if len(dpaList.Items) > 1 {
-		return false, errors.New("only one DPA CR can exist per OADP installation namespace")
+	for _, dpa := range dpaList.Items {
+		if dpa.Name != r.dpa.Name {
+			return newFieldError(field.Forbidden(field.NewPath("metadata", "namespace"), "only one DPA CR can exist per OADP installation namespace"))
+		}
 	}
 
+	specPath := field.NewPath("spec")
+	veleroPath := specPath.Child("configuration", "velero")
+	nodeAgentPath := specPath.Child("configuration", "nodeAgent")
+	nonAdminPath := specPath.Child("nonAdmin")
+
 	if r.dpa.Spec.Configuration == nil || r.dpa.Spec.Configuration.Velero == nil {
-		return false, errors.New("DPA CR Velero configuration cannot be nil")
+		return newFieldError(field.Required(veleroPath, "DPA CR Velero configuration cannot be nil"))
 	}
 
 	if r.dpa.Spec.Configuration.Velero.NoDefaultBackupLocation {
 		if len(r.dpa.Spec.BackupLocations) != 0 {
-			return false, errors.New("DPA CR Velero configuration cannot have backup locations if noDefaultBackupLocation is set")
+			return newFieldError(field.Forbidden(specPath.Child("backupLocations"), "DPA CR Velero configuration cannot have backup locations if noDefaultBackupLocation is set"))
 		}
 		if r.dpa.BackupImages() {
-			return false, errors.New("backupImages needs to be set to false when noDefaultBackupLocation is set")
+			return newFieldError(field.Invalid(specPath.Child("backupImages"), r.dpa.Spec.BackupImages, "backupImages needs to be set to false when noDefaultBackupLocation is set"))
 		}
 	} else {
 		if len(r.dpa.Spec.BackupLocations) == 0 {
-			return false, errors.New("no backupstoragelocations configured, ensure a backupstoragelocation has been configured or use the noDefaultBackupLocation flag")
+			return newFieldError(field.Required(specPath.Child("backupLocations"), "no backupstoragelocations configured, ensure a backupstoragelocation has been configured or use the noDefaultBackupLocation flag"))
+		}
+	}
+
+	if window := r.dpa.Spec.MaintenanceWindow; window != nil {
+		windowPath := specPath.Child("maintenanceWindow")
+		if _, err := cron.ParseStandard(window.Schedule); err != nil {
+			return newFieldError(field.Invalid(windowPath.Child("schedule"), window.Schedule, fmt.Sprintf("maintenance window schedule is not a valid cronspec: %v", err)))
+		}
+		if window.Duration.Duration <= 0 {
+			return newFieldError(field.Invalid(windowPath.Child("duration"), window.Duration.Duration.String(), "maintenance window duration must be greater than 0"))
+		}
+	}
+
+	if notifications := r.dpa.Spec.Notifications; notifications != nil {
+		sinksPath := specPath.Child("notifications", "sinks")
+		sinkNames := map[string]bool{}
+		for i, sink := range notifications.Sinks {
+			if sinkNames[sink.Name] {
+				return newFieldError(field.Duplicate(sinksPath.Index(i).Child("name"), sink.Name))
+			}
+			sinkNames[sink.Name] = true
+			if (sink.URL == "") == (sink.URLSecret == nil) {
+				return newFieldError(field.Invalid(sinksPath.Index(i), sink.Name, "notification sink must set exactly one of url and urlSecret"))
+			}
+			if _, err := notification.ParseTemplate(sink.Template); err != nil {
+				return newFieldError(field.Invalid(sinksPath.Index(i).Child("template"), sink.Template, err.Error()))
+			}
 		}
 	}
 
-	if validBsl, err := r.ValidateBackupStorageLocations(); !validBsl || err != nil {
-		return validBsl, err
+	if _, err := r.ValidateBackupStorageLocations(); err != nil {
+		return err
 	}
-	if validVsl, err := r.ValidateVolumeSnapshotLocations(); !validVsl || err != nil {
-		return validVsl, err
+	if _, err := r.ValidateVolumeSnapshotLocations(); err != nil {
+		return err
 	}
 
 	// Ensure DPA spec.configuration.nodeAgent.PodConfig is not different from spec.configuration.nodeAgent.LoadAffinityConfig
@@ -67,7 +163,7 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 		r.dpa.Spec.Configuration.NodeAgent.LoadAffinityConfig != nil {
 
 		if len(r.dpa.Spec.Configuration.NodeAgent.LoadAffinityConfig) > 1 {
-			return false, errors.New("when spec.configuration.nodeAgent.PodConfig is set, spec.configuration.nodeAgent.LoadAffinityConfig must contain no more than one entry")
+			return newFieldError(field.Invalid(nodeAgentPath.Child("loadAffinityConfig"), len(r.dpa.Spec.Configuration.NodeAgent.LoadAffinityConfig), "when spec.configuration.nodeAgent.PodConfig is set, spec.configuration.nodeAgent.LoadAffinityConfig must contain no more than one entry"))
 		}
 
 		// podConfig is set !
@@ -77,16 +173,16 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 
 			// Ensure MatchLabels is set and MatchExpressions is not used
 			if affinitySelector.MatchLabels == nil {
-				return false, errors.New("when spec.configuration.nodeAgent.PodConfig is set, spec.configuration.nodeAgent.LoadAffinityConfig must define matchLabels")
+				return newFieldError(field.Required(nodeAgentPath.Child("loadAffinityConfig").Index(0).Child("nodeSelector", "matchLabels"), "when spec.configuration.nodeAgent.PodConfig is set, spec.configuration.nodeAgent.LoadAffinityConfig must define matchLabels"))
 			}
 			if affinitySelector.MatchExpressions != nil {
-				return false, errors.New("when spec.configuration.nodeAgent.PodConfig is set, spec.configuration.nodeAgent.LoadAffinityConfig must not define matchExpressions")
+				return newFieldError(field.Forbidden(nodeAgentPath.Child("loadAffinityConfig").Index(0).Child("nodeSelector", "matchExpressions"), "when spec.configuration.nodeAgent.PodConfig is set, spec.configuration.nodeAgent.LoadAffinityConfig must not define matchExpressions"))
 			}
 
 			// Ensure all labels in PodConfig are present in LoadAffinityConfig
 			for key, valA := range podConfigSelector {
 				if valB, exists := affinitySelector.MatchLabels[key]; !exists || valA != valB {
-					return false, errors.New("when spec.configuration.nodeAgent.PodConfig is set, all labels from the spec.configuration.nodeAgent.PodConfig must be present in spec.configuration.nodeAgent.LoadAffinityConfig")
+					return newFieldError(field.Invalid(nodeAgentPath.Child("loadAffinityConfig").Index(0).Child("nodeSelector", "matchLabels"), affinitySelector.MatchLabels, "when spec.configuration.nodeAgent.PodConfig is set, all labels from the spec.configuration.nodeAgent.PodConfig must be present in spec.configuration.nodeAgent.LoadAffinityConfig"))
 				}
 			}
 		}
@@ -95,44 +191,30 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 	// ENSURE UPGRADES --------------------------------------------------------
 	// check for VSM/Volsync DataMover (OADP 1.2 or below) syntax
 	if r.dpa.Spec.Features != nil && r.dpa.Spec.Features.DataMover != nil {
-		return false, errors.New("Delete vsm from spec.configuration.velero.defaultPlugins and dataMover object from spec.features. Use Velero Built-in Data Mover instead")
+		return newFieldError(field.Forbidden(specPath.Child("features", "dataMover"), "Delete vsm from spec.configuration.velero.defaultPlugins and dataMover object from spec.features. Use Velero Built-in Data Mover instead"))
 	}
 
 	// check for ResticConfig (OADP 1.4 or below) syntax
 	if r.dpa.Spec.Configuration.Restic != nil {
-		return false, errors.New("Delete restic object from spec.configuration, use spec.configuration.nodeAgent instead")
+		return newFieldError(field.Forbidden(specPath.Child("configuration", "restic"), "Delete restic object from spec.configuration, use spec.configuration.nodeAgent instead"))
 	}
 	// ENSURE UPGRADES --------------------------------------------------------
 
-	// DEPRECATIONS -----------------------------------------------------------
-	if r.dpa.Spec.Configuration.NodeAgent != nil && r.dpa.Spec.Configuration.NodeAgent.UploaderType == "restic" {
-		if !wasRestic {
-			deprecationWarning := "(Deprecation Warning) Use kopia instead of restic in spec.configuration.nodeAgent.uploaderType, which is deprecated and will be removed in the future"
-			// V(-1) corresponds to the warn level
-			log.V(-1).Info(deprecationWarning)
-			r.EventRecorder.Event(r.dpa, corev1.EventTypeWarning, "DeprecationResticFileSystemBackup", deprecationWarning)
-		}
-		wasRestic = true
-	} else {
-		wasRestic = false
-	}
-	// DEPRECATIONS -----------------------------------------------------------
-
 	if val, found := r.dpa.Spec.UnsupportedOverrides[oadpv1alpha1.OperatorTypeKey]; found && val != oadpv1alpha1.OperatorTypeMTC {
-		return false, errors.New("only mtc operator type override is supported")
+		return newFieldError(field.Invalid(specPath.Child("unsupportedOverrides").Key(string(oadpv1alpha1.OperatorTypeKey)), val, "only mtc operator type override is supported"))
 	}
 
 	if _, err := r.ValidateVeleroPlugins(); err != nil {
-		return false, err
+		return err
 	}
 
 	// TODO refactor to call functions only once
 	// they are called here to check error, and then after to get value
 	if _, err := r.getVeleroResourceReqs(); err != nil {
-		return false, err
+		return newFieldError(field.Invalid(veleroPath.Child("podConfig", "resourceAllocations"), r.dpa.Spec.Configuration.Velero.PodConfig.ResourceAllocations, err.Error()))
 	}
 	if _, err := getNodeAgentResourceReqs(r.dpa); err != nil {
-		return false, err
+		return newFieldError(field.Invalid(nodeAgentPath.Child("podConfig", "resourceAllocations"), r.dpa.Spec.Configuration.NodeAgent.PodConfig.ResourceAllocations, err.Error()))
 	}
 
 	// validate non-admin enable
@@ -142,7 +224,7 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 			dpaList := &oadpv1alpha1.DataProtectionApplicationList{}
 			err = r.ClusterWideClient.List(r.Context, dpaList)
 			if err != nil {
-				return false, err
+				return err
 			}
 			for _, dpa := range dpaList.Items {
 				if dpa.Namespace != r.NamespacedName.Namespace && (&DataProtectionApplicationReconciler{dpa: &dpa}).checkNonAdminEnabled() {
@@ -160,7 +242,7 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 						},
 						nonAdminDeployment,
 					); err == nil {
-						return false, fmt.Errorf("only a single instance of Non-Admin Controller can be installed across the entire cluster. Non-Admin controller is already configured and installed in %s namespace", dpa.Namespace)
+						return newFieldError(field.Forbidden(nonAdminPath.Child("enable"), fmt.Sprintf("only a single instance of Non-Admin Controller can be installed across the entire cluster. Non-Admin controller is already configured and installed in %s namespace", dpa.Namespace)))
 					}
 				}
 			}
@@ -170,7 +252,7 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 		appliedGarbageCollectionPeriod := oadpv1alpha1.DefaultGarbageCollectionPeriod
 		if garbageCollectionPeriod != nil {
 			if garbageCollectionPeriod.Duration < 0 {
-				return false, fmt.Errorf("DPA spec.nonAdmin.garbageCollectionPeriod can not be negative")
+				return newFieldError(field.Invalid(nonAdminPath.Child("garbageCollectionPeriod"), garbageCollectionPeriod.Duration.String(), "DPA spec.nonAdmin.garbageCollectionPeriod can not be negative"))
 			}
 			appliedGarbageCollectionPeriod = garbageCollectionPeriod.Duration
 		}
@@ -179,16 +261,16 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 		appliedBackupSyncPeriod := oadpv1alpha1.DefaultBackupSyncPeriod
 		if backupSyncPeriod != nil {
 			if backupSyncPeriod.Duration < 0 {
-				return false, fmt.Errorf("DPA spec.nonAdmin.backupSyncPeriod can not be negative")
+				return newFieldError(field.Invalid(nonAdminPath.Child("backupSyncPeriod"), backupSyncPeriod.Duration.String(), "DPA spec.nonAdmin.backupSyncPeriod can not be negative"))
 			}
 			appliedBackupSyncPeriod = backupSyncPeriod.Duration
 		}
 
 		if appliedGarbageCollectionPeriod <= appliedBackupSyncPeriod {
-			return false, fmt.Errorf(
+			return newFieldError(field.Invalid(nonAdminPath.Child("backupSyncPeriod"), appliedBackupSyncPeriod.String(), fmt.Sprintf(
 				"DPA spec.nonAdmin.backupSyncPeriod (%v) can not be greater or equal spec.nonAdmin.garbageCollectionPeriod (%v)",
 				appliedBackupSyncPeriod, appliedGarbageCollectionPeriod,
-			)
+			)))
 		}
 
 		defaultBSLIndex := -1
@@ -219,26 +301,26 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 			defaultBSLSyncPeriodErrorMessage := "default BSL spec.backupSyncPeriod (%v) can not be greater or equal spec.nonAdmin.backupSyncPeriod (%v)"
 			if defaultBSLSpec.BackupSyncPeriod != nil {
 				if appliedBackupSyncPeriod <= defaultBSLSpec.BackupSyncPeriod.Duration {
-					return false, fmt.Errorf(
+					return newFieldError(field.Invalid(nonAdminPath.Child("backupSyncPeriod"), appliedBackupSyncPeriod.String(), fmt.Sprintf(
 						defaultBSLSyncPeriodErrorMessage,
 						defaultBSLSpec.BackupSyncPeriod.Duration, appliedBackupSyncPeriod,
-					)
+					)))
 				}
 			} else {
 				if r.dpa.Spec.Configuration.Velero.Args != nil && r.dpa.Spec.Configuration.Velero.Args.BackupSyncPeriod != nil {
 					if appliedBackupSyncPeriod <= *r.dpa.Spec.Configuration.Velero.Args.BackupSyncPeriod {
-						return false, fmt.Errorf(
+						return newFieldError(field.Invalid(nonAdminPath.Child("backupSyncPeriod"), appliedBackupSyncPeriod.String(), fmt.Sprintf(
 							defaultBSLSyncPeriodErrorMessage,
 							r.dpa.Spec.Configuration.Velero.Args.BackupSyncPeriod, appliedBackupSyncPeriod,
-						)
+						)))
 					}
 				} else {
 					// https://github.com/vmware-tanzu/velero/blob/9295be4cc061038b91b7bfaf55d99e9bc9dcf0af/pkg/cmd/server/config/config.go#L24
 					if appliedBackupSyncPeriod <= time.Minute {
-						return false, fmt.Errorf(
+						return newFieldError(field.Invalid(nonAdminPath.Child("backupSyncPeriod"), appliedBackupSyncPeriod.String(), fmt.Sprintf(
 							defaultBSLSyncPeriodErrorMessage,
 							time.Minute, appliedBackupSyncPeriod,
-						)
+						)))
 					}
 				}
 			}
@@ -250,27 +332,27 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 			// check if BSL name is enforced by the admin
 			// We do not support this, we restrict enforcing BSL name
 			if enforcedBackupSpec.StorageLocation != "" {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.storageLocation")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceBackupSpec", "storageLocation"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.storageLocation")))
 			}
 
 			if enforcedBackupSpec.VolumeSnapshotLocations != nil {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.volumeSnapshotLocations")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceBackupSpec", "volumeSnapshotLocations"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.volumeSnapshotLocations")))
 			}
 
 			if enforcedBackupSpec.IncludedNamespaces != nil {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.includedNamespaces")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceBackupSpec", "includedNamespaces"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.includedNamespaces")))
 			}
 
 			if enforcedBackupSpec.ExcludedNamespaces != nil {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.excludedNamespaces")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceBackupSpec", "excludedNamespaces"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedBackupSpec.excludedNamespaces")))
 			}
 
 			if enforcedBackupSpec.IncludeClusterResources != nil && *enforcedBackupSpec.IncludeClusterResources {
-				return false, fmt.Errorf(NACNonEnforceableErr+" as true, must be set to false if enforced by admins", "spec.nonAdmin.enforcedBackupSpec.includeClusterResources")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceBackupSpec", "includeClusterResources"), fmt.Sprintf(NACNonEnforceableErr+" as true, must be set to false if enforced by admins", "spec.nonAdmin.enforcedBackupSpec.includeClusterResources")))
 			}
 
 			if len(enforcedBackupSpec.IncludedClusterScopedResources) > 0 {
-				return false, fmt.Errorf(NACNonEnforceableErr+" and must remain empty", "spec.nonAdmin.enforcedBackupSpec.includedClusterScopedResources")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceBackupSpec", "includedClusterScopedResources"), fmt.Sprintf(NACNonEnforceableErr+" and must remain empty", "spec.nonAdmin.enforcedBackupSpec.includedClusterScopedResources")))
 			}
 
 		}
@@ -279,19 +361,19 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 
 		if enforcedRestoreSpec != nil {
 			if len(enforcedRestoreSpec.ScheduleName) > 0 {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.scheduleName")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceRestoreSpec", "scheduleName"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.scheduleName")))
 			}
 
 			if enforcedRestoreSpec.IncludedNamespaces != nil {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.includedNamespaces")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceRestoreSpec", "includedNamespaces"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.includedNamespaces")))
 			}
 
 			if enforcedRestoreSpec.ExcludedNamespaces != nil {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.excludedNamespaces")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceRestoreSpec", "excludedNamespaces"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.excludedNamespaces")))
 			}
 
 			if enforcedRestoreSpec.NamespaceMapping != nil {
-				return false, fmt.Errorf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.namespaceMapping")
+				return newFieldError(field.Forbidden(nonAdminPath.Child("enforceRestoreSpec", "namespaceMapping"), fmt.Sprintf(NACNonEnforceableErr, "spec.nonAdmin.enforcedRestoreSpec.namespaceMapping")))
 			}
 		}
 
@@ -299,16 +381,16 @@ func (r *DataProtectionApplicationReconciler) ValidateDataProtectionCR(log logr.
 
 		if enforcedBSLSpec != nil {
 			if enforcedBSLSpec.BackupSyncPeriod != nil && enforcedBSLSpec.BackupSyncPeriod.Duration >= appliedBackupSyncPeriod {
-				return false, fmt.Errorf(
+				return newFieldError(field.Invalid(nonAdminPath.Child("enforceBSLSpec", "backupSyncPeriod"), enforcedBSLSpec.BackupSyncPeriod.Duration.String(), fmt.Sprintf(
 					"DPA spec.nonAdmin.enforcedBSLSpec.backupSyncPeriod (%v) can not be greater or equal DPA spec.nonAdmin.backupSyncPeriod (%v)",
 					enforcedBSLSpec.BackupSyncPeriod.Duration, appliedBackupSyncPeriod,
-				)
+				)))
 
 			}
 		}
 	}
 
-	return true, nil
+	return nil
 }
 
 // For later: Move this code into validator.go when more need for validation arises
@@ -319,7 +401,7 @@ func (r *DataProtectionApplicationReconciler) ValidateVeleroPlugins() (bool, err
 	dpa := r.dpa
 
 	providerNeedsDefaultCreds, err := r.noDefaultCredentials()
-	if err != nil {
+	if err := r.resourceCheck(err); err != nil {
 		return false, err
 	}
 
@@ -339,13 +421,13 @@ func (r *DataProtectionApplicationReconciler) ValidateVeleroPlugins() (bool, err
 
 		// check for VSM/Volsync DataMover (OADP 1.2 or below) syntax
 		if plugin == oadpv1alpha1.DefaultPluginVSM {
-			return false, errors.New("Delete vsm from spec.configuration.velero.defaultPlugins and dataMover object from spec.features. Use Velero Built-in Data Mover instead")
+			return false, newFieldError(field.Forbidden(field.NewPath("spec", "configuration", "velero", "defaultPlugins"), "Delete vsm from spec.configuration.velero.defaultPlugins and dataMover object from spec.features. Use Velero Built-in Data Mover instead"))
 		}
 		if ok && pluginSpecificMap.IsCloudProvider && pluginNeedsCheck && !dpa.Spec.Configuration.Velero.NoDefaultBackupLocation && !dpa.Spec.Configuration.Velero.HasFeatureFlag("no-secret") {
 			secretNamesToValidate := mapset.NewSet[string]()
 			// check specified credentials in backup locations exists in the cluster
 			for _, location := range dpa.Spec.BackupLocations {
-				if location.Velero != nil {
+				if location.Velero != nil && location.SecretProviderClass == nil {
 					provider := strings.TrimPrefix(location.Velero.Provider, veleroIOPrefix)
 					if provider == string(plugin) && location.Velero != nil {
 						if location.Velero.Credential != nil {
@@ -358,7 +440,7 @@ func (r *DataProtectionApplicationReconciler) ValidateVeleroPlugins() (bool, err
 			}
 			// check specified credentials in snapshot locations exists in the cluster
 			for _, location := range dpa.Spec.SnapshotLocations {
-				if location.Velero != nil {
+				if location.Velero != nil && location.SecretProviderClass == nil {
 					provider := strings.TrimPrefix(location.Velero.Provider, veleroIOPrefix)
 					if provider == string(plugin) && location.Velero != nil {
 						if location.Velero.Credential != nil {
@@ -373,14 +455,16 @@ func (r *DataProtectionApplicationReconciler) ValidateVeleroPlugins() (bool, err
 				_, err := r.getProviderSecret(secretName)
 				if err != nil {
 					r.Log.Info(fmt.Sprintf("error validating %s provider secret:  %s/%s", string(plugin), r.NamespacedName.Namespace, secretName))
-					return false, err
+					if err := r.resourceCheck(err); err != nil {
+						return false, err
+					}
 				}
 			}
 		}
 	}
 
 	if foundAWSPlugin && foundLegacyAWSPlugin {
-		return false, fmt.Errorf("%s and %s can not be both specified in DPA spec.configuration.velero.defaultPlugins", oadpv1alpha1.DefaultPluginAWS, oadpv1alpha1.DefaultPluginLegacyAWS)
+		return false, newFieldError(field.Invalid(field.NewPath("spec", "configuration", "velero", "defaultPlugins"), dpa.Spec.Configuration.Velero.DefaultPlugins, fmt.Sprintf("%s and %s can not be both specified in DPA spec.configuration.velero.defaultPlugins", oadpv1alpha1.DefaultPluginAWS, oadpv1alpha1.DefaultPluginLegacyAWS)))
 	}
 
 	return true, nil
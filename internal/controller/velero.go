@@ -17,6 +17,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -93,12 +94,29 @@ func (r *DataProtectionApplicationReconciler) ReconcileVeleroDeployment(log logr
 			}
 		}
 
+		var liveTemplate *corev1.PodTemplateSpec
+		if !veleroDeployment.ObjectMeta.CreationTimestamp.IsZero() {
+			liveTemplate = veleroDeployment.Spec.Template.DeepCopy()
+		}
+
 		// update the Deployment template
 		err := r.buildVeleroDeployment(veleroDeployment)
 		if err != nil {
 			return err
 		}
 
+		// a template change restarts the Velero pod, keep the running template while operations are in progress
+		if liveTemplate != nil && !equality.Semantic.DeepEqual(*liveTemplate, veleroDeployment.Spec.Template) {
+			deferred, err := r.deferRollout(common.Velero)
+			if err != nil {
+				return err
+			}
+			if deferred {
+				log.Info("Deferring Velero Deployment rollout until in-progress operations finish")
+				veleroDeployment.Spec.Template = *liveTemplate
+			}
+		}
+
 		// Setting controller owner reference on the velero deployment
 		return controllerutil.SetControllerReference(dpa, veleroDeployment, r.Scheme)
 	})
@@ -111,12 +129,22 @@ func (r *DataProtectionApplicationReconciler) ReconcileVeleroDeployment(log logr
 			cause, isStatusCause := errors.StatusCause(err, metav1.CauseTypeFieldValueInvalid)
 			if isStatusCause && cause.Field == "spec.selector" {
 				// recreate deployment
-				// TODO: check for in-progress backup/restore to wait for it to finish
+				deferred, err := r.deferRollout(common.Velero)
+				if err != nil {
+					return false, err
+				}
+				if deferred {
+					return true, nil
+				}
 				log.Info("Found immutable selector from previous deployment, recreating Velero Deployment")
-				err := r.Delete(r.Context, veleroDeployment)
+				err = r.Delete(r.Context, veleroDeployment)
 				if err != nil {
 					return false, err
 				}
+				// a dry-run delete leaves the object in place, the plan records the delete
+				if r.isPlanning() {
+					return true, nil
+				}
 				return r.ReconcileVeleroDeployment(log)
 			}
 		}
@@ -435,7 +463,12 @@ func (r *DataProtectionApplicationReconciler) customizeVeleroDeployment(veleroDe
 		veleroDeployment.Spec.ProgressDeadlineSeconds = ptr.To(int32(600))
 	}
 	r.appendPluginSpecificSpecs(veleroDeployment, veleroContainer, providerNeedsDefaultCreds)
+	credentials.AppendSecretProviderClassVolumes(dpa, &veleroDeployment.Spec.Template.Spec, veleroContainer)
+	credentials.AppendLocationSecretVolumes(dpa, &veleroDeployment.Spec.Template.Spec, veleroContainer)
 	setPodTemplateSpecDefaults(&veleroDeployment.Spec.Template)
+	if err := r.setCredentialsHashAnnotation(&veleroDeployment.Spec.Template); err != nil {
+		return err
+	}
 	if configMapName, ok := dpa.Annotations[common.UnsupportedVeleroServerArgsAnnotation]; ok {
 		if configMapName != "" {
 			unsupportedServerArgsCM := corev1.ConfigMap{}
@@ -504,12 +537,7 @@ func (r *DataProtectionApplicationReconciler) appendPluginSpecificSpecs(veleroDe
 				})
 
 			// append plugin specific env vars
-			veleroContainer.Env = append(
-				veleroContainer.Env,
-				corev1.EnvVar{
-					Name:  pluginSpecificMap.EnvCredentialsFile,
-					Value: pluginSpecificMap.MountPath + "/" + credentials.CloudFieldPath,
-				})
+			veleroContainer.Env = append(veleroContainer.Env, credentials.CloudProviderEnvVars(plugin)...)
 
 			// append plugin specific volumes
 			veleroDeployment.Spec.Template.Spec.Volumes = append(
@@ -825,11 +853,11 @@ func (r DataProtectionApplicationReconciler) noDefaultCredentials() (map[string]
 		}
 	} else {
 		for _, bsl := range dpa.Spec.BackupLocations {
-			if bsl.Velero != nil && bsl.Velero.Credential == nil {
+			if bsl.Velero != nil && bsl.Velero.Credential == nil && bsl.SecretProviderClass == nil {
 				bslProvider := strings.TrimPrefix(bsl.Velero.Provider, veleroIOPrefix)
 				providerNeedsDefaultCreds[bslProvider] = true
 			}
-			if bsl.Velero != nil && bsl.Velero.Credential != nil {
+			if bsl.Velero != nil && (bsl.Velero.Credential != nil || bsl.SecretProviderClass != nil) {
 				bslProvider := strings.TrimPrefix(bsl.Velero.Provider, veleroIOPrefix)
 				if _, found := providerNeedsDefaultCreds[bslProvider]; !found {
 					providerNeedsDefaultCreds[bslProvider] = false
@@ -854,7 +882,7 @@ func (r DataProtectionApplicationReconciler) noDefaultCredentials() (map[string]
 			// To handle the case where we want to manually hand the credentials for a cloud storage created
 			// Bucket credentials via configuration. Only AWS is supported
 			provider := strings.TrimPrefix(vsl.Velero.Provider, veleroIOPrefix)
-			if vsl.Velero.Credential != nil || provider == string(oadpv1alpha1.AWSBucketProvider) && hasCloudStorage {
+			if vsl.Velero.Credential != nil || vsl.SecretProviderClass != nil || provider == string(oadpv1alpha1.AWSBucketProvider) && hasCloudStorage {
 				if _, found := providerNeedsDefaultCreds[provider]; !found {
 					providerNeedsDefaultCreds[provider] = false
 				}
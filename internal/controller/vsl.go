@@ -1,16 +1,14 @@
 package controller
 
 import (
-	"errors"
 	"fmt"
-	"slices"
 	"strings"
 
 	"github.com/go-logr/logr"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
@@ -54,6 +52,10 @@ var validAzureKeys = map[string]bool{
 func (r *DataProtectionApplicationReconciler) LabelVSLSecrets(log logr.Logger) (bool, error) {
 	dpa := r.dpa
 	for _, vsl := range dpa.Spec.SnapshotLocations {
+		// the credentials of a SecretProviderClass are not in a secret
+		if vsl.SecretProviderClass != nil {
+			continue
+		}
 		provider := strings.TrimPrefix(vsl.Velero.Provider, veleroIOPrefix)
 		switch provider {
 		case "aws":
@@ -98,6 +100,14 @@ func (r *DataProtectionApplicationReconciler) LabelVSLSecrets(log logr.Logger) (
 					return false, err
 				}
 			}
+		default:
+			// the providers of custom plugins only have the secret of their credential
+			if vsl.Velero.Credential != nil {
+				err := r.UpdateCredentialsSecretLabels(vsl.Velero.Credential.Name, dpa.Name)
+				if err != nil {
+					return false, err
+				}
+			}
 		}
 
 	}
@@ -106,76 +116,52 @@ func (r *DataProtectionApplicationReconciler) LabelVSLSecrets(log logr.Logger) (
 
 func (r *DataProtectionApplicationReconciler) ValidateVolumeSnapshotLocations() (bool, error) {
 	dpa := r.dpa
+	providers, err := snapshotProviders(dpa)
+	if err != nil {
+		return false, err
+	}
 	for i, vslSpec := range dpa.Spec.SnapshotLocations {
 		vslYAMLPath := fmt.Sprintf("spec.snapshotLocations[%v]", i)
 		veleroVSLYAMLPath := vslYAMLPath + ".velero"
 		veleroConfigYAMLPath := "spec.configuration.velero"
+		veleroVSLPath := field.NewPath("spec", "snapshotLocations").Index(i).Child("velero")
+		defaultPluginsPath := field.NewPath("spec", "configuration", "velero", "defaultPlugins")
 
 		if vslSpec.Velero == nil {
-			return false, errors.New("snapshotLocation velero configuration cannot be nil")
+			return false, newFieldError(field.Required(veleroVSLPath, "snapshotLocation velero configuration cannot be nil"))
+		}
+		if err := validateSecretProviderClass(vslSpec.SecretProviderClass, vslSpec.Velero.Credential, vslSpec.Velero.Config, field.NewPath("spec", "snapshotLocations").Index(i)); err != nil {
+			return false, err
 		}
 
 		// check for valid provider
-		if vslSpec.Velero.Provider != AWSProvider && vslSpec.Velero.Provider != GCPProvider &&
-			vslSpec.Velero.Provider != AzureProvider {
-			return false, fmt.Errorf("DPA %s.provider %s is invalid: only %s, %s and %s are supported", veleroVSLYAMLPath, vslSpec.Velero.Provider, AWSProvider, GCPProvider, AzureProvider)
+		provider, ok := providers[vslSpec.Velero.Provider]
+		if !ok {
+			return false, newFieldError(field.Invalid(veleroVSLPath.Child("provider"), vslSpec.Velero.Provider, fmt.Sprintf("DPA %s.provider %s is invalid: only %s are supported, declare the providers of custom plugins in %s.customPlugins", veleroVSLYAMLPath, vslSpec.Velero.Provider, strings.Join(snapshotProviderNames(providers), ", "), veleroConfigYAMLPath)))
 		}
 
-		//AWS
-		if vslSpec.Velero.Provider == AWSProvider {
-			//in AWS, region is a required field
-			if len(vslSpec.Velero.Config[AWSRegion]) == 0 {
-				return false, fmt.Errorf("region for %s VSL in DPA %s.config is not configured, please ensure a region is configured", AWSProvider, veleroVSLYAMLPath)
-			}
-
-			// check for invalid config key
-			for key := range vslSpec.Velero.Config {
-				valid := validAWSKeys[key]
-				if !valid {
-					return false, fmt.Errorf("DPA %s.config key %s is not a valid %s config key", veleroVSLYAMLPath, key, AWSProvider)
-				}
-			}
-			//checking the aws plugin, if not present, throw warning message
-			if !containsPlugin(dpa.Spec.Configuration.Velero.DefaultPlugins, AWSProvider) {
-				return false, fmt.Errorf("to use VSL for %s specified in DPA %s, %s plugin must be present in %s.defaultPlugins", AWSProvider, vslYAMLPath, AWSProvider, veleroConfigYAMLPath)
+		// check for required config keys, like the region of AWS
+		for _, key := range provider.requiredKeys {
+			if len(vslSpec.Velero.Config[key]) == 0 {
+				return false, newFieldError(field.Required(veleroVSLPath.Child("config").Key(key), fmt.Sprintf("%s for %s VSL in DPA %s.config is not configured, please ensure a %s is configured", key, vslSpec.Velero.Provider, veleroVSLYAMLPath, key)))
 			}
 		}
 
-		//GCP
-		if vslSpec.Velero.Provider == GCPProvider {
-
-			// check for invalid config key
+		// check for invalid config key
+		if provider.validKeys != nil {
 			for key := range vslSpec.Velero.Config {
-				valid := validGCPKeys[key]
-				if !valid {
-					return false, fmt.Errorf("DPA %s.config key %s is not a valid %s config key", veleroVSLYAMLPath, key, GCPProvider)
+				if !provider.validKeys[key] {
+					return false, newFieldError(field.Invalid(veleroVSLPath.Child("config").Key(key), vslSpec.Velero.Config[key], fmt.Sprintf("DPA %s.config key %s is not a valid %s config key", veleroVSLYAMLPath, key, vslSpec.Velero.Provider)))
 				}
 			}
-			//checking the gcp plugin, if not present, throw warning message
-			if !containsPlugin(dpa.Spec.Configuration.Velero.DefaultPlugins, "gcp") {
-
-				return false, fmt.Errorf("to use VSL for %s specified in DPA %s, %s plugin must be present in %s.defaultPlugins", GCPProvider, vslYAMLPath, GCPProvider, veleroConfigYAMLPath)
-			}
 		}
 
-		//Azure
-		if vslSpec.Velero.Provider == AzureProvider {
-
-			// check for invalid config key
-			for key := range vslSpec.Velero.Config {
-				valid := validAzureKeys[key]
-				if !valid {
-					return false, fmt.Errorf("DPA %s.config key %s is not a valid %s config key", veleroVSLYAMLPath, key, AzureProvider)
-				}
-			}
-			//checking the azure plugin, if not present, throw warning message
-			if !containsPlugin(dpa.Spec.Configuration.Velero.DefaultPlugins, "azure") {
-
-				return false, fmt.Errorf("to use VSL for %s specified in DPA %s, %s plugin must be present in %s.defaultPlugins", AzureProvider, vslYAMLPath, AzureProvider, veleroConfigYAMLPath)
-			}
+		// checking the plugin of the provider, custom plugins are configured with their providers
+		if provider.defaultPlugin && !containsPlugin(dpa.Spec.Configuration.Velero.DefaultPlugins, vslSpec.Velero.Provider) {
+			return false, newFieldError(field.Required(defaultPluginsPath, fmt.Sprintf("to use VSL for %s specified in DPA %s, %s plugin must be present in %s.defaultPlugins", vslSpec.Velero.Provider, vslYAMLPath, vslSpec.Velero.Provider, veleroConfigYAMLPath)))
 		}
 
-		if err := r.ensureVslSecretDataExists(&vslSpec); err != nil {
+		if err := r.resourceCheck(r.ensureVslSecretDataExists(&vslSpec)); err != nil {
 			return false, err
 		}
 
@@ -183,20 +169,22 @@ func (r *DataProtectionApplicationReconciler) ValidateVolumeSnapshotLocations()
 	return true, nil
 }
 
+func getVolumeSnapshotLocationName(dpaName string, i int, vslSpec oadpv1alpha1.SnapshotLocation) string {
+	// check if VSL name is specified in DPA spec
+	if vslSpec.Name != "" {
+		return vslSpec.Name
+	}
+	return fmt.Sprintf("%s-%d", dpaName, i+1)
+}
+
 func (r *DataProtectionApplicationReconciler) ReconcileVolumeSnapshotLocations(log logr.Logger) (bool, error) {
 	dpa := r.dpa
-	dpaVSLNames := []string{}
 	// Loop through all configured VSLs
 	for i, vslSpec := range dpa.Spec.SnapshotLocations {
 		// Create VSL as is, we can safely assume they are valid from
 		// ValidateVolumeSnapshotLocations
 
-		// check if VSL name is specified in DPA spec
-		vslName := fmt.Sprintf("%s-%d", r.NamespacedName.Name, i+1)
-		if vslSpec.Name != "" {
-			vslName = vslSpec.Name
-		}
-		dpaVSLNames = append(dpaVSLNames, vslName)
+		vslName := getVolumeSnapshotLocationName(r.NamespacedName.Name, i, vslSpec)
 
 		vsl := velerov1.VolumeSnapshotLocation{
 			ObjectMeta: metav1.ObjectMeta{
@@ -204,7 +192,7 @@ func (r *DataProtectionApplicationReconciler) ReconcileVolumeSnapshotLocations(l
 				Name:      vslName,
 				Namespace: r.NamespacedName.Namespace,
 			},
-			Spec: *vslSpec.Velero,
+			Spec: snapshotLocationVeleroSpec(vslSpec, r.dpa.MountLocationCredentials()),
 		}
 		// Create VSL
 		op, err := controllerutil.CreateOrPatch(r.Context, r.Client, &vsl, func() error {
@@ -227,7 +215,7 @@ func (r *DataProtectionApplicationReconciler) ReconcileVolumeSnapshotLocations(l
 				oadpv1alpha1.OadpOperatorLabel: "True",
 			}
 
-			vsl.Spec = *vslSpec.Velero
+			vsl.Spec = snapshotLocationVeleroSpec(vslSpec, r.dpa.MountLocationCredentials())
 			return nil
 		})
 		if err != nil {
@@ -244,33 +232,7 @@ func (r *DataProtectionApplicationReconciler) ReconcileVolumeSnapshotLocations(l
 
 	}
 
-	dpaVSLs := velerov1.VolumeSnapshotLocationList{}
-	dpaVslLabels := map[string]string{
-		"app.kubernetes.io/name":       common.OADPOperatorVelero,
-		"app.kubernetes.io/managed-by": common.OADPOperator,
-		"app.kubernetes.io/component":  "vsl",
-	}
-	err := r.List(r.Context, &dpaVSLs, client.InNamespace(r.NamespacedName.Namespace), client.MatchingLabels(dpaVslLabels))
-	if err != nil {
-		return false, err
-	}
-
-	// If current VSLs do not match the spec, delete extra VSLs
-	if len(dpaVSLNames) != len(dpaVSLs.Items) {
-		for _, vsl := range dpaVSLs.Items {
-			if !slices.Contains(dpaVSLNames, vsl.Name) {
-				if err := r.Delete(r.Context, &vsl); err != nil {
-					return false, err
-				}
-				// Record event for VSL deletion
-				r.EventRecorder.Event(&vsl,
-					corev1.EventTypeNormal,
-					"VolumeSnapshotLocationDeleted",
-					fmt.Sprintf("VolumeSnapshotLocation %s created by OADP in namespace %s was deleted as it was not in DPA spec.", vsl.Name, vsl.Namespace))
-			}
-		}
-	}
-
+	// VSLs removed from the spec are deleted by ReconcileOrphanedResources
 	return true, nil
 }
 
@@ -284,8 +246,8 @@ func containsPlugin(d []oadpv1alpha1.DefaultPlugin, value string) bool {
 }
 
 func (r *DataProtectionApplicationReconciler) ensureVslSecretDataExists(vsl *oadpv1alpha1.SnapshotLocation) error {
-	// Check if the Velero feature flag 'no-secret' is not set
-	if !(r.dpa.Spec.Configuration.Velero.HasFeatureFlag("no-secret")) {
+	// Check if the Velero feature flag 'no-secret' is not set, the credentials of a SecretProviderClass are not in a secret
+	if !(r.dpa.Spec.Configuration.Velero.HasFeatureFlag("no-secret")) && vsl.SecretProviderClass == nil {
 		// Check if the user specified credential under velero
 		if vsl.Velero != nil && vsl.Velero.Credential != nil {
 			// Check if user specified empty credential key
@@ -304,10 +266,27 @@ func (r *DataProtectionApplicationReconciler) ensureVslSecretDataExists(vsl *oad
 			if vsl.Velero.Config[CredentialsFileKey] != "" {
 				return nil
 			}
-			_, _, err := r.getSecretNameAndKey(vsl.Velero.Config, vsl.Velero.Credential, oadpv1alpha1.DefaultPlugin(vsl.Velero.Provider))
+			// the providers of custom plugins have no default secret
+			if _, ok := credentials.PluginSpecificFields[oadpv1alpha1.DefaultPlugin(vsl.Velero.Provider)]; !ok && vsl.Velero.Credential == nil {
+				return nil
+			}
+			secretName, secretKey, err := r.getSecretNameAndKey(vsl.Velero.Config, vsl.Velero.Credential, oadpv1alpha1.DefaultPlugin(vsl.Velero.Provider))
 			if err != nil {
 				return err
 			}
+			// Only parse secrets when Velero reads the mounted secrets of the locations as is
+			if !r.dpa.MountLocationCredentials() {
+				return nil
+			}
+			secret, err := r.getProviderSecret(secretName)
+			if err != nil {
+				return err
+			}
+			awsProfile := "default"
+			if value, exists := vsl.Velero.Config[Profile]; exists {
+				awsProfile = value
+			}
+			return r.parseProviderSecret(vsl.Velero.Provider, secret, secretKey, awsProfile)
 		}
 	}
 	return nil
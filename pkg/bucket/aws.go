@@ -2,6 +2,7 @@ package bucket
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -11,6 +12,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/utils"
 )
 
 type awsBucketClient struct {
@@ -124,6 +126,95 @@ func CreateBucketTaggingInput(bucketname string, tags map[string]string) *s3.Put
 	return putInput
 }
 
+// PutLifecycleRules replaces the bucket lifecycle configuration with the
+// lifecycleRules of the CloudStorage. An empty lifecycleRules removes the
+// lifecycle configuration from the bucket.
+func (a awsBucketClient) PutLifecycleRules() error {
+	if a.bucket.Spec.LifecycleRules == nil {
+		return nil
+	}
+	s3Client, err := a.getS3Client()
+	if err != nil {
+		return err
+	}
+	input := CreateBucketLifecycleConfigurationInput(a.bucket.Spec.Name, a.bucket.Spec.LifecycleRules)
+	if len(input.LifecycleConfiguration.Rules) == 0 {
+		_, err = s3Client.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{Bucket: aws.String(a.bucket.Spec.Name)})
+		return err
+	}
+	if err := input.Validate(); err != nil {
+		return fmt.Errorf("unable to validate %v bucket lifecycle configuration: %v", a.bucket.Spec.Name, err)
+	}
+	_, err = s3Client.PutBucketLifecycleConfiguration(input)
+	return err
+}
+
+// CreateBucketLifecycleConfigurationInput creates an S3 PutBucketLifecycleConfigurationInput object,
+// with one rule applying to the whole bucket for each configured lifecycle rule.
+func CreateBucketLifecycleConfigurationInput(bucketname string, rules *v1alpha1.LifecycleRules) *s3.PutBucketLifecycleConfigurationInput {
+	input := &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucketname),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{
+			Rules: []*s3.LifecycleRule{},
+		},
+	}
+	newRule := func(id string) *s3.LifecycleRule {
+		return &s3.LifecycleRule{
+			ID:     aws.String(id),
+			Status: aws.String(s3.ExpirationStatusEnabled),
+			Filter: &s3.LifecycleRuleFilter{Prefix: aws.String("")},
+		}
+	}
+	if rules.AbortIncompleteMultipartUploadDays != nil {
+		rule := newRule("oadp-abort-incomplete-multipart-upload")
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: rules.AbortIncompleteMultipartUploadDays,
+		}
+		input.LifecycleConfiguration.Rules = append(input.LifecycleConfiguration.Rules, rule)
+	}
+	if rules.NoncurrentVersionExpirationDays != nil {
+		rule := newRule("oadp-noncurrent-version-expiration")
+		rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+			NoncurrentDays: rules.NoncurrentVersionExpirationDays,
+		}
+		input.LifecycleConfiguration.Rules = append(input.LifecycleConfiguration.Rules, rule)
+	}
+	if rules.Transition != nil {
+		rule := newRule("oadp-transition")
+		rule.Transitions = []*s3.Transition{
+			{
+				Days:         aws.Int64(rules.Transition.Days),
+				StorageClass: aws.String(string(TransitionStorageClass(rules.Transition))),
+			},
+		}
+		input.LifecycleConfiguration.Rules = append(input.LifecycleConfiguration.Rules, rule)
+	}
+	return input
+}
+
+// GetUsage lists every object in the bucket and returns their total size and
+// count, broken down by the given prefixes.
+func (a awsBucketClient) GetUsage(prefixes []string) (*v1alpha1.CloudStorageUsage, error) {
+	s3Client, err := a.getS3Client()
+	if err != nil {
+		return nil, err
+	}
+	acc := newUsageAccumulator(prefixes)
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(a.bucket.Spec.Name),
+	}
+	err = s3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			acc.add(aws.StringValue(object.Key), aws.Int64Value(object.Size))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list objects in bucket %v: %v", a.bucket.Spec.Name, err)
+	}
+	return acc.result(), nil
+}
+
 func (a awsBucketClient) getS3Client() (s3iface.S3API, error) {
 	awsConfig := &aws.Config{Region: &a.bucket.Spec.Region}
 	cred, err := getCredentialFromCloudStorageSecret(a.client, a.bucket)
@@ -131,12 +222,22 @@ func (a awsBucketClient) getS3Client() (s3iface.S3API, error) {
 		return nil, err
 	}
 
+	content, err := os.ReadFile(cred)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := utils.ResolveAWSCredentials(content, session.DefaultSharedConfigProfile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AWS credentials in secret %s: %w", a.bucket.Spec.CreationSecret.Name, err)
+	}
+
 	opts := session.Options{
 		Config:            *awsConfig,
 		SharedConfigFiles: []string{cred},
 	}
 
-	if a.bucket.Spec.EnableSharedConfig != nil && *a.bucket.Spec.EnableSharedConfig {
+	// the SDK only reads roles, SSO and credential processes from the shared config
+	if (a.bucket.Spec.EnableSharedConfig != nil && *a.bucket.Spec.EnableSharedConfig) || !creds.HasKeys() {
 		opts.SharedConfigState = session.SharedConfigEnable
 	}
 
@@ -28,6 +28,8 @@ type Client interface {
 	Create() (bool, error)
 	Delete() (bool, error)
 	ForceCredentialRefresh() error
+	PutLifecycleRules() error
+	GetUsage(prefixes []string) (*v1alpha1.CloudStorageUsage, error)
 }
 
 func NewClient(b v1alpha1.CloudStorage, c client.Client) (Client, error) {
@@ -0,0 +1,56 @@
+package bucket
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// minimumInfrequentAccessTransitionDays is the minimum age objects must reach
+// before they can be transitioned to STANDARD_IA or ONEZONE_IA.
+const minimumInfrequentAccessTransitionDays = 30
+
+// DefaultBackupTTL is the Velero server default backup TTL, used when a DPA does not configure one.
+// https://github.com/vmware-tanzu/velero/blob/v1.14.0/pkg/cmd/server/server.go#L100
+const DefaultBackupTTL = 720 * time.Hour
+
+// ValidateLifecycleRules validates the lifecycle rules against the storage class
+// constraints and the TTLs of the backups stored in the bucket.
+func ValidateLifecycleRules(rules *v1alpha1.LifecycleRules, backupTTLs []time.Duration) error {
+	if rules == nil {
+		return nil
+	}
+	if rules.AbortIncompleteMultipartUploadDays != nil && *rules.AbortIncompleteMultipartUploadDays < 1 {
+		return fmt.Errorf("spec.lifecycleRules.abortIncompleteMultipartUploadDays must be at least 1")
+	}
+	if rules.NoncurrentVersionExpirationDays != nil && *rules.NoncurrentVersionExpirationDays < 1 {
+		return fmt.Errorf("spec.lifecycleRules.noncurrentVersionExpirationDays must be at least 1")
+	}
+	if rules.Transition == nil {
+		return nil
+	}
+	days := rules.Transition.Days
+	storageClass := TransitionStorageClass(rules.Transition)
+	if (storageClass == v1alpha1.StandardIAStorageClass || storageClass == v1alpha1.OneZoneIAStorageClass) && days < minimumInfrequentAccessTransitionDays {
+		return fmt.Errorf("spec.lifecycleRules.transition.days must be at least %d when transitioning to %s", minimumInfrequentAccessTransitionDays, storageClass)
+	}
+	for _, ttl := range backupTTLs {
+		// backups with a TTL of 0 never expire
+		if ttl <= 0 {
+			continue
+		}
+		if time.Duration(days)*24*time.Hour >= ttl {
+			return fmt.Errorf("spec.lifecycleRules.transition.days (%d) must be less than the backup TTL (%v), otherwise backups expire before they are transitioned", days, ttl)
+		}
+	}
+	return nil
+}
+
+// TransitionStorageClass returns the storage class of the transition, defaulting to STANDARD_IA.
+func TransitionStorageClass(transition *v1alpha1.LifecycleTransition) v1alpha1.LifecycleTransitionStorageClass {
+	if transition.StorageClass == "" {
+		return v1alpha1.StandardIAStorageClass
+	}
+	return transition.StorageClass
+}
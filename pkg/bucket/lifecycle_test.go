@@ -0,0 +1,143 @@
+package bucket_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"k8s.io/utils/ptr"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/bucket"
+)
+
+func TestValidateLifecycleRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      *oadpv1alpha1.LifecycleRules
+		backupTTLs []time.Duration
+		wantErr    bool
+	}{
+		{
+			name:    "nil rules are valid",
+			rules:   nil,
+			wantErr: false,
+		},
+		{
+			name: "abort and noncurrent expiration rules are valid",
+			rules: &oadpv1alpha1.LifecycleRules{
+				AbortIncompleteMultipartUploadDays: ptr.To(int64(7)),
+				NoncurrentVersionExpirationDays:    ptr.To(int64(30)),
+			},
+			backupTTLs: []time.Duration{bucket.DefaultBackupTTL},
+			wantErr:    false,
+		},
+		{
+			name: "abort incomplete multipart upload days must be positive",
+			rules: &oadpv1alpha1.LifecycleRules{
+				AbortIncompleteMultipartUploadDays: ptr.To(int64(0)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "transition to STANDARD_IA before 30 days is invalid",
+			rules: &oadpv1alpha1.LifecycleRules{
+				Transition: &oadpv1alpha1.LifecycleTransition{Days: 10},
+			},
+			wantErr: true,
+		},
+		{
+			name: "transition to GLACIER_IR before 30 days is valid",
+			rules: &oadpv1alpha1.LifecycleRules{
+				Transition: &oadpv1alpha1.LifecycleTransition{Days: 10, StorageClass: oadpv1alpha1.GlacierIRStorageClass},
+			},
+			backupTTLs: []time.Duration{bucket.DefaultBackupTTL},
+			wantErr:    false,
+		},
+		{
+			name: "transition before the backup TTL is valid",
+			rules: &oadpv1alpha1.LifecycleRules{
+				Transition: &oadpv1alpha1.LifecycleTransition{Days: 29, StorageClass: oadpv1alpha1.IntelligentTieringStorageClass},
+			},
+			backupTTLs: []time.Duration{bucket.DefaultBackupTTL},
+			wantErr:    false,
+		},
+		{
+			name: "transition after the backup TTL is invalid",
+			rules: &oadpv1alpha1.LifecycleRules{
+				Transition: &oadpv1alpha1.LifecycleTransition{Days: 30},
+			},
+			backupTTLs: []time.Duration{bucket.DefaultBackupTTL, 7 * 24 * time.Hour},
+			wantErr:    true,
+		},
+		{
+			name: "backups which never expire do not restrict transition",
+			rules: &oadpv1alpha1.LifecycleRules{
+				Transition: &oadpv1alpha1.LifecycleTransition{Days: 90},
+			},
+			backupTTLs: []time.Duration{0},
+			wantErr:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bucket.ValidateLifecycleRules(tt.rules, tt.backupTTLs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLifecycleRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateBucketLifecycleConfigurationInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     *oadpv1alpha1.LifecycleRules
+		wantRules map[string]bool
+	}{
+		{
+			name:      "empty rules",
+			rules:     &oadpv1alpha1.LifecycleRules{},
+			wantRules: map[string]bool{},
+		},
+		{
+			name: "all rules",
+			rules: &oadpv1alpha1.LifecycleRules{
+				AbortIncompleteMultipartUploadDays: ptr.To(int64(7)),
+				NoncurrentVersionExpirationDays:    ptr.To(int64(30)),
+				Transition:                         &oadpv1alpha1.LifecycleTransition{Days: 60},
+			},
+			wantRules: map[string]bool{
+				"oadp-abort-incomplete-multipart-upload": true,
+				"oadp-noncurrent-version-expiration":     true,
+				"oadp-transition":                        true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := bucket.CreateBucketLifecycleConfigurationInput("test-bucket", tt.rules)
+			if aws.StringValue(input.Bucket) != "test-bucket" {
+				t.Errorf("want bucket test-bucket, got %v", aws.StringValue(input.Bucket))
+			}
+			if len(input.LifecycleConfiguration.Rules) != len(tt.wantRules) {
+				t.Errorf("want %d rules, got %d", len(tt.wantRules), len(input.LifecycleConfiguration.Rules))
+			}
+			for _, rule := range input.LifecycleConfiguration.Rules {
+				if !tt.wantRules[aws.StringValue(rule.ID)] {
+					t.Errorf("unexpected rule %v", aws.StringValue(rule.ID))
+				}
+				if rule.Transitions != nil && aws.StringValue(rule.Transitions[0].StorageClass) != string(oadpv1alpha1.StandardIAStorageClass) {
+					t.Errorf("want transition storage class to default to %v, got %v", oadpv1alpha1.StandardIAStorageClass, aws.StringValue(rule.Transitions[0].StorageClass))
+				}
+			}
+			if len(tt.wantRules) > 0 {
+				if err := input.Validate(); err != nil {
+					t.Errorf("input is not valid: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package bucket
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// veleroTopLevelPrefixes are the directories Velero and its uploaders create under a backup location prefix
+var veleroTopLevelPrefixes = []string{"backups/", "kopia/", "restic/"}
+
+// UsagePrefixes returns the object key prefixes the bucket usage is broken down by,
+// given the prefixes of the backup locations using the bucket.
+func UsagePrefixes(locationPrefixes []string) []string {
+	prefixSet := map[string]bool{}
+	if len(locationPrefixes) == 0 {
+		locationPrefixes = []string{""}
+	}
+	for _, locationPrefix := range locationPrefixes {
+		locationPrefix = strings.Trim(locationPrefix, "/")
+		if locationPrefix != "" {
+			locationPrefix += "/"
+		}
+		for _, prefix := range veleroTopLevelPrefixes {
+			prefixSet[locationPrefix+prefix] = true
+		}
+	}
+	prefixes := make([]string, 0, len(prefixSet))
+	for prefix := range prefixSet {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// usageAccumulator sums the size and count of objects, in total and per prefix
+type usageAccumulator struct {
+	usage    *v1alpha1.CloudStorageUsage
+	prefixes map[string]*v1alpha1.PrefixUsage
+}
+
+func newUsageAccumulator(prefixes []string) *usageAccumulator {
+	acc := &usageAccumulator{
+		usage:    &v1alpha1.CloudStorageUsage{},
+		prefixes: map[string]*v1alpha1.PrefixUsage{},
+	}
+	for _, prefix := range prefixes {
+		acc.prefixes[prefix] = &v1alpha1.PrefixUsage{Prefix: prefix}
+	}
+	return acc
+}
+
+// add accounts an object to the total and to the longest matching prefix
+func (acc *usageAccumulator) add(key string, size int64) {
+	acc.usage.TotalSizeBytes += size
+	acc.usage.ObjectCount++
+	var match *v1alpha1.PrefixUsage
+	for prefix, prefixUsage := range acc.prefixes {
+		if strings.HasPrefix(key, prefix) && (match == nil || len(prefix) > len(match.Prefix)) {
+			match = prefixUsage
+		}
+	}
+	if match != nil {
+		match.SizeBytes += size
+		match.ObjectCount++
+	}
+}
+
+func (acc *usageAccumulator) result() *v1alpha1.CloudStorageUsage {
+	acc.usage.Prefixes = make([]v1alpha1.PrefixUsage, 0, len(acc.prefixes))
+	for _, prefixUsage := range acc.prefixes {
+		acc.usage.Prefixes = append(acc.usage.Prefixes, *prefixUsage)
+	}
+	sort.Slice(acc.usage.Prefixes, func(i, j int) bool {
+		return acc.usage.Prefixes[i].Prefix < acc.usage.Prefixes[j].Prefix
+	})
+	return acc.usage
+}
@@ -0,0 +1,63 @@
+package bucket
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestUsagePrefixes(t *testing.T) {
+	tests := []struct {
+		name             string
+		locationPrefixes []string
+		want             []string
+	}{
+		{
+			name:             "no backup locations",
+			locationPrefixes: nil,
+			want:             []string{"backups/", "kopia/", "restic/"},
+		},
+		{
+			name:             "backup location without prefix",
+			locationPrefixes: []string{""},
+			want:             []string{"backups/", "kopia/", "restic/"},
+		},
+		{
+			name:             "backup locations with prefixes",
+			locationPrefixes: []string{"velero", "/cluster-a/", "velero"},
+			want: []string{
+				"cluster-a/backups/", "cluster-a/kopia/", "cluster-a/restic/",
+				"velero/backups/", "velero/kopia/", "velero/restic/",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UsagePrefixes(tt.locationPrefixes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UsagePrefixes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageAccumulator(t *testing.T) {
+	acc := newUsageAccumulator([]string{"velero/backups/", "velero/kopia/", "velero/restic/"})
+	acc.add("velero/backups/backup-1/velero-backup.json", 100)
+	acc.add("velero/backups/backup-2/velero-backup.json", 200)
+	acc.add("velero/kopia/ns-1/p0001", 1000)
+	acc.add("other/object", 5)
+
+	want := &v1alpha1.CloudStorageUsage{
+		TotalSizeBytes: 1305,
+		ObjectCount:    4,
+		Prefixes: []v1alpha1.PrefixUsage{
+			{Prefix: "velero/backups/", SizeBytes: 300, ObjectCount: 2},
+			{Prefix: "velero/kopia/", SizeBytes: 1000, ObjectCount: 1},
+			{Prefix: "velero/restic/"},
+		},
+	}
+	if got := acc.result(); !reflect.DeepEqual(got, want) {
+		t.Errorf("result() = %+v, want %+v", got, want)
+	}
+}
@@ -3,14 +3,22 @@ package cloudprovider
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"path"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/go-logr/logr"
 
 	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
@@ -43,6 +51,66 @@ func NewAWSProvider(region, endpoint, accessKey, secretKey string) *AWSProvider
 	}
 }
 
+// AWSProviderOptions are the optional settings of the S3 client of an AWSProvider
+type AWSProviderOptions struct {
+	// ForcePathStyle puts the bucket in the path of the request URLs instead of their host
+	ForcePathStyle bool
+	// CACert is a PEM bundle of the certificate authorities of the endpoint, trusted instead of the system ones like Velero does
+	CACert []byte
+	// InsecureSkipTLSVerify does not verify the certificate of the endpoint
+	InsecureSkipTLSVerify bool
+	// SessionToken is the token of temporary keys
+	SessionToken string
+	// AssumeRoles are the roles assumed in order with the keys, each with the credentials of the previous one, like
+	// the source_profile chain of an AWS profile
+	AssumeRoles []utils.AWSAssumeRole
+}
+
+// NewAWSProviderWithOptions creates an AWSProvider like NewAWSProvider, with the TLS and addressing options of a BackupStorageLocation.
+func NewAWSProviderWithOptions(region, endpoint, accessKey, secretKey string, options AWSProviderOptions) (*AWSProvider, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: options.InsecureSkipTLSVerify}
+
+	creds := credentials.NewStaticCredentials(accessKey, secretKey, options.SessionToken)
+	for _, role := range options.AssumeRoles {
+		// STS is called at its AWS endpoint, not at the endpoint of the storage
+		stsSession, err := session.NewSession(&aws.Config{Region: aws.String(region), Credentials: creds})
+		if err != nil {
+			return nil, err
+		}
+		creds = stscreds.NewCredentials(stsSession, role.RoleARN, func(provider *stscreds.AssumeRoleProvider) {
+			if role.ExternalID != "" {
+				provider.ExternalID = aws.String(role.ExternalID)
+			}
+			if role.RoleSessionName != "" {
+				provider.RoleSessionName = role.RoleSessionName
+			}
+		})
+	}
+	sessionOptions := session.Options{
+		Config: aws.Config{
+			Region:           aws.String(region),
+			Credentials:      creds,
+			S3ForcePathStyle: aws.Bool(options.ForcePathStyle),
+			HTTPClient:       &http.Client{Transport: transport},
+		},
+	}
+	if endpoint != "" {
+		sessionOptions.Config.Endpoint = aws.String(endpoint)
+	}
+	if len(options.CACert) > 0 {
+		sessionOptions.CustomCABundle = bytes.NewReader(options.CACert)
+	}
+
+	sess, err := session.NewSessionWithOptions(sessionOptions)
+	if err != nil {
+		return nil, err
+	}
+	return &AWSProvider{
+		s3Client: s3.New(sess),
+	}, nil
+}
+
 func (a *AWSProvider) UploadTest(ctx context.Context, config oadpv1alpha1.UploadSpeedTestConfig, bucket string, log logr.Logger) (int64, time.Duration, error) {
 
 	log.Info("Starting upload speed test", "fileSize", config.FileSize, "timeout", config.Timeout.Duration.String())
@@ -139,3 +207,177 @@ func (a *AWSProvider) GetBucketMetadata(ctx context.Context, bucket string, log
 
 	return result, nil
 }
+
+// CheckAccess lists the objects under the prefix of the bucket, then writes and deletes a small object there,
+// the operations Velero needs from the credentials of a BackupStorageLocation.
+// The error describes the likely cause of a failure, like a missing permission or a wrong region.
+func (a *AWSProvider) CheckAccess(ctx context.Context, bucket, prefix string) error {
+	location := "s3://" + path.Join(bucket, prefix)
+	listPrefix := prefix
+	if listPrefix != "" {
+		listPrefix = path.Clean(listPrefix) + "/"
+	}
+	_, err := a.s3Client.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		Prefix:  aws.String(listPrefix),
+		MaxKeys: aws.Int64(1),
+	})
+	if err != nil {
+		return describeS3Error("ListObjectsV2", "s3:ListBucket", location, err)
+	}
+
+	key := path.Join(prefix, fmt.Sprintf("oadp-connectivity-check-%d", time.Now().UnixNano()))
+	_, err = a.s3Client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader([]byte("oadp")),
+	})
+	if err != nil {
+		return describeS3Error("PutObject", "s3:PutObject", location, err)
+	}
+
+	_, err = a.s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return describeS3Error("DeleteObject", "s3:DeleteObject", location, fmt.Errorf("%w, remove the object %s by hand", err, key))
+	}
+	return nil
+}
+
+// AccessCheckError is a failure of CheckAccess, with its likely cause
+type AccessCheckError struct {
+	Reason oadpv1alpha1.BackupLocationConnectivityReason
+	err    error
+}
+
+func (e *AccessCheckError) Error() string {
+	return e.err.Error()
+}
+
+func (e *AccessCheckError) Unwrap() error {
+	return e.err
+}
+
+// describeS3Error returns the error of an S3 operation with the likely cause of the known failures
+func describeS3Error(operation, permission, location string, err error) error {
+	if isTLSError(err) {
+		return &AccessCheckError{oadpv1alpha1.BackupLocationConnectivityReasonUntrustedCertificate, fmt.Errorf("%s on %s failed, the certificate of the endpoint is not trusted, "+
+			"set caCert of the backup location to the certificate authority of the endpoint: %w", operation, location, err)}
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case "AccessDenied", "Forbidden":
+			return &AccessCheckError{oadpv1alpha1.BackupLocationConnectivityReasonAccessDenied,
+				fmt.Errorf("%s on %s failed, access denied, the credentials need the %s permission: %w", operation, location, permission, err)}
+		case "BucketRegionError", "AuthorizationHeaderMalformed", "PermanentRedirect", "IllegalLocationConstraintException":
+			return &AccessCheckError{oadpv1alpha1.BackupLocationConnectivityReasonWrongRegion,
+				fmt.Errorf("%s on %s failed, the bucket is in another region, set the region of the backup location config to the region of the bucket: %w", operation, location, err)}
+		case "NoSuchBucket":
+			return &AccessCheckError{oadpv1alpha1.BackupLocationConnectivityReasonBucketNotFound,
+				fmt.Errorf("%s on %s failed, the bucket does not exist: %w", operation, location, err)}
+		case "InvalidAccessKeyId", "SignatureDoesNotMatch":
+			return &AccessCheckError{oadpv1alpha1.BackupLocationConnectivityReasonInvalidCredentials,
+				fmt.Errorf("%s on %s failed, the credentials are invalid: %w", operation, location, err)}
+		}
+	}
+	return &AccessCheckError{oadpv1alpha1.BackupLocationConnectivityReasonError, fmt.Errorf("%s on %s failed: %w", operation, location, err)}
+}
+
+// isTLSError returns true when err, or an error it wraps, is a failed verification of a certificate.
+// awserr errors wrap their cause with OrigErr, which errors.As does not follow.
+func isTLSError(err error) bool {
+	for err != nil {
+		var verificationErr *tls.CertificateVerificationError
+		var unknownAuthorityErr x509.UnknownAuthorityError
+		var hostnameErr x509.HostnameError
+		var invalidErr x509.CertificateInvalidError
+		if errors.As(err, &verificationErr) || errors.As(err, &unknownAuthorityErr) ||
+			errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+			return true
+		}
+		var awsErr awserr.Error
+		if !errors.As(err, &awsErr) {
+			return false
+		}
+		err = awsErr.OrigErr()
+	}
+	return false
+}
+
+// ObjectInfo describes an object of a bucket
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// WalkObjects calls fn with each object of the bucket under the prefix whose key is after startAfter, in key order,
+// one page of the listing at a time, and stops at the first error of fn
+func (a *AWSProvider) WalkObjects(ctx context.Context, bucket, prefix, startAfter string, fn func(ObjectInfo) error) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	if startAfter != "" {
+		input.StartAfter = aws.String(startAfter)
+	}
+	var fnErr error
+	err := a.s3Client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			fnErr = fn(ObjectInfo{
+				Key:          aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+			if fnErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return describeS3Error("ListObjectsV2", "s3:ListBucket", "s3://"+path.Join(bucket, prefix), err)
+	}
+	return fnErr
+}
+
+// GetObject returns the content of an object, which the caller must close
+func (a *AWSProvider) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	output, err := a.s3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, describeS3Error("GetObject", "s3:GetObject", "s3://"+path.Join(bucket, key), err)
+	}
+	return output.Body, nil
+}
+
+// PutObject writes an object from body, in parts for large objects so the content is not held in memory
+func (a *AWSProvider) PutObject(ctx context.Context, bucket, key string, body io.Reader) error {
+	uploader := s3manager.NewUploaderWithClient(a.s3Client)
+	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	if err != nil {
+		return describeS3Error("PutObject", "s3:PutObject", "s3://"+path.Join(bucket, key), err)
+	}
+	return nil
+}
+
+// DeleteObject deletes an object
+func (a *AWSProvider) DeleteObject(ctx context.Context, bucket, key string) error {
+	_, err := a.s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return describeS3Error("DeleteObject", "s3:DeleteObject", "s3://"+path.Join(bucket, key), err)
+	}
+	return nil
+}
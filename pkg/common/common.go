@@ -97,6 +97,10 @@ const (
 	AWSSharedCredentialsFileEnvKey = "AWS_SHARED_CREDENTIALS_FILE"
 	AzureCredentialsFileEnvKey     = "AZURE_CREDENTIALS_FILE"
 	GCPCredentialsEnvKey           = "GOOGLE_APPLICATION_CREDENTIALS"
+	AzureClientIDEnvKey            = "AZURE_CLIENT_ID"
+	AzureTenantIDEnvKey            = "AZURE_TENANT_ID"
+	AzureSubscriptionIDEnvKey      = "AZURE_SUBSCRIPTION_ID"
+	AzureFederatedTokenFileEnvKey  = "AZURE_FEDERATED_TOKEN_FILE"
 	HTTPProxyEnvVar                = "HTTP_PROXY"
 	HTTPSProxyEnvVar               = "HTTPS_PROXY"
 	NoProxyEnvVar                  = "NO_PROXY"
@@ -110,6 +114,10 @@ const (
 	UnsupportedNodeAgentServerArgsAnnotation = "oadp.openshift.io/unsupported-node-agent-server-args"
 )
 
+// CredentialsHashAnnotation is the pod template annotation holding the hash of the credential secrets and CA bundles
+// referenced by the DPA, so the Velero and NodeAgent pods are restarted when they are rotated
+const CredentialsHashAnnotation = "oadp.openshift.io/credentials-hash"
+
 // Volume permissions
 const (
 	// Owner and Group can read; Public do not have any permissions
@@ -218,6 +226,30 @@ func CCOWorkflow() bool {
 	return false
 }
 
+// BoundServiceAccountTokenPath is the service account token projected into the operator, Velero and NodeAgent pods
+// for short-lived cloud credentials, with the openshift audience
+const BoundServiceAccountTokenPath = "/var/run/secrets/openshift/serviceaccount/token"
+
+// AzureWorkloadIdentity returns the client, tenant and subscription IDs of the Azure Workload Identity
+// set as env vars on the operator deployment by the operator subscription during installation via OLM
+func AzureWorkloadIdentity() (clientID, tenantID, subscriptionID string, ok bool) {
+	clientID, tenantID, subscriptionID = os.Getenv("CLIENTID"), os.Getenv("TENANTID"), os.Getenv("SUBSCRIPTIONID")
+	return clientID, tenantID, subscriptionID, clientID != "" && tenantID != "" && subscriptionID != ""
+}
+
+// GCPWorkloadIdentityFederation returns the audience of the workload identity pool provider and the service account email
+// of the GCP Workload Identity Federation set as env vars on the operator deployment by the operator subscription
+// during installation via OLM
+func GCPWorkloadIdentityFederation() (audience, serviceAccountEmail string, ok bool) {
+	projectNumber, poolID, providerID := os.Getenv("PROJECT_NUMBER"), os.Getenv("POOL_ID"), os.Getenv("PROVIDER_ID")
+	serviceAccountEmail = os.Getenv("SERVICE_ACCOUNT_EMAIL")
+	if projectNumber == "" || poolID == "" || providerID == "" || serviceAccountEmail == "" {
+		return "", "", false
+	}
+	audience = fmt.Sprintf("//iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s/providers/%s", projectNumber, poolID, providerID)
+	return audience, serviceAccountEmail, true
+}
+
 // GetImagePullPolicy get imagePullPolicy for a container, based on its image, if an override is not provided.
 // If override is provided, use the override imagePullPolicy.
 // If image contains a sha256 or sha512 digest, use IfNotPresent; otherwise, Always.
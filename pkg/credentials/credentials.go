@@ -30,6 +30,8 @@ type DefaultPluginFields struct {
 
 const (
 	CloudFieldPath = "cloud"
+	// GCPExternalAccountFieldPath is the key of the external account credentials written by CCO for the GCP Workload Identity Federation
+	GCPExternalAccountFieldPath = "service_account.json"
 )
 
 var (
@@ -85,12 +87,15 @@ var (
 	}
 )
 
-// Get secretName and secretKey from "secretName/secretKey"
+// Get secretName and secretKey from "secretName/secretKey", or from the path of a mounted location secret
 func GetSecretNameKeyFromCredentialsFileConfigString(credentialsFile string) (string, string, error) {
 	credentialsFile = strings.TrimSpace(credentialsFile)
 	if credentialsFile == "" {
 		return "", "", nil
 	}
+	if IsLocationSecretFilePath(credentialsFile) {
+		credentialsFile = strings.TrimPrefix(credentialsFile, LocationSecretMountPath+"/")
+	}
 	nameKeyArray := strings.Split(credentialsFile, "/")
 	if len(nameKeyArray) != 2 {
 		return "", "", errors.New("credentials file is not supported")
@@ -200,6 +205,34 @@ func GetPluginImage(defaultPlugin oadpv1alpha1.DefaultPlugin, dpa *oadpv1alpha1.
 	return ""
 }
 
+// CloudProviderEnvVars returns the env vars pointing a cloud provider plugin to its default credentials. With Azure Workload
+// Identity, the plugin also authenticates with the projected service account token, and with GCP Workload Identity
+// Federation, the credentials file is the external account credentials written by CCO.
+func CloudProviderEnvVars(plugin oadpv1alpha1.DefaultPlugin) []corev1.EnvVar {
+	cloudProviderMap := PluginSpecificFields[plugin]
+	credentialsFile := corev1.EnvVar{
+		Name:  cloudProviderMap.EnvCredentialsFile,
+		Value: cloudProviderMap.MountPath + "/" + CloudFieldPath,
+	}
+	switch plugin {
+	case oadpv1alpha1.DefaultPluginMicrosoftAzure:
+		if clientID, tenantID, subscriptionID, ok := common.AzureWorkloadIdentity(); ok {
+			return []corev1.EnvVar{
+				credentialsFile,
+				{Name: common.AzureClientIDEnvKey, Value: clientID},
+				{Name: common.AzureTenantIDEnvKey, Value: tenantID},
+				{Name: common.AzureSubscriptionIDEnvKey, Value: subscriptionID},
+				{Name: common.AzureFederatedTokenFileEnvKey, Value: common.BoundServiceAccountTokenPath},
+			}
+		}
+	case oadpv1alpha1.DefaultPluginGCP:
+		if _, _, ok := common.GCPWorkloadIdentityFederation(); ok {
+			credentialsFile.Value = cloudProviderMap.MountPath + "/" + GCPExternalAccountFieldPath
+		}
+	}
+	return []corev1.EnvVar{credentialsFile}
+}
+
 func AppendCloudProviderVolumes(dpa *oadpv1alpha1.DataProtectionApplication, ds *appsv1.DaemonSet, providerNeedsDefaultCreds map[string]bool) {
 	var nodeAgentContainer *corev1.Container
 	for i, container := range ds.Spec.Template.Spec.Containers {
@@ -245,13 +278,7 @@ func AppendCloudProviderVolumes(dpa *oadpv1alpha1.DataProtectionApplication, ds
 						MountPath: cloudProviderMap.MountPath,
 					},
 				)
-				nodeAgentContainer.Env = append(
-					nodeAgentContainer.Env,
-					corev1.EnvVar{
-						Name:  cloudProviderMap.EnvCredentialsFile,
-						Value: cloudProviderMap.MountPath + "/" + CloudFieldPath,
-					},
-				)
+				nodeAgentContainer.Env = append(nodeAgentContainer.Env, CloudProviderEnvVars(plugin)...)
 			}
 
 		}
@@ -276,6 +303,8 @@ func AppendCloudProviderVolumes(dpa *oadpv1alpha1.DataProtectionApplication, ds
 		}
 
 	}
+	AppendSecretProviderClassVolumes(dpa, &ds.Spec.Template.Spec, nodeAgentContainer)
+	AppendLocationSecretVolumes(dpa, &ds.Spec.Template.Spec, nodeAgentContainer)
 }
 
 // TODO: remove duplicate func in registry.go - refactoring away registry.go later
@@ -482,3 +482,86 @@ func TestCredentials_getPluginImage(t *testing.T) {
 		})
 	}
 }
+
+func TestCredentials_CloudProviderEnvVars(t *testing.T) {
+	tests := []struct {
+		name       string
+		plugin     oadpv1alpha1.DefaultPlugin
+		setEnvVars map[string]string
+		want       map[string]string
+	}{
+		{
+			name:   "azure plugin with a client secret",
+			plugin: oadpv1alpha1.DefaultPluginMicrosoftAzure,
+			want:   map[string]string{common.AzureCredentialsFileEnvKey: "/credentials-azure/cloud"},
+		},
+		{
+			name:   "azure plugin with Azure Workload Identity",
+			plugin: oadpv1alpha1.DefaultPluginMicrosoftAzure,
+			setEnvVars: map[string]string{
+				"CLIENTID":       "client",
+				"TENANTID":       "tenant",
+				"SUBSCRIPTIONID": "subscription",
+			},
+			want: map[string]string{
+				common.AzureCredentialsFileEnvKey:    "/credentials-azure/cloud",
+				common.AzureClientIDEnvKey:           "client",
+				common.AzureTenantIDEnvKey:           "tenant",
+				common.AzureSubscriptionIDEnvKey:     "subscription",
+				common.AzureFederatedTokenFileEnvKey: common.BoundServiceAccountTokenPath,
+			},
+		},
+		{
+			name:       "azure plugin with an incomplete Azure Workload Identity",
+			plugin:     oadpv1alpha1.DefaultPluginMicrosoftAzure,
+			setEnvVars: map[string]string{"CLIENTID": "client"},
+			want:       map[string]string{common.AzureCredentialsFileEnvKey: "/credentials-azure/cloud"},
+		},
+		{
+			name:   "gcp plugin with a service account key",
+			plugin: oadpv1alpha1.DefaultPluginGCP,
+			want:   map[string]string{common.GCPCredentialsEnvKey: "/credentials-gcp/cloud"},
+		},
+		{
+			name:   "gcp plugin with GCP Workload Identity Federation",
+			plugin: oadpv1alpha1.DefaultPluginGCP,
+			setEnvVars: map[string]string{
+				"PROJECT_NUMBER":        "123",
+				"POOL_ID":               "pool",
+				"PROVIDER_ID":           "provider",
+				"SERVICE_ACCOUNT_EMAIL": "velero@project.iam.gserviceaccount.com",
+			},
+			want: map[string]string{common.GCPCredentialsEnvKey: "/credentials-gcp/service_account.json"},
+		},
+		{
+			name:   "aws plugin",
+			plugin: oadpv1alpha1.DefaultPluginAWS,
+			setEnvVars: map[string]string{
+				"CLIENTID":       "client",
+				"TENANTID":       "tenant",
+				"SUBSCRIPTIONID": "subscription",
+			},
+			want: map[string]string{common.AWSSharedCredentialsFileEnvKey: "/credentials/cloud"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.setEnvVars {
+				t.Setenv(key, value)
+			}
+			envVars := CloudProviderEnvVars(tt.plugin)
+			got := map[string]string{}
+			for _, envVar := range envVars {
+				got[envVar.Name] = envVar.Value
+			}
+			if len(got) != len(envVars) || len(got) != len(tt.want) {
+				t.Fatalf("CloudProviderEnvVars() = %v, want %v", envVars, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("CloudProviderEnvVars() %s = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,17 @@
 package credentials
 
-import "encoding/json"
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
 
 type gcpCredAccountKeys string
 
@@ -15,8 +26,11 @@ func getGCPSecretAccountTypeKey(secretByte []byte) (gcpCredAccountKeys, error) {
 	if err := json.Unmarshal(secretByte, &f); err != nil {
 		return "", err
 	}
-	// following will panic if cannot cast to credAccountKeys
-	return gcpCredAccountKeys(f["type"].(string)), nil
+	accountType, ok := f["type"].(string)
+	if !ok {
+		return "", errors.New("credentials file has no type")
+	}
+	return gcpCredAccountKeys(accountType), nil
 }
 
 func gcpSecretAccountTypeIsShortLived(secretName, secretKey, namespace string) (bool, error) {
@@ -30,3 +44,91 @@ func gcpSecretAccountTypeIsShortLived(secretName, secretKey, namespace string) (
 	}
 	return credAccountTypeKey == externalAccountKey, nil
 }
+
+// ErrGCPServiceAccountKeyNotFound is returned for a key deleted from its service account
+var ErrGCPServiceAccountKeyNotFound = errors.New("key was deleted from the service account")
+
+// GCPServiceAccountKey is the key of a GCP service account key file
+type GCPServiceAccountKey struct {
+	ClientEmail       string `json:"client_email"`
+	PrivateKey        string `json:"private_key"`
+	PrivateKeyID      string `json:"private_key_id"`
+	ClientX509CertURL string `json:"client_x509_cert_url"`
+}
+
+// GetGCPServiceAccountKey returns the key of a GCP service account key file, and false for the short-lived credentials
+// of an external account, which have no key
+func GetGCPServiceAccountKey(content []byte) (GCPServiceAccountKey, bool, error) {
+	key := GCPServiceAccountKey{}
+	accountType, err := getGCPSecretAccountTypeKey(content)
+	if err != nil {
+		return key, false, err
+	}
+	switch accountType {
+	case externalAccountKey:
+		return key, false, nil
+	case serviceAccountKey:
+	default:
+		return key, false, fmt.Errorf("credentials of type %s are not supported, use a service_account key or external_account credentials", accountType)
+	}
+	if err := json.Unmarshal(content, &key); err != nil {
+		return key, false, err
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" || key.PrivateKeyID == "" {
+		return key, false, errors.New("service account key file needs client_email, private_key and private_key_id")
+	}
+	return key, true, nil
+}
+
+// gcpX509CertURLPrefix is the prefix of the URLs Google publishes the certificates of service account keys at,
+// replaced in tests. The client_x509_cert_url of a key file is only fetched under it, as the file is user input.
+var gcpX509CertURLPrefix = "https://www.googleapis.com/robot/v1/metadata/x509/"
+
+// GCPServiceAccountKeyExpiration returns when a GCP service account key expires, from the certificate Google publishes
+// for the key at the client_x509_cert_url of the key file. The zero time is returned for a key that does not expire.
+func GCPServiceAccountKeyExpiration(ctx context.Context, httpClient *http.Client, key GCPServiceAccountKey) (time.Time, error) {
+	if key.ClientX509CertURL == "" {
+		return time.Time{}, errors.New("service account key file has no client_x509_cert_url")
+	}
+	if !strings.HasPrefix(key.ClientX509CertURL, gcpX509CertURLPrefix) {
+		return time.Time{}, fmt.Errorf("client_x509_cert_url %s of the service account key file is not under %s", key.ClientX509CertURL, gcpX509CertURLPrefix)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, key.ClientX509CertURL, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("unable to get the certificates of service account %s: %s", key.ClientEmail, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return time.Time{}, err
+	}
+	// the certificates of the keys of the service account, by key id
+	certificates := map[string]string{}
+	if err := json.Unmarshal(body, &certificates); err != nil {
+		return time.Time{}, err
+	}
+	certificate, ok := certificates[key.PrivateKeyID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("key %s of service account %s: %w", key.PrivateKeyID, key.ClientEmail, ErrGCPServiceAccountKeyNotFound)
+	}
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		return time.Time{}, fmt.Errorf("certificate of key %s is not PEM encoded", key.PrivateKeyID)
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// keys without expiration have certificates valid until the end of year 9999
+	if parsed.NotAfter.Year() >= 9999 {
+		return time.Time{}, nil
+	}
+	return parsed.NotAfter, nil
+}
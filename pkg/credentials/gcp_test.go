@@ -0,0 +1,142 @@
+package credentials
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCredentials_GetGCPServiceAccountKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantKey bool
+		wantErr bool
+	}{
+		{
+			name:    "service account key",
+			content: `{"type": "service_account", "client_email": "velero@project.iam.gserviceaccount.com", "private_key": "key", "private_key_id": "1234"}`,
+			wantKey: true,
+		},
+		{
+			name:    "external account",
+			content: `{"type": "external_account", "audience": "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/pool/providers/provider"}`,
+		},
+		{
+			name:    "service account key without private key",
+			content: `{"type": "service_account", "client_email": "velero@project.iam.gserviceaccount.com", "private_key_id": "1234"}`,
+			wantErr: true,
+		},
+		{
+			name:    "authorized user",
+			content: `{"type": "authorized_user"}`,
+			wantErr: true,
+		},
+		{
+			name:    "no type",
+			content: `{"client_email": "velero@project.iam.gserviceaccount.com"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			content: `[default]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, isKey, err := GetGCPServiceAccountKey([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetGCPServiceAccountKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if isKey != tt.wantKey {
+				t.Errorf("GetGCPServiceAccountKey() = %v, want a key %v", isKey, tt.wantKey)
+			}
+			if isKey && key.PrivateKeyID != "1234" {
+				t.Errorf("GetGCPServiceAccountKey() key id = %s, want 1234", key.PrivateKeyID)
+			}
+		})
+	}
+}
+
+func newTestCertificate(t *testing.T, notAfter time.Time) string {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now().Add(-time.Hour), NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestCredentials_GCPServiceAccountKeyExpiration(t *testing.T) {
+	expiration := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+	certificates := map[string]string{
+		"expiring": newTestCertificate(t, expiration),
+		"forever":  newTestCertificate(t, time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(certificates)
+	}))
+	defer server.Close()
+	defaultPrefix := gcpX509CertURLPrefix
+	gcpX509CertURLPrefix = server.URL + "/robot/v1/metadata/x509/"
+	defer func() { gcpX509CertURLPrefix = defaultPrefix }()
+
+	certURL := gcpX509CertURLPrefix + "velero%40project.iam.gserviceaccount.com"
+
+	tests := []struct {
+		name     string
+		keyID    string
+		certURL  string
+		want     time.Time
+		wantErr  error
+		wantText string
+	}{
+		{name: "key with expiration", keyID: "expiring", want: expiration},
+		{name: "key without expiration", keyID: "forever"},
+		{name: "deleted key", keyID: "deleted", wantErr: ErrGCPServiceAccountKeyNotFound},
+		{name: "certificate URL outside the Google certificates", keyID: "expiring", certURL: server.URL + "/latest/meta-data", wantText: "is not under"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.certURL == "" {
+				tt.certURL = certURL
+			}
+			key := GCPServiceAccountKey{ClientEmail: "velero@project.iam.gserviceaccount.com", PrivateKeyID: tt.keyID, ClientX509CertURL: tt.certURL}
+			got, err := GCPServiceAccountKeyExpiration(context.Background(), server.Client(), key)
+			if tt.wantText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantText) {
+					t.Errorf("GCPServiceAccountKeyExpiration() error = %v, want %q", err, tt.wantText)
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GCPServiceAccountKeyExpiration() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GCPServiceAccountKeyExpiration() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("GCPServiceAccountKeyExpiration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package credentials
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const (
+	// LocationSecretMountPath is where the secret of each credential of the locations is mounted with
+	// mountLocationCredentials, in a directory named after the secret
+	LocationSecretMountPath = "/credentials-secrets"
+	// locationSecretVolumePrefix prefixes the volume names of the location secrets, apart from the volumes of the default secrets
+	locationSecretVolumePrefix = "creds-"
+)
+
+// LocationSecretFilePath returns the path of the key of a location secret in the Velero and NodeAgent pods, set as
+// credentialsFile in the config of the BSL or VSL
+func LocationSecretFilePath(credential *corev1.SecretKeySelector) string {
+	return path.Join(LocationSecretMountPath, credential.Name, credential.Key)
+}
+
+// IsLocationSecretFilePath returns true for the credentialsFile of a location using a mounted location secret
+func IsLocationSecretFilePath(credentialsFile string) bool {
+	return strings.HasPrefix(credentialsFile, LocationSecretMountPath+"/")
+}
+
+// LocationSecrets returns the sorted names of the secrets of the credentials of the backup and snapshot locations,
+// mounted with mountLocationCredentials
+func LocationSecrets(dpa *oadpv1alpha1.DataProtectionApplication) []string {
+	if !dpa.MountLocationCredentials() {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, location := range dpa.Spec.BackupLocations {
+		if location.Velero != nil && location.Velero.Credential != nil && location.Velero.Credential.Name != "" {
+			names[location.Velero.Credential.Name] = true
+		}
+		if location.CloudStorage != nil && location.CloudStorage.Credential != nil && location.CloudStorage.Credential.Name != "" {
+			names[location.CloudStorage.Credential.Name] = true
+		}
+	}
+	for _, location := range dpa.Spec.SnapshotLocations {
+		if location.Velero != nil && location.Velero.Credential != nil && location.Velero.Credential.Name != "" {
+			names[location.Velero.Credential.Name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// hashedVolumeName returns a volume name for an object. Volume names are DNS labels and the names of secrets and
+// SecretProviderClasses DNS subdomains, so the name is derived from a hash of the object name, which can not collide
+// like a shortened name.
+func hashedVolumeName(prefix, name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return prefix + hex.EncodeToString(sum[:8])
+}
+
+// locationSecretVolumeName returns the volume name of a location secret
+func locationSecretVolumeName(secretName string) string {
+	return hashedVolumeName(locationSecretVolumePrefix, secretName)
+}
+
+// AppendLocationSecretVolumes mounts the secret of each credential of the locations into a container
+func AppendLocationSecretVolumes(dpa *oadpv1alpha1.DataProtectionApplication, podSpec *corev1.PodSpec, container *corev1.Container) {
+	for _, name := range LocationSecrets(dpa) {
+		volumeName := locationSecretVolumeName(name)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: name,
+				},
+			},
+		})
+		if container != nil {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: path.Join(LocationSecretMountPath, name),
+				ReadOnly:  true,
+			})
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package credentials
+
+import (
+	"strings"
+	"testing"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+func TestCredentials_AppendLocationSecretVolumes(t *testing.T) {
+	credential := func(name, key string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key}
+	}
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{Velero: &oadpv1alpha1.VeleroConfig{}},
+			BackupLocations: []oadpv1alpha1.BackupLocation{
+				{Velero: &velerov1.BackupStorageLocationSpec{Provider: "aws", Credential: credential("team-b.aws", "cloud")}},
+				{Velero: &velerov1.BackupStorageLocationSpec{Provider: "aws", Credential: credential("team-a", "cloud")}},
+				{Velero: &velerov1.BackupStorageLocationSpec{Provider: "aws"}},
+			},
+			SnapshotLocations: []oadpv1alpha1.SnapshotLocation{
+				{Velero: &velerov1.VolumeSnapshotLocationSpec{Provider: "aws", Credential: credential("team-a", "snapshots")}},
+			},
+		},
+	}
+
+	podSpec := &corev1.PodSpec{}
+	container := &corev1.Container{}
+	AppendLocationSecretVolumes(dpa, podSpec, container)
+	if len(podSpec.Volumes) != 0 {
+		t.Fatalf("volumes = %v, want no volumes without mountLocationCredentials", podSpec.Volumes)
+	}
+
+	dpa.Spec.Configuration.Velero.MountLocationCredentials = ptr.To(true)
+	AppendLocationSecretVolumes(dpa, podSpec, container)
+	if len(podSpec.Volumes) != 2 || podSpec.Volumes[0].Name != locationSecretVolumeName("team-a") || podSpec.Volumes[1].Name != locationSecretVolumeName("team-b.aws") ||
+		podSpec.Volumes[1].Secret == nil || podSpec.Volumes[1].Secret.SecretName != "team-b.aws" {
+		t.Fatalf("volumes = %+v, want a volume of each secret", podSpec.Volumes)
+	}
+	if len(container.VolumeMounts) != 2 || container.VolumeMounts[1].MountPath != "/credentials-secrets/team-b.aws" || !container.VolumeMounts[1].ReadOnly {
+		t.Errorf("volume mounts = %+v, want team-b.aws mounted read only at /credentials-secrets/team-b.aws", container.VolumeMounts)
+	}
+
+	// names shortened or with dots replaced would collide
+	long := strings.Repeat("a", 70)
+	for _, names := range [][2]string{{"team-b.aws", "team-b-aws"}, {long + "1", long + "2"}} {
+		first, second := locationSecretVolumeName(names[0]), locationSecretVolumeName(names[1])
+		if first == second || len(first) > 63 || validation.IsDNS1123Label(first) != nil {
+			t.Errorf("locationSecretVolumeName(%s) = %s, locationSecretVolumeName(%s) = %s, want distinct DNS labels", names[0], first, names[1], second)
+		}
+	}
+
+	credentialsFile := LocationSecretFilePath(credential("team-b.aws", "cloud"))
+	name, key, err := GetSecretNameKeyFromCredentialsFileConfigString(credentialsFile)
+	if !IsLocationSecretFilePath(credentialsFile) || err != nil || name != "team-b.aws" || key != "cloud" {
+		t.Errorf("GetSecretNameKeyFromCredentialsFileConfigString(%s) = %s, %s, %v", credentialsFile, name, key, err)
+	}
+}
@@ -0,0 +1,102 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+const (
+	// SecretsStoreCSIDriver is the driver of the Secrets Store CSI volumes
+	SecretsStoreCSIDriver = "secrets-store.csi.k8s.io"
+	// SecretProviderClassMountPath is where the volume of each SecretProviderClass is mounted, in a directory named
+	// after the SecretProviderClass
+	SecretProviderClassMountPath = "/credentials-spc"
+	// secretProviderClassVolumePrefix prefixes the volume names of the SecretProviderClasses
+	secretProviderClassVolumePrefix = "spc-"
+)
+
+// secretProviderClassRoot is where the operator reads the credentials files of the SecretProviderClasses, the same
+// path as the Velero and NodeAgent pods when mounted in the operator pod with the config of its Subscription
+var secretProviderClassRoot = SecretProviderClassMountPath
+
+// SecretProviderClassFilePath returns the path of the credentials file of a SecretProviderClass in the Velero and NodeAgent pods,
+// set as credentialsFile in the config of the BSL or VSL
+func SecretProviderClassFilePath(spc *oadpv1alpha1.SecretProviderClassCredential) string {
+	return path.Join(SecretProviderClassMountPath, spc.Name, spc.File)
+}
+
+// IsSecretProviderClassFilePath returns true for the credentialsFile of a location using a SecretProviderClass
+func IsSecretProviderClassFilePath(credentialsFile string) bool {
+	return strings.HasPrefix(credentialsFile, SecretProviderClassMountPath+"/")
+}
+
+// GetSecretProviderClassFileSecret returns the credentials file of a SecretProviderClass as the data of a Secret named after the
+// SecretProviderClass, keyed by the name of the file, so the parsers of the provider secrets read its content
+func GetSecretProviderClassFileSecret(credentialsFile string) (corev1.Secret, string, error) {
+	relative := strings.TrimPrefix(credentialsFile, SecretProviderClassMountPath+"/")
+	name, file, found := strings.Cut(relative, "/")
+	if !IsSecretProviderClassFilePath(credentialsFile) || !found || name == "" || file == "" || strings.Contains(file, "/") {
+		return corev1.Secret{}, "", fmt.Errorf("credentials file %s is not a file of a SecretProviderClass", credentialsFile)
+	}
+	content, err := os.ReadFile(path.Join(secretProviderClassRoot, name, file))
+	if err != nil {
+		return corev1.Secret{}, "", fmt.Errorf("unable to read credentials file %s of SecretProviderClass %s, mount the SecretProviderClass "+
+			"in the operator pod at %s with the config of its Subscription: %w", file, name, path.Join(SecretProviderClassMountPath, name), err)
+	}
+	secret := corev1.Secret{Data: map[string][]byte{file: []byte(strings.ReplaceAll(string(content), "\r\n", "\n"))}}
+	secret.Name = name
+	return secret, file, nil
+}
+
+// SecretProviderClasses returns the sorted names of the SecretProviderClasses of the backup and snapshot locations
+func SecretProviderClasses(dpa *oadpv1alpha1.DataProtectionApplication) []string {
+	names := map[string]bool{}
+	for _, location := range dpa.Spec.BackupLocations {
+		if location.SecretProviderClass != nil {
+			names[location.SecretProviderClass.Name] = true
+		}
+	}
+	for _, location := range dpa.Spec.SnapshotLocations {
+		if location.SecretProviderClass != nil {
+			names[location.SecretProviderClass.Name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// AppendSecretProviderClassVolumes mounts the Secrets Store CSI volume of each SecretProviderClass of the locations into a container
+func AppendSecretProviderClassVolumes(dpa *oadpv1alpha1.DataProtectionApplication, podSpec *corev1.PodSpec, container *corev1.Container) {
+	for _, name := range SecretProviderClasses(dpa) {
+		volumeName := hashedVolumeName(secretProviderClassVolumePrefix, name)
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				CSI: &corev1.CSIVolumeSource{
+					Driver:           SecretsStoreCSIDriver,
+					ReadOnly:         ptr.To(true),
+					VolumeAttributes: map[string]string{"secretProviderClass": name},
+				},
+			},
+		})
+		if container != nil {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: path.Join(SecretProviderClassMountPath, name),
+				ReadOnly:  true,
+			})
+		}
+	}
+}
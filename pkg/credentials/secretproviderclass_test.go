@@ -0,0 +1,93 @@
+package credentials
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	oadpv1alpha1 "github.com/openshift/oadp-operator/api/v1alpha1"
+	"github.com/openshift/oadp-operator/pkg/common"
+)
+
+func TestCredentials_AppendSecretProviderClassVolumes(t *testing.T) {
+	dpa := &oadpv1alpha1.DataProtectionApplication{
+		Spec: oadpv1alpha1.DataProtectionApplicationSpec{
+			Configuration: &oadpv1alpha1.ApplicationConfig{Velero: &oadpv1alpha1.VeleroConfig{}},
+			BackupLocations: []oadpv1alpha1.BackupLocation{
+				{
+					Velero:              &velerov1.BackupStorageLocationSpec{Provider: "aws"},
+					SecretProviderClass: &oadpv1alpha1.SecretProviderClassCredential{Name: "vault.aws", File: "cloud"},
+				},
+				{
+					Velero: &velerov1.BackupStorageLocationSpec{Provider: "aws"},
+				},
+			},
+			SnapshotLocations: []oadpv1alpha1.SnapshotLocation{
+				{
+					Velero:              &velerov1.VolumeSnapshotLocationSpec{Provider: "aws"},
+					SecretProviderClass: &oadpv1alpha1.SecretProviderClassCredential{Name: "vault.aws", File: "snapshots"},
+				},
+			},
+		},
+	}
+	ds := &appsv1.DaemonSet{}
+	ds.Spec.Template.Spec.Containers = []corev1.Container{{Name: common.NodeAgent}}
+
+	AppendCloudProviderVolumes(dpa, ds, map[string]bool{})
+
+	volumes := ds.Spec.Template.Spec.Volumes
+	if len(volumes) != 1 {
+		t.Fatalf("volumes = %v, want the volume of the SecretProviderClass", volumes)
+	}
+	if volumes[0].Name != hashedVolumeName(secretProviderClassVolumePrefix, "vault.aws") || volumes[0].CSI == nil || volumes[0].CSI.Driver != SecretsStoreCSIDriver ||
+		volumes[0].CSI.VolumeAttributes["secretProviderClass"] != "vault.aws" {
+		t.Errorf("volume = %+v, want the Secrets Store CSI volume of vault.aws", volumes[0])
+	}
+	mounts := ds.Spec.Template.Spec.Containers[0].VolumeMounts
+	if len(mounts) != 1 || mounts[0].Name != volumes[0].Name || mounts[0].MountPath != "/credentials-spc/vault.aws" || !mounts[0].ReadOnly {
+		t.Errorf("volume mounts = %+v, want vault.aws mounted read only at /credentials-spc/vault.aws", mounts)
+	}
+
+	// names shortened or with dots replaced would collide
+	dpa.Spec.BackupLocations[0].SecretProviderClass.Name = "vault-aws"
+	podSpec := &corev1.PodSpec{}
+	AppendSecretProviderClassVolumes(dpa, podSpec, nil)
+	if len(podSpec.Volumes) != 2 || podSpec.Volumes[0].Name == podSpec.Volumes[1].Name {
+		t.Errorf("volumes = %+v, want distinct volumes of vault-aws and vault.aws", podSpec.Volumes)
+	}
+}
+
+func TestCredentials_GetSecretProviderClassFileSecret(t *testing.T) {
+	secretProviderClassRoot = t.TempDir()
+	defer func() { secretProviderClassRoot = SecretProviderClassMountPath }()
+	if err := os.MkdirAll(path.Join(secretProviderClassRoot, "vault-aws"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "[default]\r\naws_access_key_id=access\r\naws_secret_access_key=secret\r\n"
+	if err := os.WriteFile(path.Join(secretProviderClassRoot, "vault-aws", "cloud"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	credentialsFile := SecretProviderClassFilePath(&oadpv1alpha1.SecretProviderClassCredential{Name: "vault-aws", File: "cloud"})
+	if credentialsFile != "/credentials-spc/vault-aws/cloud" || !IsSecretProviderClassFilePath(credentialsFile) {
+		t.Fatalf("SecretProviderClassFilePath() = %s", credentialsFile)
+	}
+	secret, key, err := GetSecretProviderClassFileSecret(credentialsFile)
+	if err != nil {
+		t.Fatalf("GetSecretProviderClassFileSecret() error = %v", err)
+	}
+	if secret.Name != "vault-aws" || key != "cloud" || string(secret.Data[key]) != "[default]\naws_access_key_id=access\naws_secret_access_key=secret\n" {
+		t.Errorf("GetSecretProviderClassFileSecret() = %s %s %q", secret.Name, key, secret.Data[key])
+	}
+
+	if _, _, err := GetSecretProviderClassFileSecret("/credentials-spc/vault-gcp/cloud"); err == nil {
+		t.Errorf("GetSecretProviderClassFileSecret() of a SecretProviderClass not mounted in the operator pod succeeded")
+	}
+	if _, _, err := GetSecretProviderClassFileSecret("cloud-credentials/cloud"); err == nil {
+		t.Errorf("GetSecretProviderClassFileSecret() of a secret succeeded")
+	}
+}
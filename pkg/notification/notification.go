@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+// cloudEventTypePrefix is the prefix of the CloudEvents type attribute, followed by the lower case event
+const cloudEventTypePrefix = "com.redhat.oadp."
+
+// defaultSlackTemplate is the message text of Slack sinks without a template
+const defaultSlackTemplate = `{{if eq .Event "ScheduleMissed"}}:warning: Schedule {{.Namespace}}/{{.Schedule}} missed its run at {{.Time.Format "2006-01-02T15:04:05Z07:00"}}` +
+	`{{else}}:x: {{.Kind}} {{.Namespace}}/{{.Name}} is {{.Phase}}{{if .Schedule}} (schedule {{.Schedule}}){{end}}{{if .Reason}}: {{.Reason}}{{end}}{{end}}`
+
+// Event is a notified event, and the data of the notification templates
+type Event struct {
+	Event                     v1alpha1.NotificationEvent `json:"event"`
+	Kind                      string                     `json:"kind"`
+	Namespace                 string                     `json:"namespace"`
+	Name                      string                     `json:"name"`
+	Phase                     string                     `json:"phase,omitempty"`
+	Schedule                  string                     `json:"schedule,omitempty"`
+	Reason                    string                     `json:"reason,omitempty"`
+	Time                      time.Time                  `json:"time"`
+	DataProtectionApplication string                     `json:"dataProtectionApplication"`
+	// ID uniquely identifies the event, so receivers can deduplicate retried deliveries
+	ID string `json:"id"`
+}
+
+// String returns the event and object, like "BackupFailed backup/daily-20250101000000"
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s/%s", e.Event, strings.ToLower(e.Kind), e.Name)
+}
+
+// permanentError is an error that retrying the delivery will not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// IsPermanent returns true if the delivery failed for a reason retrying will not fix, like an invalid template
+// or a client error response
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Send delivers the event to the sink at url once. Server errors and connection failures are returned for the caller
+// to retry, the other failures are permanent.
+func Send(ctx context.Context, httpClient *http.Client, sink v1alpha1.NotificationSink, url string, event Event) error {
+	body, headers, err := newPayload(sink, event)
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("notification sink %s responded %s", sink.Name, response.Status)
+	if response.StatusCode >= 400 && response.StatusCode < 500 && response.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err: err}
+	}
+	return err
+}
+
+// newPayload returns the body and headers posting the event in the payload format of the sink
+func newPayload(sink v1alpha1.NotificationSink, event Event) ([]byte, map[string]string, error) {
+	var body []byte
+	headers := map[string]string{"Content-Type": "application/json"}
+	switch sink.Type {
+	case v1alpha1.NotificationSinkWebhook:
+		data, err := render(sink.Template, event)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = data
+	case v1alpha1.NotificationSinkCloudEvents:
+		data, err := render(sink.Template, event)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = data
+		// binary content mode, https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/bindings/http-protocol-binding.md#31-binary-content-mode
+		headers["ce-specversion"] = "1.0"
+		headers["ce-id"] = event.ID
+		headers["ce-type"] = cloudEventTypePrefix + strings.ToLower(string(event.Event))
+		headers["ce-source"] = fmt.Sprintf("/apis/velero.io/v1/namespaces/%s/%ss/%s", event.Namespace, strings.ToLower(event.Kind), event.Name)
+		headers["ce-subject"] = event.Name
+		headers["ce-time"] = event.Time.UTC().Format(time.RFC3339)
+	case v1alpha1.NotificationSinkSlack:
+		text := sink.Template
+		if text == "" {
+			text = defaultSlackTemplate
+		}
+		message, err := render(text, event)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err = json.Marshal(map[string]string{"text": string(message)})
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("notification sink %s has unknown type %s", sink.Name, sink.Type)
+	}
+	for key, value := range sink.Headers {
+		headers[key] = value
+	}
+	return body, headers, nil
+}
+
+// render executes the template with the event, or returns the event as JSON without a template
+func render(text string, event Event) ([]byte, error) {
+	if text == "" {
+		return json.Marshal(event)
+	}
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("unable to execute notification template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseTemplate parses a notification sink template
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse notification template: %w", err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,151 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift/oadp-operator/api/v1alpha1"
+)
+
+var testEvent = Event{
+	Event:                     v1alpha1.NotificationEventBackupPartiallyFailed,
+	Kind:                      "Backup",
+	Namespace:                 "openshift-adp",
+	Name:                      "daily-20250101000000",
+	Phase:                     "PartiallyFailed",
+	Schedule:                  "daily",
+	Time:                      time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC),
+	DataProtectionApplication: "dpa",
+	ID:                        "uid/BackupPartiallyFailed",
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name          string
+		sink          v1alpha1.NotificationSink
+		statusCodes   []int
+		wantErr       bool
+		wantPermanent bool
+		wantRequests  int
+		wantBody      string
+		wantHeaders   map[string]string
+	}{
+		{
+			name:         "slack sink with the default template",
+			sink:         v1alpha1.NotificationSink{Name: "slack", Type: v1alpha1.NotificationSinkSlack},
+			statusCodes:  []int{http.StatusOK},
+			wantRequests: 1,
+			wantBody:     `{"text":":x: Backup openshift-adp/daily-20250101000000 is PartiallyFailed (schedule daily)"}`,
+		},
+		{
+			name: "webhook sink with a template and headers",
+			sink: v1alpha1.NotificationSink{
+				Name:     "webhook",
+				Type:     v1alpha1.NotificationSinkWebhook,
+				Template: `{"summary": "{{.Event}} {{.Name}}"}`,
+				Headers:  map[string]string{"Authorization": "Bearer token"},
+			},
+			statusCodes:  []int{http.StatusAccepted},
+			wantRequests: 1,
+			wantBody:     `{"summary": "BackupPartiallyFailed daily-20250101000000"}`,
+			wantHeaders:  map[string]string{"Authorization": "Bearer token"},
+		},
+		{
+			name:         "cloudevents sink in binary content mode",
+			sink:         v1alpha1.NotificationSink{Name: "broker", Type: v1alpha1.NotificationSinkCloudEvents},
+			statusCodes:  []int{http.StatusOK},
+			wantRequests: 1,
+			wantHeaders: map[string]string{
+				"ce-specversion": "1.0",
+				"ce-id":          "uid/BackupPartiallyFailed",
+				"ce-type":        "com.redhat.oadp.backuppartiallyfailed",
+				"ce-source":      "/apis/velero.io/v1/namespaces/openshift-adp/backups/daily-20250101000000",
+				"ce-time":        "2025-01-01T00:05:00Z",
+			},
+		},
+		{
+			name:         "server errors are transient",
+			sink:         v1alpha1.NotificationSink{Name: "webhook", Type: v1alpha1.NotificationSinkWebhook},
+			statusCodes:  []int{http.StatusServiceUnavailable},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "rate limits are transient",
+			sink:         v1alpha1.NotificationSink{Name: "webhook", Type: v1alpha1.NotificationSinkWebhook},
+			statusCodes:  []int{http.StatusTooManyRequests},
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:          "client errors are not retried",
+			sink:          v1alpha1.NotificationSink{Name: "webhook", Type: v1alpha1.NotificationSinkWebhook},
+			statusCodes:   []int{http.StatusBadRequest},
+			wantErr:       true,
+			wantPermanent: true,
+			wantRequests:  1,
+		},
+		{
+			name:          "invalid templates are not retried",
+			sink:          v1alpha1.NotificationSink{Name: "slack", Type: v1alpha1.NotificationSinkSlack, Template: "{{.Missing}}"},
+			wantErr:       true,
+			wantPermanent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			var body []byte
+			var headers http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				headers = r.Header
+				w.WriteHeader(tt.statusCodes[min(requests, len(tt.statusCodes)-1)])
+				requests++
+			}))
+			defer server.Close()
+
+			err := Send(context.Background(), server.Client(), tt.sink, server.URL, testEvent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsPermanent(err) != tt.wantPermanent {
+				t.Errorf("IsPermanent() = %v, want %v", IsPermanent(err), tt.wantPermanent)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Send() sent %d requests, want %d", requests, tt.wantRequests)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("Send() body = %s, want %s", body, tt.wantBody)
+			}
+			for key, value := range tt.wantHeaders {
+				if headers.Get(key) != value {
+					t.Errorf("Send() header %s = %s, want %s", key, headers.Get(key), value)
+				}
+			}
+		})
+	}
+}
+
+func TestSend_DefaultWebhookPayload(t *testing.T) {
+	var got Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("unable to decode webhook payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	sink := v1alpha1.NotificationSink{Name: "webhook", Type: v1alpha1.NotificationSinkWebhook}
+	if err := Send(context.Background(), server.Client(), sink, server.URL, testEvent); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got != testEvent {
+		t.Errorf("Send() payload = %+v, want %+v", got, testEvent)
+	}
+}
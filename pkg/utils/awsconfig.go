@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Keys of the profiles of AWS shared credentials and config files
+const (
+	awsAccessKeyIDKey          = "aws_access_key_id"
+	awsSecretAccessKeyKey      = "aws_secret_access_key"
+	awsSessionTokenKey         = "aws_session_token"
+	awsRoleARNKey              = "role_arn"
+	awsSourceProfileKey        = "source_profile"
+	awsCredentialSourceKey     = "credential_source"
+	awsWebIdentityTokenFileKey = "web_identity_token_file"
+	awsExternalIDKey           = "external_id"
+	awsRoleSessionNameKey      = "role_session_name"
+	awsCredentialProcessKey    = "credential_process"
+	awsSSOSessionKey           = "sso_session"
+	awsSSOStartURLKey          = "sso_start_url"
+	awsSSORegionKey            = "sso_region"
+	awsSSOAccountIDKey         = "sso_account_id"
+	awsSSORoleNameKey          = "sso_role_name"
+	awsRegionKey               = "region"
+)
+
+// awsExpirationKeys are the keys credential helpers write the expiration of temporary keys to
+var awsExpirationKeys = []string{"expiration", "aws_expiration", "aws_session_expiration", "x_security_token_expires"}
+
+// awsCredentialSources are the values of credential_source supported by the AWS SDKs
+var awsCredentialSources = []string{"Environment", "Ec2InstanceMetadata", "EcsContainer"}
+
+// AWSCredentialsSource is where the base credentials of an AWS profile come from
+type AWSCredentialsSource string
+
+const (
+	// AWSCredentialsSourceStatic are the keys of a profile, with a session token for temporary keys
+	AWSCredentialsSourceStatic AWSCredentialsSource = "Static"
+	// AWSCredentialsSourceWebIdentity is a web identity token exchanged for the credentials of a role, like AWS STS on OpenShift
+	AWSCredentialsSourceWebIdentity AWSCredentialsSource = "WebIdentity"
+	// AWSCredentialsSourceCredentialSource are the credentials of the environment, instance or container of the pod
+	AWSCredentialsSourceCredentialSource AWSCredentialsSource = "CredentialSource"
+	// AWSCredentialsSourceProcess are the credentials printed by the command of credential_process
+	AWSCredentialsSourceProcess AWSCredentialsSource = "Process"
+	// AWSCredentialsSourceSSO are the credentials of an IAM Identity Center role, from a cached SSO token
+	AWSCredentialsSourceSSO AWSCredentialsSource = "SSO"
+)
+
+// AWSAssumeRole is a role assumed with the credentials of the profile its profile chains from
+type AWSAssumeRole struct {
+	// Profile that sets the role
+	Profile         string
+	RoleARN         string
+	ExternalID      string
+	RoleSessionName string
+}
+
+// AWSProfileCredentials are the resolved credentials of a profile of an AWS shared config file
+type AWSProfileCredentials struct {
+	// Source of the base credentials
+	Source AWSCredentialsSource
+	// SourceProfile is the profile of the base credentials, the end of the source_profile chain
+	SourceProfile string
+	// AccessKeyID, SecretAccessKey and SessionToken are the keys of static credentials
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expiration of temporary keys, when the credential helper that wrote them sets it
+	Expiration time.Time
+	// WebIdentityTokenFile is the token of web identity credentials, exchanged for the credentials of the first role
+	WebIdentityTokenFile string
+	// CredentialSource is Environment, Ec2InstanceMetadata or EcsContainer
+	CredentialSource string
+	// CredentialProcess is the command printing the credentials of process credentials
+	CredentialProcess string
+	// SSOStartURL, SSORegion, SSOAccountID and SSORoleName are the IAM Identity Center settings of SSO credentials
+	SSOStartURL  string
+	SSORegion    string
+	SSOAccountID string
+	SSORoleName  string
+	// AssumeRoles are the roles assumed in order from the base credentials, the last one is the role of the profile
+	AssumeRoles []AWSAssumeRole
+	// Region of the profile
+	Region string
+}
+
+// HasKeys returns true for static keys used as is, without assuming a role
+func (c AWSProfileCredentials) HasKeys() bool {
+	return c.Source == AWSCredentialsSourceStatic && len(c.AssumeRoles) == 0
+}
+
+// AWSSharedConfig is a parsed AWS shared credentials or config file
+type AWSSharedConfig struct {
+	// profiles are the keys of each profile, by name
+	profiles map[string]map[string]string
+	// ssoSessions are the keys of each sso-session section, by name
+	ssoSessions map[string]map[string]string
+}
+
+// ParseAWSSharedConfig parses the INI content of an AWS shared credentials or config file. Profiles are the [name]
+// sections of a credentials file and the [profile name] sections of a config file, accepted in either file as the
+// credentials files of the locations often mix both.
+func ParseAWSSharedConfig(content []byte) (*AWSSharedConfig, error) {
+	config := &AWSSharedConfig{profiles: map[string]map[string]string{}, ssoSessions: map[string]map[string]string{}}
+	section := func(sections map[string]map[string]string, name string) map[string]string {
+		if sections[name] == nil {
+			sections[name] = map[string]string{}
+		}
+		return sections[name]
+	}
+
+	var current map[string]string
+	// inSubSection is true after a key without value, like s3 =, whose indented keys are a sub-section
+	inSubSection := false
+	for i, rawLine := range strings.Split(string(content), "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: section %s is not closed", i+1, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			inSubSection = false
+			switch {
+			case strings.HasPrefix(name, "sso-session "):
+				current = section(config.ssoSessions, strings.TrimSpace(strings.TrimPrefix(name, "sso-session ")))
+			case strings.HasPrefix(name, "services "):
+				// endpoints of services are not used by the operator
+				current = map[string]string{}
+			default:
+				name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+				if name == "" {
+					return nil, fmt.Errorf("line %d: profile has no name", i+1)
+				}
+				current = section(config.profiles, name)
+			}
+			continue
+		}
+		if inSubSection && (rawLine[0] == ' ' || rawLine[0] == '\t') {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: %q is not a key = value pair", i+1, line)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: key %s is not in a profile", i+1, strings.TrimSpace(key))
+		}
+		value = strings.TrimSpace(value)
+		inSubSection = value == ""
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = strings.TrimSpace(value[1 : len(value)-1])
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return config, nil
+}
+
+// Profiles returns the sorted names of the profiles
+func (c *AWSSharedConfig) Profiles() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the credentials of a profile, following its source_profile chain like the AWS SDKs: the role of a
+// profile is assumed with the credentials of its source profile, and the keys of a source profile end the chain.
+func (c *AWSSharedConfig) Resolve(profile string) (AWSProfileCredentials, error) {
+	creds := AWSProfileCredentials{}
+	if _, ok := c.profiles[profile]; !ok {
+		if len(c.profiles) == 0 {
+			return creds, fmt.Errorf("profile %s not found, the file has no profiles", profile)
+		}
+		return creds, fmt.Errorf("profile %s not found, the file has profiles %s", profile, strings.Join(c.Profiles(), ", "))
+	}
+	creds.Region = c.profiles[profile][awsRegionKey]
+
+	var roles []AWSAssumeRole
+	visited := map[string]bool{}
+	for name := profile; ; {
+		values := c.profiles[name]
+		visited[name] = true
+		hasKeys := values[awsAccessKeyIDKey] != "" || values[awsSecretAccessKeyKey] != ""
+		roleARN := values[awsRoleARNKey]
+		sourceProfile := values[awsSourceProfileKey]
+		// the keys of a source profile are used even when it has a role
+		if name != profile && hasKeys {
+			roleARN = ""
+		}
+		if roleARN != "" {
+			roles = append(roles, AWSAssumeRole{
+				Profile:         name,
+				RoleARN:         roleARN,
+				ExternalID:      values[awsExternalIDKey],
+				RoleSessionName: values[awsRoleSessionNameKey],
+			})
+			switch {
+			case sourceProfile != "" && values[awsCredentialSourceKey] != "":
+				return creds, fmt.Errorf("profile %s sets both source_profile and credential_source", name)
+			case sourceProfile != "" && sourceProfile != name:
+				if visited[sourceProfile] {
+					return creds, fmt.Errorf("source_profile of profile %s loops back to profile %s", name, sourceProfile)
+				}
+				if _, ok := c.profiles[sourceProfile]; !ok {
+					return creds, fmt.Errorf("source_profile %s of profile %s not found", sourceProfile, name)
+				}
+				name = sourceProfile
+				continue
+			case sourceProfile == name:
+				// a profile that is its own source assumes its role with its keys
+			case values[awsWebIdentityTokenFileKey] != "":
+				creds.Source = AWSCredentialsSourceWebIdentity
+				creds.WebIdentityTokenFile = values[awsWebIdentityTokenFileKey]
+			case values[awsCredentialSourceKey] != "":
+				if !slices.Contains(awsCredentialSources, values[awsCredentialSourceKey]) {
+					return creds, fmt.Errorf("credential_source of profile %s must be one of %s", name, strings.Join(awsCredentialSources, ", "))
+				}
+				creds.Source = AWSCredentialsSourceCredentialSource
+				creds.CredentialSource = values[awsCredentialSourceKey]
+			default:
+				return creds, fmt.Errorf("role_arn of profile %s needs source_profile, credential_source or web_identity_token_file", name)
+			}
+		}
+		creds.SourceProfile = name
+		if creds.Source == "" {
+			if err := c.resolveBaseCredentials(name, values, &creds); err != nil {
+				return creds, err
+			}
+		}
+		break
+	}
+	// roles are assumed from the base credentials up to the profile
+	for i := len(roles) - 1; i >= 0; i-- {
+		creds.AssumeRoles = append(creds.AssumeRoles, roles[i])
+	}
+	return creds, nil
+}
+
+// resolveBaseCredentials sets the keys, SSO or process credentials of the profile at the end of a source_profile chain
+func (c *AWSSharedConfig) resolveBaseCredentials(name string, values map[string]string, creds *AWSProfileCredentials) error {
+	switch {
+	case values[awsAccessKeyIDKey] != "" || values[awsSecretAccessKeyKey] != "":
+		if values[awsAccessKeyIDKey] == "" || values[awsSecretAccessKeyKey] == "" {
+			return fmt.Errorf("profile %s needs both aws_access_key_id and aws_secret_access_key", name)
+		}
+		creds.Source = AWSCredentialsSourceStatic
+		creds.AccessKeyID = values[awsAccessKeyIDKey]
+		creds.SecretAccessKey = values[awsSecretAccessKeyKey]
+		creds.SessionToken = values[awsSessionTokenKey]
+		for _, key := range awsExpirationKeys {
+			if value := values[key]; value != "" {
+				expiration, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					return fmt.Errorf("%s of profile %s is not an RFC 3339 time: %w", key, name, err)
+				}
+				creds.Expiration = expiration
+				break
+			}
+		}
+	case values[awsSSOSessionKey] != "" || values[awsSSOStartURLKey] != "":
+		creds.Source = AWSCredentialsSourceSSO
+		creds.SSOStartURL, creds.SSORegion = values[awsSSOStartURLKey], values[awsSSORegionKey]
+		if sessionName := values[awsSSOSessionKey]; sessionName != "" {
+			session, ok := c.ssoSessions[sessionName]
+			if !ok {
+				return fmt.Errorf("sso-session %s of profile %s not found", sessionName, name)
+			}
+			creds.SSOStartURL, creds.SSORegion = session[awsSSOStartURLKey], session[awsSSORegionKey]
+		}
+		creds.SSOAccountID, creds.SSORoleName = values[awsSSOAccountIDKey], values[awsSSORoleNameKey]
+		if creds.SSOStartURL == "" || creds.SSORegion == "" || creds.SSOAccountID == "" || creds.SSORoleName == "" {
+			return fmt.Errorf("profile %s needs sso_start_url, sso_region, sso_account_id and sso_role_name", name)
+		}
+	case values[awsCredentialProcessKey] != "":
+		creds.Source = AWSCredentialsSourceProcess
+		creds.CredentialProcess = values[awsCredentialProcessKey]
+	default:
+		return fmt.Errorf("profile %s has no credentials", name)
+	}
+	return nil
+}
+
+// ResolveAWSCredentials parses an AWS shared credentials or config file and returns the credentials of a profile
+func ResolveAWSCredentials(content []byte, profile string) (AWSProfileCredentials, error) {
+	config, err := ParseAWSSharedConfig(content)
+	if err != nil {
+		return AWSProfileCredentials{}, err
+	}
+	return config.Resolve(profile)
+}
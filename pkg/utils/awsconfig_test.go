@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAWSCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		want    AWSProfileCredentials
+		wantErr string
+	}{
+		{
+			name:    "static keys",
+			content: "[default]\naws_access_key_id = access\naws_secret_access_key = secret\nregion = us-east-1\n",
+			profile: "default",
+			want:    AWSProfileCredentials{Source: AWSCredentialsSourceStatic, SourceProfile: "default", AccessKeyID: "access", SecretAccessKey: "secret", Region: "us-east-1"},
+		},
+		{
+			name: "temporary keys with comments, quotes and upper case keys",
+			content: "# written by a credential helper\n[profile backups]\n; temporary\nAWS_ACCESS_KEY_ID = \"access\"\naws_secret_access_key='secret'\n" +
+				"aws_session_token = token\nx_security_token_expires = 2025-06-01T00:00:00Z\n",
+			profile: "backups",
+			want: AWSProfileCredentials{Source: AWSCredentialsSourceStatic, SourceProfile: "backups", AccessKeyID: "access", SecretAccessKey: "secret",
+				SessionToken: "token", Expiration: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "keys after an unknown key",
+			content: "[default]\ns3 =\n  max_concurrent_requests = 20\naws_access_key_id = access\naws_secret_access_key = secret\n",
+			profile: "default",
+			want:    AWSProfileCredentials{Source: AWSCredentialsSourceStatic, SourceProfile: "default", AccessKeyID: "access", SecretAccessKey: "secret"},
+		},
+		{
+			name: "role chain",
+			content: "[base]\naws_access_key_id = access\naws_secret_access_key = secret\n" +
+				"[profile intermediate]\nrole_arn = arn:aws:iam::111111111111:role/intermediate\nsource_profile = base\nexternal_id = id\n" +
+				"[profile velero]\nrole_arn = arn:aws:iam::222222222222:role/velero\nsource_profile = intermediate\nrole_session_name = velero\n",
+			profile: "velero",
+			want: AWSProfileCredentials{Source: AWSCredentialsSourceStatic, SourceProfile: "base", AccessKeyID: "access", SecretAccessKey: "secret",
+				AssumeRoles: []AWSAssumeRole{
+					{Profile: "intermediate", RoleARN: "arn:aws:iam::111111111111:role/intermediate", ExternalID: "id"},
+					{Profile: "velero", RoleARN: "arn:aws:iam::222222222222:role/velero", RoleSessionName: "velero"},
+				}},
+		},
+		{
+			name:    "role chain loop",
+			content: "[a]\nrole_arn = arn:aws:iam::111111111111:role/a\nsource_profile = b\n[b]\nrole_arn = arn:aws:iam::111111111111:role/b\nsource_profile = a\n",
+			profile: "a",
+			wantErr: "loops back to profile a",
+		},
+		{
+			name:    "web identity",
+			content: "[default]\nrole_arn = arn:aws:iam::111111111111:role/velero\nweb_identity_token_file = /var/run/secrets/openshift/serviceaccount/token\n",
+			profile: "default",
+			want: AWSProfileCredentials{Source: AWSCredentialsSourceWebIdentity, SourceProfile: "default", WebIdentityTokenFile: "/var/run/secrets/openshift/serviceaccount/token",
+				AssumeRoles: []AWSAssumeRole{{Profile: "default", RoleARN: "arn:aws:iam::111111111111:role/velero"}}},
+		},
+		{
+			name:    "credential source",
+			content: "[default]\nrole_arn = arn:aws:iam::111111111111:role/velero\ncredential_source = Ec2InstanceMetadata\n",
+			profile: "default",
+			want: AWSProfileCredentials{Source: AWSCredentialsSourceCredentialSource, SourceProfile: "default", CredentialSource: "Ec2InstanceMetadata",
+				AssumeRoles: []AWSAssumeRole{{Profile: "default", RoleARN: "arn:aws:iam::111111111111:role/velero"}}},
+		},
+		{
+			name:    "unknown credential source",
+			content: "[default]\nrole_arn = arn:aws:iam::111111111111:role/velero\ncredential_source = Kubernetes\n",
+			profile: "default",
+			wantErr: "credential_source of profile default must be one of",
+		},
+		{
+			name:    "role without credentials",
+			content: "[default]\nrole_arn = arn:aws:iam::111111111111:role/velero\n",
+			profile: "default",
+			wantErr: "needs source_profile, credential_source or web_identity_token_file",
+		},
+		{
+			name:    "credential process",
+			content: "[default]\ncredential_process = /usr/bin/credentials-helper --profile velero\n",
+			profile: "default",
+			want:    AWSProfileCredentials{Source: AWSCredentialsSourceProcess, SourceProfile: "default", CredentialProcess: "/usr/bin/credentials-helper --profile velero"},
+		},
+		{
+			name: "SSO session",
+			content: "[profile default]\nsso_session = company\nsso_account_id = 111111111111\nsso_role_name = Backup\n" +
+				"[sso-session company]\nsso_start_url = https://company.awsapps.com/start\nsso_region = us-east-1\n",
+			profile: "default",
+			want: AWSProfileCredentials{Source: AWSCredentialsSourceSSO, SourceProfile: "default", SSOStartURL: "https://company.awsapps.com/start",
+				SSORegion: "us-east-1", SSOAccountID: "111111111111", SSORoleName: "Backup"},
+		},
+		{
+			name:    "missing SSO session",
+			content: "[default]\nsso_session = company\nsso_account_id = 111111111111\nsso_role_name = Backup\n",
+			profile: "default",
+			wantErr: "sso-session company of profile default not found",
+		},
+		{
+			name:    "missing secret key",
+			content: "[default]\naws_access_key_id = access\n",
+			profile: "default",
+			wantErr: "needs both aws_access_key_id and aws_secret_access_key",
+		},
+		{
+			name:    "expiration is not a time",
+			content: "[default]\naws_access_key_id = access\naws_secret_access_key = secret\nexpiration = tomorrow\n",
+			profile: "default",
+			wantErr: "expiration of profile default is not an RFC 3339 time",
+		},
+		{
+			name:    "missing profile",
+			content: "[default]\naws_access_key_id = access\naws_secret_access_key = secret\n[other]\ncredential_process = helper\n",
+			profile: "backups",
+			wantErr: "profile backups not found, the file has profiles default, other",
+		},
+		{
+			name:    "key outside of a profile",
+			content: "aws_access_key_id = access\n[default]\n",
+			profile: "default",
+			wantErr: "line 1: key aws_access_key_id is not in a profile",
+		},
+		{
+			name:    "malformed line",
+			content: "[default]\naws_access_key_id access\n",
+			profile: "default",
+			wantErr: "line 2:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveAWSCredentials([]byte(tt.content), tt.profile)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseAWSCredentials(t *testing.T) {
+	accessKey, secretKey, err := ParseAWSCredentials([]byte("[default]\naws_access_key_id=access\naws_secret_access_key=secret\n"), "default")
+	require.NoError(t, err)
+	require.Equal(t, "access", accessKey)
+	require.Equal(t, "secret", secretKey)
+
+	_, _, err = ParseAWSCredentials([]byte("[default]\ncredential_process = helper\n"), "default")
+	require.ErrorContains(t, err, "profile default has no keys, it uses Process credentials")
+}
@@ -2,9 +2,7 @@ package utils
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -51,62 +49,18 @@ func GetProviderSecret(secretName, namespace string, k8sClient client.Client, ct
 
 // Parse AWS credential content from secret
 func ParseAWSSecret(secret corev1.Secret, secretKey, matchProfile string) (string, string, error) {
-	AWSAccessKey, AWSSecretKey := "", ""
-	profile := ""
-	lines := strings.Split(string(secret.Data[secretKey]), "\n")
-
-	keyNameRegex := regexp.MustCompile(`\[.*\]`)
-	accessKeyKey := "aws_access_key_id"
-	secretKeyKey := "aws_secret_access_key"
-	accessRegex := regexp.MustCompile(`\b` + accessKeyKey + `\b`)
-	secretRegex := regexp.MustCompile(`\b` + secretKeyKey + `\b`)
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		if keyNameRegex.MatchString(line) {
-			// Extract profile name
-			profileName := strings.Trim(line, "[] ")
-			if profileName != matchProfile {
-				continue
-			}
-			profile = profileName
-
-			// Parse credentials
-			for _, profLine := range lines[i+1:] {
-				if strings.TrimSpace(profLine) == "" {
-					continue
-				}
-				if accessRegex.MatchString(profLine) {
-					AWSAccessKey, _ = getMatchedKeyValue(accessKeyKey, profLine)
-				} else if secretRegex.MatchString(profLine) {
-					AWSSecretKey, _ = getMatchedKeyValue(secretKeyKey, profLine)
-				} else {
-					break
-				}
-			}
-			break
-		}
-	}
-
-	if profile == "" || AWSAccessKey == "" || AWSSecretKey == "" {
-		return "", "", errors.New("failed to extract AWS credentials or profile from secret")
-	}
-	return AWSAccessKey, AWSSecretKey, nil
+	return ParseAWSCredentials(secret.Data[secretKey], matchProfile)
 }
 
-// Return value to the right of = sign with quotations and spaces removed.
-func getMatchedKeyValue(key string, s string) (string, error) {
-	for _, removeChar := range []string{"\"", "'", " "} {
-		s = strings.ReplaceAll(s, removeChar, "")
-	}
-	for _, prefix := range []string{key, "="} {
-		s = strings.TrimPrefix(s, prefix)
+// Parse AWS credential content of a credentials file, from a secret or a mounted SecretProviderClass, and return the
+// keys of a profile. Profiles without keys, like roles and SSO, are resolved with ResolveAWSCredentials.
+func ParseAWSCredentials(content []byte, matchProfile string) (string, string, error) {
+	creds, err := ResolveAWSCredentials(content, matchProfile)
+	if err != nil {
+		return "", "", err
 	}
-	if len(s) == 0 {
-		return s, errors.New("secret parsing error in key " + key)
+	if !creds.HasKeys() {
+		return "", "", fmt.Errorf("profile %s has no keys, it uses %s credentials", matchProfile, creds.Source)
 	}
-	return s, nil
+	return creds.AccessKeyID, creds.SecretAccessKey, nil
 }